@@ -43,7 +43,15 @@ type ModelAdapterSpec struct {
 	// +kubebuilder:validation:Required
 	ArtifactURL string `json:"artifactURL,omitempty"`
 
-	// CredentialsSecretRef points to the secret used to authenticate the artifact download requests
+	// ArtifactChecksum verifies the artifact downloaded from s3, gcs or http(s) before it is loaded, as "sha256:<hex>".
+	// It is the sha256 of the file of a single file artifact, otherwise the sha256 of the sha256sum listing of its files
+	// sorted by path.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^sha256:[0-9a-f]{64}$`
+	ArtifactChecksum string `json:"artifactChecksum,omitempty"`
+
+	// CredentialsSecretRef points to the secret used to authenticate the artifact download requests. s3 and gcs use the
+	// keys AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_ENDPOINT_URL and AWS_REGION, http(s) uses HTTP_BEARER_TOKEN.
 	// +optional
 	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
 
@@ -52,11 +60,48 @@ type ModelAdapterSpec struct {
 	// +kubebuilder:default=1
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Autoscaling scales the replicas of the model adapter with its request rate, Replicas is ignored if it is set.
+	// +optional
+	Autoscaling *ModelAdapterAutoscaling `json:"autoscaling,omitempty"`
+
 	// Additional fields can be added here to customize the scheduling and deployment
 	// +optional
 	AdditionalConfig map[string]string `json:"additionalConfig,omitempty"`
 }
 
+// ModelAdapterAutoscaling scales a ModelAdapter with the request rate reported by the gateway
+type ModelAdapterAutoscaling struct {
+	// MinReplicas is the number of replicas of an idle model adapter. With 0, the model adapter is unloaded from all
+	// pods once idle and loaded again on the next request.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit of the number of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetRequestsPerSecond is the request rate served by each replica, e.g. "2.5"
+	// +optional
+	// +kubebuilder:default="1"
+	TargetRequestsPerSecond string `json:"targetRequestsPerSecond,omitempty"`
+	// IdleTimeoutSeconds is how long the model adapter gets no request before it is scaled to MinReplicas
+	// +optional
+	// +kubebuilder:default=300
+	IdleTimeoutSeconds int32 `json:"idleTimeoutSeconds,omitempty"`
+}
+
+// ModelAdapterDemandAnnotationPrefix prefixes the annotations the gateway reports the request rate of an autoscaled
+// ModelAdapter with, one per gateway pod named by the rest of the key. The value is "<unix seconds>,<requests per
+// second>", the time of the report and the request rate since the previous report.
+const ModelAdapterDemandAnnotationPrefix = "demand.adapter.model.aibrix.ai/"
+
+const (
+	// ModelAdapterAPIKeyConfig is the AdditionalConfig key of the api key of the inference engines in plain text.
+	ModelAdapterAPIKeyConfig = "api-key"
+	// ModelAdapterAPIKeySecretConfig is the AdditionalConfig key of the name of the Secret holding the api key of the
+	// inference engines under the key "api-key", it takes precedence over ModelAdapterAPIKeyConfig.
+	ModelAdapterAPIKeySecretConfig = "api-key-secret"
+)
+
 // ModelAdapterPhase is a string representation of the ModelAdapter lifecycle phase.
 type ModelAdapterPhase string
 
@@ -75,8 +120,7 @@ const (
 	ModelAdapterFailed ModelAdapterPhase = "Failed"
 	// ModelAdapterUnknown means ModelAdapter clean up some stable resources
 	ModelAdapterUnknown ModelAdapterPhase = "Unknown"
-	// ModelAdapterScaled means ModelAdapter is scaled, could be scaling in or out. won't be enabled until we allow multiple replicas
-	// TODO: not implemented yet.
+	// ModelAdapterScaled means ModelAdapter is scaled, could be scaling in or out.
 	ModelAdapterScaled ModelAdapterPhase = "Scaled"
 )
 
@@ -94,6 +138,23 @@ type ModelAdapterStatus struct {
 	// Instances lists all pod instances of ModelAdapter
 	// +optional
 	Instances []string `json:"instances,omitempty"`
+	// InstanceStatuses lists whether the ModelAdapter is loaded on each pod of Instances
+	// +optional
+	InstanceStatuses []ModelAdapterInstanceStatus `json:"instanceStatuses,omitempty"`
+	// ReadyReplicas is the number of pods the ModelAdapter is loaded on
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+}
+
+// ModelAdapterInstanceStatus is the state of the ModelAdapter on a pod
+type ModelAdapterInstanceStatus struct {
+	// PodName is the name of the pod
+	PodName string `json:"podName"`
+	// Ready is true once the ModelAdapter is loaded on the pod
+	Ready bool `json:"ready"`
+	// Message tells why the ModelAdapter is not loaded on the pod
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type ModelAdapterConditionType string
@@ -104,6 +165,9 @@ const (
 	ModelAdapterConditionTypeBound           ModelAdapterConditionType = "Bound"
 	ModelAdapterConditionTypeResourceCreated ModelAdapterConditionType = "ResourceCreated"
 	ModelAdapterConditionReady               ModelAdapterConditionType = "Ready"
+	// ModelAdapterConditionTypeArtifactDownloaded tells whether the artifact is downloaded to the pods, it is only set
+	// for artifacts staged to the pods by the runtime sidecar.
+	ModelAdapterConditionTypeArtifactDownloaded ModelAdapterConditionType = "ArtifactDownloaded"
 )
 
 // +genclient
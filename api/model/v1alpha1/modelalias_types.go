@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelAliasStickiness decides how requests are assigned to the backends of a ModelAlias.
+// +kubebuilder:validation:Enum=None;User;Header
+type ModelAliasStickiness string
+
+const (
+	// ModelAliasStickinessNone assigns each request to a backend at random by weight.
+	ModelAliasStickinessNone ModelAliasStickiness = "None"
+	// ModelAliasStickinessUser assigns the requests of a user to the same backend.
+	ModelAliasStickinessUser ModelAliasStickiness = "User"
+	// ModelAliasStickinessHeader assigns the requests with the same value of StickyHeader to the same backend.
+	ModelAliasStickinessHeader ModelAliasStickiness = "Header"
+)
+
+// ModelAliasBackend is a model serving the requests of an alias.
+type ModelAliasBackend struct {
+	// Model is the name of the backend model, i.e. the model.aibrix.ai/name label of its pods.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Model string `json:"model"`
+
+	// Weight is the share of the requests of the alias relative to the other backends.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// ModelAliasSpec defines the desired state of ModelAlias
+type ModelAliasSpec struct {
+	// Name is the model name requested by clients, defaults to the name of the ModelAlias.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Backends are the models the requests are split across by weight.
+	// +kubebuilder:validation:MinItems=1
+	Backends []ModelAliasBackend `json:"backends"`
+
+	// Stickiness assigns the requests of a user or a session to the same backend, defaults to None.
+	// Requests without the user or the header are assigned at random.
+	// +kubebuilder:default=None
+	// +optional
+	Stickiness ModelAliasStickiness `json:"stickiness,omitempty"`
+
+	// StickyHeader is the request header keying the assignment if Stickiness is Header, e.g. x-session-id.
+	// +optional
+	StickyHeader string `json:"stickyHeader,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Alias",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Stickiness",type=string,JSONPath=`.spec.stickiness`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ModelAlias is the Schema for the modelaliases API.
+// It maps a model name requested by clients to backend models, e.g. for canaries across model versions.
+type ModelAlias struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModelAliasSpec `json:"spec,omitempty"`
+}
+
+// AliasName returns the model name requested by clients.
+func (a *ModelAlias) AliasName() string {
+	if a.Spec.Name != "" {
+		return a.Spec.Name
+	}
+	return a.Name
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ModelAliasList contains a list of ModelAlias
+type ModelAliasList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelAlias `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelAlias{}, &ModelAliasList{})
+}
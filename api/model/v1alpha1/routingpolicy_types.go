@@ -0,0 +1,104 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RoutingStrategy is a routing algorithm of the gateway with its parameters.
+type RoutingStrategy struct {
+	// Algorithm is the name of the routing algorithm, e.g. random, least-request or prefix-cache.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Algorithm string `json:"algorithm"`
+
+	// Parameters of the algorithm, e.g. standardDeviationFactor of prefix-cache.
+	// Parameters not set use the defaults of the gateway.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ShadowTraffic mirrors a share of the requests of a model to a shadow model, e.g. a candidate version to evaluate.
+// The responses of the shadow model are recorded and never returned to clients.
+type ShadowTraffic struct {
+	// Model is the name of the shadow model.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Model string `json:"model"`
+
+	// Percentage of the requests mirrored to the shadow model.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percentage int32 `json:"percentage"`
+}
+
+// RoutingPolicySpec defines the desired state of RoutingPolicy
+type RoutingPolicySpec struct {
+	// Models selects models by name.
+	// +optional
+	Models []string `json:"models,omitempty"`
+
+	// ModelSelector selects models by the labels of their pods.
+	// +optional
+	ModelSelector *metav1.LabelSelector `json:"modelSelector,omitempty"`
+
+	// Priority decides the policy of a model selected by multiple policies, the highest priority wins.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// RoutingStrategy is the routing algorithm of the selected models.
+	RoutingStrategy `json:",inline"`
+
+	// Fallbacks are tried in order if the algorithm fails to route a request.
+	// +optional
+	Fallbacks []RoutingStrategy `json:"fallbacks,omitempty"`
+
+	// Shadow mirrors a share of the requests of the selected models to a shadow model.
+	// +optional
+	Shadow *ShadowTraffic `json:"shadow,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.spec.algorithm`
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RoutingPolicy is the Schema for the routingpolicies API.
+// It configures how the gateway routes the requests of the selected models.
+type RoutingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RoutingPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RoutingPolicyList contains a list of RoutingPolicy
+type RoutingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RoutingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RoutingPolicy{}, &RoutingPolicyList{})
+}
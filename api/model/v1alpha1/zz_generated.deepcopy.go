@@ -53,6 +53,36 @@ func (in *ModelAdapter) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAdapterAutoscaling) DeepCopyInto(out *ModelAdapterAutoscaling) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterAutoscaling.
+func (in *ModelAdapterAutoscaling) DeepCopy() *ModelAdapterAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAdapterAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAdapterInstanceStatus) DeepCopyInto(out *ModelAdapterInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterInstanceStatus.
+func (in *ModelAdapterInstanceStatus) DeepCopy() *ModelAdapterInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAdapterInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModelAdapterList) DeepCopyInto(out *ModelAdapterList) {
 	*out = *in
@@ -108,6 +138,11 @@ func (in *ModelAdapterSpec) DeepCopyInto(out *ModelAdapterSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(ModelAdapterAutoscaling)
+		**out = **in
+	}
 	if in.AdditionalConfig != nil {
 		in, out := &in.AdditionalConfig, &out.AdditionalConfig
 		*out = make(map[string]string, len(*in))
@@ -142,6 +177,11 @@ func (in *ModelAdapterStatus) DeepCopyInto(out *ModelAdapterStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.InstanceStatuses != nil {
+		in, out := &in.InstanceStatuses, &out.InstanceStatuses
+		*out = make([]ModelAdapterInstanceStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterStatus.
@@ -153,3 +193,229 @@ func (in *ModelAdapterStatus) DeepCopy() *ModelAdapterStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAlias) DeepCopyInto(out *ModelAlias) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAlias.
+func (in *ModelAlias) DeepCopy() *ModelAlias {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAlias)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelAlias) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAliasBackend) DeepCopyInto(out *ModelAliasBackend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAliasBackend.
+func (in *ModelAliasBackend) DeepCopy() *ModelAliasBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAliasBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAliasList) DeepCopyInto(out *ModelAliasList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModelAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAliasList.
+func (in *ModelAliasList) DeepCopy() *ModelAliasList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAliasList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelAliasList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAliasSpec) DeepCopyInto(out *ModelAliasSpec) {
+	*out = *in
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]ModelAliasBackend, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAliasSpec.
+func (in *ModelAliasSpec) DeepCopy() *ModelAliasSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAliasSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingPolicy) DeepCopyInto(out *RoutingPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingPolicy.
+func (in *RoutingPolicy) DeepCopy() *RoutingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RoutingPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingPolicyList) DeepCopyInto(out *RoutingPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RoutingPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingPolicyList.
+func (in *RoutingPolicyList) DeepCopy() *RoutingPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RoutingPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingPolicySpec) DeepCopyInto(out *RoutingPolicySpec) {
+	*out = *in
+	if in.Models != nil {
+		in, out := &in.Models, &out.Models
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ModelSelector != nil {
+		in, out := &in.ModelSelector, &out.ModelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.RoutingStrategy.DeepCopyInto(&out.RoutingStrategy)
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]RoutingStrategy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Shadow != nil {
+		in, out := &in.Shadow, &out.Shadow
+		*out = new(ShadowTraffic)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingPolicySpec.
+func (in *RoutingPolicySpec) DeepCopy() *RoutingPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingStrategy) DeepCopyInto(out *RoutingStrategy) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingStrategy.
+func (in *RoutingStrategy) DeepCopy() *RoutingStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowTraffic) DeepCopyInto(out *ShadowTraffic) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowTraffic.
+func (in *ShadowTraffic) DeepCopy() *ShadowTraffic {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowTraffic)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -32,8 +32,11 @@ import (
 	"k8s.io/klog/v2"
 
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/vllm-project/aibrix/pkg/cache"
+	aibrixversioned "github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
 	"github.com/vllm-project/aibrix/pkg/plugins/gateway"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
 	"github.com/vllm-project/aibrix/pkg/utils"
 	"google.golang.org/grpc/health"
 	healthPb "google.golang.org/grpc/health/grpc_health_v1"
@@ -41,11 +44,13 @@ import (
 )
 
 var (
-	grpc_port int
+	grpc_port    int
+	metrics_port int
 )
 
 func main() {
 	flag.IntVar(&grpc_port, "port", 50052, "gRPC port")
+	flag.IntVar(&metrics_port, "metrics-port", 8080, "Prometheus metrics port")
 	klog.InitFlags(flag.CommandLine)
 	defer klog.Flush()
 	flag.Parse()
@@ -95,8 +100,25 @@ func main() {
 		klog.Fatalf("Error on creating gateway k8s client: %v", err)
 	}
 
+	aibrixClient, err := aibrixversioned.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("Error on creating aibrix k8s client: %v", err)
+	}
+
 	s := grpc.NewServer()
-	extProcPb.RegisterExternalProcessorServer(s, gateway.NewServer(redisClient, k8sClient, gatewayK8sClient))
+	gatewayServer := gateway.NewServer(redisClient, k8sClient, gatewayK8sClient)
+	extProcPb.RegisterExternalProcessorServer(s, gatewayServer)
+
+	// Routers are initialized by the gateway server, routing policies are watched after.
+	if err := routing.WatchRoutingPolicies(aibrixClient, stopCh); err != nil {
+		klog.Fatalf("Error on watching routing policies: %v", err)
+	}
+	if err := gateway.WatchModelAliases(aibrixClient, stopCh); err != nil {
+		klog.Fatalf("Error on watching model aliases: %v", err)
+	}
+	if err := gateway.ReportModelAdapterDemand(aibrixClient, stopCh); err != nil {
+		klog.Fatalf("Error on reporting model adapter demand: %v", err)
+	}
 
 	healthCheck := health.NewServer()
 	healthPb.RegisterHealthServer(s, healthCheck)
@@ -110,6 +132,15 @@ func main() {
 		}
 	}()
 
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		klog.Infof("starting Prometheus metrics server on :%d", metrics_port)
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", metrics_port), mux); err != nil {
+			klog.Fatalf("failed to start Prometheus metrics server: %v", err)
+		}
+	}()
+
 	// shutdown
 	var gracefulStop = make(chan os.Signal, 1)
 	signal.Notify(gracefulStop, syscall.SIGINT, syscall.SIGTERM)
@@ -117,6 +148,7 @@ func main() {
 		sig := <-gracefulStop
 		klog.Warningf("signal received: %v, initiating graceful shutdown...", sig)
 		s.GracefulStop()
+		gatewayServer.Close()
 		os.Exit(0)
 	}()
 
@@ -19,7 +19,9 @@ package cache
 import (
 	"fmt"
 	"sync/atomic"
+	"time"
 
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/metrics"
 	"github.com/vllm-project/aibrix/pkg/types"
 	"github.com/vllm-project/aibrix/pkg/utils"
@@ -80,6 +82,42 @@ func (c *Store) ListPodsByModel(modelName string) (types.PodList, error) {
 	return meta.Pods.Array(), nil
 }
 
+// MarkPodUnhealthy excludes a Pod from routing until the cooldown expires
+// Parameters:
+//
+//	podName: Name of the Pod
+//	podNamespace: Namespace of the Pod
+//	cooldown: Duration the Pod is excluded from routing
+func (c *Store) MarkPodUnhealthy(podName, podNamespace string, cooldown time.Duration) {
+	key := utils.GeneratePodKey(podNamespace, podName)
+	metaPod, ok := c.metaPods.Load(key)
+	if !ok {
+		return
+	}
+
+	atomic.StoreInt64(&metaPod.unhealthyUntil, time.Now().Add(cooldown).UnixNano())
+	klog.InfoS("pod marked unhealthy", "pod", key, "cooldown", cooldown)
+}
+
+// IsPodHealthy checks if a Pod is out of its unhealthy cooldown
+// Parameters:
+//
+//	podName: Name of the Pod
+//	podNamespace: Namespace of the Pod
+//
+// Returns:
+//
+//	bool: False if the Pod is in an unhealthy cooldown
+func (c *Store) IsPodHealthy(podName, podNamespace string) bool {
+	key := utils.GeneratePodKey(podNamespace, podName)
+	metaPod, ok := c.metaPods.Load(key)
+	if !ok {
+		return true
+	}
+
+	return time.Now().UnixNano() >= atomic.LoadInt64(&metaPod.unhealthyUntil)
+}
+
 // ListModels returns all cached model names
 // Returns:
 //
@@ -102,6 +140,19 @@ func (c *Store) HasModel(modelName string) bool {
 	return ok
 }
 
+// GetModelAdapter gets the ModelAdapter of a LoRA adapter model
+// Parameters:
+//
+//	modelName: Name of the adapter
+//
+// Returns:
+//
+//	*modelv1alpha1.ModelAdapter: The ModelAdapter object
+//	bool: False if the model is not a LoRA adapter
+func (c *Store) GetModelAdapter(modelName string) (*modelv1alpha1.ModelAdapter, bool) {
+	return c.modelAdapters.Load(modelName)
+}
+
 // ListModelsByPod gets models associated with a specific Pod
 // Parameters:
 //
@@ -162,6 +213,9 @@ func (c *Store) GetMetricValueByPodModel(podName, podNamespace, modelName string
 		return nil, fmt.Errorf("key does not exist in the cache: %s", key)
 	}
 
+	if _, ok := gatewayLatencyMetrics[metricName]; ok {
+		return c.getGatewayLatencyMetric(metaPod, modelName, metricName)
+	}
 	return c.getPodMetricImpl(podName, &metaPod.ModelMetrics, c.getPodModelMetricName(modelName, metricName))
 }
 
@@ -254,6 +308,62 @@ func (c *Store) DoneRequestTrace(ctx *types.RoutingContext, requestID string, mo
 	}
 }
 
+// AddShadowRequestCount starts tracking a shadow request
+// Parameters:
+//
+//	ctx: Routing context
+//	requestID: Unique request identifier
+func (c *Store) AddShadowRequestCount(ctx *types.RoutingContext, requestID string) {
+	c.addPodStats(ctx, requestID)
+	if meta, ok := c.metaModels.Load(ctx.Model); ok {
+		setShadowRequestsRunning(ctx.Model, atomic.AddInt32(&meta.shadowRequests, 1))
+	}
+}
+
+// DoneShadowRequestCount completes shadow request tracking
+// Parameters:
+//
+//	ctx: Routing context
+//	requestID: Unique request identifier
+func (c *Store) DoneShadowRequestCount(ctx *types.RoutingContext, requestID string) {
+	c.donePodStats(ctx, requestID)
+	if meta, ok := c.metaModels.Load(ctx.Model); ok {
+		setShadowRequestsRunning(ctx.Model, atomic.AddInt32(&meta.shadowRequests, -1))
+	}
+}
+
+// AddPrefillRequestCount starts tracking the prefill of a request on its prefill pod
+// Parameters:
+//
+//	ctx: Routing context
+//	requestID: Unique request identifier
+func (c *Store) AddPrefillRequestCount(ctx *types.RoutingContext, requestID string) {
+	if pod := ctx.PrefillPod(); pod != nil {
+		c.updatePodRunningRequests(pod, ctx.Model, requestID, 1)
+	}
+}
+
+// DonePrefillRequestCount completes tracking the prefill of a request on its prefill pod
+// Parameters:
+//
+//	ctx: Routing context
+//	requestID: Unique request identifier
+func (c *Store) DonePrefillRequestCount(ctx *types.RoutingContext, requestID string) {
+	if pod := ctx.PrefillPod(); pod != nil {
+		c.updatePodRunningRequests(pod, ctx.Model, requestID, -1)
+	}
+}
+
+func setShadowRequestsRunning(model string, requests int32) {
+	metrics.SetGaugeMetric(
+		metrics.GatewayShadowRequestsRunning,
+		metrics.GetMetricHelp(metrics.GatewayShadowRequestsRunning),
+		float64(requests),
+		[]string{"model"},
+		model,
+	)
+}
+
 // AddSubscriber registers new metric subscriber
 // Parameters:
 //
@@ -28,6 +28,7 @@ import (
 	"k8s.io/klog/v2"
 
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/metrics"
 	"github.com/vllm-project/aibrix/pkg/utils"
 )
@@ -56,6 +57,9 @@ type Store struct {
 	// Mapping relationships
 	metaModels utils.SyncMap[string, *Model] // model_name -> *Model
 
+	// LoRA adapters
+	modelAdapters utils.SyncMap[string, *modelv1alpha1.ModelAdapter] // model_name -> *ModelAdapter
+
 	// buffer for sync map operations
 	bufferPod   *Pod
 	bufferModel *Model
@@ -127,7 +131,11 @@ func NewTestCacheWithPodsMetrics(pods []*v1.Pod, model string, podMetrics map[st
 		}
 		if podmetrics, ok := podMetrics[podName]; ok {
 			for metricName, metric := range podmetrics {
-				if err := c.updatePodRecord(metaPod, model, metricName, metrics.PodMetricScope, metric); err != nil {
+				scope := metrics.PodMetricScope
+				if registered, ok := metrics.Metrics[metricName]; ok {
+					scope = registered.MetricScope
+				}
+				if err := c.updatePodRecord(metaPod, model, metricName, scope, metric); err != nil {
 					return false
 				}
 			}
@@ -137,6 +145,18 @@ func NewTestCacheWithPodsMetrics(pods []*v1.Pod, model string, podMetrics map[st
 	return c
 }
 
+// NewTestCacheWithModelAdapters creates a cache of the pods labeled with their models, and the ModelAdapters loaded to the pods.
+func NewTestCacheWithModelAdapters(pods []*v1.Pod, adapters []*modelv1alpha1.ModelAdapter) *Store {
+	c := &Store{}
+	for _, pod := range pods {
+		c.addPod(pod)
+	}
+	for _, adapter := range adapters {
+		c.addModelAdapter(adapter)
+	}
+	return c
+}
+
 // InitForTest initializes the cache store for testing purposes
 func InitForTest() *Store {
 	once.Do(func() {
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	latencyKindTTFT = "ttft"
+	latencyKindTPOT = "tpot"
+)
+
+var (
+	// latencyWindowSize is how long a latency sample measured by the gateway is kept.
+	latencyWindowSize = time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_LATENCY_WINDOW_SECONDS", 60)) * time.Second
+	// latencyWindowMaxSamples bounds the samples kept per pod and model, the oldest are dropped first.
+	latencyWindowMaxSamples = utils.LoadEnvInt("AIBRIX_GATEWAY_LATENCY_WINDOW_MAX_SAMPLES", 256)
+
+	// gatewayLatencyMetrics maps the gateway observed metrics to the latency they are computed from.
+	gatewayLatencyMetrics = map[string]struct {
+		kind string
+		stat func(sorted []float64) float64
+	}{
+		metrics.GatewayAvgTTFT: {latencyKindTTFT, mean},
+		metrics.GatewayP50TTFT: {latencyKindTTFT, percentile(0.5)},
+		metrics.GatewayP95TTFT: {latencyKindTTFT, percentile(0.95)},
+		metrics.GatewayAvgTPOT: {latencyKindTPOT, mean},
+		metrics.GatewayP50TPOT: {latencyKindTPOT, percentile(0.5)},
+		metrics.GatewayP95TPOT: {latencyKindTPOT, percentile(0.95)},
+	}
+)
+
+type latencySample struct {
+	at    time.Time
+	value float64
+}
+
+// latencyWindow keeps the latency samples of the last window, up to maxSamples.
+type latencyWindow struct {
+	mu         sync.Mutex
+	size       time.Duration
+	maxSamples int
+	samples    []latencySample // Ordered by time.
+	sorted     []float64       // Sorted values of samples, nil if outdated.
+}
+
+func newLatencyWindow(size time.Duration, maxSamples int) *latencyWindow {
+	return &latencyWindow{size: size, maxSamples: max(maxSamples, 1)}
+}
+
+func (w *latencyWindow) add(now time.Time, value float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.expireLocked(now)
+	if len(w.samples) >= w.maxSamples {
+		w.samples = w.samples[len(w.samples)-w.maxSamples+1:]
+	}
+	w.samples = append(w.samples, latencySample{at: now, value: value})
+	w.sorted = nil
+}
+
+// stat computes the statistic over the samples in the window, false if the window is empty.
+func (w *latencyWindow) stat(now time.Time, stat func(sorted []float64) float64) (float64, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.expireLocked(now)
+	if len(w.samples) == 0 {
+		return 0, false
+	}
+	if w.sorted == nil {
+		w.sorted = make([]float64, len(w.samples))
+		for i, sample := range w.samples {
+			w.sorted[i] = sample.value
+		}
+		sort.Float64s(w.sorted)
+	}
+	return stat(w.sorted), true
+}
+
+func (w *latencyWindow) expireLocked(now time.Time) {
+	expired := sort.Search(len(w.samples), func(i int) bool {
+		return now.Sub(w.samples[i].at) <= w.size
+	})
+	if expired > 0 {
+		w.samples = w.samples[expired:]
+		w.sorted = nil
+	}
+}
+
+func mean(sorted []float64) float64 {
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+	return sum / float64(len(sorted))
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(p float64) func(sorted []float64) float64 {
+	return func(sorted []float64) float64 {
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(rank, 0)]
+	}
+}
+
+// ObserveTTFT records the time to first token of a request on its target pod.
+func (c *Store) ObserveTTFT(ctx *types.RoutingContext, ttft time.Duration) {
+	c.observeLatency(ctx, latencyKindTTFT, ttft)
+}
+
+// ObserveTPOT records the time per output token of a request on its target pod.
+func (c *Store) ObserveTPOT(ctx *types.RoutingContext, tpot time.Duration) {
+	c.observeLatency(ctx, latencyKindTPOT, tpot)
+}
+
+func (c *Store) observeLatency(ctx *types.RoutingContext, kind string, latency time.Duration) {
+	if ctx == nil || !ctx.HasRouted() {
+		return
+	}
+	pod := ctx.TargetPod()
+	metaPod, ok := c.metaPods.Load(utils.GeneratePodKey(pod.Namespace, pod.Name))
+	if !ok {
+		klog.Warningf("can't find routing pod: %s, requestID: %s", pod.Name, ctx.RequestID)
+		return
+	}
+	key := c.getPodModelMetricName(ctx.Model, kind)
+	window, ok := metaPod.latencyWindows.Load(key)
+	if !ok {
+		window, _ = metaPod.latencyWindows.LoadOrStore(key, newLatencyWindow(latencyWindowSize, latencyWindowMaxSamples))
+	}
+	window.add(time.Now(), latency.Seconds())
+}
+
+// getGatewayLatencyMetric computes a gateway observed metric of a model on the pod.
+func (c *Store) getGatewayLatencyMetric(metaPod *Pod, modelName, metricName string) (metrics.MetricValue, error) {
+	latencyMetric := gatewayLatencyMetrics[metricName]
+	window, ok := metaPod.latencyWindows.Load(c.getPodModelMetricName(modelName, latencyMetric.kind))
+	if !ok {
+		return nil, fmt.Errorf("no metric available for %s - %v", metaPod.Name, metricName)
+	}
+	value, ok := window.stat(time.Now(), latencyMetric.stat)
+	if !ok {
+		return nil, fmt.Errorf("no metric available for %s - %v in the last %v", metaPod.Name, metricName, window.size)
+	}
+	return &metrics.SimpleMetricValue{Value: value}, nil
+}
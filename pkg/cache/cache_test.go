@@ -16,6 +16,7 @@ limitations under the License.
 package cache
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand"
@@ -23,11 +24,13 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
 	"github.com/vllm-project/aibrix/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -68,22 +71,15 @@ func getNewPod(podName, podNamespace string, modelName string, id int) *v1.Pod {
 }
 
 func getNewModelAdapter(modelName, namespace string, podName string) *modelv1alpha1.ModelAdapter {
-	adapter := &modelv1alpha1.ModelAdapter{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      modelName,
-			Namespace: namespace,
-		},
-		Status: modelv1alpha1.ModelAdapterStatus{
-			Instances: []string{podName},
-		},
-	}
 	if podName == "" {
-		adapter.Status.Instances = nil
+		return getNewModelAdapterWithPods(modelName, namespace, nil)
 	}
-	return adapter
+	return getNewModelAdapterWithPods(modelName, namespace, []string{podName})
 }
 
-func getNewModelAdapterWithPods(modelName, namespace string, podNames []string) *modelv1alpha1.ModelAdapter {
+// getNewModelAdapterWithPods returns a ModelAdapter loaded on the pods, and scheduled but not loaded on the
+// unreadyPods if set.
+func getNewModelAdapterWithPods(modelName, namespace string, podNames []string, unreadyPods ...string) *modelv1alpha1.ModelAdapter {
 	adapter := &modelv1alpha1.ModelAdapter{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      modelName,
@@ -93,6 +89,15 @@ func getNewModelAdapterWithPods(modelName, namespace string, podNames []string)
 			Instances: podNames,
 		},
 	}
+	for _, podName := range podNames {
+		adapter.Status.InstanceStatuses = append(adapter.Status.InstanceStatuses,
+			modelv1alpha1.ModelAdapterInstanceStatus{PodName: podName, Ready: true})
+	}
+	for _, podName := range unreadyPods {
+		adapter.Status.Instances = append(adapter.Status.Instances, podName)
+		adapter.Status.InstanceStatuses = append(adapter.Status.InstanceStatuses,
+			modelv1alpha1.ModelAdapterInstanceStatus{PodName: podName, Message: "downloading"})
+	}
 	return adapter
 }
 
@@ -356,6 +361,35 @@ var _ = Describe("Cache", func() {
 		Expect(exist).To(BeFalse())
 	})
 
+	It("should addModelAdapter map only the pods the adapter is loaded on", func() {
+		cache := newCache()
+		cache.addPod(getReadyPod("p1", "default", "m1", 0))
+		cache.addPod(getReadyPod("p2", "default", "m1", 0))
+		cache.addPod(getReadyPod("p3", "default", "m1", 0))
+		adapter := getNewModelAdapterWithPods("m1adapter1", "default", []string{"p1"}, "p2", "p3")
+		cache.addModelAdapter(adapter)
+
+		pods, err := cache.ListPodsByModel("m1adapter1")
+		Expect(err).To(BeNil())
+		Expect(pods.Len()).To(Equal(1))
+		Expect(pods.All()[0].Name).To(Equal("p1"))
+
+		// The adapter becomes loaded on p2, and fails to load on p3.
+		loaded := getNewModelAdapterWithPods("m1adapter1", "default", []string{"p1", "p2"}, "p3")
+		cache.updateModelAdapter(adapter, loaded)
+		pods, err = cache.ListPodsByModel("m1adapter1")
+		Expect(err).To(BeNil())
+		Expect(pods.Len()).To(Equal(2))
+		p3MetaPod, exist := cache.metaPods.Load("default/p3")
+		Expect(exist).To(BeTrue())
+		_, exist = p3MetaPod.Models.Load("m1adapter1")
+		Expect(exist).To(BeFalse())
+
+		cache.deleteModelAdapter(loaded)
+		_, exist = cache.metaModels.Load("m1adapter1")
+		Expect(exist).To(BeFalse())
+	})
+
 	It("should updateModelAdapter reset mappings", func() {
 		cache := newCache()
 		cache.addPod(getReadyPod("p1", "default", "m1", 0))
@@ -426,6 +460,21 @@ var _ = Describe("Cache", func() {
 		Expect(actual).To(BeIdenticalTo(pod))
 	})
 
+	It("should MarkPodUnhealthy exclude pod until cooldown expires", func() {
+		cache := newCache()
+		pod := getReadyPod("p1", "default", "m1", 0)
+		cache.addPod(pod)
+
+		Expect(cache.IsPodHealthy(pod.Name, pod.Namespace)).To(BeTrue())
+		Expect(cache.IsPodHealthy("p0", "default")).To(BeTrue()) // unknown pod
+
+		cache.MarkPodUnhealthy(pod.Name, pod.Namespace, time.Hour)
+		Expect(cache.IsPodHealthy(pod.Name, pod.Namespace)).To(BeFalse())
+
+		cache.MarkPodUnhealthy(pod.Name, pod.Namespace, 0)
+		Expect(cache.IsPodHealthy(pod.Name, pod.Namespace)).To(BeTrue())
+	})
+
 	It("should GetPods return k8s pod slice", func() {
 		cache := newCache()
 		pod1 := getReadyPod("p1", "default", "m1", 0)
@@ -524,6 +573,103 @@ var _ = Describe("Cache", func() {
 		Expect(*pProfileCounter.(*int32)).To(Equal(int32(1)))
 	})
 
+	It("should shadow request count to the pod but not to the model trace", func() {
+		modelName := "llama-7b"
+		cache := newTraceCache()
+		pod := getReadyPod("p1", "default", modelName, 0)
+		cache.AddPod(pod)
+
+		ctx := types.NewRoutingContext(context.Background(), "random", modelName, "", "r1-shadow", "")
+		ctx.SetTargetPod(pod)
+		cache.AddShadowRequestCount(ctx, ctx.RequestID)
+		running, err := cache.GetMetricValueByPod("p1", "default", metrics.RealtimeNumRequestsRunning)
+		Expect(err).To(BeNil())
+		Expect(running.GetSimpleValue()).To(Equal(1.0))
+		meta, exist := cache.metaModels.Load(modelName)
+		Expect(exist).To(BeTrue())
+		Expect(meta.pendingRequests).To(Equal(int32(0)))
+		Expect(meta.shadowRequests).To(Equal(int32(1)))
+		Expect(cache.numRequestsTraces).To(Equal(int32(0)))
+
+		cache.DoneShadowRequestCount(ctx, ctx.RequestID)
+		running, err = cache.GetMetricValueByPod("p1", "default", metrics.RealtimeNumRequestsRunning)
+		Expect(err).To(BeNil())
+		Expect(running.GetSimpleValue()).To(Equal(0.0))
+		Expect(meta.shadowRequests).To(Equal(int32(0)))
+	})
+
+	It("should count the prefill of a disaggregated request to its prefill pod", func() {
+		modelName := "llama-7b"
+		cache := newTraceCache()
+		prefill := getReadyPod("p1", "default", modelName, 0)
+		decode := getReadyPod("p2", "default", modelName, 1)
+		cache.AddPod(prefill)
+		cache.AddPod(decode)
+
+		ctx := types.NewRoutingContext(context.Background(), "pd-disagg", modelName, "", "r1", "")
+		ctx.SetPrefillPod(prefill)
+		ctx.SetTargetPod(decode)
+		cache.AddPrefillRequestCount(ctx, ctx.RequestID)
+		running, err := cache.GetMetricValueByPod("p1", "default", metrics.RealtimeNumRequestsRunning)
+		Expect(err).To(BeNil())
+		Expect(running.GetSimpleValue()).To(Equal(1.0))
+		_, err = cache.GetMetricValueByPod("p2", "default", metrics.RealtimeNumRequestsRunning)
+		Expect(err).NotTo(BeNil())
+
+		cache.DonePrefillRequestCount(ctx, ctx.RequestID)
+		running, err = cache.GetMetricValueByPod("p1", "default", metrics.RealtimeNumRequestsRunning)
+		Expect(err).To(BeNil())
+		Expect(running.GetSimpleValue()).To(Equal(0.0))
+	})
+
+	It("should gateway observed latency be computed over the window", func() {
+		modelName := "llama-7b"
+		cache := newCache()
+		pod := getReadyPod("p1", "default", modelName, 0)
+		cache.AddPod(pod)
+
+		_, err := cache.GetMetricValueByPodModel("p1", "default", modelName, metrics.GatewayAvgTTFT)
+		Expect(err).ToNot(BeNil())
+
+		ctx := types.NewRoutingContext(context.Background(), "random", modelName, "", "r1", "")
+		cache.ObserveTTFT(ctx, time.Second) // Not routed, ignored.
+		ctx.SetTargetPod(pod)
+		for i := 1; i <= 4; i++ {
+			cache.ObserveTTFT(ctx, time.Duration(i)*100*time.Millisecond)
+		}
+		cache.ObserveTPOT(ctx, 20*time.Millisecond)
+
+		for metricName, expected := range map[string]float64{
+			metrics.GatewayAvgTTFT: 0.25,
+			metrics.GatewayP50TTFT: 0.2,
+			metrics.GatewayP95TTFT: 0.4,
+			metrics.GatewayAvgTPOT: 0.02,
+		} {
+			value, err := cache.GetMetricValueByPodModel("p1", "default", modelName, metricName)
+			Expect(err).To(BeNil())
+			Expect(value.GetSimpleValue()).To(BeNumerically("~", expected, 1e-9), metricName)
+		}
+	})
+
+	It("should latency window drop expired and exceeding samples", func() {
+		now := time.Now()
+		window := newLatencyWindow(time.Minute, 3)
+		window.add(now.Add(-2*time.Minute), 10)
+		window.add(now.Add(-time.Second), 1)
+		window.add(now, 2)
+		value, ok := window.stat(now, mean)
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(1.5))
+
+		window.add(now, 3)
+		window.add(now, 4)
+		value, _ = window.stat(now, percentile(0.5))
+		Expect(value).To(Equal(3.0))
+
+		_, ok = window.stat(now.Add(2*time.Minute), mean)
+		Expect(ok).To(BeFalse())
+	})
+
 	It("should global pending counter return 0.", func() {
 		modelName := "llama-7b"
 		cache := newTraceCache()
@@ -25,6 +25,7 @@ import (
 	"github.com/vllm-project/aibrix/pkg/metrics"
 	"github.com/vllm-project/aibrix/pkg/types"
 	"github.com/vllm-project/aibrix/pkg/utils"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -47,25 +48,17 @@ func (c *Store) addPodStats(ctx *types.RoutingContext, requestID string) {
 	if !ctx.HasRouted() {
 		return
 	}
-	pod := ctx.TargetPod()
-	key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
-	metaPod, ok := c.metaPods.Load(key)
-	if !ok {
-		klog.Warningf("can't find routing pod: %s, requestID: %s", pod.Name, requestID)
-		return
-	}
-	requests := atomic.AddInt32(&metaPod.runningRequests, 1)
-	if err := c.updatePodRecord(metaPod, ctx.Model, metrics.RealtimeNumRequestsRunning, metrics.PodMetricScope, &metrics.SimpleMetricValue{Value: float64(requests)}); err != nil {
-		klog.Warningf("can't update realtime metric: %s, pod: %s, requestID: %s", metrics.RealtimeNumRequestsRunning, pod.Name, requestID)
-	}
+	c.updatePodRunningRequests(ctx.TargetPod(), ctx.Model, requestID, 1)
 }
 
 func (c *Store) donePodStats(ctx *types.RoutingContext, requestID string) {
 	if !ctx.HasRouted() {
 		return
 	}
-	pod := ctx.TargetPod()
+	c.updatePodRunningRequests(ctx.TargetPod(), ctx.Model, requestID, -1)
+}
 
+func (c *Store) updatePodRunningRequests(pod *v1.Pod, model string, requestID string, delta int32) {
 	// Now that pendingLoadProvider must be set.
 	key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
 	metaPod, ok := c.metaPods.Load(key)
@@ -73,8 +66,8 @@ func (c *Store) donePodStats(ctx *types.RoutingContext, requestID string) {
 		klog.Warningf("can't find routing pod: %s, requestID: %s", pod.Name, requestID)
 		return
 	}
-	requests := atomic.AddInt32(&metaPod.runningRequests, -1)
-	if err := c.updatePodRecord(metaPod, ctx.Model, metrics.RealtimeNumRequestsRunning, metrics.PodMetricScope, &metrics.SimpleMetricValue{Value: float64(requests)}); err != nil {
+	requests := atomic.AddInt32(&metaPod.runningRequests, delta)
+	if err := c.updatePodRecord(metaPod, model, metrics.RealtimeNumRequestsRunning, metrics.PodMetricScope, &metrics.SimpleMetricValue{Value: float64(requests)}); err != nil {
 		klog.Warningf("can't update realtime metric: %s, pod: %s, requestID: %s", metrics.RealtimeNumRequestsRunning, pod.Name, requestID)
 	}
 }
@@ -208,12 +208,25 @@ func (c *Store) deletePod(obj interface{}) {
 	c.debugInfo()
 }
 
+// readyAdapterInstances returns the pods the ModelAdapter is loaded on. Instances also lists the pods the adapter is
+// scheduled to but not loaded on yet, e.g. downloading its artifact, or failed to load on, which must not be routed to.
+func readyAdapterInstances(adapter *modelv1alpha1.ModelAdapter) []string {
+	pods := make([]string, 0, len(adapter.Status.InstanceStatuses))
+	for _, status := range adapter.Status.InstanceStatuses {
+		if status.Ready {
+			pods = append(pods, status.PodName)
+		}
+	}
+	return pods
+}
+
 func (c *Store) addModelAdapter(obj interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	model := obj.(*modelv1alpha1.ModelAdapter)
-	for _, pod := range model.Status.Instances {
+	c.modelAdapters.Store(model.Name, model)
+	for _, pod := range readyAdapterInstances(model) {
 		c.addPodAndModelMappingLockedByName(pod, model.Namespace, model.Name)
 	}
 
@@ -227,12 +240,13 @@ func (c *Store) updateModelAdapter(oldObj interface{}, newObj interface{}) {
 
 	oldModel := oldObj.(*modelv1alpha1.ModelAdapter)
 	newModel := newObj.(*modelv1alpha1.ModelAdapter)
-	for _, pod := range oldModel.Status.Instances {
+	for _, pod := range readyAdapterInstances(oldModel) {
 		// the namespace of the pod is same as the namespace of model
 		c.deletePodAndModelMappingLocked(pod, oldModel.Namespace, oldModel.Name, 0)
 	}
 
-	for _, pod := range newModel.Status.Instances {
+	c.modelAdapters.Store(newModel.Name, newModel)
+	for _, pod := range readyAdapterInstances(newModel) {
 		c.addPodAndModelMappingLockedByName(pod, newModel.Namespace, newModel.Name)
 	}
 
@@ -256,7 +270,8 @@ func (c *Store) deleteModelAdapter(obj interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for _, pod := range model.Status.Instances {
+	c.modelAdapters.Delete(model.Name)
+	for _, pod := range readyAdapterInstances(model) {
 		// the namespace of the pod is same as the namespace of model
 		c.deletePodAndModelMappingLocked(pod, model.Namespace, model.Name, 0)
 	}
@@ -348,7 +363,7 @@ func (c *Store) resyncModelAdapters(store cache.Store) {
 		if modelAdapter, ok := obj.(*modelv1alpha1.ModelAdapter); ok {
 			c.mu.Lock()
 			// Process each pod instance in the ModelAdapter
-			for _, podName := range modelAdapter.Status.Instances {
+			for _, podName := range readyAdapterInstances(modelAdapter) {
 				key := fmt.Sprintf("%s/%s", modelAdapter.Namespace, podName)
 				// Check if pod exists in cache before creating mapping
 				if _, exists := c.metaPods.Load(key); exists {
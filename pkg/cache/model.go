@@ -28,4 +28,6 @@ type Model struct {
 	// Metrics utils.SyncMap[string, metrics.MetricValue] // reserved
 
 	pendingRequests int32
+	// shadowRequests are the running requests mirrored to the model, which are not traced.
+	shadowRequests int32
 }
@@ -28,4 +28,7 @@ type Pod struct {
 	ModelMetrics utils.SyncMap[string, metrics.MetricValue] // Pod-model metrics (model_name/metric_name -> value)
 
 	runningRequests int32 // Realtime running requests counter.
+	unhealthyUntil  int64 // Unix nano timestamp until which the pod is excluded from routing.
+
+	latencyWindows utils.SyncMap[string, *latencyWindow] // Latencies measured by the gateway (model_name/kind -> window)
 }
@@ -25,13 +25,15 @@ import (
 // ModelAdapterSpecApplyConfiguration represents a declarative configuration of the ModelAdapterSpec type for use
 // with apply.
 type ModelAdapterSpecApplyConfiguration struct {
-	BaseModel            *string                             `json:"baseModel,omitempty"`
-	PodSelector          *v1.LabelSelectorApplyConfiguration `json:"podSelector,omitempty"`
-	SchedulerName        *string                             `json:"schedulerName,omitempty"`
-	ArtifactURL          *string                             `json:"artifactURL,omitempty"`
-	CredentialsSecretRef *corev1.LocalObjectReference        `json:"credentialsSecretRef,omitempty"`
-	Replicas             *int32                              `json:"replicas,omitempty"`
-	AdditionalConfig     map[string]string                   `json:"additionalConfig,omitempty"`
+	BaseModel            *string                                    `json:"baseModel,omitempty"`
+	PodSelector          *v1.LabelSelectorApplyConfiguration        `json:"podSelector,omitempty"`
+	SchedulerName        *string                                    `json:"schedulerName,omitempty"`
+	ArtifactURL          *string                                    `json:"artifactURL,omitempty"`
+	ArtifactChecksum     *string                                    `json:"artifactChecksum,omitempty"`
+	CredentialsSecretRef *corev1.LocalObjectReference               `json:"credentialsSecretRef,omitempty"`
+	Replicas             *int32                                     `json:"replicas,omitempty"`
+	Autoscaling          *ModelAdapterAutoscalingApplyConfiguration `json:"autoscaling,omitempty"`
+	AdditionalConfig     map[string]string                          `json:"additionalConfig,omitempty"`
 }
 
 // ModelAdapterSpecApplyConfiguration constructs a declarative configuration of the ModelAdapterSpec type for use with
@@ -72,6 +74,14 @@ func (b *ModelAdapterSpecApplyConfiguration) WithArtifactURL(value string) *Mode
 	return b
 }
 
+// WithArtifactChecksum sets the ArtifactChecksum field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ArtifactChecksum field is set to the value of the last call.
+func (b *ModelAdapterSpecApplyConfiguration) WithArtifactChecksum(value string) *ModelAdapterSpecApplyConfiguration {
+	b.ArtifactChecksum = &value
+	return b
+}
+
 // WithCredentialsSecretRef sets the CredentialsSecretRef field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the CredentialsSecretRef field is set to the value of the last call.
@@ -88,6 +98,14 @@ func (b *ModelAdapterSpecApplyConfiguration) WithReplicas(value int32) *ModelAda
 	return b
 }
 
+// WithAutoscaling sets the Autoscaling field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Autoscaling field is set to the value of the last call.
+func (b *ModelAdapterSpecApplyConfiguration) WithAutoscaling(value *ModelAdapterAutoscalingApplyConfiguration) *ModelAdapterSpecApplyConfiguration {
+	b.Autoscaling = value
+	return b
+}
+
 // WithAdditionalConfig puts the entries into the AdditionalConfig field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, the entries provided by each call will be put on the AdditionalConfig field,
@@ -25,9 +25,11 @@ import (
 // ModelAdapterStatusApplyConfiguration represents a declarative configuration of the ModelAdapterStatus type for use
 // with apply.
 type ModelAdapterStatusApplyConfiguration struct {
-	Phase      *v1alpha1.ModelAdapterPhase      `json:"phase,omitempty"`
-	Conditions []v1.ConditionApplyConfiguration `json:"conditions,omitempty"`
-	Instances  []string                         `json:"instances,omitempty"`
+	Phase            *v1alpha1.ModelAdapterPhase                    `json:"phase,omitempty"`
+	Conditions       []v1.ConditionApplyConfiguration               `json:"conditions,omitempty"`
+	Instances        []string                                       `json:"instances,omitempty"`
+	InstanceStatuses []ModelAdapterInstanceStatusApplyConfiguration `json:"instanceStatuses,omitempty"`
+	ReadyReplicas    *int32                                         `json:"readyReplicas,omitempty"`
 }
 
 // ModelAdapterStatusApplyConfiguration constructs a declarative configuration of the ModelAdapterStatus type for use with
@@ -66,3 +68,24 @@ func (b *ModelAdapterStatusApplyConfiguration) WithInstances(values ...string) *
 	}
 	return b
 }
+
+// WithInstanceStatuses adds the given value to the InstanceStatuses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the InstanceStatuses field.
+func (b *ModelAdapterStatusApplyConfiguration) WithInstanceStatuses(values ...*ModelAdapterInstanceStatusApplyConfiguration) *ModelAdapterStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithInstanceStatuses")
+		}
+		b.InstanceStatuses = append(b.InstanceStatuses, *values[i])
+	}
+	return b
+}
+
+// WithReadyReplicas sets the ReadyReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReadyReplicas field is set to the value of the last call.
+func (b *ModelAdapterStatusApplyConfiguration) WithReadyReplicas(value int32) *ModelAdapterStatusApplyConfiguration {
+	b.ReadyReplicas = &value
+	return b
+}
@@ -47,10 +47,28 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		// Group=model, Version=v1alpha1
 	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapter"):
 		return &applyconfigurationmodelv1alpha1.ModelAdapterApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterAutoscaling"):
+		return &applyconfigurationmodelv1alpha1.ModelAdapterAutoscalingApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterInstanceStatus"):
+		return &applyconfigurationmodelv1alpha1.ModelAdapterInstanceStatusApplyConfiguration{}
 	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterSpec"):
 		return &applyconfigurationmodelv1alpha1.ModelAdapterSpecApplyConfiguration{}
 	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterStatus"):
 		return &applyconfigurationmodelv1alpha1.ModelAdapterStatusApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAlias"):
+		return &applyconfigurationmodelv1alpha1.ModelAliasApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAliasBackend"):
+		return &applyconfigurationmodelv1alpha1.ModelAliasBackendApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAliasSpec"):
+		return &applyconfigurationmodelv1alpha1.ModelAliasSpecApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("RoutingPolicy"):
+		return &applyconfigurationmodelv1alpha1.RoutingPolicyApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("RoutingPolicySpec"):
+		return &applyconfigurationmodelv1alpha1.RoutingPolicySpecApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("RoutingStrategy"):
+		return &applyconfigurationmodelv1alpha1.RoutingStrategyApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ShadowTraffic"):
+		return &applyconfigurationmodelv1alpha1.ShadowTrafficApplyConfiguration{}
 
 		// Group=orchestration, Version=v1alpha1
 	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterFleet"):
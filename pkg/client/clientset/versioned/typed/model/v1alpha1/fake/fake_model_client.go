@@ -31,6 +31,14 @@ func (c *FakeModelV1alpha1) ModelAdapters(namespace string) v1alpha1.ModelAdapte
 	return &FakeModelAdapters{c, namespace}
 }
 
+func (c *FakeModelV1alpha1) ModelAliases(namespace string) v1alpha1.ModelAliasInterface {
+	return &FakeModelAliases{c, namespace}
+}
+
+func (c *FakeModelV1alpha1) RoutingPolicies(namespace string) v1alpha1.RoutingPolicyInterface {
+	return &FakeRoutingPolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeModelV1alpha1) RESTClient() rest.Interface {
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+
+	v1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	modelv1alpha1 "github.com/vllm-project/aibrix/pkg/client/applyconfiguration/model/v1alpha1"
+	scheme "github.com/vllm-project/aibrix/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// RoutingPoliciesGetter has a method to return a RoutingPolicyInterface.
+// A group's client should implement this interface.
+type RoutingPoliciesGetter interface {
+	RoutingPolicies(namespace string) RoutingPolicyInterface
+}
+
+// RoutingPolicyInterface has methods to work with RoutingPolicy resources.
+type RoutingPolicyInterface interface {
+	Create(ctx context.Context, routingPolicy *v1alpha1.RoutingPolicy, opts v1.CreateOptions) (*v1alpha1.RoutingPolicy, error)
+	Update(ctx context.Context, routingPolicy *v1alpha1.RoutingPolicy, opts v1.UpdateOptions) (*v1alpha1.RoutingPolicy, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.RoutingPolicy, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.RoutingPolicyList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.RoutingPolicy, err error)
+	Apply(ctx context.Context, routingPolicy *modelv1alpha1.RoutingPolicyApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.RoutingPolicy, err error)
+	RoutingPolicyExpansion
+}
+
+// routingPolicies implements RoutingPolicyInterface
+type routingPolicies struct {
+	*gentype.ClientWithListAndApply[*v1alpha1.RoutingPolicy, *v1alpha1.RoutingPolicyList, *modelv1alpha1.RoutingPolicyApplyConfiguration]
+}
+
+// newRoutingPolicies returns a RoutingPolicies
+func newRoutingPolicies(c *ModelV1alpha1Client, namespace string) *routingPolicies {
+	return &routingPolicies{
+		gentype.NewClientWithListAndApply[*v1alpha1.RoutingPolicy, *v1alpha1.RoutingPolicyList, *modelv1alpha1.RoutingPolicyApplyConfiguration](
+			"routingpolicies",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			namespace,
+			func() *v1alpha1.RoutingPolicy { return &v1alpha1.RoutingPolicy{} },
+			func() *v1alpha1.RoutingPolicyList { return &v1alpha1.RoutingPolicyList{} }),
+	}
+}
@@ -24,3 +24,19 @@ type ModelAdapterListerExpansion interface{}
 // ModelAdapterNamespaceListerExpansion allows custom methods to be added to
 // ModelAdapterNamespaceLister.
 type ModelAdapterNamespaceListerExpansion interface{}
+
+// ModelAliasListerExpansion allows custom methods to be added to
+// ModelAliasLister.
+type ModelAliasListerExpansion interface{}
+
+// ModelAliasNamespaceListerExpansion allows custom methods to be added to
+// ModelAliasNamespaceLister.
+type ModelAliasNamespaceListerExpansion interface{}
+
+// RoutingPolicyListerExpansion allows custom methods to be added to
+// RoutingPolicyLister.
+type RoutingPolicyListerExpansion interface{}
+
+// RoutingPolicyNamespaceListerExpansion allows custom methods to be added to
+// RoutingPolicyNamespaceLister.
+type RoutingPolicyNamespaceListerExpansion interface{}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// Keys of the Secret referenced by CredentialsSecretRef, named after the environment variables of the runtime
+	// downloader which are used when the keys are absent.
+	CredentialsAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	CredentialsSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	CredentialsEndpointURL     = "AWS_ENDPOINT_URL"
+	CredentialsRegion          = "AWS_REGION"
+	CredentialsBearerToken     = "HTTP_BEARER_TOKEN"
+
+	// gcsEndpointURL is the S3 compatible endpoint gcs artifacts are downloaded from, with HMAC keys as credentials.
+	gcsEndpointURL = "https://storage.googleapis.com"
+
+	// Statuses of a model downloaded by the runtime sidecar.
+	modelStatusDownloading = "downloading"
+	modelStatusDownloaded  = "downloaded"
+
+	// Reasons of the ArtifactDownloaded condition.
+	ArtifactDownloadingReason    = "ArtifactDownloading"
+	ArtifactDownloadedReason     = "ArtifactDownloaded"
+	ArtifactDownloadFailedReason = "ArtifactDownloadFailed"
+
+	// artifactDownloadPollInterval is how often the download of an artifact is checked until it is downloaded.
+	artifactDownloadPollInterval = 5 * time.Second
+)
+
+var (
+	// errArtifactDownloading is returned while the artifact is being downloaded to a pod.
+	errArtifactDownloading = errors.New("artifact is downloading")
+	// errArtifactDownload wraps the errors of resolving or downloading the artifact.
+	errArtifactDownload = errors.New("failed to download artifact")
+
+	// credentialsDownloadConfig maps the keys of the credentials Secret to the download_extra_config of the runtime.
+	credentialsDownloadConfig = map[string]string{
+		CredentialsAccessKeyID:     "ak",
+		CredentialsSecretAccessKey: "sk",
+		CredentialsEndpointURL:     "endpoint",
+		CredentialsRegion:          "region",
+		CredentialsBearerToken:     "http_bearer_token",
+	}
+)
+
+// artifact is where the inference engine loads a model adapter from.
+type artifact struct {
+	// path is the lora_path of the load request unless the artifact is downloaded first.
+	path string
+	// download is the request to download the artifact to the pod by the runtime sidecar, nil if the artifact is
+	// loaded from path directly.
+	download *downloadModelRequest
+}
+
+// downloadModelRequest is the request of the /v1/model/download API of the runtime sidecar.
+type downloadModelRequest struct {
+	ModelURI            string            `json:"model_uri"`
+	ModelName           string            `json:"model_name"`
+	DownloadExtraConfig map[string]string `json:"download_extra_config,omitempty"`
+	Checksum            string            `json:"checksum,omitempty"`
+}
+
+// modelStatusCard is the response of the /v1/model/download API of the runtime sidecar.
+type modelStatusCard struct {
+	ModelName     string `json:"model_name"`
+	ModelRootPath string `json:"model_root_path"`
+	ModelStatus   string `json:"model_status"`
+}
+
+// resolveArtifact returns where the model adapter is loaded from. s3, gcs and http(s) artifacts are downloaded to the
+// pods by the runtime sidecar with the credentials of CredentialsSecretRef, gcs through its S3 compatible endpoint.
+func (r *ModelAdapterReconciler) resolveArtifact(ctx context.Context, instance *modelv1alpha1.ModelAdapter) (artifact, error) {
+	artifactURL := instance.Spec.ArtifactURL
+	if strings.HasPrefix(artifactURL, "huggingface://") {
+		path, err := extractHuggingFacePath(artifactURL)
+		if err != nil {
+			return artifact{}, err
+		}
+		return artifact{path: path}, nil
+	}
+	if !utils.IsDownloadedArtifact(artifactURL) {
+		return artifact{path: artifactURL}, nil
+	}
+
+	if !r.RuntimeConfig.EnableRuntimeSidecar {
+		return artifact{}, fmt.Errorf("%w: artifact %s is downloaded by the runtime sidecar, which is not enabled", errArtifactDownload, artifactURL)
+	}
+	config, err := r.artifactCredentials(ctx, instance)
+	if err != nil {
+		return artifact{}, fmt.Errorf("%w: %v", errArtifactDownload, err)
+	}
+	if after, ok := strings.CutPrefix(artifactURL, "gcs://"); ok {
+		artifactURL = "s3://" + after
+		if _, ok := config["endpoint"]; !ok {
+			config["endpoint"] = gcsEndpointURL
+		}
+	}
+	return artifact{download: &downloadModelRequest{
+		ModelURI:            artifactURL,
+		ModelName:           instance.Name,
+		DownloadExtraConfig: config,
+		Checksum:            instance.Spec.ArtifactChecksum,
+	}}, nil
+}
+
+// artifactCredentials returns the download_extra_config of the credentials in the Secret of CredentialsSecretRef.
+func (r *ModelAdapterReconciler) artifactCredentials(ctx context.Context, instance *modelv1alpha1.ModelAdapter) (map[string]string, error) {
+	config := map[string]string{}
+	if instance.Spec.CredentialsSecretRef == nil {
+		return config, nil
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.CredentialsSecretRef.Name}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("credentials secret %s not found", key)
+		}
+		return nil, err
+	}
+	for secretKey, configKey := range credentialsDownloadConfig {
+		if value, ok := secret.Data[secretKey]; ok {
+			config[configKey] = string(value)
+		}
+	}
+	return config, nil
+}
+
+// stageArtifact downloads the artifact to the pod by the runtime sidecar, which verifies its checksum once downloaded.
+// It returns the path of the artifact on the pod, or errArtifactDownloading until the artifact is downloaded.
+func (r *ModelAdapterReconciler) stageArtifact(ctx context.Context, url string, instance *modelv1alpha1.ModelAdapter, download *downloadModelRequest) (string, error) {
+	payloadBytes, err := json.Marshal(download)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := r.setAuthorization(ctx, req, instance); err != nil {
+		return "", err
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			klog.InfoS("Error closing response body:", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("%w: %s", errArtifactDownload, body)
+	}
+	var card modelStatusCard
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		return "", err
+	}
+	switch card.ModelStatus {
+	case modelStatusDownloaded:
+		return card.ModelRootPath, nil
+	case modelStatusDownloading:
+		return "", errArtifactDownloading
+	default:
+		return "", fmt.Errorf("%w: unexpected model status %q", errArtifactDownload, card.ModelStatus)
+	}
+}
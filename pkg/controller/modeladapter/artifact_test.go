@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+func TestResolveArtifact(t *testing.T) {
+	ctx := context.Background()
+	r := &ModelAdapterReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), Scheme: scheme.Scheme}
+	instance := &modelv1alpha1.ModelAdapter{ObjectMeta: metav1.ObjectMeta{Name: "lora-1", Namespace: "default"}}
+
+	// Artifacts of huggingface and local paths are loaded by the engine directly.
+	instance.Spec.ArtifactURL = "huggingface://org/lora"
+	source, err := r.resolveArtifact(ctx, instance)
+	assert.NoError(t, err)
+	assert.Equal(t, artifact{path: "org/lora"}, source)
+	instance.Spec.ArtifactURL = "/models/lora"
+	source, err = r.resolveArtifact(ctx, instance)
+	assert.NoError(t, err)
+	assert.Equal(t, artifact{path: "/models/lora"}, source)
+
+	// Other artifacts are downloaded by the runtime sidecar.
+	instance.Spec.ArtifactURL = "s3://bucket/lora"
+	_, err = r.resolveArtifact(ctx, instance)
+	assert.ErrorIs(t, err, errArtifactDownload)
+	r.RuntimeConfig = config.RuntimeConfig{EnableRuntimeSidecar: true}
+
+	instance.Spec.CredentialsSecretRef = &corev1.LocalObjectReference{Name: "lora-credentials"}
+	_, err = r.resolveArtifact(ctx, instance)
+	assert.ErrorIs(t, err, errArtifactDownload)
+	assert.ErrorContains(t, err, "credentials secret default/lora-credentials not found")
+
+	assert.NoError(t, r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			CredentialsAccessKeyID:     []byte("ak"),
+			CredentialsSecretAccessKey: []byte("sk"),
+			"unknown":                  []byte("ignored"),
+		},
+	}))
+	instance.Spec.ArtifactChecksum = "sha256:0123"
+	source, err = r.resolveArtifact(ctx, instance)
+	assert.NoError(t, err)
+	assert.Equal(t, &downloadModelRequest{
+		ModelURI:            "s3://bucket/lora",
+		ModelName:           "lora-1",
+		DownloadExtraConfig: map[string]string{"ak": "ak", "sk": "sk"},
+		Checksum:            "sha256:0123",
+	}, source.download)
+
+	// gcs artifacts are downloaded from its S3 compatible endpoint.
+	instance.Spec.ArtifactURL = "gcs://bucket/lora"
+	source, err = r.resolveArtifact(ctx, instance)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3://bucket/lora", source.download.ModelURI)
+	assert.Equal(t, gcsEndpointURL, source.download.DownloadExtraConfig["endpoint"])
+}
+
+func TestStageArtifact(t *testing.T) {
+	var status int
+	var card modelStatusCard
+	var request downloadModelRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, DownloadModelRuntimeAPIPath, req.URL.Path)
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			assert.NoError(t, json.NewEncoder(w).Encode(card))
+		} else {
+			_, _ = w.Write([]byte(`{"message": "checksum mismatch"}`))
+		}
+	}))
+	defer server.Close()
+
+	r := &ModelAdapterReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), Scheme: scheme.Scheme}
+	instance := &modelv1alpha1.ModelAdapter{ObjectMeta: metav1.ObjectMeta{Name: "lora-1", Namespace: "default"}}
+	download := &downloadModelRequest{ModelURI: "https://example.com/lora.tar.gz", ModelName: "lora-1", Checksum: "sha256:0123"}
+	url := server.URL + DownloadModelRuntimeAPIPath
+
+	status, card = http.StatusOK, modelStatusCard{ModelName: "lora-1", ModelRootPath: "/models/lora-1", ModelStatus: modelStatusDownloading}
+	_, err := r.stageArtifact(context.Background(), url, instance, download)
+	assert.ErrorIs(t, err, errArtifactDownloading)
+	assert.Equal(t, *download, request)
+
+	card.ModelStatus = modelStatusDownloaded
+	path, err := r.stageArtifact(context.Background(), url, instance, download)
+	assert.NoError(t, err)
+	assert.Equal(t, "/models/lora-1", path)
+
+	status = http.StatusUnprocessableEntity
+	_, err = r.stageArtifact(context.Background(), url, instance, download)
+	assert.ErrorIs(t, err, errArtifactDownload)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/aggregation"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// adapterDemandStaleness is the age of a request rate reported by a gateway beyond which it no longer counts, the
+	// gateways report every few seconds while they get requests of the model adapter.
+	adapterDemandStaleness = 30 * time.Second
+	// adapterAutoscalingSyncPeriod is the period autoscaled model adapters are reconciled at without events.
+	adapterAutoscalingSyncPeriod = 10 * time.Second
+
+	defaultAdapterIdleTimeout             = 300 * time.Second
+	defaultAdapterTargetRequestsPerSecond = 1.0
+)
+
+var (
+	// adapterDemandWindow is the window the replicas of an autoscaled model adapter are scaled with the maximal
+	// request rate over, which keeps the replicas of a model adapter of bursty traffic from flapping.
+	adapterDemandWindow = time.Duration(utils.LoadEnvInt("AIBRIX_LORA_AUTOSCALING_WINDOW_SECONDS", 60)) * time.Second
+)
+
+// adapterDemand is the demand of a model adapter reported by the gateways in its annotations.
+type adapterDemand struct {
+	// requestRate is the sum of the request rates reported recently, in requests per second.
+	requestRate float64
+	// lastRequestTime is the time of the latest report, zero if the model adapter never got a request.
+	lastRequestTime time.Time
+	// expiredKeys are the annotations of reports older than the idle timeout, besides the latest one.
+	expiredKeys []string
+}
+
+// parseAdapterDemand parses the demand reported by the gateways, malformed reports are ignored.
+func parseAdapterDemand(annotations map[string]string, now time.Time, idleTimeout time.Duration) adapterDemand {
+	var demand adapterDemand
+	var latestKey string
+	reports := map[string]time.Time{}
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, modelv1alpha1.ModelAdapterDemandAnnotationPrefix) {
+			continue
+		}
+		reportedAt, rate, err := parseDemandReport(value)
+		if err != nil {
+			klog.V(4).InfoS("ignored malformed demand report", "annotation", key, "value", value, "error", err)
+			continue
+		}
+		reports[key] = reportedAt
+		if now.Sub(reportedAt) <= adapterDemandStaleness {
+			demand.requestRate += rate
+		}
+		if reportedAt.After(demand.lastRequestTime) {
+			demand.lastRequestTime, latestKey = reportedAt, key
+		}
+	}
+	for key, reportedAt := range reports {
+		if key != latestKey && now.Sub(reportedAt) > idleTimeout {
+			demand.expiredKeys = append(demand.expiredKeys, key)
+		}
+	}
+	return demand
+}
+
+// parseDemandReport parses a report of "<unix seconds>,<requests per second>".
+func parseDemandReport(value string) (time.Time, float64, error) {
+	timestamp, rateValue, _ := strings.Cut(value, ",")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	rate, err := strconv.ParseFloat(rateValue, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return time.Unix(seconds, 0), rate, nil
+}
+
+// demandWindows keeps the windows of the request rates of autoscaled model adapters.
+type demandWindows struct {
+	mu      sync.Mutex
+	windows map[types.NamespacedName]*aggregation.TimeWindow
+}
+
+// record records the request rate of a model adapter and returns the maximal rate over the window.
+func (d *demandWindows) record(key types.NamespacedName, now time.Time, rate float64) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.windows == nil {
+		d.windows = map[types.NamespacedName]*aggregation.TimeWindow{}
+	}
+	window, ok := d.windows[key]
+	if !ok {
+		window = aggregation.NewTimeWindow(adapterDemandWindow, time.Second)
+		d.windows[key] = window
+	}
+	window.Record(now, rate)
+	maxRate, err := window.Max()
+	if err != nil {
+		return rate
+	}
+	return maxRate
+}
+
+func (d *demandWindows) forget(key types.NamespacedName) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.windows, key)
+}
+
+// autoscaledReplicas returns the number of replicas of an autoscaled model adapter by its request rate, at least one
+// until the model adapter gets no request for the idle timeout.
+func (r *ModelAdapterReconciler) autoscaledReplicas(ctx context.Context, instance *modelv1alpha1.ModelAdapter, now time.Time) int {
+	autoscaling := instance.Spec.Autoscaling
+	idleTimeout := defaultAdapterIdleTimeout
+	if autoscaling.IdleTimeoutSeconds > 0 {
+		idleTimeout = time.Duration(autoscaling.IdleTimeoutSeconds) * time.Second
+	}
+	target := defaultAdapterTargetRequestsPerSecond
+	if value, err := strconv.ParseFloat(autoscaling.TargetRequestsPerSecond, 64); err == nil && value > 0 {
+		target = value
+	}
+
+	demand := parseAdapterDemand(instance.Annotations, now, idleTimeout)
+	r.pruneDemandReports(ctx, instance, demand.expiredKeys)
+	rate := r.demandWindows.record(client.ObjectKeyFromObject(instance), now, demand.requestRate)
+
+	replicas := int(math.Ceil(rate / target))
+	if !demand.lastRequestTime.IsZero() && now.Sub(demand.lastRequestTime) < idleTimeout {
+		replicas = max(replicas, 1)
+	}
+	replicas = max(replicas, int(autoscaling.MinReplicas))
+	if autoscaling.MaxReplicas > 0 {
+		replicas = min(replicas, int(autoscaling.MaxReplicas))
+	}
+	klog.V(4).InfoS("autoscaled model adapter", "modelAdapter", klog.KObj(instance), "requestRate", rate,
+		"lastRequestTime", demand.lastRequestTime, "replicas", replicas)
+	return replicas
+}
+
+// pruneDemandReports removes the expired reports of gateways, which are gone or no longer get requests of the model adapter.
+func (r *ModelAdapterReconciler) pruneDemandReports(ctx context.Context, instance *modelv1alpha1.ModelAdapter, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	patch := client.MergeFrom(instance.DeepCopy())
+	for _, key := range keys {
+		delete(instance.Annotations, key)
+	}
+	if err := r.Patch(ctx, instance, patch); err != nil {
+		klog.ErrorS(err, "Failed to prune demand reports of ModelAdapter", "modelAdapter", klog.KObj(instance), "annotations", keys)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+)
+
+func demandReport(reportedAt time.Time, rate float64) string {
+	return fmt.Sprintf("%d,%g", reportedAt.Unix(), rate)
+}
+
+func TestParseAdapterDemand(t *testing.T) {
+	now := time.Unix(10000, 0)
+	demand := parseAdapterDemand(map[string]string{
+		modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-1": demandReport(now.Add(-5*time.Second), 1.5),
+		modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-2": demandReport(now.Add(-10*time.Second), 2),
+		modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-3": demandReport(now.Add(-time.Minute), 4),
+		modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-4": demandReport(now.Add(-time.Hour), 8),
+		modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-5": "malformed",
+		"other": demandReport(now, 16),
+	}, now, 5*time.Minute)
+
+	// Stale reports do not count in the request rate, expired reports are pruned.
+	assert.Equal(t, 3.5, demand.requestRate)
+	assert.Equal(t, now.Add(-5*time.Second), demand.lastRequestTime)
+	assert.Equal(t, []string{modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-4"}, demand.expiredKeys)
+
+	// The latest report is kept to tell when the model adapter got the last request.
+	demand = parseAdapterDemand(map[string]string{
+		modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-1": demandReport(now.Add(-time.Hour), 1),
+	}, now, 5*time.Minute)
+	assert.Equal(t, 0.0, demand.requestRate)
+	assert.Empty(t, demand.expiredKeys)
+}
+
+func TestAutoscaledReplicas(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r := &ModelAdapterReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), Scheme: scheme.Scheme}
+	instance := &modelv1alpha1.ModelAdapter{ObjectMeta: metav1.ObjectMeta{Name: "lora-1", Namespace: "default"}}
+	instance.Spec.Autoscaling = &modelv1alpha1.ModelAdapterAutoscaling{
+		MaxReplicas:             3,
+		TargetRequestsPerSecond: "2",
+		IdleTimeoutSeconds:      60,
+	}
+	assert.NoError(t, r.Create(ctx, instance))
+
+	// The model adapter is not loaded until it gets a request.
+	assert.Equal(t, 0, r.autoscaledReplicas(ctx, instance, now))
+
+	// It is scaled with the request rate, up to MaxReplicas.
+	instance.Annotations = map[string]string{modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-1": demandReport(now, 0.5)}
+	assert.Equal(t, 1, r.autoscaledReplicas(ctx, instance, now))
+	instance.Annotations[modelv1alpha1.ModelAdapterDemandAnnotationPrefix+"gw-2"] = demandReport(now, 4)
+	assert.Equal(t, 3, r.autoscaledReplicas(ctx, instance, now))
+	instance.Annotations[modelv1alpha1.ModelAdapterDemandAnnotationPrefix+"gw-2"] = demandReport(now, 20)
+	assert.Equal(t, 3, r.autoscaledReplicas(ctx, instance, now))
+
+	// It is not scaled in within the window of the request rate.
+	later := now.Add(adapterDemandStaleness + time.Second)
+	assert.Equal(t, 3, r.autoscaledReplicas(ctx, instance, later))
+
+	// Idle model adapters are scaled to MinReplicas, the expired reports are pruned.
+	later = now.Add(adapterDemandWindow + 2*time.Minute)
+	instance.Annotations[modelv1alpha1.ModelAdapterDemandAnnotationPrefix+"gw-2"] = demandReport(now.Add(-time.Minute), 1)
+	assert.NoError(t, r.Update(ctx, instance))
+	assert.Equal(t, 0, r.autoscaledReplicas(ctx, instance, later))
+	instance.Spec.Autoscaling.MinReplicas = 1
+	assert.Equal(t, 1, r.autoscaledReplicas(ctx, instance, later))
+
+	updated := &modelv1alpha1.ModelAdapter{}
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(instance), updated))
+	assert.Equal(t, map[string]string{modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-1": demandReport(now, 0.5)},
+		updated.Annotations)
+}
@@ -24,7 +24,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+	"reflect"
+	"slices"
 	"time"
 
 	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
@@ -70,6 +71,10 @@ const (
 	ModelAdapterInitializedReason = "ModelAdapterPending"
 	// FailedServiceCreateReason is added in a model adapter when it cannot create a new service.
 	FailedServiceCreateReason = "ServiceCreateError"
+	// ModelAdapterScaledInReason is added in a model adapter when it is unloaded from pods beyond its replicas.
+	ModelAdapterScaledInReason = "ScaledIn"
+	// ModelAdapterIdleReason is added in an autoscaled model adapter when it is idle and loaded on the next request.
+	ModelAdapterIdleReason = "Idle"
 	// FailedEndpointSliceCreateReason is added in a model adapter when it cannot create a new replica set.
 	FailedEndpointSliceCreateReason = "EndpointSliceCreateError"
 	// ModelAdapterLoadingErrorReason is added in a model adapter when it cannot be loaded in an engine pod.
@@ -93,12 +98,13 @@ const (
 	DefaultDebugInferenceEnginePort = "30081"
 	DefaultRuntimeAPIPort           = "8080"
 
-	ModelListPath            = "/v1/models"
-	ModelListRuntimeAPIPath  = "/v1/models"
-	LoadLoraAdapterPath      = "/v1/load_lora_adapter"
-	LoadLoraRuntimeAPIPath   = "/v1/lora_adapter/load"
-	UnloadLoraAdapterPath    = "/v1/unload_lora_adapter"
-	UnloadLoraRuntimeAPIPath = "/v1/lora_adapter/unload"
+	ModelListPath               = "/v1/models"
+	ModelListRuntimeAPIPath     = "/v1/models"
+	LoadLoraAdapterPath         = "/v1/load_lora_adapter"
+	LoadLoraRuntimeAPIPath      = "/v1/lora_adapter/load"
+	UnloadLoraAdapterPath       = "/v1/unload_lora_adapter"
+	UnloadLoraRuntimeAPIPath    = "/v1/lora_adapter/unload"
+	DownloadModelRuntimeAPIPath = "/v1/model/download"
 
 	// DefaultModelAdapterSchedulerPolicy is the default scheduler policy for ModelAdapter Controller.
 	DefaultModelAdapterSchedulerPolicy = "leastAdapters"
@@ -115,6 +121,8 @@ type URLConfig struct {
 	ListModelsURL    string
 	LoadAdapterURL   string
 	UnloadAdapterURL string
+	// DownloadModelURL is empty without the runtime sidecar
+	DownloadModelURL string
 }
 
 // Add creates a new ModelAdapter Controller and adds it to the Manager with default RBAC.
@@ -256,6 +264,9 @@ type ModelAdapterReconciler struct {
 	// EndpointSliceLister is able to list/get services from a shared informer's cache store
 	EndpointSliceLister discoverylisters.EndpointSliceLister
 	RuntimeConfig       config.RuntimeConfig
+
+	// demandWindows keeps the request rates of autoscaled model adapters.
+	demandWindows demandWindows
 }
 
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
@@ -264,6 +275,7 @@ type ModelAdapterReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=services/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=model.aibrix.ai,resources=modeladapters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=model.aibrix.ai,resources=modeladapters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=model.aibrix.ai,resources=modeladapters/finalizers,verbs=update
@@ -281,6 +293,7 @@ func (r *ModelAdapterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			// Object not found, return.
 			// For service, endpoint objects, clean up the resources using finalizers
 			klog.InfoS("ModelAdapter resource not found. Ignoring since object mush be deleted", "modelAdapter", req.NamespacedName)
+			r.demandWindows.forget(req.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 
@@ -343,89 +356,72 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 
 	oldInstance := instance.DeepCopy()
 
-	// Step 1: Schedule Pod for ModelAdapter
-	selectedPod := &corev1.Pod{}
-	existPods := false
-	var err error
-	if instance.Status.Instances != nil && len(instance.Status.Instances) != 0 {
-		// model adapter has already been scheduled to some pods
-		// check the scheduled pod first, verify the mapping is still valid.
-		// TODO: this needs to be changed once we support multiple lora adapters
-		selectedPodName := instance.Status.Instances[0]
-		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: selectedPodName}, selectedPod); err != nil && apierrors.IsNotFound(err) {
-			klog.ErrorS(err, "Selected pod has been deleted and it should be removed from model adapter instance list", "modelAdapter", klog.KObj(instance))
-			// instance.Status.Instances has been outdated, and we need to clear the pod list
-			// after the pod list is cleaned up, let's reconcile the instance object again in the next loop
-			return ctrl.Result{}, r.clearModelAdapterInstanceList(ctx, instance, selectedPodName)
-		} else if err != nil {
-			// failed to fetch the pod, let's requeue
-			return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
-		} else {
-			// compare instance and model adapter labels.
-			selector, err := metav1.LabelSelectorAsSelector(instance.Spec.PodSelector)
-			if err != nil {
-				// TODO: this should barely happen, let's move this logic to earlier validation logics.
-				return ctrl.Result{}, fmt.Errorf("failed to convert pod selector: %v", err)
-			}
-			if !selector.Matches(labels.Set(selectedPod.Labels)) {
-				klog.Warning("current assigned pod selector doesn't match model adapter selector")
-				return ctrl.Result{}, r.clearModelAdapterInstanceList(ctx, instance, selectedPodName)
-			}
-
-			// base model pod could be unhealthy or in termination, let's clean up the instances.
-			if !utils.IsPodReady(selectedPod) || utils.IsPodTerminating(selectedPod) {
-				klog.Warningf("current assigned pod %s/%s is not ready, remove it and reschedule the adapter", selectedPod.Namespace, selectedPod.Name)
-				return ctrl.Result{}, r.clearModelAdapterInstanceList(ctx, instance, selectedPodName)
-			}
-
-			existPods = true
-		}
+	// Step 1: Verify the pods the model adapter has been scheduled to
+	// the pods could be deleted, unhealthy or not selected anymore, let's clean them up and reconcile again in the next loop.
+	instancePods, stalePodNames, err := r.getInstancePods(ctx, instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
+	}
+	if len(stalePodNames) != 0 {
+		return ctrl.Result{}, r.clearModelAdapterInstanceList(ctx, instance, stalePodNames...)
 	}
 
-	if !existPods {
-		// TODO: as we plan to support lora replicas, it needs some corresponding changes.
-		// it should return a list of pods in future, otherwise, it should be invoked by N times.
-		activePods, err := r.getActivePodsForModelAdapter(ctx, instance)
+	// Step 2: Schedule Pods for ModelAdapter, or release pods if it has been scaled in
+	// autoscaled model adapters are scaled with their request rates reported by the gateways.
+	replicas := desiredReplicas(instance)
+	if instance.Spec.Autoscaling != nil {
+		replicas = r.autoscaledReplicas(ctx, instance, time.Now())
+	}
+	if len(instance.Status.Instances) < replicas {
+		selectedPods, err := r.scheduleInstances(ctx, instance, replicas-len(instance.Status.Instances))
 		if err != nil {
+			klog.ErrorS(err, "Failed to schedule Pod for ModelAdapter", "modelAdapter", klog.KObj(instance))
 			return ctrl.Result{}, err
 		}
-		if len(activePods) != 0 {
-			selectedPod, err = r.schedulePod(ctx, instance, activePods)
-			if err != nil {
-				klog.ErrorS(err, "Failed to schedule Pod for ModelAdapter", "modelAdapter", klog.KObj(instance))
-				return ctrl.Result{}, err
-			}
-
+		if len(selectedPods) != 0 {
 			instance.Status.Phase = modelv1alpha1.ModelAdapterScheduled
-			instance.Status.Instances = append(instance.Status.Instances, selectedPod.Name)
+			instance.Status.Instances = append(instance.Status.Instances, selectedPods...)
 			condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeScheduled), metav1.ConditionTrue,
-				"Scheduled", fmt.Sprintf("ModelAdapter %s has been allocated to pod %s/%s", klog.KObj(instance), selectedPod.GetNamespace(), selectedPod.GetName()))
+				"Scheduled", fmt.Sprintf("ModelAdapter %s has been allocated to pods %v", klog.KObj(instance), instance.Status.Instances))
 			if err := r.updateStatus(ctx, instance, condition); err != nil {
 				klog.InfoS("Got error when updating status", "error", err, "ModelAdapter", instance)
 				return ctrl.Result{}, err
 			}
 
 			return ctrl.Result{Requeue: true}, nil
-		} else {
+		} else if len(instance.Status.Instances) == 0 {
 			klog.Warningf("no active pods found for model adapter %v", klog.KObj(instance))
 		}
+	} else if len(instance.Status.Instances) > replicas {
+		releasedPods := r.releaseInstances(ctx, instance, instancePods, replicas)
+		instance.Status.Phase = modelv1alpha1.ModelAdapterScaled
+		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeScheduled), metav1.ConditionTrue,
+			ModelAdapterScaledInReason, fmt.Sprintf("ModelAdapter %s has been unloaded from pods %v", klog.KObj(instance), releasedPods))
+		if err := r.updateStatus(ctx, instance, condition); err != nil {
+			klog.InfoS("Got error when updating status", "error", err, "ModelAdapter", instance)
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// Step 2: Reconcile Loading
-	if err := r.reconcileLoading(ctx, instance); err != nil {
-		// retry any of the failure.
+	// Step 3: Reconcile Loading
+	// the model adapter serves from the pods it is loaded on, pods failed to load it are retried later.
+	// artifacts downloaded to the pods first are loaded once downloaded.
+	readyPods, loadingErr := r.reconcileLoading(ctx, instance, instancePods)
+	if loadingErr != nil && len(readyPods) == 0 {
 		instance.Status.Phase = modelv1alpha1.ModelAdapterBound
 		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeBound), metav1.ConditionFalse,
-			ModelAdapterLoadingErrorReason, fmt.Sprintf("ModelAdapter %s is loaded", klog.KObj(instance)))
+			ModelAdapterLoadingErrorReason, fmt.Sprintf("ModelAdapter %s failed to load: %v", klog.KObj(instance), loadingErr))
 		if err := r.updateStatus(ctx, instance, condition); err != nil {
 			klog.InfoS("Got error when updating status", "cluster name", req.Name, "error", err, "ModelAdapter", instance)
 			return ctrl.Result{}, err
 		}
 
-		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
+		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, loadingErr
 	}
 
-	// Step 3: Reconcile Service
+	// Step 4: Reconcile Service
 	if ctrlResult, err := r.reconcileService(ctx, instance); err != nil {
 		instance.Status.Phase = modelv1alpha1.ModelAdapterResourceCreated
 		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeResourceCreated), metav1.ConditionFalse,
@@ -437,8 +433,8 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 		return ctrlResult, err
 	}
 
-	// Step 4: Reconcile EndpointSlice
-	if ctrlResult, err := r.reconcileEndpointSlice(ctx, instance); err != nil {
+	// Step 5: Reconcile EndpointSlice
+	if ctrlResult, err := r.reconcileEndpointSlice(ctx, instance, readyPods); err != nil {
 		instance.Status.Phase = modelv1alpha1.ModelAdapterResourceCreated
 		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeResourceCreated), metav1.ConditionFalse,
 			FailedEndpointSliceCreateReason, "endpointslice creation failure")
@@ -452,12 +448,31 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 	// Check if we need to update the status.
 	if r.inconsistentModelAdapterStatus(oldInstance.Status, instance.Status) {
 		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionReady), metav1.ConditionTrue,
-			ModelAdapterAvailable, fmt.Sprintf("ModelAdapter %s is ready", klog.KObj(instance)))
+			ModelAdapterAvailable, fmt.Sprintf("ModelAdapter %s is ready on %d/%d pods", klog.KObj(instance), instance.Status.ReadyReplicas, replicas))
+		if replicas == 0 {
+			condition = NewCondition(string(modelv1alpha1.ModelAdapterConditionReady), metav1.ConditionFalse,
+				ModelAdapterIdleReason, fmt.Sprintf("ModelAdapter %s is idle, it is loaded on the next request", klog.KObj(instance)))
+		} else if instance.Status.ReadyReplicas == 0 {
+			condition = NewCondition(string(modelv1alpha1.ModelAdapterConditionReady), metav1.ConditionFalse,
+				ModelAdapterUnavailable, fmt.Sprintf("ModelAdapter %s is not loaded on any pod", klog.KObj(instance)))
+		}
 		if err = r.updateStatus(ctx, instance, condition); err != nil {
 			return reconcile.Result{}, fmt.Errorf("update modelAdapter status error: %v", err)
 		}
 	}
 
+	if loadingErr != nil {
+		klog.ErrorS(loadingErr, "Failed to load ModelAdapter on some pods", "modelAdapter", klog.KObj(instance))
+		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, nil
+	}
+	if cond := meta.FindStatusCondition(instance.Status.Conditions, string(modelv1alpha1.ModelAdapterConditionTypeArtifactDownloaded)); cond != nil && cond.Reason == ArtifactDownloadingReason {
+		// the downloads make no events, poll them until the artifact is downloaded to all pods.
+		return ctrl.Result{RequeueAfter: artifactDownloadPollInterval}, nil
+	}
+	if instance.Spec.Autoscaling != nil {
+		// the request rate and idleness change without events.
+		return ctrl.Result{RequeueAfter: adapterAutoscalingSyncPeriod}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -473,13 +488,22 @@ func (r *ModelAdapterReconciler) updateStatus(ctx context.Context, instance *mod
 	return r.Status().Update(ctx, instance)
 }
 
-func (r *ModelAdapterReconciler) clearModelAdapterInstanceList(ctx context.Context, instance *modelv1alpha1.ModelAdapter, stalePodName string) error {
-	instance.Status.Instances = RemoveInstanceFromList(instance.Status.Instances, stalePodName)
-	// remove instance means the lora has not targets at this moment.
-	instance.Status.Phase = modelv1alpha1.ModelAdapterPending
+func (r *ModelAdapterReconciler) clearModelAdapterInstanceList(ctx context.Context, instance *modelv1alpha1.ModelAdapter, stalePodNames ...string) error {
+	for _, stalePodName := range stalePodNames {
+		instance.Status.Instances = RemoveInstanceFromList(instance.Status.Instances, stalePodName)
+		instance.Status.InstanceStatuses = removeInstanceStatus(instance.Status.InstanceStatuses, stalePodName)
+	}
+	instance.Status.ReadyReplicas = countReadyInstances(instance.Status.InstanceStatuses)
 	condition := NewCondition(string(modelv1alpha1.ModelAdapterFailed), metav1.ConditionTrue,
 		StableInstanceFoundReason,
-		fmt.Sprintf("Pod (%s/%s) is stale or invalid for model adapter (%s/%s), clean up the list", instance.GetNamespace(), stalePodName, instance.GetNamespace(), instance.Name))
+		fmt.Sprintf("Pods %v are stale or invalid for model adapter (%s/%s), clean up the list", stalePodNames, instance.GetNamespace(), instance.Name))
+	if len(instance.Status.Instances) != 0 {
+		// the model adapter still runs on other pods, the stale pods are replaced in the next loop.
+		return r.updateStatus(ctx, instance, condition)
+	}
+
+	// remove all instances means the lora has not targets at this moment.
+	instance.Status.Phase = modelv1alpha1.ModelAdapterPending
 
 	// We also need to update the scheduling and ready status to false
 	// When the pod get migrated, we need to update the status with latest LastTransitionTime.
@@ -546,6 +570,104 @@ func (r *ModelAdapterReconciler) getActivePodsForModelAdapter(ctx context.Contex
 	return activePods, nil
 }
 
+// getInstancePods retrieves the pods the model adapter has been scheduled to, in the order of the instance list.
+// Pods deleted, not selected by the model adapter anymore, not ready or in termination are returned as stale.
+func (r *ModelAdapterReconciler) getInstancePods(ctx context.Context, instance *modelv1alpha1.ModelAdapter) ([]*corev1.Pod, []string, error) {
+	if len(instance.Status.Instances) == 0 {
+		return nil, nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(instance.Spec.PodSelector)
+	if err != nil {
+		// TODO: this should barely happen, let's move this logic to earlier validation logics.
+		return nil, nil, fmt.Errorf("failed to convert pod selector: %v", err)
+	}
+
+	var pods []*corev1.Pod
+	var stalePodNames []string
+	for _, podName := range instance.Status.Instances {
+		pod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, pod); err != nil {
+			if !apierrors.IsNotFound(err) {
+				// failed to fetch the pod, let's requeue
+				return nil, nil, err
+			}
+			klog.ErrorS(err, "Selected pod has been deleted and it should be removed from model adapter instance list", "modelAdapter", klog.KObj(instance), "pod", podName)
+			stalePodNames = append(stalePodNames, podName)
+			continue
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			klog.Warningf("assigned pod %s/%s doesn't match model adapter selector", pod.Namespace, pod.Name)
+			stalePodNames = append(stalePodNames, podName)
+			continue
+		}
+		// base model pod could be unhealthy or in termination, let's clean up the instances.
+		if !utils.IsPodReady(pod) || utils.IsPodTerminating(pod) {
+			klog.Warningf("assigned pod %s/%s is not ready, remove it and reschedule the adapter", pod.Namespace, pod.Name)
+			stalePodNames = append(stalePodNames, podName)
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, stalePodNames, nil
+}
+
+// scheduleInstances picks up to count active pods the model adapter is not scheduled to yet, one by one by the scheduler.
+func (r *ModelAdapterReconciler) scheduleInstances(ctx context.Context, instance *modelv1alpha1.ModelAdapter, count int) ([]string, error) {
+	activePods, err := r.getActivePodsForModelAdapter(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]corev1.Pod, 0, len(activePods))
+	for _, pod := range activePods {
+		if !StringInSlice(instance.Status.Instances, pod.Name) {
+			candidates = append(candidates, pod)
+		}
+	}
+
+	var selectedPods []string
+	for len(selectedPods) < count && len(candidates) != 0 {
+		selectedPod, err := r.schedulePod(ctx, instance, candidates)
+		if err != nil {
+			return nil, err
+		}
+		selectedPods = append(selectedPods, selectedPod.Name)
+		candidates = slices.DeleteFunc(candidates, func(pod corev1.Pod) bool {
+			return pod.Name == selectedPod.Name
+		})
+	}
+	return selectedPods, nil
+}
+
+// releaseInstances unloads the model adapter from the pods beyond the desired replicas, pods the model adapter is not
+// ready on are released first. It returns the released pods.
+func (r *ModelAdapterReconciler) releaseInstances(ctx context.Context, instance *modelv1alpha1.ModelAdapter, instancePods []*corev1.Pod, replicas int) []string {
+	ready := make(map[string]bool, len(instance.Status.InstanceStatuses))
+	for _, status := range instance.Status.InstanceStatuses {
+		ready[status.PodName] = status.Ready
+	}
+	pods := slices.Clone(instancePods)
+	slices.SortStableFunc(pods, func(a, b *corev1.Pod) int {
+		switch {
+		case ready[a.Name] == ready[b.Name]:
+			return 0
+		case ready[a.Name]:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	var releasedPods []string
+	for _, pod := range pods[replicas:] {
+		r.unloadModelAdapterFromPod(ctx, instance, pod)
+		releasedPods = append(releasedPods, pod.Name)
+		instance.Status.Instances = RemoveInstanceFromList(instance.Status.Instances, pod.Name)
+		instance.Status.InstanceStatuses = removeInstanceStatus(instance.Status.InstanceStatuses, pod.Name)
+	}
+	instance.Status.ReadyReplicas = countReadyInstances(instance.Status.InstanceStatuses)
+	return releasedPods
+}
+
 // schedulePod picks a valid pod to schedule the model adapter
 func (r *ModelAdapterReconciler) schedulePod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, activePods []corev1.Pod) (*corev1.Pod, error) {
 	// Implement your scheduling logic here to select a Pod based on the instance.Spec.PodSelector
@@ -553,29 +675,62 @@ func (r *ModelAdapterReconciler) schedulePod(ctx context.Context, instance *mode
 	return r.scheduler.SelectPod(ctx, instance.Name, activePods)
 }
 
-func (r *ModelAdapterReconciler) reconcileLoading(ctx context.Context, instance *modelv1alpha1.ModelAdapter) error {
-	if len(instance.Status.Instances) == 0 {
-		return nil
-	}
-
-	targetPod := &corev1.Pod{}
-	podName := instance.Status.Instances[0]
-	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, targetPod)
-	if err != nil && apierrors.IsNotFound(err) {
-		return fmt.Errorf("pod %s/%s can not be found, skip loading", instance.GetName(), podName)
-	} else if err != nil {
-		return err
+// reconcileLoading loads the model adapter on the pods it is scheduled to and records whether it is ready on each.
+// It returns the pods the model adapter is loaded on, and the errors of the pods failed to load it. The pods the artifact
+// is still downloading to are neither ready nor failed, the download is reported by the ArtifactDownloaded condition.
+func (r *ModelAdapterReconciler) reconcileLoading(ctx context.Context, instance *modelv1alpha1.ModelAdapter, instancePods []*corev1.Pod) ([]*corev1.Pod, error) {
+	source, sourceErr := r.resolveArtifact(ctx, instance)
+	var readyPods []*corev1.Pod
+	var downloadingPods []string
+	var errs, downloadErrs []error
+	instanceStatuses := make([]modelv1alpha1.ModelAdapterInstanceStatus, 0, len(instancePods))
+	for _, pod := range instancePods {
+		status := modelv1alpha1.ModelAdapterInstanceStatus{PodName: pod.Name}
+		err := sourceErr
+		if err == nil {
+			err = r.loadModelAdapterOnPod(ctx, instance, pod, source)
+		}
+		switch {
+		case errors.Is(err, errArtifactDownloading):
+			status.Message = err.Error()
+			downloadingPods = append(downloadingPods, pod.Name)
+		case err != nil:
+			klog.ErrorS(err, "Failed to load ModelAdapter", "modelAdapter", klog.KObj(instance), "pod", klog.KObj(pod))
+			status.Message = err.Error()
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+			if errors.Is(err, errArtifactDownload) {
+				downloadErrs = append(downloadErrs, fmt.Errorf("pod %s: %w", pod.Name, err))
+			}
+		default:
+			status.Ready = true
+			readyPods = append(readyPods, pod)
+		}
+		instanceStatuses = append(instanceStatuses, status)
 	}
-
-	// selectPod could be in termination, in this case, we just do nothing.
-	if targetPod.DeletionTimestamp != nil {
-		return nil
+	instance.Status.InstanceStatuses = instanceStatuses
+	instance.Status.ReadyReplicas = int32(len(readyPods))
+
+	if utils.IsDownloadedArtifact(instance.Spec.ArtifactURL) && len(instancePods) != 0 {
+		downloaded := len(instancePods) - len(downloadingPods) - len(downloadErrs)
+		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeArtifactDownloaded), metav1.ConditionTrue,
+			ArtifactDownloadedReason, fmt.Sprintf("Artifact is downloaded to %d/%d pods", downloaded, len(instancePods)))
+		if len(downloadErrs) != 0 {
+			condition = NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeArtifactDownloaded), metav1.ConditionFalse,
+				ArtifactDownloadFailedReason, fmt.Sprintf("Artifact is downloaded to %d/%d pods: %v", downloaded, len(instancePods), errors.Join(downloadErrs...)))
+		} else if len(downloadingPods) != 0 {
+			condition = NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeArtifactDownloaded), metav1.ConditionFalse,
+				ArtifactDownloadingReason, fmt.Sprintf("Artifact is downloaded to %d/%d pods, downloading to pods %v", downloaded, len(instancePods), downloadingPods))
+		}
+		meta.SetStatusCondition(&instance.Status.Conditions, condition)
 	}
+	return readyPods, errors.Join(errs...)
+}
 
+func (r *ModelAdapterReconciler) loadModelAdapterOnPod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, targetPod *corev1.Pod, source artifact) error {
 	urls := BuildURLs(targetPod.Status.PodIP, r.RuntimeConfig)
 
 	// Check if the model is already loaded
-	exists, err := r.modelAdapterExists(urls.ListModelsURL, instance)
+	exists, err := r.modelAdapterExists(ctx, urls.ListModelsURL, instance)
 	if err != nil {
 		return err
 	}
@@ -584,24 +739,24 @@ func (r *ModelAdapterReconciler) reconcileLoading(ctx context.Context, instance
 		return nil
 	}
 
-	// Load the Model adapter
-	err = r.loadModelAdapter(urls.LoadAdapterURL, instance)
-	if err != nil {
-		return err
+	// Download the artifact to the pod first if needed, then load the Model adapter
+	loraPath := source.path
+	if source.download != nil {
+		if loraPath, err = r.stageArtifact(ctx, urls.DownloadModelURL, instance, source.download); err != nil {
+			return err
+		}
 	}
-
-	return nil
+	return r.loadModelAdapter(ctx, urls.LoadAdapterURL, instance, loraPath)
 }
 
 // Separate method to check if the model already exists
-func (r *ModelAdapterReconciler) modelAdapterExists(url string, instance *modelv1alpha1.ModelAdapter) (bool, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (r *ModelAdapterReconciler) modelAdapterExists(ctx context.Context, url string, instance *modelv1alpha1.ModelAdapter) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return false, err
 	}
-	// Check if "api-key" exists in the map and set the Authorization header accordingly
-	if token, ok := instance.Spec.AdditionalConfig["api-key"]; ok {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	if err := r.setAuthorization(ctx, req, instance); err != nil {
+		return false, err
 	}
 
 	c := &http.Client{}
@@ -643,37 +798,24 @@ func (r *ModelAdapterReconciler) modelAdapterExists(url string, instance *modelv
 	return false, nil
 }
 
-// Separate method to load the LoRA adapter
-func (r *ModelAdapterReconciler) loadModelAdapter(url string, instance *modelv1alpha1.ModelAdapter) error {
-	artifactURL := instance.Spec.ArtifactURL
-	if strings.HasPrefix(instance.Spec.ArtifactURL, "huggingface://") {
-		var err error
-		artifactURL, err = extractHuggingFacePath(instance.Spec.ArtifactURL)
-		if err != nil {
-			// Handle error, e.g., log it and return
-			klog.ErrorS(err, "Invalid artifact URL", "artifactURL", artifactURL)
-			return err
-		}
-	}
-	// TODO: extend to other artifacts
-
+// Separate method to load the LoRA adapter from the path on the pod
+func (r *ModelAdapterReconciler) loadModelAdapter(ctx context.Context, url string, instance *modelv1alpha1.ModelAdapter, loraPath string) error {
 	payload := map[string]string{
 		"lora_name": instance.Name,
-		"lora_path": artifactURL,
+		"lora_path": loraPath,
 	}
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	// Check if "api-key" exists in the map and set the Authorization header accordingly
-	if token, ok := instance.Spec.AdditionalConfig["api-key"]; ok {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	if err := r.setAuthorization(ctx, req, instance); err != nil {
+		return err
 	}
 
 	client := &http.Client{}
@@ -695,6 +837,27 @@ func (r *ModelAdapterReconciler) loadModelAdapter(url string, instance *modelv1a
 	return nil
 }
 
+// setAuthorization sets the api key of the inference engines to the request, it is read from the Secret named by the
+// "api-key-secret" additional config, otherwise from the "api-key" additional config in plain text.
+func (r *ModelAdapterReconciler) setAuthorization(ctx context.Context, req *http.Request, instance *modelv1alpha1.ModelAdapter) error {
+	token, ok := instance.Spec.AdditionalConfig[modelv1alpha1.ModelAdapterAPIKeyConfig]
+	if name, found := instance.Spec.AdditionalConfig[modelv1alpha1.ModelAdapterAPIKeySecretConfig]; found {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: name}, secret); err != nil {
+			return fmt.Errorf("failed to get api key secret %s: %w", name, err)
+		}
+		value, found := secret.Data[modelv1alpha1.ModelAdapterAPIKeyConfig]
+		if !found {
+			return fmt.Errorf("api key secret %s has no key %s", name, modelv1alpha1.ModelAdapterAPIKeyConfig)
+		}
+		token, ok = string(value), true
+	}
+	if ok {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	return nil
+}
+
 // unloadModelAdapter unloads the loras from inference engines
 // base model pod could be deleted, in this case, we just do optimistic unloading. It only returns some necessary errors and http errors should not be returned.
 func (r *ModelAdapterReconciler) unloadModelAdapter(ctx context.Context, instance *modelv1alpha1.ModelAdapter) error {
@@ -703,45 +866,52 @@ func (r *ModelAdapterReconciler) unloadModelAdapter(ctx context.Context, instanc
 		return nil
 	}
 
-	// TODO:(jiaxin.shan) Support multiple instances
-
-	podName := instance.Status.Instances[0]
-	targetPod := &corev1.Pod{}
-	if err := r.Get(ctx, types.NamespacedName{
-		Namespace: instance.Namespace,
-		Name:      podName,
-	}, targetPod); err != nil {
-		if apierrors.IsNotFound(err) {
-			klog.Warningf("Failed to find lora Pod instance %s/%s from apiserver, skip unloading", instance.GetNamespace(), podName)
-			return nil
+	for _, podName := range instance.Status.Instances {
+		targetPod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{
+			Namespace: instance.Namespace,
+			Name:      podName,
+		}, targetPod); err != nil {
+			if apierrors.IsNotFound(err) {
+				klog.Warningf("Failed to find lora Pod instance %s/%s from apiserver, skip unloading", instance.GetNamespace(), podName)
+				continue
+			}
+			klog.Warning("Error getting Pod from lora instance list", err)
+			return err
 		}
-		klog.Warning("Error getting Pod from lora instance list", err)
-		return err
+		r.unloadModelAdapterFromPod(ctx, instance, targetPod)
 	}
+	return nil
+}
 
+// unloadModelAdapterFromPod unloads the lora from the inference engine of the pod, failures are only logged.
+func (r *ModelAdapterReconciler) unloadModelAdapterFromPod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, targetPod *corev1.Pod) {
 	payload := map[string]string{
 		"lora_name": instance.Name,
 	}
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		klog.Warningf("failed to unload LoRA adapter from pod %s: %v", targetPod.Name, err)
+		return
 	}
 
 	urls := BuildURLs(targetPod.Status.PodIP, r.RuntimeConfig)
-	req, err := http.NewRequest("POST", urls.UnloadAdapterURL, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", urls.UnloadAdapterURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		return err
+		klog.Warningf("failed to unload LoRA adapter from pod %s: %v", targetPod.Name, err)
+		return
 	}
 	req.Header.Set("Content-Type", "application/json")
-	// Check if "api-key" exists in the map and set the Authorization header accordingly
-	if token, ok := instance.Spec.AdditionalConfig["api-key"]; ok {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	if err := r.setAuthorization(ctx, req, instance); err != nil {
+		klog.Warningf("failed to unload LoRA adapter from pod %s: %v", targetPod.Name, err)
+		return
 	}
 
 	httpClient := &http.Client{}
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil
+		klog.Warningf("failed to unload LoRA adapter from pod %s: %v", targetPod.Name, err)
+		return
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -751,10 +921,8 @@ func (r *ModelAdapterReconciler) unloadModelAdapter(ctx context.Context, instanc
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		klog.Warningf("failed to unload LoRA adapter: %s", body)
+		klog.Warningf("failed to unload LoRA adapter from pod %s: %s", targetPod.Name, body)
 	}
-
-	return nil
 }
 
 func (r *ModelAdapterReconciler) reconcileService(ctx context.Context, instance *modelv1alpha1.ModelAdapter) (ctrl.Result, error) {
@@ -792,70 +960,23 @@ func (r *ModelAdapterReconciler) reconcileService(ctx context.Context, instance
 	return ctrl.Result{}, nil
 }
 
-func (r *ModelAdapterReconciler) reconcileEndpointSlice(ctx context.Context, instance *modelv1alpha1.ModelAdapter) (ctrl.Result, error) {
+// reconcileEndpointSlice lists the pods the model adapter is loaded on in its endpoint slice.
+func (r *ModelAdapterReconciler) reconcileEndpointSlice(ctx context.Context, instance *modelv1alpha1.ModelAdapter, readyPods []*corev1.Pod) (ctrl.Result, error) {
 	// check if the endpoint slice already exists, if not create a new one.
 	found := &discoveryv1.EndpointSlice{}
-	// instance could be clean up in earlier reconciliation, we need to clean up the instance from endpoint list.
-	if len(instance.Status.Instances) == 0 {
-		klog.Warningf("model adapter %s has not been deployed to any pods yet or being deleted, skip creating endpointslice", klog.KObj(instance))
-
-		// reset endpoint slice
-		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, found); err != nil {
-			if apierrors.IsNotFound(err) {
-				klog.Warningf("Failed to fetch the endpoint slice %s", klog.KObj(instance))
-			}
-			return ctrl.Result{}, err
-		}
-		found.Endpoints = []discoveryv1.Endpoint{}
-		if err := r.Update(ctx, found); err != nil {
-			klog.ErrorS(err, "Failed to update EndpointSlice after clearing endpoints", "EndpointSlice", found.Name)
-			return ctrl.Result{}, err
-		}
-
-		return ctrl.Result{}, nil
-	}
-
-	// TODO: do necessary refactor to support multiple lora instance
-	podName := instance.Status.Instances[0]
-	pod := &corev1.Pod{}
-	if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, pod); err != nil {
-		if !apierrors.IsNotFound(err) {
-			klog.Warning("Error getting Pod from lora instance list", err)
-			return ctrl.Result{}, err
-		}
-
-		klog.Warningf("pod %s/%s has been deleted, let's clean up the endpoint slice", instance.GetNamespace(), podName)
-		// TODO: do necessary refactor to support multiple lora instance
-		// the tricky thing is we do not know the pod map to pod ip mapping. instance only save pods, endpointslice only save ips
-		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, found); err != nil {
-			if apierrors.IsNotFound(err) {
-				// this should barely happen, in this case, there's no need to move forward
-				klog.Warningf("Endpoint slice %s doesn't exist", klog.KObj(instance))
-				return ctrl.Result{}, nil
-			}
-			return ctrl.Result{}, err
-		}
-
-		// reset endpoint slice
-		found.Endpoints = []discoveryv1.Endpoint{}
-		if err := r.Update(ctx, found); err != nil {
-			klog.ErrorS(err, "Failed to update EndpointSlice after clearing endpoints", "EndpointSlice", found.Name)
-			return ctrl.Result{}, err
-		}
-
-		return ctrl.Result{}, nil
-	}
-
-	// pod object fetched, let's check existence of endpoint slice
 	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, found)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			klog.ErrorS(err, "Failed to get EndpointSlice")
 			return ctrl.Result{}, err
 		}
+		if len(readyPods) == 0 {
+			klog.Warningf("model adapter %s has not been loaded on any pods yet, skip creating endpointslice", klog.KObj(instance))
+			return ctrl.Result{}, nil
+		}
 
 		// EndpointSlice does not exist, create it
-		eps := buildModelAdapterEndpointSlice(instance, pod)
+		eps := buildModelAdapterEndpointSlice(instance, readyPods...)
 		// Set the owner reference
 		if err := ctrl.SetControllerReference(instance, eps, r.Scheme); err != nil {
 			klog.Error(err, "Failed to set controller reference to modelAdapter")
@@ -875,80 +996,23 @@ func (r *ModelAdapterReconciler) reconcileEndpointSlice(ctx context.Context, ins
 			return ctrl.Result{}, err
 		}
 		instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
-	} else {
-		// Existing EndpointSlice Found. Check if the Pod IP is already in the EndpointSlice
-		podIP := pod.Status.PodIP
-		alreadyExists := false
-		for _, endpoint := range found.Endpoints {
-			for _, address := range endpoint.Addresses {
-				if address == podIP {
-					alreadyExists = true
-					break
-				}
-			}
-			if alreadyExists {
-				break
-			}
-		}
-
-		// Append the Pod IP to the EndpointSlice if it doesn't exist
-		if !alreadyExists {
-			// TODO: come back when we start to support multi-instance.
-			//found.Endpoints = append(found.Endpoints, discoveryv1.Endpoint{
-			//	Addresses: []string{podIP},
-			//})
-
-			// override the endpoint with only one pod id.
-			// TODO: We need to refactor the logic once we start to support multi instances later
-			found.Endpoints = []discoveryv1.Endpoint{
-				{
-					Addresses: []string{podIP},
-				},
-			}
+		return ctrl.Result{}, nil
+	}
 
-			if err := r.Update(ctx, found); err != nil {
-				klog.ErrorS(err, "Failed to update EndpointSlice", "EndpointSlice", found.Name)
-				return ctrl.Result{}, err
-			}
-			instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
-			klog.InfoS("Successfully updated EndpointSlice", "EndpointSlice", found.Name)
-		} else {
-			// pod has been deleted, and we should remove the pod name from the list
-			if pod.DeletionTimestamp != nil {
-				var updatedEndpoints []discoveryv1.Endpoint
-				podIP := pod.Status.PodIP
-
-				for _, endpoint := range found.Endpoints {
-					shouldRemove := false
-					var newAddresses []string
-
-					for _, address := range endpoint.Addresses {
-						if address == podIP {
-							shouldRemove = true
-						} else {
-							newAddresses = append(newAddresses, address)
-						}
-					}
-
-					if !shouldRemove || len(newAddresses) > 0 {
-						endpoint.Addresses = newAddresses
-						updatedEndpoints = append(updatedEndpoints, endpoint)
-					}
-				}
-
-				found.Endpoints = updatedEndpoints
-				if err := r.Update(ctx, found); err != nil {
-					klog.ErrorS(err, "Failed to update EndpointSlice after removing PodIP", "EndpointSlice", found.Name)
-					return ctrl.Result{}, err
-				}
-
-				instance.Status.Phase = modelv1alpha1.ModelAdapterFailed
-				klog.InfoS("Successfully removed Pod IP from EndpointSlice", "PodIP", podIP, "EndpointSlice", found.Name)
-			} else {
-				klog.V(4).InfoS("Pod IP already exists in EndpointSlice", "PodName", pod.Name, "PodIP", podIP)
-				instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
-			}
+	// Existing EndpointSlice Found. Update it if the pod IPs changed, pods could be added, removed or deleted.
+	endpoints := buildModelAdapterEndpoints(readyPods)
+	if !equalStringSlices(endpointAddresses(found.Endpoints), endpointAddresses(endpoints)) {
+		found.Endpoints = endpoints
+		if err := r.Update(ctx, found); err != nil {
+			klog.ErrorS(err, "Failed to update EndpointSlice", "EndpointSlice", found.Name)
+			return ctrl.Result{}, err
 		}
+		klog.InfoS("Successfully updated EndpointSlice", "EndpointSlice", found.Name, "addresses", endpointAddresses(endpoints))
+	} else {
+		klog.V(4).InfoS("Pod IPs already exist in EndpointSlice", "EndpointSlice", found.Name)
+	}
+	if len(readyPods) != 0 {
+		instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
 	}
 
 	return ctrl.Result{}, nil
@@ -956,7 +1020,8 @@ func (r *ModelAdapterReconciler) reconcileEndpointSlice(ctx context.Context, ins
 
 func (r *ModelAdapterReconciler) inconsistentModelAdapterStatus(oldStatus, newStatus modelv1alpha1.ModelAdapterStatus) bool {
 	// Implement your logic to check if the status is inconsistent
-	if oldStatus.Phase != newStatus.Phase || !equalStringSlices(oldStatus.Instances, newStatus.Instances) {
+	if oldStatus.Phase != newStatus.Phase || !equalStringSlices(oldStatus.Instances, newStatus.Instances) ||
+		oldStatus.ReadyReplicas != newStatus.ReadyReplicas || !reflect.DeepEqual(oldStatus.InstanceStatuses, newStatus.InstanceStatuses) {
 		return true
 	}
 
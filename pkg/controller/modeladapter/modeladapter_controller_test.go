@@ -18,14 +18,22 @@ package modeladapter
 
 import (
 	"context"
+	"net/http"
+	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/modeladapter/scheduling"
 )
 
 var _ = Describe("ModelAdapter Controller", func() {
@@ -80,3 +88,133 @@ var _ = Describe("ModelAdapter Controller", func() {
 		//})
 	})
 })
+
+func TestScheduleInstances(t *testing.T) {
+	labels := map[string]string{"model.aibrix.ai/name": "llama"}
+	ready := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	r := &ModelAdapterReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default", Labels: labels},
+				Status: corev1.PodStatus{PodIP: "127.0.0.1", Conditions: ready}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default", Labels: labels},
+				Status: corev1.PodStatus{PodIP: "127.0.0.1", Conditions: ready}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p3", Namespace: "default", Labels: labels},
+				Status: corev1.PodStatus{PodIP: "127.0.0.1", Conditions: ready}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p4", Namespace: "default", Labels: labels},
+				Status: corev1.PodStatus{PodIP: "127.0.0.1"}},
+		).Build(),
+		Scheme:    scheme.Scheme,
+		scheduler: scheduling.NewRandomScheduler(nil),
+	}
+	instance := &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora-1", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAdapterSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: labels},
+			Replicas:    ptr.To(int32(3)),
+		},
+		Status: modelv1alpha1.ModelAdapterStatus{Instances: []string{"p1"}},
+	}
+
+	// Pods are scheduled once, only to ready pods the adapter is not scheduled to yet.
+	selectedPods, err := r.scheduleInstances(context.Background(), instance, 2)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"p2", "p3"}, selectedPods)
+
+	selectedPods, err = r.scheduleInstances(context.Background(), instance, 5)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"p2", "p3"}, selectedPods)
+}
+
+func TestGetInstancePods(t *testing.T) {
+	labels := map[string]string{"model.aibrix.ai/name": "llama"}
+	ready := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	r := &ModelAdapterReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default", Labels: labels},
+				Status: corev1.PodStatus{PodIP: "127.0.0.1", Conditions: ready}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default", Labels: labels},
+				Status: corev1.PodStatus{PodIP: "127.0.0.1"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p3", Namespace: "default",
+				Labels: map[string]string{"model.aibrix.ai/name": "mistral"}},
+				Status: corev1.PodStatus{PodIP: "127.0.0.1", Conditions: ready}},
+		).Build(),
+		Scheme: scheme.Scheme,
+	}
+	instance := &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora-1", Namespace: "default"},
+		Spec:       modelv1alpha1.ModelAdapterSpec{PodSelector: &metav1.LabelSelector{MatchLabels: labels}},
+		Status:     modelv1alpha1.ModelAdapterStatus{Instances: []string{"p1", "p2", "p3", "deleted"}},
+	}
+
+	pods, stalePodNames, err := r.getInstancePods(context.Background(), instance)
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "p1", pods[0].Name)
+	assert.Equal(t, []string{"p2", "p3", "deleted"}, stalePodNames)
+}
+
+func TestReleaseInstances(t *testing.T) {
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "127.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "127.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p3", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "127.0.0.1"}},
+	}
+	r := &ModelAdapterReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pods[0], pods[1], pods[2]).Build(),
+		Scheme: scheme.Scheme,
+	}
+	instance := &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora-1", Namespace: "default"},
+		Spec:       modelv1alpha1.ModelAdapterSpec{Replicas: ptr.To(int32(2))},
+		Status: modelv1alpha1.ModelAdapterStatus{
+			Instances: []string{"p1", "p2", "p3"},
+			InstanceStatuses: []modelv1alpha1.ModelAdapterInstanceStatus{
+				{PodName: "p1", Ready: false, Message: "failed to load"},
+				{PodName: "p2", Ready: true},
+				{PodName: "p3", Ready: true},
+			},
+		},
+	}
+
+	// The pods the adapter is not ready on are released first.
+	releasedPods := r.releaseInstances(context.Background(), instance, pods, 2)
+	assert.Equal(t, []string{"p1"}, releasedPods)
+	assert.Equal(t, []string{"p2", "p3"}, instance.Status.Instances)
+	assert.Equal(t, int32(2), instance.Status.ReadyReplicas)
+
+	releasedPods = r.releaseInstances(context.Background(), instance, pods[1:], 1)
+	assert.Equal(t, []string{"p3"}, releasedPods)
+	assert.Equal(t, []string{"p2"}, instance.Status.Instances)
+	assert.Equal(t, []modelv1alpha1.ModelAdapterInstanceStatus{{PodName: "p2", Ready: true}}, instance.Status.InstanceStatuses)
+}
+
+func TestSetAuthorization(t *testing.T) {
+	ctx := context.Background()
+	r := &ModelAdapterReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), Scheme: scheme.Scheme}
+	instance := &modelv1alpha1.ModelAdapter{ObjectMeta: metav1.ObjectMeta{Name: "lora-1", Namespace: "default"}}
+	newRequest := func() *http.Request {
+		req, err := http.NewRequestWithContext(ctx, "POST", "http://localhost:8000/v1/load_lora_adapter", nil)
+		assert.NoError(t, err)
+		return req
+	}
+
+	req := newRequest()
+	assert.NoError(t, r.setAuthorization(ctx, req, instance))
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	instance.Spec.AdditionalConfig = map[string]string{modelv1alpha1.ModelAdapterAPIKeyConfig: "plain"}
+	req = newRequest()
+	assert.NoError(t, r.setAuthorization(ctx, req, instance))
+	assert.Equal(t, "Bearer plain", req.Header.Get("Authorization"))
+
+	// The api key of the Secret takes precedence over the one in plain text.
+	instance.Spec.AdditionalConfig[modelv1alpha1.ModelAdapterAPIKeySecretConfig] = "lora-api-key"
+	assert.ErrorContains(t, r.setAuthorization(ctx, newRequest(), instance), "failed to get api key secret lora-api-key")
+	assert.NoError(t, r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora-api-key", Namespace: "default"},
+		Data:       map[string][]byte{modelv1alpha1.ModelAdapterAPIKeyConfig: []byte("secret")},
+	}))
+	req = newRequest()
+	assert.NoError(t, r.setAuthorization(ctx, req, instance))
+	assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+}
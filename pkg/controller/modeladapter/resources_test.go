@@ -27,6 +27,24 @@ import (
 	"k8s.io/utils/ptr"
 )
 
+func TestBuildModelAdapterEndpointSliceWithPods(t *testing.T) {
+	instance := &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-instance",
+			Namespace: "default",
+		},
+	}
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "192.168.1.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "192.168.1.2"}},
+	}
+
+	endpointSlice := buildModelAdapterEndpointSlice(instance, pods...)
+	assert.Len(t, endpointSlice.Endpoints, 2)
+	assert.Equal(t, []string{"192.168.1.1", "192.168.1.2"}, endpointAddresses(endpointSlice.Endpoints))
+	assert.Equal(t, "p2", endpointSlice.Endpoints[1].TargetRef.Name)
+}
+
 func TestBuildModelAdapterEndpointSlice(t *testing.T) {
 	// Mock input for ModelAdapter
 	instance := &modelv1alpha1.ModelAdapter{
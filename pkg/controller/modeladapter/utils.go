@@ -22,7 +22,9 @@ import (
 	"net/url"
 	"strings"
 
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/config"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -87,6 +89,43 @@ func RemoveInstanceFromList(slice []string, strToRemove string) []string {
 	return result
 }
 
+// removeInstanceStatus removes the status of a pod from a list of instance statuses
+func removeInstanceStatus(statuses []modelv1alpha1.ModelAdapterInstanceStatus, podName string) []modelv1alpha1.ModelAdapterInstanceStatus {
+	var result []modelv1alpha1.ModelAdapterInstanceStatus
+	for _, status := range statuses {
+		if status.PodName != podName {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+func countReadyInstances(statuses []modelv1alpha1.ModelAdapterInstanceStatus) int32 {
+	var ready int32
+	for _, status := range statuses {
+		if status.Ready {
+			ready++
+		}
+	}
+	return ready
+}
+
+// desiredReplicas returns the number of pods the model adapter should be loaded on, one if not set.
+func desiredReplicas(instance *modelv1alpha1.ModelAdapter) int {
+	if instance.Spec.Replicas == nil {
+		return 1
+	}
+	return int(*instance.Spec.Replicas)
+}
+
+func endpointAddresses(endpoints []discoveryv1.Endpoint) []string {
+	var addresses []string
+	for _, endpoint := range endpoints {
+		addresses = append(addresses, endpoint.Addresses...)
+	}
+	return addresses
+}
+
 // NewCondition creates a new condition.
 func NewCondition(condType string, status metav1.ConditionStatus, reason, msg string) metav1.Condition {
 	return metav1.Condition{
@@ -117,10 +156,14 @@ func BuildURLs(podIP string, config config.RuntimeConfig) URLConfig {
 		unloadPath = UnloadLoraRuntimeAPIPath
 	}
 
-	return URLConfig{
+	urls := URLConfig{
 		BaseURL:          host,
 		ListModelsURL:    fmt.Sprintf("%s%s", host, apiPath),
 		LoadAdapterURL:   fmt.Sprintf("%s%s", host, loadPath),
 		UnloadAdapterURL: fmt.Sprintf("%s%s", host, unloadPath),
 	}
+	if config.EnableRuntimeSidecar {
+		urls.DownloadModelURL = fmt.Sprintf("%s%s", host, DownloadModelRuntimeAPIPath)
+	}
+	return urls
 }
@@ -21,7 +21,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
 
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/config"
 )
 
@@ -143,6 +145,7 @@ func TestBuildURLs(t *testing.T) {
 				ListModelsURL:    fmt.Sprintf("http://%s:%s%s", "192.168.1.2", DefaultRuntimeAPIPort, ModelListRuntimeAPIPath),
 				LoadAdapterURL:   fmt.Sprintf("http://%s:%s%s", "192.168.1.2", DefaultRuntimeAPIPort, LoadLoraRuntimeAPIPath),
 				UnloadAdapterURL: fmt.Sprintf("http://%s:%s%s", "192.168.1.2", DefaultRuntimeAPIPort, UnloadLoraRuntimeAPIPath),
+				DownloadModelURL: fmt.Sprintf("http://%s:%s%s", "192.168.1.2", DefaultRuntimeAPIPort, DownloadModelRuntimeAPIPath),
 			},
 			expectError: false,
 		},
@@ -177,3 +180,26 @@ func TestBuildURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestInstanceStatuses(t *testing.T) {
+	statuses := []modelv1alpha1.ModelAdapterInstanceStatus{
+		{PodName: "p1", Ready: true},
+		{PodName: "p2", Ready: false, Message: "failed to load"},
+		{PodName: "p3", Ready: true},
+	}
+	assert.Equal(t, int32(2), countReadyInstances(statuses))
+
+	statuses = removeInstanceStatus(statuses, "p1")
+	assert.Equal(t, []modelv1alpha1.ModelAdapterInstanceStatus{
+		{PodName: "p2", Ready: false, Message: "failed to load"},
+		{PodName: "p3", Ready: true},
+	}, statuses)
+	assert.Equal(t, int32(1), countReadyInstances(statuses))
+}
+
+func TestDesiredReplicas(t *testing.T) {
+	instance := &modelv1alpha1.ModelAdapter{}
+	assert.Equal(t, 1, desiredReplicas(instance))
+	instance.Spec.Replicas = ptr.To(int32(3))
+	assert.Equal(t, 3, desiredReplicas(instance))
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelrouter
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=model.aibrix.ai,resources=modelaliases,verbs=get;list;watch
+
+func (m *ModelRouter) addRouteFromModelAlias(obj interface{}) {
+	alias := obj.(*modelv1alpha1.ModelAlias)
+	m.applyAliasHTTPRoute(alias)
+}
+
+func (m *ModelRouter) updateRouteFromModelAlias(oldObj interface{}, newObj interface{}) {
+	oldAlias := oldObj.(*modelv1alpha1.ModelAlias)
+	newAlias := newObj.(*modelv1alpha1.ModelAlias)
+	if oldAlias.AliasName() != newAlias.AliasName() {
+		m.deleteAliasHTTPRoute(oldAlias)
+	}
+	m.applyAliasHTTPRoute(newAlias)
+}
+
+func (m *ModelRouter) deleteRouteFromModelAlias(obj interface{}) {
+	alias, ok := obj.(*modelv1alpha1.ModelAlias)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		alias, ok = tombstone.Obj.(*modelv1alpha1.ModelAlias)
+		if !ok {
+			return
+		}
+	}
+	m.deleteAliasHTTPRoute(alias)
+}
+
+// applyAliasHTTPRoute creates or updates the httproute of the alias, which splits the requests of the alias across
+// the services of its backend models by weight. The gateway plugin rewrites the model of aliased requests, the route
+// serves requests the plugin does not process.
+func (m *ModelRouter) applyAliasHTTPRoute(alias *modelv1alpha1.ModelAlias) {
+	aliasName := alias.AliasName()
+	namespace := alias.Namespace
+
+	backendRefs := make([]gatewayv1.HTTPBackendRef, 0, len(alias.Spec.Backends))
+	for _, backend := range alias.Spec.Backends {
+		backendRefs = append(backendRefs, gatewayv1.HTTPBackendRef{
+			BackendRef: gatewayv1.BackendRef{
+				BackendObjectReference: gatewayv1.BackendObjectReference{
+					Name:      gatewayv1.ObjectName(backend.Model),
+					Namespace: (*gatewayv1.Namespace)(&namespace),
+					Port:      ptr.To(gatewayv1.PortNumber(m.modelServicePort(namespace, backend.Model))),
+				},
+				Weight: ptr.To(backend.Weight),
+			},
+		})
+	}
+
+	modelHeaderMatch := gatewayv1.HTTPHeaderMatch{
+		Type:  ptr.To(gatewayv1.HeaderMatchExact),
+		Name:  modelHeaderIdentifier,
+		Value: aliasName,
+	}
+	spec := gatewayv1.HTTPRouteSpec{
+		CommonRouteSpec: gatewayv1.CommonRouteSpec{
+			ParentRefs: []gatewayv1.ParentReference{
+				{
+					Name:      aibrixEnvoyGateway,
+					Namespace: ptr.To(gatewayv1.Namespace(aibrixEnvoyGatewayNamespace)),
+				},
+			},
+		},
+		Rules: []gatewayv1.HTTPRouteRule{
+			{
+				Matches:     buildModelRouteMatches(modelHeaderMatch),
+				BackendRefs: backendRefs,
+				Timeouts: &gatewayv1.HTTPRouteTimeouts{
+					Request: ptr.To(gatewayv1.Duration("120s")),
+				},
+			},
+		},
+	}
+
+	httpRoute := gatewayv1.HTTPRoute{}
+	key := types.NamespacedName{Name: fmt.Sprintf("%s-router", aliasName), Namespace: aibrixEnvoyGatewayNamespace}
+	err := m.Client.Get(context.Background(), key, &httpRoute)
+	switch {
+	case apierrors.IsNotFound(err):
+		httpRoute = gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       spec,
+		}
+		if err := m.Client.Create(context.Background(), &httpRoute); err != nil {
+			klog.ErrorS(err, "Failed to create httproute", "namespace", key.Namespace, "name", key.Name)
+			return
+		}
+		klog.Infof("httproute: %v created for model alias: %v", key.Name, aliasName)
+	case err != nil:
+		klog.ErrorS(err, "Failed to get httproute", "namespace", key.Namespace, "name", key.Name)
+		return
+	default:
+		httpRoute.Spec = spec
+		if err := m.Client.Update(context.Background(), &httpRoute); err != nil {
+			klog.ErrorS(err, "Failed to update httproute", "namespace", key.Namespace, "name", key.Name)
+			return
+		}
+		klog.Infof("httproute: %v updated for model alias: %v", key.Name, aliasName)
+	}
+
+	if aibrixEnvoyGatewayNamespace != namespace {
+		m.createReferenceGrant(namespace)
+	}
+}
+
+func (m *ModelRouter) deleteAliasHTTPRoute(alias *modelv1alpha1.ModelAlias) {
+	httpRoute := gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-router", alias.AliasName()),
+			Namespace: aibrixEnvoyGatewayNamespace,
+		},
+	}
+	if err := m.Client.Delete(context.Background(), &httpRoute); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete httproute", "name", httpRoute.Name)
+		}
+		return
+	}
+	klog.Infof("httproute: %v deleted for model alias: %v", httpRoute.Name, alias.AliasName())
+}
+
+// modelServicePort returns the port of the service of a model, the service is named after the model.
+func (m *ModelRouter) modelServicePort(namespace, modelName string) int32 {
+	service := corev1.Service{}
+	if err := m.Client.Get(context.Background(), types.NamespacedName{Name: modelName, Namespace: namespace}, &service); err != nil || len(service.Spec.Ports) == 0 {
+		klog.Infof("service of model %s not found, default port %d will be used", modelName, defaultModelServingPort)
+		return defaultModelServingPort
+	}
+	return service.Spec.Ports[0].Port
+}
@@ -51,6 +51,17 @@ const (
 	defaultModelServingPort = 8000
 )
 
+// modelRoutePathPrefixes are the OpenAI-compatible paths routed to model backends.
+// Gateway API allows at most 8 matches per rule.
+var modelRoutePathPrefixes = []string{
+	"/v1/completions",
+	"/v1/chat/completions",
+	"/v1/embeddings",
+	"/v1/rerank",
+	"/v1/score",
+	"/v1/audio",
+}
+
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=orchestration.aibrix.ai,resources=rayclusterfleets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
@@ -75,6 +86,11 @@ func Add(mgr manager.Manager, runtimeConfig config.RuntimeConfig) error {
 		return err
 	}
 
+	aliasInformer, err := cacher.GetInformer(context.TODO(), &modelv1alpha1.ModelAlias{})
+	if err != nil {
+		return err
+	}
+
 	utilruntime.Must(gatewayv1.AddToScheme(mgr.GetClient().Scheme()))
 	utilruntime.Must(gatewayv1beta1.AddToScheme(mgr.GetClient().Scheme()))
 
@@ -103,6 +119,15 @@ func Add(mgr manager.Manager, runtimeConfig config.RuntimeConfig) error {
 		AddFunc:    modelRouter.addRouteFromRayClusterFleet,
 		DeleteFunc: modelRouter.deleteRouteFromRayClusterFleet,
 	})
+	if err != nil {
+		return err
+	}
+
+	_, err = aliasInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    modelRouter.addRouteFromModelAlias,
+		UpdateFunc: modelRouter.updateRouteFromModelAlias,
+		DeleteFunc: modelRouter.deleteRouteFromModelAlias,
+	})
 
 	return err
 }
@@ -208,26 +233,7 @@ func (m *ModelRouter) createHTTPRoute(namespace string, labels map[string]string
 			},
 			Rules: []gatewayv1.HTTPRouteRule{
 				{
-					Matches: []gatewayv1.HTTPRouteMatch{
-						{
-							Path: &gatewayv1.HTTPPathMatch{
-								Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
-								Value: ptr.To("/v1/completions"),
-							},
-							Headers: []gatewayv1.HTTPHeaderMatch{
-								modelHeaderMatch,
-							},
-						},
-						{
-							Path: &gatewayv1.HTTPPathMatch{
-								Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
-								Value: ptr.To("/v1/chat/completions"),
-							},
-							Headers: []gatewayv1.HTTPHeaderMatch{
-								modelHeaderMatch,
-							},
-						},
-					},
+					Matches: buildModelRouteMatches(modelHeaderMatch),
 					BackendRefs: []gatewayv1.HTTPBackendRef{
 						{
 							BackendRef: gatewayv1.BackendRef{
@@ -264,6 +270,23 @@ func (m *ModelRouter) createHTTPRoute(namespace string, labels map[string]string
 	}
 }
 
+// buildModelRouteMatches returns a path prefix match for every inference path served by the gateway.
+func buildModelRouteMatches(modelHeaderMatch gatewayv1.HTTPHeaderMatch) []gatewayv1.HTTPRouteMatch {
+	matches := make([]gatewayv1.HTTPRouteMatch, 0, len(modelRoutePathPrefixes))
+	for _, pathPrefix := range modelRoutePathPrefixes {
+		matches = append(matches, gatewayv1.HTTPRouteMatch{
+			Path: &gatewayv1.HTTPPathMatch{
+				Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+				Value: ptr.To(pathPrefix),
+			},
+			Headers: []gatewayv1.HTTPHeaderMatch{
+				modelHeaderMatch,
+			},
+		})
+	}
+	return matches
+}
+
 func (m *ModelRouter) createReferenceGrant(namespace string) {
 	referenceGrantName := fmt.Sprintf("%s-reserved-referencegrant-in-%s", aibrixEnvoyGatewayNamespace, namespace)
 	referenceGrant := gatewayv1beta1.ReferenceGrant{
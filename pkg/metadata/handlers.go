@@ -17,6 +17,7 @@ limitations under the License.
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -44,12 +45,16 @@ func NewHTTPServer(addr string, redis *redis.Client) *http.Server {
 		redisClient: redis,
 		cache:       c,
 	}
+	if err := utils.IndexUsers(context.Background(), redis); err != nil {
+		klog.ErrorS(err, "failed to index existing users, they are not listed until updated")
+	}
 	r := mux.NewRouter()
 	// User related handlers
 	r.HandleFunc("/CreateUser", server.createUser).Methods("POST")
 	r.HandleFunc("/ReadUser", server.readUser).Methods("POST")
 	r.HandleFunc("/UpdateUser", server.updateUser).Methods("POST")
 	r.HandleFunc("/DeleteUser", server.deleteUser).Methods("POST")
+	server.registerRESTHandlers(r)
 	// OpenAI API related handlers
 	r.HandleFunc("/v1/models", server.models).Methods("GET")
 
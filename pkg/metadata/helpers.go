@@ -22,10 +22,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/vllm-project/aibrix/pkg/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
 )
 
 type malformedRequest struct {
@@ -37,7 +43,7 @@ func (mr *malformedRequest) Error() string {
 	return mr.msg
 }
 
-func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst *utils.User) error {
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
 	ct := r.Header.Get("Content-Type")
 	if ct != "" {
 		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
@@ -52,7 +58,7 @@ func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst *utils.User) err
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
-	err := dec.Decode(&dst)
+	err := dec.Decode(dst)
 	if err != nil {
 		var syntaxError *json.SyntaxError
 		var unmarshalTypeError *json.UnmarshalTypeError
@@ -97,3 +103,44 @@ func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst *utils.User) err
 	validate := validator.New()
 	return validate.Struct(dst)
 }
+
+// writeDecodeError writes the error of decodeJSONBody.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var mr *malformedRequest
+	if errors.As(err, &mr) {
+		writeJSONError(w, mr.status, mr.msg)
+		return
+	}
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		writeJSONError(w, http.StatusBadRequest, ve.Error())
+		return
+	}
+	klog.Info(err.Error())
+	writeJSONError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.ErrorS(err, "failed to write response")
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, ErrorResponse{Error: ErrorDetail{Message: msg}})
+}
+
+// parsePagination reads the limit and after query parameters of list requests.
+func parsePagination(r *http.Request) (string, int64, error) {
+	limit := int64(defaultListLimit)
+	if val := r.URL.Query().Get("limit"); val != "" {
+		parsed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || parsed <= 0 || parsed > maxListLimit {
+			return "", 0, fmt.Errorf("limit must be an integer between 1 and %d", maxListLimit)
+		}
+		limit = parsed
+	}
+	return r.URL.Query().Get("after"), limit, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		expectedAfter string
+		expectedLimit int64
+		expectedErr   bool
+	}{
+		{name: "defaults", url: "/v1/users", expectedLimit: defaultListLimit},
+		{name: "limit and after", url: "/v1/users?limit=5&after=alice", expectedAfter: "alice", expectedLimit: 5},
+		{name: "zero limit", url: "/v1/users?limit=0", expectedErr: true},
+		{name: "limit too large", url: "/v1/users?limit=1000", expectedErr: true},
+		{name: "invalid limit", url: "/v1/users?limit=abc", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			after, limit, err := parsePagination(httptest.NewRequest("GET", tc.url, nil))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if after != tc.expectedAfter || limit != tc.expectedLimit {
+				t.Errorf("expected after: %q, limit: %d, got after: %q, limit: %d", tc.expectedAfter, tc.expectedLimit, after, limit)
+			}
+		})
+	}
+}
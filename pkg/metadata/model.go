@@ -16,6 +16,8 @@ limitations under the License.
 
 package metadata
 
+import "github.com/vllm-project/aibrix/pkg/utils"
+
 // ModelInfo represents the information about a single model
 type ModelInfo struct {
 	ID      string `json:"id"`
@@ -30,6 +32,30 @@ type ModelListResponse struct {
 	Data   []ModelInfo `json:"data"`
 }
 
+// ListResponse is a page of a list, the next page starts after LastID if HasMore is true.
+type ListResponse[T any] struct {
+	Object  string `json:"object"`
+	Data    []T    `json:"data"`
+	FirstID string `json:"first_id,omitempty"`
+	LastID  string `json:"last_id,omitempty"`
+	HasMore bool   `json:"has_more"`
+}
+
+// ErrorResponse is the error body of the REST api
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Message string `json:"message"`
+}
+
+// CreateAPIKeyResponse carries the api key, which is only returned on creation
+type CreateAPIKeyResponse struct {
+	utils.APIKey
+	Key string `json:"key"`
+}
+
 // BuildModelsResponse converts a list of model names to the target response type
 func BuildModelsResponse(modelNames []string) ModelListResponse {
 	response := ModelListResponse{
@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"k8s.io/klog/v2"
+)
+
+// registerRESTHandlers registers the REST api of users and api keys.
+// The RPC style user handlers are kept for compatibility, both share the same storage.
+func (s *httpServer) registerRESTHandlers(r *mux.Router) {
+	r.HandleFunc("/v1/users", s.listUsers).Methods("GET")
+	r.HandleFunc("/v1/users", s.createUserV1).Methods("POST")
+	r.HandleFunc("/v1/users/{name}", s.getUserV1).Methods("GET")
+	r.HandleFunc("/v1/users/{name}", s.updateUserV1).Methods("PUT")
+	r.HandleFunc("/v1/users/{name}", s.deleteUserV1).Methods("DELETE")
+	r.HandleFunc("/v1/keys", s.listKeys).Methods("GET")
+	r.HandleFunc("/v1/keys", s.createKey).Methods("POST")
+	r.HandleFunc("/v1/keys/{id}", s.getKey).Methods("GET")
+	r.HandleFunc("/v1/keys/{id}", s.deleteKey).Methods("DELETE")
+}
+
+func (s *httpServer) listUsers(w http.ResponseWriter, r *http.Request) {
+	after, limit, err := parsePagination(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, hasMore, err := utils.ListUsers(r.Context(), after, limit, s.redisClient)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on listing users: %+v", err))
+		return
+	}
+
+	response := ListResponse[utils.User]{Object: "list", Data: users, HasMore: hasMore}
+	if len(users) > 0 {
+		response.FirstID = users[0].Name
+		response.LastID = users[len(users)-1].Name
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *httpServer) createUserV1(w http.ResponseWriter, r *http.Request) {
+	var u utils.User
+	if err := decodeJSONBody(w, r, &u); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := utils.ValidateUser(u); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if utils.CheckUser(r.Context(), u, s.redisClient) {
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("user: %s exists", u.Name))
+		return
+	}
+
+	if err := utils.SetUser(r.Context(), u, s.redisClient); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on creating user: %+v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (s *httpServer) getUserV1(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	user, err := utils.GetUser(r.Context(), utils.User{Name: name}, s.redisClient)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("user: %s does not exist", name))
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on reading user: %+v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *httpServer) updateUserV1(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	// The name can be omitted from the body, it is identified by the path.
+	u := utils.User{Name: name}
+	if err := decodeJSONBody(w, r, &u); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if u.Name != name {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("user name: %s does not match the path: %s", u.Name, name))
+		return
+	}
+	if err := utils.ValidateUser(u); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !utils.CheckUser(r.Context(), u, s.redisClient) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("user: %s does not exist", name))
+		return
+	}
+
+	if err := utils.SetUser(r.Context(), u, s.redisClient); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on updating user: %+v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *httpServer) deleteUserV1(w http.ResponseWriter, r *http.Request) {
+	u := utils.User{Name: mux.Vars(r)["name"]}
+	if !utils.CheckUser(r.Context(), u, s.redisClient) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("user: %s does not exist", u.Name))
+		return
+	}
+
+	if err := utils.DelUser(r.Context(), u, s.redisClient); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on deleting user: %+v", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *httpServer) listKeys(w http.ResponseWriter, r *http.Request) {
+	after, limit, err := parsePagination(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	keys, hasMore, err := utils.ListAPIKeys(r.Context(), r.URL.Query().Get("user"), after, limit, s.redisClient)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on listing api keys: %+v", err))
+		return
+	}
+
+	response := ListResponse[utils.APIKey]{Object: "list", Data: keys, HasMore: hasMore}
+	if len(keys) > 0 {
+		response.FirstID = keys[0].ID
+		response.LastID = keys[len(keys)-1].ID
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *httpServer) createKey(w http.ResponseWriter, r *http.Request) {
+	var k utils.APIKey
+	if err := decodeJSONBody(w, r, &k); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if k.ID != "" || k.Prefix != "" || k.CreatedAt != 0 {
+		writeJSONError(w, http.StatusBadRequest, "id, prefix and createdAt are generated and can not be set")
+		return
+	}
+	if !utils.CheckUser(r.Context(), utils.User{Name: k.User}, s.redisClient) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("user: %s does not exist", k.User))
+		return
+	}
+
+	created, key, err := utils.CreateAPIKey(r.Context(), k, s.redisClient)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on creating api key: %+v", err))
+		return
+	}
+
+	klog.InfoS("api key created", "id", created.ID, "user", created.User)
+	writeJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: created, Key: key})
+}
+
+func (s *httpServer) getKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	k, err := utils.GetAPIKey(r.Context(), id, s.redisClient)
+	if err != nil {
+		if errors.Is(err, utils.ErrAPIKeyNotFound) {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("api key: %s does not exist", id))
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on reading api key: %+v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, k)
+}
+
+func (s *httpServer) deleteKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := utils.DelAPIKey(r.Context(), id, s.redisClient); err != nil {
+		if errors.Is(err, utils.ErrAPIKeyNotFound) {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("api key: %s does not exist", id))
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error occurred on deleting api key: %+v", err))
+		return
+	}
+
+	klog.InfoS("api key revoked", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
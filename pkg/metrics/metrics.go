@@ -48,6 +48,14 @@ const (
 	RunningLoraAdapters                  = "running_lora_adapters"
 	VTCBucketSizeActive                  = "vtc_bucket_size_active"
 	RealtimeNumRequestsRunning           = "realtime_num_requests_running"
+	GatewayQueueDepth                    = "gateway_queue_depth"
+	GatewayShadowRequestsRunning         = "gateway_shadow_requests_running"
+	GatewayAvgTTFT                       = "gateway_avg_ttft_seconds"
+	GatewayP50TTFT                       = "gateway_p50_ttft_seconds"
+	GatewayP95TTFT                       = "gateway_p95_ttft_seconds"
+	GatewayAvgTPOT                       = "gateway_avg_tpot_seconds"
+	GatewayP50TPOT                       = "gateway_p50_tpot_seconds"
+	GatewayP95TPOT                       = "gateway_p95_tpot_seconds"
 )
 
 var (
@@ -311,5 +319,69 @@ var (
 			},
 			Description: "Current adaptive bucket size used by VTC algorithm for token normalization",
 		},
+		GatewayQueueDepth: {
+			MetricScope:  ModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "Number of requests held in the gateway admission queue of a model",
+		},
+		GatewayShadowRequestsRunning: {
+			MetricScope:  ModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "Number of requests mirrored by the gateway to a shadow model and not yet completed",
+		},
+		GatewayAvgTTFT: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: GatewayObserved,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "Average time to first token of streaming requests measured by the gateway",
+		},
+		GatewayP50TTFT: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: GatewayObserved,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "P50 time to first token of streaming requests measured by the gateway",
+		},
+		GatewayP95TTFT: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: GatewayObserved,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "P95 time to first token of streaming requests measured by the gateway",
+		},
+		GatewayAvgTPOT: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: GatewayObserved,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "Average time per output token of streaming requests measured by the gateway",
+		},
+		GatewayP50TPOT: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: GatewayObserved,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "P50 time per output token of streaming requests measured by the gateway",
+		},
+		GatewayP95TPOT: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: GatewayObserved,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "P95 time per output token of streaming requests measured by the gateway",
+		},
 	}
 )
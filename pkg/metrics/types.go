@@ -33,6 +33,9 @@ const (
 	PrometheusEndpoint MetricSource = "PrometheusEndpoint"
 	// PodRawMetrics indicates metrics are collected directly from the metricPort of a Pod.
 	PodRawMetrics MetricSource = "PodRawMetrics"
+	// GatewayObserved indicates metrics are measured by the gateway on the requests it routes,
+	// over a sliding window kept in memory.
+	GatewayObserved MetricSource = "GatewayObserved"
 )
 
 // RawMetricType defines the type of raw metrics (e.g., collected directly from a source).
@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Parameters of the composite router, parameters of a filter or scorer are prefixed by its name, e.g. "kv-cache-usage.maxUsage".
+	compositeParamFilters = "filters"
+	compositeParamScorers = "scorers"
+
+	defaultCompositeScorers = "least-request:1"
+)
+
+var (
+	RouterComposite  types.RoutingAlgorithm = "composite"
+	compositeFilters                        = utils.LoadEnv("AIBRIX_ROUTER_COMPOSITE_FILTERS", "")
+	compositeScorers                        = utils.LoadEnv("AIBRIX_ROUTER_COMPOSITE_SCORERS", defaultCompositeScorers)
+)
+
+func init() {
+	RegisterWithParameters(RouterComposite, NewCompositeRouter)
+}
+
+type namedFilter struct {
+	name   string
+	filter types.Filter
+}
+
+type weightedScorer struct {
+	name   string
+	weight float64
+	scorer types.Scorer
+}
+
+// compositeRouter drops pods by filters, then routes to the pod with the highest weighted sum of scores.
+type compositeRouter struct {
+	filters []namedFilter
+	scorers []weightedScorer
+}
+
+// NewCompositeRouter returns a composite router. Filters are a comma separated list of filter names,
+// scorers are a comma separated list of scorer names with optional weights, e.g. "prefix-cache:2,least-request:1".
+func NewCompositeRouter(params types.RouterParameters) (types.Router, error) {
+	filterNames := splitList(params.String(compositeParamFilters, compositeFilters))
+	scorerWeights, err := parseScorerWeights(params.String(compositeParamScorers, compositeScorers))
+	if err != nil {
+		return nil, err
+	}
+	if len(scorerWeights) == 0 {
+		return nil, fmt.Errorf("no scorers set")
+	}
+
+	componentParams := map[string]types.RouterParameters{}
+	for key, value := range params {
+		if key == compositeParamFilters || key == compositeParamScorers {
+			continue
+		}
+		name, param, ok := strings.Cut(key, ".")
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q", key)
+		}
+		if componentParams[name] == nil {
+			componentParams[name] = types.RouterParameters{}
+		}
+		componentParams[name][param] = value
+	}
+
+	r := &compositeRouter{}
+	used := map[string]bool{}
+	for _, name := range filterNames {
+		filter, err := NewFilter(name, componentParams[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %s: %w", name, err)
+		}
+		r.filters = append(r.filters, namedFilter{name: name, filter: filter})
+		used[name] = true
+	}
+	for _, sw := range scorerWeights {
+		scorer, err := NewScorer(sw.name, componentParams[sw.name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid scorer %s: %w", sw.name, err)
+		}
+		sw.scorer = scorer
+		r.scorers = append(r.scorers, sw)
+		used[sw.name] = true
+	}
+	for name := range componentParams {
+		if !used[name] {
+			return nil, fmt.Errorf("parameters of %s which is not a filter or scorer in use", name)
+		}
+	}
+
+	klog.InfoS("composite_configurations", "filters", filterNames, "scorers", params.String(compositeParamScorers, compositeScorers))
+	return r, nil
+}
+
+// Close releases the resources of the scorers.
+func (r *compositeRouter) Close() {
+	for _, sw := range r.scorers {
+		closeRouter(sw.scorer)
+	}
+}
+
+func (r *compositeRouter) Route(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
+	pods := readyPodList.All()
+	for _, f := range r.filters {
+		filtered := f.filter.Filter(ctx, pods)
+		if len(filtered) == 0 {
+			// Skip the filter rather than fail the request if no pod passes it.
+			klog.V(4).InfoS("composite filter dropped all pods, skipped", "requestID", ctx.RequestID, "filter", f.name)
+			continue
+		}
+		pods = filtered
+	}
+	if len(pods) == 0 {
+		return "", fmt.Errorf("no pods to forward request")
+	}
+
+	scores := make([]float64, len(pods))
+	for _, s := range r.scorers {
+		podScores, err := s.scorer.Score(ctx, pods)
+		if err != nil || len(podScores) != len(pods) {
+			klog.ErrorS(err, "composite scorer failed, skipped", "requestID", ctx.RequestID, "scorer", s.name)
+			continue
+		}
+		for i, score := range podScores {
+			scores[i] += s.weight * score
+		}
+	}
+
+	var targetPods []*v1.Pod
+	var maxScore float64
+	for i, score := range scores {
+		if len(targetPods) == 0 || score > maxScore {
+			targetPods, maxScore = []*v1.Pod{pods[i]}, score
+		} else if score == maxScore {
+			targetPods = append(targetPods, pods[i])
+		}
+	}
+	targetPod := targetPods[rand.Intn(len(targetPods))]
+	klog.V(4).InfoS("composite_routing", "requestID", ctx.RequestID, "targetPod", targetPod.Name, "score", maxScore)
+
+	for _, f := range r.filters {
+		if observer, ok := f.filter.(types.RoutedObserver); ok {
+			observer.Routed(ctx, targetPod)
+		}
+	}
+	for _, s := range r.scorers {
+		if observer, ok := s.scorer.(types.RoutedObserver); ok {
+			observer.Routed(ctx, targetPod)
+		}
+	}
+
+	ctx.SetTargetPod(targetPod)
+	return ctx.TargetAddress(), nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseScorerWeights parses "name[:weight],...", the weight defaults to 1.
+func parseScorerWeights(list string) ([]weightedScorer, error) {
+	var scorers []weightedScorer
+	for _, item := range splitList(list) {
+		name, weight, hasWeight := strings.Cut(item, ":")
+		sw := weightedScorer{name: strings.TrimSpace(name), weight: 1}
+		if hasWeight {
+			w, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight of scorer %s: %q", sw.name, weight)
+			}
+			sw.weight = w
+		}
+		scorers = append(scorers, sw)
+	}
+	return scorers, nil
+}
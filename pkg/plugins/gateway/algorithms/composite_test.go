@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils/prefixcacheindexer"
+	"github.com/vllm-project/aibrix/pkg/utils/tokenizer"
+	v1 "k8s.io/api/core/v1"
+)
+
+// dropPodFilter drops a pod by name.
+type dropPodFilter string
+
+func (f dropPodFilter) Filter(_ *types.RoutingContext, pods []*v1.Pod) []*v1.Pod {
+	var filtered []*v1.Pod
+	for _, pod := range pods {
+		if pod.Name != string(f) {
+			filtered = append(filtered, pod)
+		}
+	}
+	return filtered
+}
+
+func TestCompositeRouter(t *testing.T) {
+	readyPods := getReadyPods()
+	c := cache.NewTestCacheWithPodsMetrics(
+		readyPods,
+		"m1",
+		map[string]map[string]metrics.MetricValue{
+			"p1": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+			"p2": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 4}},
+			"p3": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 2}},
+			"p4": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 8}},
+		})
+	podList := podsFromCache(c)
+	prefixScorer := &prefixCacheScorer{
+		tokenizer:          tokenizer.NewCharacterTokenizer(),
+		prefixCacheIndexer: prefixcacheindexer.NewPrefixHashTable(),
+	}
+	r := &compositeRouter{
+		filters: []namedFilter{{name: "drop-p1", filter: dropPodFilter("p1")}},
+		scorers: []weightedScorer{
+			{name: ScorerPrefixCache, weight: 2, scorer: prefixScorer},
+			{name: ScorerLeastRequest, weight: 1, scorer: &metricScorer{cache: c, value: getRunningRequests}},
+		},
+	}
+
+	// No prefix is cached, the least loaded pod not filtered wins.
+	input := "abcdefghijklmnopqrstuvwxyz"
+	ctx := types.NewRoutingContext(context.Background(), RouterComposite, "m1", input, "r1", "")
+	_, err := r.Route(ctx, podList)
+	assert.NoError(t, err)
+	assert.Equal(t, "p3", ctx.TargetPod().Name)
+
+	// p3 becomes the most loaded pod, but the prefix cached on p3 outweighs its load.
+	loaded := cache.NewTestCacheWithPodsMetrics(
+		getReadyPods(),
+		"m1",
+		map[string]map[string]metrics.MetricValue{
+			"p2": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+			"p3": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 8}},
+			"p4": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 4}},
+		})
+	r.scorers[1].scorer = &metricScorer{cache: loaded, value: getRunningRequests}
+	ctx = types.NewRoutingContext(context.Background(), RouterComposite, "m1", input, "r2", "")
+	_, err = r.Route(ctx, podList)
+	assert.NoError(t, err)
+	assert.Equal(t, "p3", ctx.TargetPod().Name)
+
+	r.scorers[0].weight = 0
+	ctx = types.NewRoutingContext(context.Background(), RouterComposite, "m1", input, "r3", "")
+	_, err = r.Route(ctx, podList)
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", ctx.TargetPod().Name)
+
+	// Filters dropping all pods are skipped.
+	r.filters = append(r.filters, namedFilter{name: "drop-all", filter: filterFunc(func([]*v1.Pod) []*v1.Pod { return nil })})
+	ctx = types.NewRoutingContext(context.Background(), RouterComposite, "m1", input, "r4", "")
+	_, err = r.Route(ctx, podList)
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", ctx.TargetPod().Name)
+}
+
+type filterFunc func(pods []*v1.Pod) []*v1.Pod
+
+func (f filterFunc) Filter(_ *types.RoutingContext, pods []*v1.Pod) []*v1.Pod {
+	return f(pods)
+}
+
+func TestNewCompositeRouter(t *testing.T) {
+	cache.InitForTest()
+
+	router, err := NewCompositeRouter(types.RouterParameters{
+		"filters":                    "kv-cache-usage,queue-depth",
+		"scorers":                    "prefix-cache:2, least-request, least-kv-cache:0.5",
+		"kv-cache-usage.maxUsage":    "0.8",
+		"queue-depth.maxWaiting":     "4",
+		"prefix-cache.tokenizerType": "tiktoken",
+	})
+	assert.NoError(t, err)
+	r := router.(*compositeRouter)
+	assert.Len(t, r.filters, 2)
+	assert.Equal(t, 0.8, r.filters[0].filter.(*metricFilter).limit)
+	assert.Equal(t, 4.0, r.filters[1].filter.(*metricFilter).limit)
+	assert.Equal(t, []float64{2, 1, 0.5}, []float64{r.scorers[0].weight, r.scorers[1].weight, r.scorers[2].weight})
+
+	for _, params := range []types.RouterParameters{
+		{"scorers": ""},
+		{"scorers": "unknown"},
+		{"scorers": "least-request:-1"},
+		{"filters": "unknown"},
+		{"unknown": "1"},
+		{"kv-cache-usage.maxUsage": "0.8"},
+		{"filters": "kv-cache-usage", "kv-cache-usage.minUsage": "0.8"},
+	} {
+		_, err := NewCompositeRouter(params)
+		assert.Error(t, err, params)
+	}
+}
+
+func TestNormalizeLowerIsBetter(t *testing.T) {
+	assert.Equal(t, []float64{1, 0.5, 0, 0}, normalizeLowerIsBetter([]float64{2, 4, 6, math.NaN()}))
+	assert.Equal(t, []float64{1, 1}, normalizeLowerIsBetter([]float64{3, 3}))
+}
@@ -54,17 +54,43 @@ func (r leastExpectedLatencyRouter) Route(ctx *types.RoutingContext, readyPodLis
 	var targetPod *v1.Pod
 	minExpectedLatency := math.MaxFloat64
 
+	readyPods := readyPodList.All()
+	for i, totalExpectedLatency := range getExpectedLatencies(r.cache, ctx.Model, readyPods) {
+		if math.IsNaN(totalExpectedLatency) {
+			continue
+		}
+		if totalExpectedLatency <= minExpectedLatency {
+			minExpectedLatency = totalExpectedLatency
+			targetPod = readyPods[i]
+		}
+	}
+
+	// Use fallback if no valid metrics
+	if targetPod == nil {
+		var err error
+		targetPod, err = SelectRandomPodAsFallback(ctx, readyPods, rand.Intn)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	ctx.SetTargetPod(targetPod)
+	return ctx.TargetAddress(), nil
+}
+
+// getExpectedLatencies returns the expected latency of a request on each of the pods, NaN if the metrics are missing.
+func getExpectedLatencies(c cache.Cache, model string, pods []*v1.Pod) []float64 {
 	sumPromptTokens := 0.0
 	sumGenerationTokens := 0.0
 	cntPromt := 0
 	cntGeneration := 0
-	for _, pod := range readyPodList.All() {
-		avgPromptTokens, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.AvgPromptToksPerReq)
+	for _, pod := range pods {
+		avgPromptTokens, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.AvgPromptToksPerReq)
 		if err != nil {
 			klog.Error(err)
 			continue
 		}
-		avgGenerationTokens, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.AvgGenerationToksPerReq)
+		avgGenerationTokens, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.AvgGenerationToksPerReq)
 		if err != nil {
 			klog.Error(err)
 			continue
@@ -87,21 +113,31 @@ func (r leastExpectedLatencyRouter) Route(ctx *types.RoutingContext, readyPodLis
 		guessGenerationTokens = sumGenerationTokens / float64(cntGeneration)
 	}
 
-	for _, pod := range readyPodList.All() {
+	// Prefer the latency observed by the gateway, which reflects the recent requests rather than the engine averages.
+	// The latencies of the pods are compared by a single source, so the engine metrics are used unless the gateway
+	// observed all the pods.
+	if latencies, ok := getGatewayObservedLatencies(c, model, pods, guessGenerationTokens); ok {
+		return latencies
+	}
+
+	latencies := make([]float64, len(pods))
+	for i, pod := range pods {
+		latencies[i] = math.NaN()
+
 		// expected queuing latency
-		queuingLatency, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.RequestQueueTimeSeconds)
+		queuingLatency, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.RequestQueueTimeSeconds)
 		if err != nil {
 			klog.Error(err)
 			continue
 		}
 
 		// expected prefill latency
-		avgPromptTokens, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.AvgPromptToksPerReq)
+		avgPromptTokens, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.AvgPromptToksPerReq)
 		if err != nil {
 			klog.Error(err)
 			continue
 		}
-		PrefillTime, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.RequestPrefillTimeSeconds)
+		PrefillTime, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.RequestPrefillTimeSeconds)
 		if err != nil {
 			klog.Error(err)
 			continue
@@ -109,12 +145,12 @@ func (r leastExpectedLatencyRouter) Route(ctx *types.RoutingContext, readyPodLis
 		prefillLatency := PrefillTime.GetHistogramValue().GetMean() / avgPromptTokens.GetSimpleValue() * guessPromptTokens
 
 		// expected decode latency
-		avgGenerationTokens, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.AvgGenerationToksPerReq)
+		avgGenerationTokens, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.AvgGenerationToksPerReq)
 		if err != nil {
 			klog.Error(err)
 			continue
 		}
-		DecodeTime, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.RequestDecodeTimeSeconds)
+		DecodeTime, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.RequestDecodeTimeSeconds)
 		if err != nil {
 			klog.Error(err)
 			continue
@@ -124,22 +160,37 @@ func (r leastExpectedLatencyRouter) Route(ctx *types.RoutingContext, readyPodLis
 		totalExpectedLatency := queuingLatency.GetSimpleValue() + prefillLatency + decodeLatency
 		klog.V(4).Infof("pod: %v, podIP: %v, queuingLatency: %v, prefillLatency: %v, decodeLatency: %v, totalExpectedLatency: %v",
 			pod.Name, pod.Status.PodIP, queuingLatency.GetSimpleValue(), prefillLatency, decodeLatency, totalExpectedLatency)
-
-		if totalExpectedLatency <= minExpectedLatency {
-			minExpectedLatency = totalExpectedLatency
-			targetPod = pod
-		}
+		latencies[i] = totalExpectedLatency
 	}
+	return latencies
+}
 
-	// Use fallback if no valid metrics
-	if targetPod == nil {
-		var err error
-		targetPod, err = SelectRandomPodAsFallback(ctx, readyPodList.All(), rand.Intn)
-		if err != nil {
-			return "", err
+// getGatewayObservedLatencies returns the expected latency of a request on each of the pods by the TTFT and TPOT
+// observed by the gateway, false if any of the pods served no request of the model in the window.
+func getGatewayObservedLatencies(c cache.Cache, model string, pods []*v1.Pod, generationTokens float64) ([]float64, bool) {
+	latencies := make([]float64, len(pods))
+	for i, pod := range pods {
+		latency, ok := getGatewayObservedLatency(c, model, pod, generationTokens)
+		if !ok {
+			return nil, false
 		}
+		klog.V(4).InfoS("gateway observed latency", "pod", pod.Name, "totalExpectedLatency", latency)
+		latencies[i] = latency
 	}
+	return latencies, len(pods) > 0
+}
 
-	ctx.SetTargetPod(targetPod)
-	return ctx.TargetAddress(), nil
+// getGatewayObservedLatency returns the expected latency by the TTFT and TPOT observed by the gateway,
+// false if no request of the model on the pod is observed in the window. The TTFT is only observed on streaming
+// requests, the TPOT of non-streaming requests includes their prefill.
+func getGatewayObservedLatency(c cache.Cache, model string, pod *v1.Pod, generationTokens float64) (float64, bool) {
+	tpot, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.GatewayAvgTPOT)
+	if err != nil {
+		return 0, false
+	}
+	latency := tpot.GetSimpleValue() * generationTokens
+	if ttft, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.GatewayAvgTTFT); err == nil {
+		latency += ttft.GetSimpleValue()
+	}
+	return latency, true
 }
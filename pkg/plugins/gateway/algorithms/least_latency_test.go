@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestLeastLatencyRouterSource(t *testing.T) {
+	// By the engine metrics, p1 is expected to take 2s and p2 3s.
+	engineMetrics := func(queueTime float64) map[string]metrics.MetricValue {
+		return map[string]metrics.MetricValue{
+			metrics.AvgPromptToksPerReq:       &metrics.SimpleMetricValue{Value: 10},
+			metrics.AvgGenerationToksPerReq:   &metrics.SimpleMetricValue{Value: 100},
+			metrics.RequestQueueTimeSeconds:   &metrics.SimpleMetricValue{Value: queueTime},
+			metrics.RequestPrefillTimeSeconds: &metrics.HistogramMetricValue{Sum: 1, Count: 1},
+			metrics.RequestDecodeTimeSeconds:  &metrics.HistogramMetricValue{Sum: 1, Count: 1},
+		}
+	}
+	c := cache.NewTestCacheWithPodsMetrics(getReadyPods()[:2], "m1", map[string]map[string]metrics.MetricValue{
+		"p1": engineMetrics(0),
+		"p2": engineMetrics(1),
+	})
+	pods := podsFromCache(c)
+	podByName := map[string]*v1.Pod{}
+	for _, pod := range pods.Pods {
+		podByName[pod.Name] = pod
+	}
+	observe := func(podName string, ttft, tpot time.Duration) {
+		ctx := types.NewRoutingContext(context.Background(), RouterLeastLatency, "m1", "", "observed", "")
+		ctx.SetTargetPod(podByName[podName])
+		if ttft > 0 {
+			c.ObserveTTFT(ctx, ttft)
+		}
+		c.ObserveTPOT(ctx, tpot)
+	}
+	route := func() string {
+		ctx := types.NewRoutingContext(context.Background(), RouterLeastLatency, "m1", "", "r1", "")
+		_, err := leastExpectedLatencyRouter{cache: c}.Route(ctx, pods)
+		assert.NoError(t, err)
+		return ctx.TargetPod().Name
+	}
+
+	assert.Equal(t, "p1", route())
+
+	// p1 is slow by the gateway, but p2 is not observed, so the engine metrics are compared.
+	observe("p1", 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, "p1", route())
+
+	// Both pods are observed, p2 only by non-streaming requests.
+	observe("p2", 0, 10*time.Millisecond)
+	assert.Equal(t, "p2", route())
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Parameters of the lora-affinity router, a RoutingPolicy overrides the defaults of the environment variables.
+	loraAffinityParamLoadImbalanceAbsCount = "loadImbalanceAbsCount"
+
+	defaultLoraAffinityLoadImbalanceAbsCount = 8
+
+	// loraScoreActive is the score of a pod the adapter of the request is active on.
+	loraScoreActive = 1.0
+	// loraScoreSwapIn is the score of a pod the adapter would be swapped in on with all its adapter slots free, it is
+	// scaled down by the share of used slots.
+	loraScoreSwapIn = 0.5
+
+	podModelLabel = "model.aibrix.ai/name"
+)
+
+var (
+	RouterLoraAffinity types.RoutingAlgorithm = "lora-affinity"
+
+	loraAffinityLoadImbalanceAbsCount = utils.LoadEnvInt("AIBRIX_LORA_AFFINITY_LOAD_IMBALANCE_ABS_COUNT", defaultLoraAffinityLoadImbalanceAbsCount)
+)
+
+func init() {
+	RegisterWithParameters(RouterLoraAffinity, NewLoraAffinityRouter)
+}
+
+// loraAdapters are the LoRA adapters of a pod reported by the lora_requests_info metric of vLLM.
+type loraAdapters struct {
+	running []string
+	waiting []string
+	maxLora int
+}
+
+// getLoraAdapters returns the LoRA adapters of the pod, false if the pod does not report them.
+func getLoraAdapters(c cache.Cache, pod *v1.Pod) (loraAdapters, bool) {
+	maxLora, err := c.GetMetricValueByPod(pod.Name, pod.Namespace, metrics.MaxLora)
+	if err != nil {
+		return loraAdapters{}, false
+	}
+	adapters := loraAdapters{}
+	if adapters.maxLora, err = strconv.Atoi(maxLora.GetLabelValue()); err != nil || adapters.maxLora <= 0 {
+		return loraAdapters{}, false
+	}
+	if running, err := c.GetMetricValueByPod(pod.Name, pod.Namespace, metrics.RunningLoraAdapters); err == nil {
+		adapters.running = splitList(running.GetLabelValue())
+	}
+	if waiting, err := c.GetMetricValueByPod(pod.Name, pod.Namespace, metrics.WaitingLoraAdapters); err == nil {
+		adapters.waiting = splitList(waiting.GetLabelValue())
+	}
+	return adapters, true
+}
+
+// getLoraAffinity scores a pod for a request of a LoRA adapter. A pod the adapter is active on scores 1. A pod the
+// adapter would be swapped in on scores up to 0.5 by the share of its free adapter slots, adapters waiting on the pod
+// take slots first, and 0 without a free slot. Pods without LoRA metrics and requests of the base model of a pod score 0.
+func getLoraAffinity(c cache.Cache, model string, pod *v1.Pod) float64 {
+	if pod.Labels[podModelLabel] == model {
+		return 0
+	}
+	adapters, ok := getLoraAdapters(c, pod)
+	if !ok {
+		return 0
+	}
+	if slices.Contains(adapters.running, model) {
+		return loraScoreActive
+	}
+	used := len(adapters.running)
+	for _, adapter := range adapters.waiting {
+		if adapter != model && !slices.Contains(adapters.running, adapter) {
+			used++
+		}
+	}
+	if used >= adapters.maxLora {
+		return 0
+	}
+	return loraScoreSwapIn * float64(adapters.maxLora-used) / float64(adapters.maxLora)
+}
+
+// loraAffinityRouter routes a request of a LoRA adapter to the least loaded pod the adapter is active on, otherwise
+// to a pod with free adapter slots, so that adapters are not swapped in and out of GPU memory.
+type loraAffinityRouter struct {
+	cache cache.Cache
+
+	// loadImbalanceAbsCount is the number of running requests over the least loaded pod beyond which a request is
+	// routed to the least loaded pod regardless of its adapters.
+	loadImbalanceAbsCount int
+}
+
+func NewLoraAffinityRouter(params types.RouterParameters) (types.Router, error) {
+	if err := params.Validate(loraAffinityParamLoadImbalanceAbsCount); err != nil {
+		return nil, err
+	}
+	loadImbalanceAbsCount, err := params.Int(loraAffinityParamLoadImbalanceAbsCount, loraAffinityLoadImbalanceAbsCount)
+	if err != nil {
+		return nil, err
+	}
+	if loadImbalanceAbsCount < 0 {
+		return nil, fmt.Errorf("parameter %q must not be negative, got %d", loraAffinityParamLoadImbalanceAbsCount, loadImbalanceAbsCount)
+	}
+
+	c, err := cache.Get()
+	if err != nil {
+		return nil, err
+	}
+	return loraAffinityRouter{cache: c, loadImbalanceAbsCount: loadImbalanceAbsCount}, nil
+}
+
+func (r loraAffinityRouter) Route(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
+	readyPods := readyPodList.All()
+	if len(readyPods) == 0 {
+		return "", fmt.Errorf("no pods to forward request")
+	}
+
+	var candidates []*v1.Pod
+	maxScore := -1.0
+	for _, pod := range readyPods {
+		score := getLoraAffinity(r.cache, ctx.Model, pod)
+		if score > maxScore {
+			candidates, maxScore = []*v1.Pod{pod}, score
+		} else if score == maxScore {
+			candidates = append(candidates, pod)
+		}
+	}
+
+	targetPod := selectTargetPodWithLeastRequestCount(r.cache, candidates)
+	if leastLoadedPod := selectTargetPodWithLeastRequestCount(r.cache, readyPods); targetPod != nil && leastLoadedPod != nil {
+		requestCounts := getRequestCounts(r.cache, []*v1.Pod{targetPod, leastLoadedPod})
+		if requestCounts[targetPod.Name]-requestCounts[leastLoadedPod.Name] > r.loadImbalanceAbsCount {
+			klog.V(4).InfoS("lora_affinity_load_imbalance", "request_id", ctx.RequestID,
+				"target_pod", targetPod.Name, "least_loaded_pod", leastLoadedPod.Name)
+			targetPod = leastLoadedPod
+		}
+	}
+	if targetPod == nil {
+		var err error
+		if targetPod, err = SelectRandomPodAsFallback(ctx, readyPods, rand.Intn); err != nil {
+			return "", err
+		}
+	}
+
+	klog.V(4).InfoS("lora_affinity_routing", "request_id", ctx.RequestID, "model", ctx.Model,
+		"target_pod", targetPod.Name, "score", maxScore)
+	ctx.SetTargetPod(targetPod)
+	return ctx.TargetAddress(), nil
+}
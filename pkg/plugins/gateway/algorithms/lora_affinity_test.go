@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+)
+
+func TestLoraAffinity(t *testing.T) {
+	// p1 runs lora-1 and lora-2 of 4 slots, p2 runs lora-2 of 4 slots, p3 has its 2 slots full and lora-4 waiting,
+	// and p4 reports no LoRA metrics.
+	c := cache.NewTestCacheWithPodsMetrics(getReadyPods(), "m1", map[string]map[string]metrics.MetricValue{
+		"p1": {
+			metrics.MaxLora:                    &metrics.LabelValueMetricValue{Value: "4"},
+			metrics.RunningLoraAdapters:        &metrics.LabelValueMetricValue{Value: "lora-1,lora-2"},
+			metrics.WaitingLoraAdapters:        &metrics.LabelValueMetricValue{Value: ""},
+			metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 3},
+		},
+		"p2": {
+			metrics.MaxLora:                    &metrics.LabelValueMetricValue{Value: "4"},
+			metrics.RunningLoraAdapters:        &metrics.LabelValueMetricValue{Value: "lora-2"},
+			metrics.WaitingLoraAdapters:        &metrics.LabelValueMetricValue{Value: ""},
+			metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0},
+		},
+		"p3": {
+			metrics.MaxLora:                    &metrics.LabelValueMetricValue{Value: "2"},
+			metrics.RunningLoraAdapters:        &metrics.LabelValueMetricValue{Value: "lora-2,lora-3"},
+			metrics.WaitingLoraAdapters:        &metrics.LabelValueMetricValue{Value: "lora-4"},
+			metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 2},
+		},
+		"p4": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 1}},
+	})
+	pods := podsFromCache(c)
+	scorer := &loraAffinityScorer{cache: c}
+	router := loraAffinityRouter{cache: c, loadImbalanceAbsCount: defaultLoraAffinityLoadImbalanceAbsCount}
+
+	tests := []struct {
+		name      string
+		model     string
+		scores    map[string]float64
+		targetPod string
+	}{
+		{
+			name:      "adapter active on one pod",
+			model:     "lora-1",
+			scores:    map[string]float64{"p1": 1, "p2": 0.375, "p3": 0, "p4": 0},
+			targetPod: "p1",
+		},
+		{
+			name:      "adapter active on several pods, the least loaded is selected",
+			model:     "lora-2",
+			scores:    map[string]float64{"p1": 1, "p2": 1, "p3": 1, "p4": 0},
+			targetPod: "p2",
+		},
+		{
+			name:      "adapter waiting on a pod without free slot",
+			model:     "lora-4",
+			scores:    map[string]float64{"p1": 0.25, "p2": 0.375, "p3": 0, "p4": 0},
+			targetPod: "p2",
+		},
+		{
+			name:      "adapter active on no pod is swapped in on the pod with the most free slots",
+			model:     "lora-5",
+			scores:    map[string]float64{"p1": 0.25, "p2": 0.375, "p3": 0, "p4": 0},
+			targetPod: "p2",
+		},
+		{
+			name:      "base model is routed by least request",
+			model:     "m1",
+			scores:    map[string]float64{"p1": 0, "p2": 0, "p3": 0, "p4": 0},
+			targetPod: "p2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := types.NewRoutingContext(context.Background(), RouterLoraAffinity, tt.model, "", "r1", "")
+			scores, err := scorer.Score(ctx, pods.Pods)
+			assert.NoError(t, err)
+			scoresByPod := map[string]float64{}
+			for i, score := range scores {
+				scoresByPod[pods.Pods[i].Name] = score
+			}
+			assert.Equal(t, tt.scores, scoresByPod)
+
+			address, err := router.Route(ctx, pods)
+			assert.NoError(t, err)
+			assert.Equal(t, ctx.TargetAddress(), address)
+			assert.Equal(t, tt.targetPod, ctx.TargetPod().Name)
+		})
+	}
+
+	// The pod the adapter is active on is skipped if it is overloaded compared to the least loaded pod.
+	router.loadImbalanceAbsCount = 2
+	ctx := types.NewRoutingContext(context.Background(), RouterLoraAffinity, "lora-1", "", "r1", "")
+	_, err := router.Route(ctx, pods)
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", ctx.TargetPod().Name)
+}
+
+func TestNewLoraAffinityRouter(t *testing.T) {
+	_, err := NewLoraAffinityRouter(types.RouterParameters{loraAffinityParamLoadImbalanceAbsCount: "-1"})
+	assert.ErrorContains(t, err, "must not be negative")
+	_, err = NewLoraAffinityRouter(types.RouterParameters{"unknown": "1"})
+	assert.ErrorContains(t, err, "unknown parameter")
+}
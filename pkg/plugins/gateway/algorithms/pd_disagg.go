@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PodRoleLabel is the label of the role of a pod of a model whose prefill and decode are disaggregated.
+	PodRoleLabel   = "model.aibrix.ai/role"
+	PodRolePrefill = "prefill"
+	PodRoleDecode  = "decode"
+
+	// Parameters of the pd-disagg router besides the ones of the prefix-cache router selecting the prefill pod,
+	// a RoutingPolicy overrides the defaults of the environment variables.
+	pdDisaggParamDecodeKVCacheTolerance = "decodeKVCacheTolerance"
+
+	defaultPDDisaggDecodeKVCacheTolerance = 0.1
+)
+
+var (
+	RouterPDDisagg types.RoutingAlgorithm = "pd-disagg"
+
+	pdDisaggDecodeKVCacheTolerance = utils.LoadEnvFloat("AIBRIX_PD_DISAGG_DECODE_KV_CACHE_TOLERANCE", defaultPDDisaggDecodeKVCacheTolerance)
+)
+
+func init() {
+	RegisterWithParameters(RouterPDDisagg, NewPDDisaggRouter)
+}
+
+// pdDisaggRouter routes a request to a prefill pod and a decode pod of the model, told apart by PodRoleLabel.
+// The prefill pod is selected like by the prefix-cache router, the decode pod is the one with the most free KV cache.
+type pdDisaggRouter struct {
+	cache   cache.Cache
+	prefill prefixCacheRouter
+
+	// decodeKVCacheTolerance is the difference of KV cache usage under which decode pods are ranked by running requests.
+	decodeKVCacheTolerance float64
+}
+
+func NewPDDisaggRouter(params types.RouterParameters) (types.Router, error) {
+	if err := params.Validate(prefixCacheParamTokenizerType,
+		prefixCacheParamPodRunningRequestImbalanceAbsCount,
+		prefixCacheParamStandardDeviationFactor,
+		pdDisaggParamDecodeKVCacheTolerance); err != nil {
+		return nil, err
+	}
+	tolerance, err := params.Float(pdDisaggParamDecodeKVCacheTolerance, pdDisaggDecodeKVCacheTolerance)
+	if err != nil {
+		return nil, err
+	}
+	if tolerance < 0 {
+		return nil, fmt.Errorf("parameter %q must not be negative, got %v", pdDisaggParamDecodeKVCacheTolerance, tolerance)
+	}
+
+	prefillParams := types.RouterParameters{}
+	for key, value := range params {
+		if key != pdDisaggParamDecodeKVCacheTolerance {
+			prefillParams[key] = value
+		}
+	}
+	prefill, err := NewPrefixCacheRouter(prefillParams)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cache.Get()
+	if err != nil {
+		return nil, err
+	}
+	return pdDisaggRouter{
+		cache:                  c,
+		prefill:                prefill.(prefixCacheRouter),
+		decodeKVCacheTolerance: tolerance,
+	}, nil
+}
+
+// Route sets the prefill pod of the request and targets its decode pod.
+func (r pdDisaggRouter) Route(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
+	prefillPods, decodePods := splitPodsByRole(readyPodList.All())
+	if len(prefillPods) == 0 || len(decodePods) == 0 {
+		return "", fmt.Errorf("model %s needs ready pods of both roles for %s routing, got %d prefill and %d decode pods",
+			ctx.Model, RouterPDDisagg, len(prefillPods), len(decodePods))
+	}
+
+	prefillPod, err := r.prefill.selectPod(ctx, prefillPods)
+	if err != nil {
+		return "", err
+	}
+	decodePod := r.selectDecodePod(ctx, decodePods)
+
+	klog.V(4).InfoS("pd_disagg_routing",
+		"request_id", ctx.RequestID,
+		"prefill_pod", prefillPod.Name,
+		"decode_pod", decodePod.Name)
+	ctx.SetPrefillPod(prefillPod)
+	ctx.SetTargetPod(decodePod)
+	return ctx.TargetAddress(), nil
+}
+
+// selectDecodePod selects the decode pod with the lowest KV cache usage, the one with the fewest running requests
+// among pods within the tolerance. It falls back to the fewest running requests if no pod reports KV cache usage.
+func (r pdDisaggRouter) selectDecodePod(ctx *types.RoutingContext, decodePods []*v1.Pod) *v1.Pod {
+	usages := make(map[string]float64, len(decodePods))
+	minUsage := math.MaxFloat64
+	for _, pod := range decodePods {
+		usage, err := r.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, metrics.GPUCacheUsagePerc)
+		if err != nil {
+			klog.V(4).InfoS("no kv cache usage of decode pod", "pod", pod.Name, "error", err)
+			continue
+		}
+		usages[pod.Name] = usage.GetSimpleValue()
+		minUsage = math.Min(minUsage, usage.GetSimpleValue())
+	}
+	if len(usages) == 0 {
+		return selectTargetPodWithLeastRequestCount(r.cache, decodePods)
+	}
+
+	candidates := make([]*v1.Pod, 0, len(usages))
+	for _, pod := range decodePods {
+		if usage, ok := usages[pod.Name]; ok && usage <= minUsage+r.decodeKVCacheTolerance {
+			candidates = append(candidates, pod)
+		}
+	}
+	return selectTargetPodWithLeastRequestCount(r.cache, candidates)
+}
+
+// splitPodsByRole splits the pods into prefill and decode pods, pods without a role are neither.
+func splitPodsByRole(pods []*v1.Pod) (prefillPods []*v1.Pod, decodePods []*v1.Pod) {
+	for _, pod := range pods {
+		switch pod.Labels[PodRoleLabel] {
+		case PodRolePrefill:
+			prefillPods = append(prefillPods, pod)
+		case PodRoleDecode:
+			decodePods = append(decodePods, pod)
+		}
+	}
+	return prefillPods, decodePods
+}
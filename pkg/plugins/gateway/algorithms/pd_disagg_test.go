@@ -0,0 +1,114 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"github.com/vllm-project/aibrix/pkg/utils/prefixcacheindexer"
+	"github.com/vllm-project/aibrix/pkg/utils/tokenizer"
+)
+
+func TestPDDisaggRoute(t *testing.T) {
+	c := cache.NewTestCacheWithPodsMetrics(getReadyPods(), "m1",
+		map[string]map[string]metrics.MetricValue{
+			"p1": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+			"p2": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+			"p3": {metrics.GPUCacheUsagePerc: &metrics.SimpleMetricValue{Value: 0.8}},
+			"p4": {metrics.GPUCacheUsagePerc: &metrics.SimpleMetricValue{Value: 0.2}},
+		})
+	roles := map[string]string{"p1": PodRolePrefill, "p2": PodRolePrefill, "p3": PodRoleDecode, "p4": PodRoleDecode}
+	pods := podsFromCache(c)
+	for _, pod := range pods.Pods {
+		pod.Labels[PodRoleLabel] = roles[pod.Name]
+	}
+	router := pdDisaggRouter{
+		cache: c,
+		prefill: prefixCacheRouter{
+			cache:                              c,
+			tokenizer:                          tokenizer.NewCharacterTokenizer(),
+			prefixCacheIndexer:                 prefixcacheindexer.NewPrefixHashTable(),
+			podRunningRequestImbalanceAbsCount: defaultPodRunningRequestImbalanceAbsCount,
+			standardDeviationFactor:            defaultStandardDeviationFactor,
+		},
+		decodeKVCacheTolerance: defaultPDDisaggDecodeKVCacheTolerance,
+	}
+
+	ctx := types.NewRoutingContext(context.Background(), RouterPDDisagg, "m1", "abcdefgh", "r1", "")
+	address, err := router.Route(ctx, pods)
+	assert.NoError(t, err)
+	assert.Equal(t, "p4", ctx.TargetPod().Name)
+	assert.Equal(t, ctx.TargetAddress(), address)
+	assert.Contains(t, []string{"p1", "p2"}, ctx.PrefillPod().Name)
+	assert.Equal(t, ctx.PrefillPod().Status.PodIP+":8000", ctx.PrefillAddress())
+
+	// The same prefix is prefilled by the pod which cached it.
+	c.AddPrefillRequestCount(ctx, ctx.RequestID)
+	c.DonePrefillRequestCount(ctx, ctx.RequestID)
+	ctx2 := types.NewRoutingContext(context.Background(), RouterPDDisagg, "m1", "abcdefgh", "r2", "")
+	_, err = router.Route(ctx2, pods)
+	assert.NoError(t, err)
+	assert.Equal(t, ctx.PrefillPod().Name, ctx2.PrefillPod().Name)
+
+	// Pods of a single role are not routed.
+	for _, pod := range pods.Pods {
+		pod.Labels[PodRoleLabel] = PodRoleDecode
+	}
+	ctx3 := types.NewRoutingContext(context.Background(), RouterPDDisagg, "m1", "", "r3", "")
+	_, err = router.Route(ctx3, pods)
+	assert.ErrorContains(t, err, "got 0 prefill and 4 decode pods")
+	assert.False(t, ctx3.HasRouted())
+	assert.Nil(t, ctx3.PrefillPod())
+}
+
+func TestPDDisaggSelectDecodePod(t *testing.T) {
+	c := cache.NewTestCacheWithPodsMetrics(getReadyPods(), "m1",
+		map[string]map[string]metrics.MetricValue{
+			"p3": {metrics.GPUCacheUsagePerc: &metrics.SimpleMetricValue{Value: 0.25}},
+			"p4": {metrics.GPUCacheUsagePerc: &metrics.SimpleMetricValue{Value: 0.2}},
+		})
+	pods := podsFromCache(c).Pods
+	p3, _ := utils.FilterPodByName("p3", pods)
+	p4, _ := utils.FilterPodByName("p4", pods)
+	decodePods := []*v1.Pod{p3, p4}
+	router := pdDisaggRouter{cache: c, decodeKVCacheTolerance: defaultPDDisaggDecodeKVCacheTolerance}
+
+	// p3 is within the tolerance of the KV cache usage of p4 and has fewer running requests.
+	ctx := types.NewRoutingContext(context.Background(), RouterPDDisagg, "m1", "", "r1", "")
+	ctx.SetTargetPod(p4)
+	c.AddRequestCount(ctx, ctx.RequestID, ctx.Model)
+	assert.Equal(t, "p3", router.selectDecodePod(ctx, decodePods).Name)
+
+	// p4 is out of the tolerance.
+	router.decodeKVCacheTolerance = 0.01
+	assert.Equal(t, "p4", router.selectDecodePod(ctx, decodePods).Name)
+}
+
+func TestNewPDDisaggRouter(t *testing.T) {
+	_, err := NewPDDisaggRouter(types.RouterParameters{pdDisaggParamDecodeKVCacheTolerance: "-1"})
+	assert.ErrorContains(t, err, "must not be negative")
+	_, err = NewPDDisaggRouter(types.RouterParameters{"unknown": "1"})
+	assert.ErrorContains(t, err, "unknown parameter")
+}
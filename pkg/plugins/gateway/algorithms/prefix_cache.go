@@ -17,6 +17,7 @@ limitations under the License.
 package routingalgorithms
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"sort"
@@ -44,22 +45,54 @@ var (
 )
 
 func init() {
-	Register(RouterPrefixCache, NewPrefixCacheRouter)
+	RegisterWithParameters(RouterPrefixCache, NewPrefixCacheRouter)
 }
 
+// Parameters of the prefix-cache router, a RoutingPolicy overrides the defaults of the environment variables.
+const (
+	prefixCacheParamTokenizerType                      = "tokenizerType"
+	prefixCacheParamPodRunningRequestImbalanceAbsCount = "podRunningRequestImbalanceAbsCount"
+	prefixCacheParamStandardDeviationFactor            = "standardDeviationFactor"
+)
+
 type prefixCacheRouter struct {
 	cache              cache.Cache
 	tokenizer          tokenizer.Tokenizer
+	tokenizers         *tokenizer.Registry
 	prefixCacheIndexer *prefixcacheindexer.PrefixHashTable
+
+	podRunningRequestImbalanceAbsCount int
+	standardDeviationFactor            int
 }
 
-func NewPrefixCacheRouter() (types.Router, error) {
+func NewPrefixCacheRouter(params types.RouterParameters) (types.Router, error) {
+	if err := params.Validate(prefixCacheParamTokenizerType,
+		prefixCacheParamPodRunningRequestImbalanceAbsCount,
+		prefixCacheParamStandardDeviationFactor); err != nil {
+		return nil, err
+	}
+	tokenizerType := params.String(prefixCacheParamTokenizerType, tokenizerType)
+	imbalanceAbsCount, err := params.Int(prefixCacheParamPodRunningRequestImbalanceAbsCount, podRunningRequestImbalanceAbsCount)
+	if err != nil {
+		return nil, err
+	}
+	standardDeviationFactor, err := params.Int(prefixCacheParamStandardDeviationFactor, standardDeviationFactor)
+	if err != nil {
+		return nil, err
+	}
+
 	var tokenizerObj tokenizer.Tokenizer
 	// TODO: refactor initilization
 	// supported tokenizers: ["character", "tiktoken"]
-	if tokenizerType == "tiktoken" {
+	switch tokenizerType {
+	case tokenizer.TypeTiktoken:
 		tokenizerObj = tokenizer.NewTiktokenTokenizer()
-	} else {
+	case tokenizer.TypeCharacter:
+		tokenizerObj = tokenizer.NewCharacterTokenizer()
+	default:
+		if _, ok := params[prefixCacheParamTokenizerType]; ok {
+			return nil, fmt.Errorf("unsupported tokenizer type: %q", tokenizerType)
+		}
 		tokenizerObj = tokenizer.NewCharacterTokenizer()
 	}
 
@@ -71,34 +104,59 @@ func NewPrefixCacheRouter() (types.Router, error) {
 
 	klog.InfoS("prefix_cache_configurations",
 		"tokenizer_type", tokenizerType,
-		"pod_running_request_imbalance_abs_count", podRunningRequestImbalanceAbsCount,
+		"pod_running_request_imbalance_abs_count", imbalanceAbsCount,
 		"matched_pods_running_requests_standard_deviation_factor", standardDeviationFactor)
 
 	return prefixCacheRouter{
-		cache:              c,
-		tokenizer:          tokenizerObj,
-		prefixCacheIndexer: prefixcacheindexer.NewPrefixHashTable(),
+		cache:                              c,
+		tokenizer:                          tokenizerObj,
+		tokenizers:                         tokenizerRegistry,
+		prefixCacheIndexer:                 prefixcacheindexer.NewPrefixHashTable(),
+		podRunningRequestImbalanceAbsCount: imbalanceAbsCount,
+		standardDeviationFactor:            standardDeviationFactor,
 	}, nil
 }
 
+// Close stops the eviction of the prefix cache index.
+func (p prefixCacheRouter) Close() {
+	p.prefixCacheIndexer.Close()
+}
+
+// getTokenizer returns the tokenizer of the model, or the tokenizer of AIBRIX_PREFIX_CACHE_TOKENIZER_TYPE if the model has none.
+func (p prefixCacheRouter) getTokenizer(model string) tokenizer.Tokenizer {
+	if t, ok := p.tokenizers.Lookup(model); ok {
+		return t
+	}
+	return p.tokenizer
+}
+
 func (p prefixCacheRouter) Route(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
+	targetPod, err := p.selectPod(ctx, readyPodList.All())
+	if err != nil {
+		return "", err
+	}
+	ctx.SetTargetPod(targetPod)
+	return ctx.TargetAddress(), nil
+}
+
+// selectPod selects the pod of readyPods with the longest cached prefix of the request, unless it is overloaded.
+func (p prefixCacheRouter) selectPod(ctx *types.RoutingContext, readyPods []*v1.Pod) (*v1.Pod, error) {
 	var prefixHashes []uint64
 	var matchedPods map[string]int
 	var targetPod *v1.Pod
 
-	tokens, err := p.tokenizer.TokenizeInputText(ctx.Message)
+	tokens, err := p.getTokenizer(ctx.Model).TokenizeInputText(ctx.Message)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	readyPods := readyPodList.All()
 	readyPodsMap := map[string]struct{}{}
 	for _, pod := range readyPods {
 		readyPodsMap[pod.Name] = struct{}{}
 	}
 
 	var isLoadImbalanced bool
-	targetPod, isLoadImbalanced = getTargetPodOnLoadImbalance(p.cache, readyPods)
+	targetPod, isLoadImbalanced = getTargetPodOnLoadImbalance(p.cache, readyPods, p.podRunningRequestImbalanceAbsCount)
 	if isLoadImbalanced {
 		prefixHashes = p.prefixCacheIndexer.GetPrefixHashes(tokens)
 		klog.InfoS("prefix_cache_load_imbalanced",
@@ -111,7 +169,7 @@ func (p prefixCacheRouter) Route(ctx *types.RoutingContext, readyPodList types.P
 		klog.InfoS("prefix_hashes", "request_id", ctx.RequestID, "prefix_hashes", prefixHashes)
 
 		if len(matchedPods) > 0 {
-			targetPod = getTargetPodFromMatchedPods(p.cache, readyPods, matchedPods)
+			targetPod = getTargetPodFromMatchedPods(p.cache, readyPods, matchedPods, p.standardDeviationFactor)
 			if targetPod != nil {
 				klog.InfoS("prefix_cache_matched_pods",
 					"request_id", ctx.RequestID,
@@ -142,12 +200,10 @@ func (p prefixCacheRouter) Route(ctx *types.RoutingContext, readyPodList types.P
 	if len(prefixHashes) > 0 {
 		p.prefixCacheIndexer.AddPrefix(prefixHashes, ctx.Model, targetPod.Name)
 	}
-
-	ctx.SetTargetPod(targetPod)
-	return ctx.TargetAddress(), nil
+	return targetPod, nil
 }
 
-func getTargetPodFromMatchedPods(cache cache.Cache, readyPods []*v1.Pod, matchedPods map[string]int) *v1.Pod {
+func getTargetPodFromMatchedPods(cache cache.Cache, readyPods []*v1.Pod, matchedPods map[string]int, standardDeviationFactor int) *v1.Pod {
 	var targetPodName string
 	requestCount := []float64{}
 
@@ -188,7 +244,7 @@ func getTargetPodFromMatchedPods(cache cache.Cache, readyPods []*v1.Pod, matched
 
 // getTargetPodOnLoadImbalance evaluates if the load is imbalanced based on the abs difference between
 // pods with min and max outstanding request counts
-func getTargetPodOnLoadImbalance(cache cache.Cache, readyPods []*v1.Pod) (*v1.Pod, bool) {
+func getTargetPodOnLoadImbalance(cache cache.Cache, readyPods []*v1.Pod, podRunningRequestImbalanceAbsCount int) (*v1.Pod, bool) {
 	var imbalance bool
 	var targetPod *v1.Pod
 	targetPods := []string{}
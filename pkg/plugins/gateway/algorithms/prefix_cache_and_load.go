@@ -24,8 +24,8 @@ import (
 	"time"
 
 	"github.com/vllm-project/aibrix/pkg/types"
-	"github.com/vllm-project/aibrix/pkg/utils"
 	"github.com/vllm-project/aibrix/pkg/utils/prefixcacheindexer"
+	"github.com/vllm-project/aibrix/pkg/utils/tokenizer"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
@@ -75,6 +75,8 @@ type prefixCacheAndLoadRouter struct {
 	numPods        int
 	podAllocations map[*prefixcacheindexer.TreeNode]map[int]bool
 	podsMu         sync.RWMutex
+	tokenizer      tokenizer.Tokenizer
+	tokenizers     *tokenizer.Registry
 }
 
 // Find all prefix matches with their depths
@@ -246,6 +248,8 @@ func NewPrefixCacheAndLoadRouter() (types.Router, error) {
 		histogram:      histogram,
 		numPods:        numPods,
 		podAllocations: make(map[*prefixcacheindexer.TreeNode]map[int]bool),
+		tokenizer:      tokenizer.NewTiktokenTokenizer(),
+		tokenizers:     tokenizerRegistry,
 	}
 
 	// Start eviction ticker
@@ -429,6 +433,14 @@ func (p *prefixCacheAndLoadRouter) updatePodSet(readyPods []*v1.Pod) {
 	}
 }
 
+// getTokenizer returns the tokenizer of the model, or tiktoken if the model has none.
+func (p *prefixCacheAndLoadRouter) getTokenizer(model string) tokenizer.Tokenizer {
+	if t, ok := p.tokenizers.Lookup(model); ok {
+		return t
+	}
+	return p.tokenizer
+}
+
 func (p *prefixCacheAndLoadRouter) Route(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
 	readyPods := readyPodList.All()
 	var podUpdateNeeded bool
@@ -445,7 +457,7 @@ func (p *prefixCacheAndLoadRouter) Route(ctx *types.RoutingContext, readyPodList
 		klog.InfoS("Request processing", "requestID", ctx.RequestID, "updatePodSet", p.numPods)
 	}
 
-	tokens, err := utils.TokenizeInputText(ctx.Message)
+	tokens, err := p.getTokenizer(ctx.Model).TokenizeInputText(ctx.Message)
 	if err != nil {
 		klog.Errorf("requestID: %s, Tokenization failed: %v", ctx.RequestID, err)
 		return "", err
@@ -515,13 +527,8 @@ func (p *prefixCacheAndLoadRouter) Route(ctx *types.RoutingContext, readyPodList
 			}
 			klog.InfoS("requestID: %s, Selected pod %s from longest matching node with match length %d", "requestID", ctx.RequestID, "podName", targetPod.Name, "matchLength", longestMatch.matchLength)
 		} else {
-			tokenInString, err := utils.DetokenizeText(tokens)
-			matchedTokensInString, _ := utils.DetokenizeText(matchedTokens)
-			if err != nil {
-				klog.ErrorS(err, "requestID: %s, DetokenizeTexts failed: %s, tokens: '%v', matchedTokens: '%v', model: %s", "requestID", ctx.RequestID, "tokens", tokenInString, "matchedTokens", matchedTokensInString, "model", ctx.Model)
-			} else {
-				klog.InfoS("requestID: %s, No matched pods found for tokens: '%v', matchedTokens: '%v', model: %s", "requestID", ctx.RequestID, "tokens", tokenInString, "matchedTokens", matchedTokensInString, "model", ctx.Model)
-			}
+			// Token ids may come from the tokenizer of the model, log their counts instead of detokenized text.
+			klog.InfoS("requestID: %s, No matched pods found for tokens: %d, matchedTokens: %d, model: %s", "requestID", ctx.RequestID, "tokens", len(tokens), "matchedTokens", len(matchedTokens), "model", ctx.Model)
 		}
 	}
 
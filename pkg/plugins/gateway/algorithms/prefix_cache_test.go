@@ -49,6 +49,9 @@ func Test_PrefixCacheE2E(t *testing.T) {
 		cache:              c,
 		tokenizer:          tokenizer.NewCharacterTokenizer(),
 		prefixCacheIndexer: prefixcacheindexer.NewPrefixHashTable(),
+
+		podRunningRequestImbalanceAbsCount: podRunningRequestImbalanceAbsCount,
+		standardDeviationFactor:            standardDeviationFactor,
 	}
 
 	// no prefix match -> select least request pod
@@ -202,7 +205,7 @@ func Test_ValidatePrePrefixMatchLoadBalance(t *testing.T) {
 			"p3": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 3}},
 			"p4": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 9}},
 		})
-	targetPod, imbalance := getTargetPodOnLoadImbalance(c, readyPods)
+	targetPod, imbalance := getTargetPodOnLoadImbalance(c, readyPods, podRunningRequestImbalanceAbsCount)
 	assert.False(t, imbalance, "pod running request count is less than equal to default abs value of 8")
 	assert.Nil(t, targetPod)
 
@@ -216,7 +219,7 @@ func Test_ValidatePrePrefixMatchLoadBalance(t *testing.T) {
 			"p3": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 8}},
 			"p4": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 16}},
 		})
-	targetPod, imbalance = getTargetPodOnLoadImbalance(c, readyPods)
+	targetPod, imbalance = getTargetPodOnLoadImbalance(c, readyPods, podRunningRequestImbalanceAbsCount)
 	assert.True(t, imbalance, "pod running request count is more than default abs value of 8")
 	assert.True(t, slices.Contains([]string{"p1", "p2"}, targetPod.Name))
 }
@@ -317,7 +320,7 @@ func Test_ValidatePostPrefixMatchLoadBalance(t *testing.T) {
 		},
 	}
 	for _, test := range testcases {
-		targetPod := getTargetPodFromMatchedPods(test.c, readyPods, test.matchedPods)
+		targetPod := getTargetPodFromMatchedPods(test.c, readyPods, test.matchedPods, standardDeviationFactor)
 		if len(test.targetPods) == 0 {
 			assert.Nil(t, targetPod, test.name)
 		} else {
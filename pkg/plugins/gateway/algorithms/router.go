@@ -17,6 +17,8 @@ limitations under the License.
 package routingalgorithms
 
 import (
+	"fmt"
+
 	"github.com/vllm-project/aibrix/pkg/types"
 
 	"k8s.io/klog/v2"
@@ -28,6 +30,7 @@ const (
 
 var routerFactory = map[types.RoutingAlgorithm]types.RouterProviderFunc{}
 var routerConstructor = map[types.RoutingAlgorithm]types.RouterProviderRegistrationFunc{}
+var parameterizedRouterConstructor = map[types.RoutingAlgorithm]types.ParameterizedRouterConstructor{}
 
 // Validate validates if user provided routing routers is supported by gateway
 func Validate(algorithms string) (types.RoutingAlgorithm, bool) {
@@ -40,13 +43,21 @@ func Validate(algorithms string) (types.RoutingAlgorithm, bool) {
 
 // Select the user provided router provider supported by gateway, no error reported and fallback to random router
 // Call Validate before this function to ensure expected behavior.
+// The provider returns the router of the RoutingPolicy of the model if the policy uses the same algorithm.
 func Select(algorithms types.RoutingAlgorithm) types.RouterProviderFunc {
-	if provider, ok := routerFactory[algorithms]; ok {
-		return provider
-	} else {
+	provider, ok := routerFactory[algorithms]
+	if !ok {
 		klog.Warningf("Unsupported router strategy: %s, use %s instead.", algorithms, RouterRandom)
 		return routerFactory[RouterRandom]
 	}
+	return func(ctx *types.RoutingContext) (types.Router, error) {
+		if ctx != nil {
+			if router, ok := routingPolicies.router(ctx.Model, algorithms); ok {
+				return router, nil
+			}
+		}
+		return provider(ctx)
+	}
 }
 
 func Register(algorithm types.RoutingAlgorithm, constructor types.RouterConstructor) {
@@ -62,6 +73,43 @@ func Register(algorithm types.RoutingAlgorithm, constructor types.RouterConstruc
 	}
 }
 
+// RegisterWithParameters registers a router accepting parameters, the router without parameters is used by default.
+func RegisterWithParameters(algorithm types.RoutingAlgorithm, constructor types.ParameterizedRouterConstructor) {
+	parameterizedRouterConstructor[algorithm] = constructor
+	Register(algorithm, func() (types.Router, error) {
+		return constructor(nil)
+	})
+}
+
+// closer is implemented by routers and scorers holding resources, e.g. the eviction goroutines of their stores.
+type closer interface {
+	Close()
+}
+
+// closeRouter releases the resources of a router or a scorer, if any.
+func closeRouter(r any) {
+	if c, ok := r.(closer); ok {
+		c.Close()
+	}
+}
+
+// NewRouter returns a router of the algorithm with parameters.
+// Without parameters, the router registered by default is shared. Call Init before this function.
+func NewRouter(algorithm types.RoutingAlgorithm, params types.RouterParameters) (types.Router, error) {
+	if len(params) > 0 {
+		constructor, ok := parameterizedRouterConstructor[algorithm]
+		if !ok {
+			return nil, fmt.Errorf("routing algorithm %s does not accept parameters", algorithm)
+		}
+		return constructor(params)
+	}
+	provider, ok := routerFactory[algorithm]
+	if !ok || provider == nil {
+		return nil, fmt.Errorf("unsupported routing algorithm: %s", algorithm)
+	}
+	return provider(nil)
+}
+
 func Init() {
 	for algorithm, constructor := range routerConstructor {
 		routerFactory[algorithm] = constructor()
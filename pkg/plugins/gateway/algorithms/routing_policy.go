@@ -0,0 +1,293 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
+	crdinformers "github.com/vllm-project/aibrix/pkg/client/informers/externalversions"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// routingPolicy is a validated RoutingPolicy with the routers of its algorithm and fallbacks.
+type routingPolicy struct {
+	name      string
+	spec      modelv1alpha1.RoutingPolicySpec
+	priority  int32
+	models    map[string]struct{}
+	selector  labels.Selector
+	algorithm types.RoutingAlgorithm
+	router    types.Router
+	shadow    *modelv1alpha1.ShadowTraffic
+	// owned are the routers constructed with the parameters of the policy, the routers without parameters are shared.
+	owned []types.Router
+}
+
+// policyStore holds the RoutingPolicies by namespace/name.
+type policyStore struct {
+	mu       sync.RWMutex
+	policies map[string]*routingPolicy
+}
+
+var routingPolicies = &policyStore{policies: map[string]*routingPolicy{}}
+
+// modelPodLabels returns the labels of the pods of a model, RoutingPolicies select models by pod labels.
+var modelPodLabels = func(model string) []labels.Set {
+	c, err := cache.Get()
+	if err != nil {
+		return nil
+	}
+	pods, err := c.ListPodsByModel(model)
+	if err != nil {
+		return nil
+	}
+	podLabels := make([]labels.Set, 0, pods.Len())
+	for _, pod := range pods.All() {
+		podLabels = append(podLabels, pod.Labels)
+	}
+	return podLabels
+}
+
+// PolicyAlgorithm returns the routing algorithm of the RoutingPolicy of the model.
+func PolicyAlgorithm(model string) (types.RoutingAlgorithm, bool) {
+	policy := routingPolicies.lookup(model)
+	if policy == nil {
+		return RouterNotSet, false
+	}
+	return policy.algorithm, true
+}
+
+// PolicyShadow returns the shadow traffic of the RoutingPolicy of the model.
+func PolicyShadow(model string) (*modelv1alpha1.ShadowTraffic, bool) {
+	policy := routingPolicies.lookup(model)
+	if policy == nil || policy.shadow == nil {
+		return nil, false
+	}
+	return policy.shadow, true
+}
+
+// WatchRoutingPolicies watches RoutingPolicies until stopCh is closed, call Init before this function.
+// Invalid policies are logged and ignored.
+func WatchRoutingPolicies(client versioned.Interface, stopCh <-chan struct{}) error {
+	factory := crdinformers.NewSharedInformerFactoryWithOptions(client, 0)
+	informer := factory.Model().V1alpha1().RoutingPolicies().Informer()
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: routingPolicies.addOrUpdate,
+		UpdateFunc: func(_, newObj interface{}) {
+			routingPolicies.addOrUpdate(newObj)
+		},
+		DeleteFunc: routingPolicies.delete,
+	}); err != nil {
+		return err
+	}
+	factory.Start(stopCh)
+	if !toolscache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return errors.New("timed out waiting for routing policies to sync")
+	}
+	return nil
+}
+
+// newRoutingPolicy validates a RoutingPolicy and constructs the routers of its algorithm and fallbacks.
+func newRoutingPolicy(obj *modelv1alpha1.RoutingPolicy) (*routingPolicy, error) {
+	policy := &routingPolicy{
+		name:      fmt.Sprintf("%s/%s", obj.Namespace, obj.Name),
+		spec:      *obj.Spec.DeepCopy(),
+		priority:  obj.Spec.Priority,
+		models:    map[string]struct{}{},
+		algorithm: types.RoutingAlgorithm(obj.Spec.Algorithm),
+	}
+	for _, model := range obj.Spec.Models {
+		policy.models[model] = struct{}{}
+	}
+	if obj.Spec.ModelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(obj.Spec.ModelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modelSelector: %w", err)
+		}
+		policy.selector = selector
+	}
+	if len(policy.models) == 0 && policy.selector == nil {
+		return nil, fmt.Errorf("no models or modelSelector set")
+	}
+	if shadow := obj.Spec.Shadow; shadow != nil && shadow.Percentage > 0 {
+		if _, ok := policy.models[shadow.Model]; ok {
+			return nil, fmt.Errorf("shadow model %s is selected by the policy", shadow.Model)
+		}
+		policy.shadow = shadow.DeepCopy()
+	}
+
+	strategies := append([]modelv1alpha1.RoutingStrategy{obj.Spec.RoutingStrategy}, obj.Spec.Fallbacks...)
+	fallback := &fallbackRouter{}
+	for _, strategy := range strategies {
+		algorithm, ok := Validate(strategy.Algorithm)
+		if !ok {
+			return nil, fmt.Errorf("unsupported routing algorithm: %q", strategy.Algorithm)
+		}
+		router, err := NewRouter(algorithm, strategy.Parameters)
+		if err != nil {
+			policy.close()
+			return nil, fmt.Errorf("invalid routing algorithm %s: %w", algorithm, err)
+		}
+		if len(strategy.Parameters) > 0 {
+			policy.owned = append(policy.owned, router)
+		}
+		fallback.algorithms = append(fallback.algorithms, algorithm)
+		fallback.routers = append(fallback.routers, router)
+	}
+	policy.router = fallback
+	if len(fallback.routers) == 1 {
+		policy.router = fallback.routers[0]
+	}
+	return policy, nil
+}
+
+// close releases the resources of the routers owned by the policy, once it is replaced or deleted.
+func (p *routingPolicy) close() {
+	for _, router := range p.owned {
+		closeRouter(router)
+	}
+}
+
+// selects returns true if the policy selects the model by name or by the labels of its pods.
+func (p *routingPolicy) selects(model string, podLabels func() []labels.Set) bool {
+	if _, ok := p.models[model]; ok {
+		return true
+	}
+	if p.selector == nil {
+		return false
+	}
+	for _, set := range podLabels() {
+		if p.selector.Matches(set) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *policyStore) addOrUpdate(obj interface{}) {
+	rp, ok := obj.(*modelv1alpha1.RoutingPolicy)
+	if !ok {
+		return
+	}
+	key := fmt.Sprintf("%s/%s", rp.Namespace, rp.Name)
+	s.mu.RLock()
+	existing, ok := s.policies[key]
+	s.mu.RUnlock()
+	if ok && equality.Semantic.DeepEqual(existing.spec, rp.Spec) {
+		// Keep the routers and what they learned, e.g. on resync.
+		return
+	}
+	policy, err := newRoutingPolicy(rp)
+	if err != nil {
+		klog.ErrorS(err, "ignored invalid routing policy", "routingPolicy", key)
+		s.remove(key)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.policies[key]; ok {
+		existing.close()
+	}
+	s.policies[key] = policy
+	klog.InfoS("routing policy updated", "routingPolicy", key, "algorithm", policy.algorithm, "priority", policy.priority)
+}
+
+func (s *policyStore) delete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	rp, ok := obj.(*modelv1alpha1.RoutingPolicy)
+	if !ok {
+		return
+	}
+	s.remove(fmt.Sprintf("%s/%s", rp.Namespace, rp.Name))
+}
+
+func (s *policyStore) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if policy, ok := s.policies[key]; ok {
+		policy.close()
+		delete(s.policies, key)
+		klog.InfoS("routing policy deleted", "routingPolicy", key)
+	}
+}
+
+// lookup returns the policy of the model with the highest priority, ties are broken by name.
+func (s *policyStore) lookup(model string) *routingPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.policies) == 0 {
+		return nil
+	}
+
+	podLabelsOnce := sync.OnceValue(func() []labels.Set {
+		return modelPodLabels(model)
+	})
+	var selected *routingPolicy
+	for _, policy := range s.policies {
+		if !policy.selects(model, podLabelsOnce) {
+			continue
+		}
+		if selected == nil || policy.priority > selected.priority ||
+			(policy.priority == selected.priority && policy.name < selected.name) {
+			selected = policy
+		}
+	}
+	return selected
+}
+
+// router returns the router of the policy of the model if the policy uses the algorithm.
+func (s *policyStore) router(model string, algorithm types.RoutingAlgorithm) (types.Router, bool) {
+	policy := s.lookup(model)
+	if policy == nil || policy.algorithm != algorithm {
+		return nil, false
+	}
+	return policy.router, true
+}
+
+// fallbackRouter tries the routers in order until one of them selects a pod.
+type fallbackRouter struct {
+	algorithms []types.RoutingAlgorithm
+	routers    []types.Router
+}
+
+func (r *fallbackRouter) Route(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
+	var err error
+	for i, router := range r.routers {
+		var targetPodIP string
+		if targetPodIP, err = router.Route(ctx, readyPodList); err == nil && targetPodIP != "" {
+			return targetPodIP, nil
+		}
+		klog.ErrorS(err, "routing failed, trying the next fallback", "requestID", ctx.RequestID, "algorithm", r.algorithms[i])
+	}
+	if err == nil {
+		err = fmt.Errorf("no target pod selected")
+	}
+	return "", err
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestRoutingPolicy(t *testing.T) {
+	cache.InitForTest()
+	Init()
+	modelPodLabels = func(model string) []labels.Set {
+		if model == "m2" {
+			return []labels.Set{{"tier": "premium"}}
+		}
+		return nil
+	}
+	routingPolicies = &policyStore{policies: map[string]*routingPolicy{}}
+
+	_, ok := PolicyAlgorithm("m1")
+	assert.False(t, ok)
+
+	routingPolicies.addOrUpdate(&modelv1alpha1.RoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "by-name"},
+		Spec: modelv1alpha1.RoutingPolicySpec{
+			Models:          []string{"m1"},
+			RoutingStrategy: modelv1alpha1.RoutingStrategy{Algorithm: string(RouterLeastRequest)},
+		},
+	})
+	routingPolicies.addOrUpdate(&modelv1alpha1.RoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "by-label"},
+		Spec: modelv1alpha1.RoutingPolicySpec{
+			ModelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "premium"}},
+			Priority:      10,
+			RoutingStrategy: modelv1alpha1.RoutingStrategy{
+				Algorithm:  string(RouterPrefixCache),
+				Parameters: map[string]string{prefixCacheParamStandardDeviationFactor: "2"},
+			},
+			Fallbacks: []modelv1alpha1.RoutingStrategy{{Algorithm: string(RouterLeastRequest)}},
+		},
+	})
+
+	algorithm, ok := PolicyAlgorithm("m1")
+	assert.True(t, ok)
+	assert.Equal(t, RouterLeastRequest, algorithm)
+	algorithm, ok = PolicyAlgorithm("m2")
+	assert.True(t, ok)
+	assert.Equal(t, RouterPrefixCache, algorithm)
+	_, ok = PolicyAlgorithm("m3")
+	assert.False(t, ok)
+
+	// The policy router is used for the algorithm of the policy only.
+	router, err := Select(RouterPrefixCache)(&types.RoutingContext{Model: "m2"})
+	assert.NoError(t, err)
+	fallback, ok := router.(*fallbackRouter)
+	assert.True(t, ok)
+	assert.Equal(t, []types.RoutingAlgorithm{RouterPrefixCache, RouterLeastRequest}, fallback.algorithms)
+	assert.Equal(t, 2, fallback.routers[0].(prefixCacheRouter).standardDeviationFactor)
+	router, err = Select(RouterPrefixCache)(&types.RoutingContext{Model: "m1"})
+	assert.NoError(t, err)
+	assert.IsType(t, prefixCacheRouter{}, router)
+
+	// A higher priority policy selecting the model by name takes over.
+	override := &modelv1alpha1.RoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "override"},
+		Spec: modelv1alpha1.RoutingPolicySpec{
+			Models:          []string{"m2"},
+			Priority:        20,
+			RoutingStrategy: modelv1alpha1.RoutingStrategy{Algorithm: string(RouterRandom)},
+		},
+	}
+	routingPolicies.addOrUpdate(override)
+	algorithm, _ = PolicyAlgorithm("m2")
+	assert.Equal(t, RouterRandom, algorithm)
+
+	// Invalid updates drop the policy.
+	override = override.DeepCopy()
+	override.Spec.RoutingStrategy.Parameters = map[string]string{"unknown": "1"}
+	routingPolicies.addOrUpdate(override)
+	algorithm, _ = PolicyAlgorithm("m2")
+	assert.Equal(t, RouterPrefixCache, algorithm)
+
+	routingPolicies.delete(&modelv1alpha1.RoutingPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "by-label"}})
+	_, ok = PolicyAlgorithm("m2")
+	assert.False(t, ok)
+}
+
+// closedSessionStore records whether the session store is closed.
+type closedSessionStore struct {
+	localSessionStore
+	closed bool
+}
+
+func (s *closedSessionStore) close() {
+	s.closed = true
+}
+
+func TestRoutingPolicyUpdate(t *testing.T) {
+	cache.InitForTest()
+	Init()
+	routingPolicies = &policyStore{policies: map[string]*routingPolicy{}}
+	policy := &modelv1alpha1.RoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "session"},
+		Spec: modelv1alpha1.RoutingPolicySpec{
+			Models: []string{"m1"},
+			RoutingStrategy: modelv1alpha1.RoutingStrategy{
+				Algorithm:  string(RouterSessionAffinity),
+				Parameters: map[string]string{sessionAffinityParamTTLSeconds: "60"},
+			},
+		},
+	}
+	routingPolicies.addOrUpdate(policy)
+	router := routingPolicies.policies["default/session"].router.(*sessionAffinityRouter)
+	sessions := &closedSessionStore{}
+	router.sessions = sessions
+
+	// Updates of the same spec, e.g. on resync, keep the routers.
+	routingPolicies.addOrUpdate(policy.DeepCopy())
+	assert.Same(t, router, routingPolicies.policies["default/session"].router)
+	assert.False(t, sessions.closed)
+
+	// The routers of a replaced policy are closed.
+	updated := policy.DeepCopy()
+	updated.Spec.RoutingStrategy.Parameters[sessionAffinityParamTTLSeconds] = "120"
+	routingPolicies.addOrUpdate(updated)
+	assert.NotSame(t, router, routingPolicies.policies["default/session"].router)
+	assert.True(t, sessions.closed)
+
+	router = routingPolicies.policies["default/session"].router.(*sessionAffinityRouter)
+	sessions = &closedSessionStore{}
+	router.sessions = sessions
+	routingPolicies.delete(updated)
+	assert.True(t, sessions.closed)
+
+	// Routers without parameters are shared, they are not closed with the policy.
+	shared := &modelv1alpha1.RoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared"},
+		Spec: modelv1alpha1.RoutingPolicySpec{
+			Models:          []string{"m1"},
+			RoutingStrategy: modelv1alpha1.RoutingStrategy{Algorithm: string(RouterSessionAffinity)},
+		},
+	}
+	routingPolicies.addOrUpdate(shared)
+	assert.Empty(t, routingPolicies.policies["default/shared"].owned)
+	routingPolicies.delete(shared)
+}
+
+func TestNewRoutingPolicyValidation(t *testing.T) {
+	cache.InitForTest()
+	Init()
+	tests := []struct {
+		name string
+		spec modelv1alpha1.RoutingPolicySpec
+	}{
+		{
+			name: "no-models",
+			spec: modelv1alpha1.RoutingPolicySpec{RoutingStrategy: modelv1alpha1.RoutingStrategy{Algorithm: string(RouterRandom)}},
+		},
+		{
+			name: "unknown-algorithm",
+			spec: modelv1alpha1.RoutingPolicySpec{
+				Models:          []string{"m1"},
+				RoutingStrategy: modelv1alpha1.RoutingStrategy{Algorithm: "rrandom"},
+			},
+		},
+		{
+			name: "invalid-parameter",
+			spec: modelv1alpha1.RoutingPolicySpec{
+				Models: []string{"m1"},
+				RoutingStrategy: modelv1alpha1.RoutingStrategy{
+					Algorithm:  string(RouterPrefixCache),
+					Parameters: map[string]string{prefixCacheParamPodRunningRequestImbalanceAbsCount: "eight"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRoutingPolicy(&modelv1alpha1.RoutingPolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: tt.name},
+				Spec:       tt.spec,
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPolicyShadow(t *testing.T) {
+	cache.InitForTest()
+	Init()
+	routingPolicies = &policyStore{policies: map[string]*routingPolicy{}}
+	policy := &modelv1alpha1.RoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shadow"},
+		Spec: modelv1alpha1.RoutingPolicySpec{
+			Models:          []string{"m1"},
+			RoutingStrategy: modelv1alpha1.RoutingStrategy{Algorithm: string(RouterRandom)},
+			Shadow:          &modelv1alpha1.ShadowTraffic{Model: "m1-candidate", Percentage: 10},
+		},
+	}
+	routingPolicies.addOrUpdate(policy)
+	defer routingPolicies.delete(policy)
+
+	shadow, ok := PolicyShadow("m1")
+	assert.True(t, ok)
+	assert.Equal(t, modelv1alpha1.ShadowTraffic{Model: "m1-candidate", Percentage: 10}, *shadow)
+	_, ok = PolicyShadow("m1-candidate")
+	assert.False(t, ok)
+
+	// A policy mirroring requests to a model it selects is invalid.
+	policy.Spec.Shadow.Model = "m1"
+	_, err := newRoutingPolicy(policy)
+	assert.Error(t, err)
+}
+
+type errorRouter struct{}
+
+func (errorRouter) Route(*types.RoutingContext, types.PodList) (string, error) {
+	return "", errors.New("no pod")
+}
+
+type staticRouter string
+
+func (r staticRouter) Route(*types.RoutingContext, types.PodList) (string, error) {
+	return string(r), nil
+}
+
+func TestFallbackRouter(t *testing.T) {
+	r := &fallbackRouter{
+		algorithms: []types.RoutingAlgorithm{"first", "second", "third"},
+		routers:    []types.Router{errorRouter{}, staticRouter("10.0.0.2:8000"), staticRouter("10.0.0.3:8000")},
+	}
+	targetPodIP, err := r.Route(&types.RoutingContext{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2:8000", targetPodIP)
+
+	r = &fallbackRouter{algorithms: []types.RoutingAlgorithm{"first"}, routers: []types.Router{errorRouter{}}}
+	_, err = r.Route(&types.RoutingContext{}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,367 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils/prefixcacheindexer"
+	"github.com/vllm-project/aibrix/pkg/utils/tokenizer"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	FilterKvCacheUsage = "kv-cache-usage"
+	FilterQueueDepth   = "queue-depth"
+
+	ScorerLeastRequest  = "least-request"
+	ScorerLeastKvCache  = "least-kv-cache"
+	ScorerThroughput    = "throughput"
+	ScorerLeastBusyTime = "least-busy-time"
+	ScorerLeastLatency  = "least-latency"
+	ScorerPrefixCache   = "prefix-cache"
+	ScorerQueueDepth    = "least-queue-depth"
+	ScorerLoraAffinity  = "lora-affinity"
+
+	defaultMaxKvCacheUsage   = 0.9
+	defaultMaxWaitingRequest = 10
+)
+
+var filterConstructor = map[string]types.FilterConstructor{}
+var scorerConstructor = map[string]types.ScorerConstructor{}
+
+func init() {
+	RegisterFilter(FilterKvCacheUsage, NewKvCacheUsageFilter)
+	RegisterFilter(FilterQueueDepth, NewQueueDepthFilter)
+
+	RegisterScorer(ScorerLeastRequest, newMetricScorer(getRunningRequests))
+	RegisterScorer(ScorerLeastKvCache, newMetricScorer(getKvCacheUsage))
+	RegisterScorer(ScorerThroughput, newMetricScorer(getWeightedThroughput))
+	RegisterScorer(ScorerLeastBusyTime, newMetricScorer(getBusyTimeRatio))
+	RegisterScorer(ScorerQueueDepth, newMetricScorer(getWaitingRequests))
+	RegisterScorer(ScorerLeastLatency, NewLeastLatencyScorer)
+	RegisterScorer(ScorerPrefixCache, NewPrefixCacheScorer)
+	RegisterScorer(ScorerLoraAffinity, NewLoraAffinityScorer)
+}
+
+// RegisterFilter registers a filter of the composite router.
+func RegisterFilter(name string, constructor types.FilterConstructor) {
+	filterConstructor[name] = constructor
+}
+
+// RegisterScorer registers a scorer of the composite router.
+func RegisterScorer(name string, constructor types.ScorerConstructor) {
+	scorerConstructor[name] = constructor
+}
+
+// NewFilter returns the registered filter with parameters.
+func NewFilter(name string, params types.RouterParameters) (types.Filter, error) {
+	constructor, ok := filterConstructor[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported filter: %q", name)
+	}
+	return constructor(params)
+}
+
+// NewScorer returns the registered scorer with parameters.
+func NewScorer(name string, params types.RouterParameters) (types.Scorer, error) {
+	constructor, ok := scorerConstructor[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported scorer: %q", name)
+	}
+	return constructor(params)
+}
+
+// normalizeLowerIsBetter maps values to scores in [0, 1] by min-max normalization, the lowest value scores 1.
+// Missing values (NaN) score 0.
+func normalizeLowerIsBetter(values []float64) []float64 {
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		if math.IsNaN(value) {
+			continue
+		}
+		minValue = math.Min(minValue, value)
+		maxValue = math.Max(maxValue, value)
+	}
+	scores := make([]float64, len(values))
+	for i, value := range values {
+		switch {
+		case math.IsNaN(value):
+			scores[i] = 0
+		case maxValue == minValue:
+			scores[i] = 1
+		default:
+			scores[i] = (maxValue - value) / (maxValue - minValue)
+		}
+	}
+	return scores
+}
+
+// getRunningRequests returns the running requests of the pod tracked by the gateway, see least-request router.
+func getRunningRequests(c cache.Cache, model string, pod *v1.Pod) (float64, error) {
+	value, err := c.GetMetricValueByPod(pod.Name, pod.Namespace, metrics.RealtimeNumRequestsRunning)
+	if err != nil {
+		// pods without requests have no record
+		return 0, nil
+	}
+	return value.GetSimpleValue(), nil
+}
+
+// getKvCacheUsage returns the GPU and CPU KV cache usage of the pod, see least-kv-cache router.
+func getKvCacheUsage(c cache.Cache, model string, pod *v1.Pod) (float64, error) {
+	gpuCache, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.GPUCacheUsagePerc)
+	if err != nil {
+		return 0, err
+	}
+	cpuCache, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.CPUCacheUsagePerc)
+	if err != nil {
+		return 0, err
+	}
+	return gpuCache.GetSimpleValue() + cpuCache.GetSimpleValue(), nil
+}
+
+// getWeightedThroughput returns the token throughput of the pod weighting prompt tokens twice, see throughput router.
+func getWeightedThroughput(c cache.Cache, model string, pod *v1.Pod) (float64, error) {
+	promptThroughput, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.AvgPromptThroughputToksPerS)
+	if err != nil {
+		return 0, err
+	}
+	generationThroughput, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.AvgGenerationThroughputToksPerS)
+	if err != nil {
+		return 0, err
+	}
+	// processing prompt tokens is twice as expensive than generation tokens
+	return 2*promptThroughput.GetSimpleValue() + generationThroughput.GetSimpleValue(), nil
+}
+
+// getBusyTimeRatio returns the GPU busy time ratio of the pod, see least-busy-time router.
+func getBusyTimeRatio(c cache.Cache, model string, pod *v1.Pod) (float64, error) {
+	busyTimeRatio, err := c.GetMetricValueByPod(pod.Name, pod.Namespace, "gpu_busy_time_ratio") // todo: replace mock
+	if err != nil {
+		return 0, err
+	}
+	return busyTimeRatio.GetSimpleValue(), nil
+}
+
+// getWaitingRequests returns the requests waiting in the engine queue of the pod.
+func getWaitingRequests(c cache.Cache, model string, pod *v1.Pod) (float64, error) {
+	waiting, err := c.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metrics.NumRequestsWaiting)
+	if err != nil {
+		return 0, err
+	}
+	return waiting.GetSimpleValue(), nil
+}
+
+// metricScorer scores pods by a load value from the cache, the least loaded pod scores 1.
+type metricScorer struct {
+	cache cache.Cache
+	value func(c cache.Cache, model string, pod *v1.Pod) (float64, error)
+}
+
+func newMetricScorer(value func(c cache.Cache, model string, pod *v1.Pod) (float64, error)) types.ScorerConstructor {
+	return func(params types.RouterParameters) (types.Scorer, error) {
+		if err := params.Validate(); err != nil {
+			return nil, err
+		}
+		c, err := cache.Get()
+		if err != nil {
+			return nil, err
+		}
+		return &metricScorer{cache: c, value: value}, nil
+	}
+}
+
+func (s *metricScorer) Score(ctx *types.RoutingContext, pods []*v1.Pod) ([]float64, error) {
+	values := make([]float64, len(pods))
+	for i, pod := range pods {
+		value, err := s.value(s.cache, ctx.Model, pod)
+		if err != nil {
+			value = math.NaN()
+		}
+		values[i] = value
+	}
+	return normalizeLowerIsBetter(values), nil
+}
+
+// leastLatencyScorer scores pods by the expected latency of the request, see least-latency router.
+type leastLatencyScorer struct {
+	cache cache.Cache
+}
+
+func NewLeastLatencyScorer(params types.RouterParameters) (types.Scorer, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	c, err := cache.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &leastLatencyScorer{cache: c}, nil
+}
+
+func (s *leastLatencyScorer) Score(ctx *types.RoutingContext, pods []*v1.Pod) ([]float64, error) {
+	return normalizeLowerIsBetter(getExpectedLatencies(s.cache, ctx.Model, pods)), nil
+}
+
+// prefixCacheScorer scores pods by the percentage of the prompt prefix cached, see prefix-cache router.
+type prefixCacheScorer struct {
+	tokenizer          tokenizer.Tokenizer
+	tokenizers         *tokenizer.Registry
+	prefixCacheIndexer *prefixcacheindexer.PrefixHashTable
+}
+
+func NewPrefixCacheScorer(params types.RouterParameters) (types.Scorer, error) {
+	if err := params.Validate(prefixCacheParamTokenizerType); err != nil {
+		return nil, err
+	}
+	var tokenizerObj tokenizer.Tokenizer
+	switch tokenizerType := params.String(prefixCacheParamTokenizerType, tokenizerType); tokenizerType {
+	case tokenizer.TypeTiktoken:
+		tokenizerObj = tokenizer.NewTiktokenTokenizer()
+	case tokenizer.TypeCharacter:
+		tokenizerObj = tokenizer.NewCharacterTokenizer()
+	default:
+		if _, ok := params[prefixCacheParamTokenizerType]; ok {
+			return nil, fmt.Errorf("unsupported tokenizer type: %q", tokenizerType)
+		}
+		tokenizerObj = tokenizer.NewCharacterTokenizer()
+	}
+	return &prefixCacheScorer{
+		tokenizer:          tokenizerObj,
+		tokenizers:         tokenizerRegistry,
+		prefixCacheIndexer: prefixcacheindexer.NewPrefixHashTable(),
+	}, nil
+}
+
+// Close stops the eviction of the prefix cache index.
+func (s *prefixCacheScorer) Close() {
+	s.prefixCacheIndexer.Close()
+}
+
+func (s *prefixCacheScorer) tokenize(ctx *types.RoutingContext) ([]int, error) {
+	if t, ok := s.tokenizers.Lookup(ctx.Model); ok {
+		return t.TokenizeInputText(ctx.Message)
+	}
+	return s.tokenizer.TokenizeInputText(ctx.Message)
+}
+
+func (s *prefixCacheScorer) Score(ctx *types.RoutingContext, pods []*v1.Pod) ([]float64, error) {
+	tokens, err := s.tokenize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	readyPods := map[string]struct{}{}
+	for _, pod := range pods {
+		readyPods[pod.Name] = struct{}{}
+	}
+	matchedPods, _ := s.prefixCacheIndexer.MatchPrefix(tokens, ctx.Model, readyPods)
+	scores := make([]float64, len(pods))
+	for i, pod := range pods {
+		scores[i] = float64(matchedPods[pod.Name]) / 100
+	}
+	return scores, nil
+}
+
+// Routed records the prompt prefix on the selected pod.
+func (s *prefixCacheScorer) Routed(ctx *types.RoutingContext, pod *v1.Pod) {
+	tokens, err := s.tokenize(ctx)
+	if err != nil {
+		return
+	}
+	if prefixHashes := s.prefixCacheIndexer.GetPrefixHashes(tokens); len(prefixHashes) > 0 {
+		s.prefixCacheIndexer.AddPrefix(prefixHashes, ctx.Model, pod.Name)
+	}
+}
+
+// loraAffinityScorer scores pods by the state of the LoRA adapter of the request on them, see lora-affinity router.
+type loraAffinityScorer struct {
+	cache cache.Cache
+}
+
+func NewLoraAffinityScorer(params types.RouterParameters) (types.Scorer, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	c, err := cache.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &loraAffinityScorer{cache: c}, nil
+}
+
+func (s *loraAffinityScorer) Score(ctx *types.RoutingContext, pods []*v1.Pod) ([]float64, error) {
+	scores := make([]float64, len(pods))
+	for i, pod := range pods {
+		scores[i] = getLoraAffinity(s.cache, ctx.Model, pod)
+	}
+	return scores, nil
+}
+
+// metricFilter drops the pods with a metric above the limit, pods without the metric are kept.
+type metricFilter struct {
+	cache  cache.Cache
+	metric string
+	limit  float64
+}
+
+// NewKvCacheUsageFilter drops the pods with a GPU KV cache usage above maxUsage, default 0.9.
+func NewKvCacheUsageFilter(params types.RouterParameters) (types.Filter, error) {
+	if err := params.Validate("maxUsage"); err != nil {
+		return nil, err
+	}
+	maxUsage, err := params.Float("maxUsage", defaultMaxKvCacheUsage)
+	if err != nil {
+		return nil, err
+	}
+	c, err := cache.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &metricFilter{cache: c, metric: metrics.GPUCacheUsagePerc, limit: maxUsage}, nil
+}
+
+// NewQueueDepthFilter drops the pods with more waiting requests than maxWaiting, default 10.
+func NewQueueDepthFilter(params types.RouterParameters) (types.Filter, error) {
+	if err := params.Validate("maxWaiting"); err != nil {
+		return nil, err
+	}
+	maxWaiting, err := params.Int("maxWaiting", defaultMaxWaitingRequest)
+	if err != nil {
+		return nil, err
+	}
+	c, err := cache.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &metricFilter{cache: c, metric: metrics.NumRequestsWaiting, limit: float64(maxWaiting)}, nil
+}
+
+func (f *metricFilter) Filter(ctx *types.RoutingContext, pods []*v1.Pod) []*v1.Pod {
+	filtered := make([]*v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		value, err := f.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, ctx.Model, f.metric)
+		if err == nil && value.GetSimpleValue() > f.limit {
+			continue
+		}
+		filtered = append(filtered, pod)
+	}
+	return filtered
+}
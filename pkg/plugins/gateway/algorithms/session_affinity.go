@@ -0,0 +1,229 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	lrustore "github.com/vllm-project/aibrix/pkg/utils/lrustore"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Parameters of the session-affinity router, a RoutingPolicy overrides the defaults of the environment variables.
+	sessionAffinityParamFallback           = "fallback"
+	sessionAffinityParamTTLSeconds         = "ttlSeconds"
+	sessionAffinityParamMaxRunningRequests = "maxRunningRequests"
+
+	defaultSessionAffinityTTLSeconds  = 1800
+	defaultSessionAffinityMaxSessions = 100000
+	sessionAffinityRedisKeyPrefix     = "aibrix:session-affinity"
+	sessionAffinityEvictionInterval   = time.Minute
+)
+
+var (
+	RouterSessionAffinity types.RoutingAlgorithm = "session-affinity"
+
+	sessionAffinityFallback           = utils.LoadEnv("AIBRIX_SESSION_AFFINITY_FALLBACK", string(RouterLeastRequest))
+	sessionAffinityTTLSeconds         = utils.LoadEnvInt("AIBRIX_SESSION_AFFINITY_TTL_SECONDS", defaultSessionAffinityTTLSeconds)
+	sessionAffinityMaxSessions        = utils.LoadEnvInt("AIBRIX_SESSION_AFFINITY_MAX_SESSIONS", defaultSessionAffinityMaxSessions)
+	sessionAffinityMaxRunningRequests = utils.LoadEnvInt("AIBRIX_SESSION_AFFINITY_MAX_RUNNING_REQUESTS", 0)
+	sessionAffinityRedisEnabled       = utils.LoadEnvBool("AIBRIX_SESSION_AFFINITY_REDIS_ENABLED", false)
+
+	// sessionRedisClient shares the sessions across gateway replicas, nil if not enabled.
+	sessionRedisClient *redis.Client
+)
+
+func init() {
+	RegisterWithParameters(RouterSessionAffinity, NewSessionAffinityRouter)
+}
+
+// InitSessionStore shares the sessions of session-affinity routers by Redis if AIBRIX_SESSION_AFFINITY_REDIS_ENABLED is set,
+// it must be called before Init to take effect on routers.
+func InitSessionStore(redisClient *redis.Client) {
+	if sessionAffinityRedisEnabled {
+		sessionRedisClient = redisClient
+	}
+}
+
+// sessionStore maps the sessions of a model to pods.
+type sessionStore interface {
+	get(ctx context.Context, key string) (string, bool)
+	// put pins the session to the pod and refreshes its ttl.
+	put(ctx context.Context, key string, podName string)
+	close()
+}
+
+type localSessionStore struct {
+	sessions lrustore.Store[string, string]
+}
+
+func (s *localSessionStore) get(_ context.Context, key string) (string, bool) {
+	return s.sessions.Get(key)
+}
+
+func (s *localSessionStore) put(_ context.Context, key string, podName string) {
+	s.sessions.Put(key, podName)
+}
+
+func (s *localSessionStore) close() {
+	s.sessions.Close()
+}
+
+type redisSessionStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func (s *redisSessionStore) get(ctx context.Context, key string) (string, bool) {
+	podName, err := s.client.Get(ctx, fmt.Sprintf("%s:%s", sessionAffinityRedisKeyPrefix, key)).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			klog.ErrorS(err, "failed to get session from redis", "session", key)
+		}
+		return "", false
+	}
+	return podName, true
+}
+
+func (s *redisSessionStore) close() {}
+
+func (s *redisSessionStore) put(ctx context.Context, key string, podName string) {
+	if err := s.client.Set(ctx, fmt.Sprintf("%s:%s", sessionAffinityRedisKeyPrefix, key), podName, s.ttl).Err(); err != nil {
+		klog.ErrorS(err, "failed to put session to redis", "session", key)
+	}
+}
+
+// sessionAffinityRouter routes the requests of a session to the same pod, so that consecutive turns reuse the KV cache.
+// Requests without a session, or whose pod is no longer ready or overloaded, are routed by the fallback algorithm.
+type sessionAffinityRouter struct {
+	cache              cache.Cache
+	sessions           sessionStore
+	fallback           types.RoutingAlgorithm
+	maxRunningRequests int
+}
+
+func NewSessionAffinityRouter(params types.RouterParameters) (types.Router, error) {
+	if err := params.Validate(sessionAffinityParamFallback, sessionAffinityParamTTLSeconds, sessionAffinityParamMaxRunningRequests); err != nil {
+		return nil, err
+	}
+	fallback := types.RoutingAlgorithm(params.String(sessionAffinityParamFallback, sessionAffinityFallback))
+	if _, ok := routerConstructor[fallback]; !ok || fallback == RouterSessionAffinity {
+		return nil, fmt.Errorf("unsupported fallback routing algorithm: %q", fallback)
+	}
+	ttlSeconds, err := params.Int(sessionAffinityParamTTLSeconds, sessionAffinityTTLSeconds)
+	if err != nil {
+		return nil, err
+	}
+	if ttlSeconds <= 0 {
+		return nil, fmt.Errorf("%s must be positive", sessionAffinityParamTTLSeconds)
+	}
+	maxRunningRequests, err := params.Int(sessionAffinityParamMaxRunningRequests, sessionAffinityMaxRunningRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cache.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(ttlSeconds) * time.Second
+	var sessions sessionStore
+	if sessionRedisClient != nil {
+		sessions = &redisSessionStore{client: sessionRedisClient, ttl: ttl}
+	} else {
+		sessions = &localSessionStore{sessions: lrustore.NewLRUStore[string, string](sessionAffinityMaxSessions,
+			ttl, min(ttl, sessionAffinityEvictionInterval), lrustore.DefaultGetCurrentTime)}
+	}
+
+	klog.InfoS("session_affinity_configurations",
+		"fallback", fallback,
+		"ttl", ttl,
+		"max_running_requests", maxRunningRequests,
+		"redis", sessionRedisClient != nil)
+
+	return &sessionAffinityRouter{
+		cache:              c,
+		sessions:           sessions,
+		fallback:           fallback,
+		maxRunningRequests: maxRunningRequests,
+	}, nil
+}
+
+func (r *sessionAffinityRouter) Route(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
+	if ctx.SessionID == "" {
+		return r.routeFallback(ctx, readyPodList)
+	}
+
+	key := fmt.Sprintf("%s:%s", ctx.Model, ctx.SessionID)
+	if podName, ok := r.sessions.get(ctx, key); ok {
+		if pod, ok := utils.FilterPodByName(podName, readyPodList.All()); ok {
+			if !r.overloaded(pod) {
+				r.sessions.put(ctx, key, pod.Name)
+				ctx.SetTargetPod(pod)
+				return ctx.TargetAddress(), nil
+			}
+			// Move the session off the overloaded pod, unless it is the only pod.
+			if others := utils.ExcludePods(readyPodList.All(), map[string]struct{}{utils.GeneratePodKey(pod.Namespace, pod.Name): {}}); len(others) > 0 {
+				readyPodList = &utils.PodArray{Pods: others}
+			}
+		}
+		klog.V(4).InfoS("session pod is not ready or overloaded, use fallback", "requestID", ctx.RequestID, "pod", podName)
+	}
+
+	targetPodIP, err := r.routeFallback(ctx, readyPodList)
+	if err != nil {
+		return "", err
+	}
+	r.sessions.put(ctx, key, ctx.TargetPod().Name)
+	return targetPodIP, nil
+}
+
+// Close stops the eviction of the local session map.
+func (r *sessionAffinityRouter) Close() {
+	r.sessions.close()
+}
+
+func (r *sessionAffinityRouter) routeFallback(ctx *types.RoutingContext, readyPodList types.PodList) (string, error) {
+	router, err := Select(r.fallback)(ctx)
+	if err != nil {
+		return "", err
+	}
+	return router.Route(ctx, readyPodList)
+}
+
+// overloaded returns true if the running requests of the pod reach maxRunningRequests.
+func (r *sessionAffinityRouter) overloaded(pod *v1.Pod) bool {
+	if r.maxRunningRequests <= 0 {
+		return false
+	}
+	running, err := r.cache.GetMetricValueByPod(pod.Name, pod.Namespace, metrics.RealtimeNumRequestsRunning)
+	if err != nil {
+		return false
+	}
+	return int(running.GetSimpleValue()) >= r.maxRunningRequests
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	lrustore "github.com/vllm-project/aibrix/pkg/utils/lrustore"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSessionAffinityRouter(t *testing.T) {
+	cache.InitForTest()
+	Init()
+	c := cache.NewTestCacheWithPodsMetrics(
+		getReadyPods(),
+		"m1",
+		map[string]map[string]metrics.MetricValue{
+			"p1": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+			"p2": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+			"p3": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+			"p4": {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 0}},
+		})
+	podList := podsFromCache(c)
+	sessions := &localSessionStore{sessions: lrustore.NewLRUStore[string, string](10, time.Minute, time.Minute, lrustore.DefaultGetCurrentTime)}
+	r := &sessionAffinityRouter{cache: c, sessions: sessions, fallback: RouterRandom, maxRunningRequests: 4}
+
+	route := func(requestID, sessionID string, pods types.PodList) string {
+		ctx := types.NewRoutingContext(context.Background(), RouterSessionAffinity, "m1", "", requestID, "")
+		ctx.SessionID = sessionID
+		_, err := r.Route(ctx, pods)
+		assert.NoError(t, err)
+		return ctx.TargetPod().Name
+	}
+
+	// Requests without a session are not pinned.
+	route("r0", "", podList)
+	_, ok := sessions.get(context.Background(), "m1:")
+	assert.False(t, ok)
+
+	// Requests of a session stick to the first routed pod.
+	pinned := route("r1", "s1", podList)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, pinned, route("r2", "s1", podList))
+	}
+
+	// The session is pinned to another pod if its pod is not ready.
+	var others []*v1.Pod
+	for _, pod := range podList.All() {
+		if pod.Name != pinned {
+			others = append(others, pod)
+		}
+	}
+	repinned := route("r3", "s1", &utils.PodArray{Pods: others})
+	assert.NotEqual(t, pinned, repinned)
+	assert.Equal(t, repinned, route("r4", "s1", podList))
+
+	// The session is pinned to another pod if its pod is overloaded.
+	overloaded := cache.NewTestCacheWithPodsMetrics(
+		getReadyPods(),
+		"m1",
+		map[string]map[string]metrics.MetricValue{
+			repinned: {metrics.RealtimeNumRequestsRunning: &metrics.SimpleMetricValue{Value: 4}},
+		})
+	r.cache = overloaded
+	assert.NotEqual(t, repinned, route("r5", "s1", podList))
+}
+
+func TestNewSessionAffinityRouter(t *testing.T) {
+	cache.InitForTest()
+
+	router, err := NewSessionAffinityRouter(types.RouterParameters{
+		sessionAffinityParamFallback:           string(RouterPrefixCache),
+		sessionAffinityParamTTLSeconds:         "60",
+		sessionAffinityParamMaxRunningRequests: "8",
+	})
+	assert.NoError(t, err)
+	r := router.(*sessionAffinityRouter)
+	assert.Equal(t, RouterPrefixCache, r.fallback)
+	assert.Equal(t, 8, r.maxRunningRequests)
+
+	for _, params := range []types.RouterParameters{
+		{sessionAffinityParamFallback: "unknown"},
+		{sessionAffinityParamFallback: string(RouterSessionAffinity)},
+		{sessionAffinityParamTTLSeconds: "0"},
+		{sessionAffinityParamMaxRunningRequests: "many"},
+		{"unknown": "1"},
+	} {
+		_, err := NewSessionAffinityRouter(params)
+		assert.Error(t, err, params)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"github.com/vllm-project/aibrix/pkg/utils/chattemplate"
+	"github.com/vllm-project/aibrix/pkg/utils/tokenizer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// tokenizerConfigPath is the file mapping models to their tokenizers, see tokenizer.Config.
+	tokenizerConfigPath = utils.LoadEnv("AIBRIX_PREFIX_CACHE_TOKENIZER_CONFIG", "")
+	// tokenizerRegistry holds the per-model tokenizers and chat templates, nil if not configured.
+	tokenizerRegistry *tokenizer.Registry
+)
+
+// InitTokenizers loads the per-model tokenizers, it must be called before Init to take effect on routers.
+// The kubernetes client is used to read tokenizers from ConfigMaps.
+func InitTokenizers(client kubernetes.Interface) {
+	if tokenizerConfigPath == "" {
+		return
+	}
+	config, err := tokenizer.LoadConfig(tokenizerConfigPath)
+	if err != nil {
+		klog.ErrorS(err, "failed to load tokenizer config, use the default tokenizer for all models", "path", tokenizerConfigPath)
+		return
+	}
+	tokenizerRegistry = tokenizer.NewRegistry(config, client)
+}
+
+// LookupChatTemplate returns the chat template configured with the tokenizer of the model.
+func LookupChatTemplate(model string) (*chattemplate.ChatTemplate, bool) {
+	return tokenizerRegistry.LookupChatTemplate(model)
+}
@@ -53,12 +53,30 @@ var (
 	utilizationWeight = utils.LoadEnvFloat(VTC_UTILIZATION_WEIGHT, defaultUtilizationWeight)
 )
 
+// Weights are the scoring parameters of vtc-basic, they default to the environment variables.
+type Weights struct {
+	MaxPodLoad        float64
+	FairnessWeight    float64
+	UtilizationWeight float64
+}
+
+// DefaultWeights returns the weights loaded from environment
+func DefaultWeights() Weights {
+	return Weights{
+		MaxPodLoad:        maxPodLoad,
+		FairnessWeight:    fairnessWeight,
+		UtilizationWeight: utilizationWeight,
+	}
+}
+
 // BasicVTCRouter implements the VTC routing algorithm
 type BasicVTCRouter struct {
 	cache          cache.Cache
 	tokenTracker   TokenTracker
 	tokenEstimator TokenEstimator
 	config         *VTCConfig
+	// weights overrides DefaultWeights if set
+	weights *Weights
 }
 
 // NewBasicVTCRouter creates a new BasicVTCRouter with the provided token tracker and estimator
@@ -121,6 +139,11 @@ func (r *BasicVTCRouter) Route(ctx *types.RoutingContext, readyPodList types.Pod
 		minTokens = tokenTrackerMinTokens // Use the configured default minimum token count
 	}
 
+	weights := DefaultWeights()
+	if r.weights != nil {
+		weights = *r.weights
+	}
+
 	maxTokens, err := r.tokenTracker.GetMaxTokenCount(ctx.Context)
 	if err != nil {
 		klog.ErrorS(err, "failed to get maximum token count, using default value")
@@ -173,13 +196,13 @@ func (r *BasicVTCRouter) Route(ctx *types.RoutingContext, readyPodList types.Pod
 		}
 
 		// 3. Calculate utilization score (normalized between 0-1)
-		utilizationScore := min(podLoad/maxPodLoad, 1.0)
+		utilizationScore := min(podLoad/weights.MaxPodLoad, 1.0)
 
 		// 4. Add a small random factor to break ties and improve distribution
 		randomFactor := rand.Float64() * 0.1
 
 		// 5. Calculate combined score (lower is better) - using configurable weights for fairness and utilization
-		score := (fairnessWeight * fairnessScore) + (utilizationWeight * utilizationScore) + randomFactor
+		score := (weights.FairnessWeight * fairnessScore) + (weights.UtilizationWeight * utilizationScore) + randomFactor
 
 		klog.InfoS("VTC hybrid pod selection",
 			"pod", pod.Name,
@@ -187,9 +210,9 @@ func (r *BasicVTCRouter) Route(ctx *types.RoutingContext, readyPodList types.Pod
 			"userTokens", userTokens,
 			"podLoad", podLoad,
 			"fairnessScore", fairnessScore,
-			"fairnessWeight", fairnessWeight,
+			"fairnessWeight", weights.FairnessWeight,
 			"utilizationScore", utilizationScore,
-			"utilizationWeight", utilizationWeight,
+			"utilizationWeight", weights.UtilizationWeight,
 			"combinedScore", score)
 
 		if score < minScore {
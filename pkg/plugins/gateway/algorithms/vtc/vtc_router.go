@@ -19,6 +19,7 @@ package vtc
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/vllm-project/aibrix/pkg/types"
 )
@@ -60,10 +61,45 @@ func DefaultVTCConfig() VTCConfig {
 	}
 }
 
-func NewVTCBasicRouter() (types.Router, error) {
+// Parameters of vtc-basic, a RoutingPolicy overrides the defaults of the environment variables.
+const (
+	ParamInputTokenWeight  = "inputTokenWeight"
+	ParamOutputTokenWeight = "outputTokenWeight"
+	ParamMaxPodLoad        = "maxPodLoad"
+	ParamFairnessWeight    = "fairnessWeight"
+	ParamUtilizationWeight = "utilizationWeight"
+)
+
+func NewVTCBasicRouter(params types.RouterParameters) (types.Router, error) {
+	if err := params.Validate(ParamInputTokenWeight, ParamOutputTokenWeight,
+		ParamMaxPodLoad, ParamFairnessWeight, ParamUtilizationWeight); err != nil {
+		return nil, err
+	}
 	config := DefaultVTCConfig()
+	weights := DefaultWeights()
+	for key, value := range map[string]*float64{
+		ParamInputTokenWeight:  &config.InputTokenWeight,
+		ParamOutputTokenWeight: &config.OutputTokenWeight,
+		ParamMaxPodLoad:        &weights.MaxPodLoad,
+		ParamFairnessWeight:    &weights.FairnessWeight,
+		ParamUtilizationWeight: &weights.UtilizationWeight,
+	} {
+		var err error
+		if *value, err = params.Float(key, *value); err != nil {
+			return nil, err
+		}
+	}
+	if weights.MaxPodLoad <= 0 {
+		return nil, fmt.Errorf("%s must be positive", ParamMaxPodLoad)
+	}
+
 	configPtr := &config
 	var tokenEstimator TokenEstimator = NewSimpleTokenEstimator()
 	var tokenTracker TokenTracker = NewInMemorySlidingWindowTokenTracker(configPtr)
-	return NewBasicVTCRouter(tokenTracker, tokenEstimator, configPtr)
+	router, err := NewBasicVTCRouter(tokenTracker, tokenEstimator, configPtr)
+	if err != nil {
+		return nil, err
+	}
+	router.weights = &weights
+	return router, nil
 }
@@ -21,7 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,13 +33,18 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/vllm-project/aibrix/pkg/cache"
 	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/guardrail"
 	"github.com/vllm-project/aibrix/pkg/plugins/gateway/ratelimiter"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
 	"github.com/vllm-project/aibrix/pkg/types"
 	"github.com/vllm-project/aibrix/pkg/utils"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayapi "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
@@ -50,10 +57,21 @@ const (
 type Server struct {
 	redisClient         *redis.Client
 	ratelimiter         ratelimiter.RateLimiter
+	tokenBucketLimiter  ratelimiter.TokenBucketRateLimiter
+	concurrencyLimiter  ratelimiter.ConcurrencyLimiter
 	client              kubernetes.Interface
 	gatewayClient       *gatewayapi.Clientset
 	requestCountTracker map[string]int
 	cache               cache.Cache
+	httpClient          *http.Client
+	requestQueue        *requestQueue
+	usageExporter       *usagelog.Exporter[usagelog.Event]
+	shadowExporter      *usagelog.Exporter[usagelog.ShadowEvent]
+	responseCache       responsecache.Store
+	guardrail           *guardrail.Guardrail
+	// stopCh stops the background loops of the server once closed.
+	stopCh    chan struct{}
+	closeOnce sync.Once
 }
 
 func NewServer(redisClient *redis.Client, client kubernetes.Interface, gatewayClient *gatewayapi.Clientset) *Server {
@@ -61,36 +79,71 @@ func NewServer(redisClient *redis.Client, client kubernetes.Interface, gatewayCl
 	if err != nil {
 		panic(err)
 	}
-	r := ratelimiter.NewRedisAccountRateLimiter("aibrix", redisClient, 1*time.Minute)
+	r := ratelimiter.NewRedisAccountRateLimiter("aibrix", redisClient, rateLimitWindow)
 
 	// Initialize the routers
+	routing.InitTokenizers(client)
+	routing.InitSessionStore(redisClient)
 	routing.Init()
 
-	return &Server{
+	s := &Server{
 		redisClient:         redisClient,
 		ratelimiter:         r,
+		tokenBucketLimiter:  ratelimiter.NewRedisTokenBucketRateLimiter("aibrix", redisClient),
+		concurrencyLimiter:  ratelimiter.NewRedisConcurrencyLimiter("aibrix", redisClient, concurrencySlotTTL),
 		client:              client,
 		gatewayClient:       gatewayClient,
 		requestCountTracker: map[string]int{},
 		cache:               c,
+		httpClient:          &http.Client{Timeout: retryTimeout},
+		usageExporter:       newUsageExporter(redisClient),
+		shadowExporter:      newShadowExporter(redisClient),
+		responseCache:       newResponseCache(redisClient),
+		guardrail:           newGuardrail(),
+		stopCh:              make(chan struct{}),
 	}
+	if queueEnabled {
+		s.requestQueue = newRequestQueue(s.queueCapacity, queueMaxSize, queueTimeouts)
+		go s.requestQueue.run(queueDispatchInterval, s.stopCh)
+	}
+	return s
 }
 
 func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 	var user utils.User
-	var rpm, traceTerm int64
+	var rpm, traceTerm, preChargedTokens int64
 	var respErrorCode int
 	var model string
 	var requestPath string
 	var routingAlgorithm types.RoutingAlgorithm
 	var routerCtx *types.RoutingContext
+	var requestHeaders []*configPb.HeaderValue
+	var requestBody []byte
 	var stream, isRespError bool
 	ctx := srv.Context()
 	requestID := uuid.New().String()
 	completed := false
 	resp := &extProcPb.ProcessingResponse{}
+	start := time.Now()
+	event := &usagelog.Event{RequestID: requestID}
 
 	klog.InfoS("processing request", "requestID", requestID)
+	defer func() {
+		// A stream aborted mid-way is accounted by the tokens streamed so far.
+		if !completed && s.doneAbortedStream(routerCtx, requestID, user, model, traceTerm, event) {
+			completed = true
+		}
+		streamUsages.Delete(requestID)
+		routedAts.Delete(requestID)
+		guardrailStreams.Delete(requestID)
+		// Either the request completed or was aborted, the usage is reconciled only on completion.
+		s.doneLimits(requestID, user, preChargedTokens, completed)
+		s.emitUsage(event, start)
+		s.doneShadowPrimary(requestID, event, start)
+		s.doneResponseCapture(requestID, event)
+		s.donePrefill(requestID)
+		s.doneAdmission(requestID)
+	}()
 
 	for {
 		select {
@@ -111,15 +164,42 @@ func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 
 		case *extProcPb.ProcessingRequest_RequestHeaders:
 			resp, user, rpm, routingAlgorithm, requestPath = s.HandleRequestHeaders(ctx, requestID, req)
+			requestHeaders = v.RequestHeaders.GetHeaders().GetHeaders()
+			event.User = user.Name
 
 		case *extProcPb.ProcessingRequest_RequestBody:
-			resp, model, routerCtx, stream, traceTerm = s.HandleRequestBody(ctx, requestID, requestPath, req, user, routingAlgorithm)
+			requestBody = v.RequestBody.GetBody()
+			resp, model, routerCtx, stream, traceTerm = s.HandleRequestBody(ctx, srv, requestID, requestPath, req, user, requestHeaders, routingAlgorithm)
+			if mutatedBody := resp.GetRequestBody().GetResponse().GetBodyMutation().GetBody(); mutatedBody != nil {
+				// Retries replay the body sent upstream.
+				requestBody = mutatedBody
+			}
 			if routerCtx != nil {
 				ctx = routerCtx
 			}
+			recordRouting(event, model, routerCtx)
+			recordResponseCacheHit(event, requestID)
+			if resp.GetImmediateResponse() == nil && routerCtx != nil {
+				preChargedTokens = s.preChargeTPM(ctx, requestID, user, routerCtx.Message)
+			}
 
 		case *extProcPb.ProcessingRequest_ResponseHeaders:
-			resp, isRespError, respErrorCode = s.HandleResponseHeaders(ctx, requestID, model, req)
+			// Snapshot the routed request before the routing context is released on response error.
+			retry := newRetryRequest(routerCtx, requestPath, requestHeaders, requestBody, user, rpm, stream)
+			resp, isRespError, respErrorCode = s.HandleResponseHeaders(ctx, requestID, model, req, user, rpm)
+			// The decode pod responds once the request is prefilled.
+			s.donePrefill(requestID)
+			event.StatusCode = http.StatusOK
+			if isRespError {
+				event.StatusCode = respErrorCode
+				if retryResp, ok := s.retryOnAnotherPod(srv, retry, respErrorCode, event); ok {
+					recordShadowPrimary(requestID, []byte(retryResp.GetImmediateResponse().GetBody()))
+					recordResponseCapture(requestID, []byte(retryResp.GetImmediateResponse().GetBody()), true)
+					resp, isRespError = retryResp, false
+					break
+				}
+			}
+
 			if isRespError && respErrorCode == 500 {
 				// for error code 500, ProcessingRequest_ResponseBody is not invoked
 				resp = s.responseErrorProcessing(ctx, resp, respErrorCode, model, requestID, "")
@@ -127,7 +207,7 @@ func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 
 			if isRespError && respErrorCode == 401 {
 				// Early return due to unauthorized or canceled context we noticed.
-				resp = s.responseErrorProcessing(ctx, resp, respErrorCode, model, requestID, `{"error":"unauthorized"}`)
+				resp = s.responseErrorProcessing(ctx, resp, respErrorCode, model, requestID, "unauthorized")
 			}
 
 		case *extProcPb.ProcessingRequest_ResponseBody:
@@ -135,17 +215,33 @@ func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 				resp = s.responseErrorProcessing(ctx, resp, respErrorCode, model, requestID,
 					string(req.Request.(*extProcPb.ProcessingRequest_ResponseBody).ResponseBody.GetBody()))
 			} else {
-				resp, completed = s.HandleResponseBody(ctx, requestID, req, user, rpm, model, stream, traceTerm, completed)
+				if event.TTFTMs == 0 {
+					event.TTFTMs = time.Since(start).Milliseconds()
+				}
+				responseBody := req.Request.(*extProcPb.ProcessingRequest_ResponseBody).ResponseBody
+				recordShadowPrimary(requestID, responseBody.GetBody())
+				resp, completed = s.HandleResponseBody(ctx, requestID, requestPath, req, user, rpm, preChargedTokens, model, stream, traceTerm, completed, event)
+				// Cached responses replay the body sent to the client.
+				sentBody := responseBody.GetBody()
+				if bodyMutation := resp.GetResponseBody().GetResponse().GetBodyMutation(); bodyMutation != nil {
+					sentBody = bodyMutation.GetBody()
+				}
+				recordResponseCapture(requestID, sentBody, responseBody.GetEndOfStream())
 			}
 		default:
 			klog.Infof("Unknown Request type %+v\n", v)
 		}
 
+		recordImmediateResponse(event, resp)
 		if err := srv.Send(resp); err != nil && len(model) > 0 {
 			klog.ErrorS(nil, err.Error(), "requestID", requestID)
-			s.cache.DoneRequestCount(routerCtx, requestID, model, traceTerm)
-			if routerCtx != nil {
-				routerCtx.Delete()
+			if !completed && s.doneAbortedStream(routerCtx, requestID, user, model, traceTerm, event) {
+				completed = true
+			} else {
+				s.cache.DoneRequestCount(routerCtx, requestID, model, traceTerm)
+				if routerCtx != nil {
+					routerCtx.Delete()
+				}
 			}
 
 			// Optional: if it's context or connection-related, don’t retry
@@ -169,7 +265,9 @@ func (s *Server) selectTargetPod(ctx *types.RoutingContext, pods types.PodList)
 	if len(readyPods) == 0 {
 		return "", fmt.Errorf("no ready pods for routing")
 	}
-	if len(readyPods) == 1 {
+	readyPods = s.filterHealthyPods(readyPods)
+	// A disaggregated request needs a prefill and a decode pod.
+	if len(readyPods) == 1 && ctx.Algorithm != routing.RouterPDDisagg {
 		ctx.SetTargetPod(readyPods[0])
 		return ctx.TargetAddress(), nil
 	}
@@ -177,6 +275,24 @@ func (s *Server) selectTargetPod(ctx *types.RoutingContext, pods types.PodList)
 	return router.Route(ctx, &utils.PodArray{Pods: readyPods})
 }
 
+// filterHealthyPods drops pods in an unhealthy cooldown, all pods are kept if none of them is healthy.
+func (s *Server) filterHealthyPods(pods []*v1.Pod) []*v1.Pod {
+	tracker, ok := s.cache.(podHealthTracker)
+	if !ok {
+		return pods
+	}
+	healthyPods := make([]*v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if tracker.IsPodHealthy(pod.Name, pod.Namespace) {
+			healthyPods = append(healthyPods, pod)
+		}
+	}
+	if len(healthyPods) == 0 {
+		return pods
+	}
+	return healthyPods
+}
+
 // validateHTTPRouteStatus checks if httproute object exists and validates its conditions are true
 func (s *Server) validateHTTPRouteStatus(ctx context.Context, model string) error {
 	errMsg := []string{}
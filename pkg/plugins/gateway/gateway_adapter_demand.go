@@ -0,0 +1,234 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	adapterLoadPollInterval = 500 * time.Millisecond
+	// maxAnnotationNameLength is the maximal length of the name of an annotation key after the prefix.
+	maxAnnotationNameLength = 63
+)
+
+var (
+	adapterDemandReportInterval = time.Duration(utils.LoadEnvInt("AIBRIX_LORA_DEMAND_REPORT_INTERVAL_SECONDS", 10)) * time.Second
+	adapterLoadTimeout          = time.Duration(utils.LoadEnvInt("AIBRIX_LORA_LOAD_TIMEOUT_SECONDS", 60)) * time.Second
+	// maxMessageTimeout must match max_message_timeout of the ext_proc filter, set by the
+	// gateway-plugins-max-message-timeout EnvoyPatchPolicy. Envoy ignores larger message timeout overrides.
+	maxMessageTimeout = time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_MAX_MESSAGE_TIMEOUT_SECONDS", 120)) * time.Second
+
+	adapterDemands = &demandReporter{requests: map[k8stypes.NamespacedName]*adapterRequests{}}
+)
+
+// demandReporter reports the request rates of autoscaled ModelAdapters seen by this gateway in their annotations.
+type demandReporter struct {
+	mu     sync.Mutex
+	client versioned.Interface
+	// annotation is the annotation key of the reports of this gateway.
+	annotation string
+	requests   map[k8stypes.NamespacedName]*adapterRequests
+}
+
+// adapterRequests counts the requests of a model adapter since the last report.
+type adapterRequests struct {
+	count int64
+	since time.Time
+	// lastReported is the time of the last report, zero if the requests are not reported yet.
+	lastReported time.Time
+}
+
+// ReportModelAdapterDemand reports the request rates of autoscaled ModelAdapters until stopCh is closed, which the
+// controller scales them with.
+func ReportModelAdapterDemand(client versioned.Interface, stopCh <-chan struct{}) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	if len(hostname) > maxAnnotationNameLength {
+		hostname = hostname[:maxAnnotationNameLength]
+	}
+
+	adapterDemands.mu.Lock()
+	adapterDemands.client = client
+	adapterDemands.annotation = modelv1alpha1.ModelAdapterDemandAnnotationPrefix + hostname
+	adapterDemands.mu.Unlock()
+	go wait.Until(func() { adapterDemands.reportAll(time.Now()) }, adapterDemandReportInterval, stopCh)
+	return nil
+}
+
+// record counts a request of a model adapter.
+func (d *demandReporter) record(key k8stypes.NamespacedName, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	requests, ok := d.requests[key]
+	if !ok {
+		requests = &adapterRequests{since: now}
+		d.requests[key] = requests
+	}
+	requests.count++
+}
+
+// reportOnDemand reports the requests of an idle model adapter so that it is loaded, unless reported recently.
+func (d *demandReporter) reportOnDemand(key k8stypes.NamespacedName, now time.Time) {
+	d.mu.Lock()
+	requests, ok := d.requests[key]
+	if !ok || requests.count == 0 || now.Sub(requests.lastReported) < adapterDemandReportInterval {
+		d.mu.Unlock()
+		return
+	}
+	value := d.takeReportLocked(requests, now)
+	d.mu.Unlock()
+	d.report(key, value)
+}
+
+// reportAll reports the request rates of the model adapters which got requests since the last report.
+func (d *demandReporter) reportAll(now time.Time) {
+	d.mu.Lock()
+	reports := map[k8stypes.NamespacedName]string{}
+	for key, requests := range d.requests {
+		if requests.count == 0 {
+			delete(d.requests, key)
+			continue
+		}
+		reports[key] = d.takeReportLocked(requests, now)
+	}
+	d.mu.Unlock()
+
+	for key, value := range reports {
+		d.report(key, value)
+	}
+}
+
+// takeReportLocked returns the report of the requests since the last report and resets the count. The request rate is
+// over the report interval at least, so that a single request reported on demand does not count as a burst.
+func (d *demandReporter) takeReportLocked(requests *adapterRequests, now time.Time) string {
+	elapsed := max(now.Sub(requests.since), adapterDemandReportInterval)
+	value := fmt.Sprintf("%d,%g", now.Unix(), float64(requests.count)/elapsed.Seconds())
+	requests.count, requests.since, requests.lastReported = 0, now, now
+	return value
+}
+
+// report patches the annotation of this gateway on the model adapter, it is a no-op until the reports are started.
+func (d *demandReporter) report(key k8stypes.NamespacedName, value string) {
+	d.mu.Lock()
+	client, annotation := d.client, d.annotation
+	d.mu.Unlock()
+	if client == nil {
+		return
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"annotations": map[string]string{annotation: value}},
+	})
+	if err != nil {
+		klog.ErrorS(err, "failed to build demand report of model adapter", "modelAdapter", key)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), adapterDemandReportInterval)
+	defer cancel()
+	if _, err := client.ModelV1alpha1().ModelAdapters(key.Namespace).Patch(ctx, key.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.ErrorS(err, "failed to report demand of model adapter", "modelAdapter", key)
+		return
+	}
+	klog.V(4).InfoS("reported demand of model adapter", "modelAdapter", key, "report", value)
+}
+
+// modelAdapterCache gets the ModelAdapter of a LoRA adapter model.
+type modelAdapterCache interface {
+	GetModelAdapter(modelName string) (*modelv1alpha1.ModelAdapter, bool)
+}
+
+var _ modelAdapterCache = (*cache.Store)(nil)
+
+// getModelAdapter returns false if the model is not a LoRA adapter, or the cache does not track ModelAdapters.
+func (s *Server) getModelAdapter(model string) (*modelv1alpha1.ModelAdapter, bool) {
+	if c, ok := s.cache.(modelAdapterCache); ok {
+		return c.GetModelAdapter(model)
+	}
+	return nil, false
+}
+
+// isAutoscaledAdapter returns whether the model is a ModelAdapter scaled with its request rate, which may be loaded on
+// no pod while it is idle.
+func (s *Server) isAutoscaledAdapter(model string) bool {
+	adapter, ok := s.getModelAdapter(model)
+	return ok && adapter.Spec.Autoscaling != nil
+}
+
+// awaitModelAdapter counts a request of an autoscaled ModelAdapter. If the model adapter is idle, it reports the demand
+// at once and waits for the model adapter to be loaded. It returns false if the model adapter is not loaded in time.
+// The ext_proc message timeout is extended while waiting, the wait is clamped to stay within maxMessageTimeout.
+func (s *Server) awaitModelAdapter(ctx context.Context, srv extProcPb.ExternalProcessor_ProcessServer, requestID, model string) bool {
+	adapter, ok := s.getModelAdapter(model)
+	if !ok || adapter.Spec.Autoscaling == nil {
+		return true
+	}
+	key := k8stypes.NamespacedName{Namespace: adapter.Namespace, Name: adapter.Name}
+	adapterDemands.record(key, time.Now())
+	if s.modelAdapterLoaded(model) {
+		return true
+	}
+
+	klog.InfoS("waiting for model adapter to be loaded", "requestID", requestID, "model", model)
+	adapterDemands.reportOnDemand(key, time.Now())
+	timeout := adapterLoadWait()
+	if srv != nil {
+		if err := srv.Send(&extProcPb.ProcessingResponse{OverrideMessageTimeout: durationpb.New(timeout + time.Second)}); err != nil {
+			klog.ErrorS(err, "failed to extend message timeout for model adapter loading", "requestID", requestID)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := wait.PollUntilContextCancel(ctx, adapterLoadPollInterval, false, func(context.Context) (bool, error) {
+		return s.modelAdapterLoaded(model), nil
+	})
+	return err == nil
+}
+
+// adapterLoadWait returns how long a request waits for its model adapter, the load timeout clamped so that the extended
+// message timeout does not exceed maxMessageTimeout.
+func adapterLoadWait() time.Duration {
+	if limit := maxMessageTimeout - time.Second; adapterLoadTimeout > limit {
+		return max(limit, 0)
+	}
+	return adapterLoadTimeout
+}
+
+// modelAdapterLoaded returns whether a ModelAdapter is loaded on a pod it would be routed to. The pods of a ModelAdapter
+// in the cache are the instances it is ready on, so the aggregate ReadyReplicas is not checked.
+func (s *Server) modelAdapterLoaded(model string) bool {
+	pods, err := s.cache.ListPodsByModel(model)
+	return err == nil && pods != nil && utils.CountRoutablePods(pods.All()) > 0
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned/fake"
+)
+
+func Test_demandReporter(t *testing.T) {
+	adapter := &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-lora", Namespace: "default"},
+		Spec:       modelv1alpha1.ModelAdapterSpec{Autoscaling: &modelv1alpha1.ModelAdapterAutoscaling{MaxReplicas: 2}},
+	}
+	client := fake.NewSimpleClientset(adapter)
+	annotation := modelv1alpha1.ModelAdapterDemandAnnotationPrefix + "gw-1"
+	d := &demandReporter{client: client, annotation: annotation, requests: map[k8stypes.NamespacedName]*adapterRequests{}}
+	key := k8stypes.NamespacedName{Namespace: adapter.Namespace, Name: adapter.Name}
+	reported := func() string {
+		adapter, err := client.ModelV1alpha1().ModelAdapters(key.Namespace).Get(context.Background(), key.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return adapter.Annotations[annotation]
+	}
+
+	// The demand of an idle model adapter is reported at once, as a rate over the report interval at least.
+	now := time.Unix(10000, 0)
+	d.record(key, now)
+	d.reportOnDemand(key, now)
+	assert.Equal(t, fmt.Sprintf("%d,%g", now.Unix(), 1/adapterDemandReportInterval.Seconds()), reported())
+
+	// The requests since are reported periodically, not on demand again within the report interval.
+	for i := 0; i < 4; i++ {
+		d.record(key, now.Add(time.Second))
+	}
+	d.reportOnDemand(key, now.Add(time.Second))
+	assert.Equal(t, fmt.Sprintf("%d,%g", now.Unix(), 1/adapterDemandReportInterval.Seconds()), reported())
+	later := now.Add(2 * adapterDemandReportInterval)
+	d.reportAll(later)
+	assert.Equal(t, fmt.Sprintf("%d,%g", later.Unix(), 4/(2*adapterDemandReportInterval).Seconds()), reported())
+
+	// Model adapters without requests are neither reported nor tracked.
+	d.reportAll(later.Add(adapterDemandReportInterval))
+	assert.Equal(t, fmt.Sprintf("%d,%g", later.Unix(), 4/(2*adapterDemandReportInterval).Seconds()), reported())
+	assert.Empty(t, d.requests)
+}
+
+func Test_awaitModelAdapter(t *testing.T) {
+	pods := []*v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default", Labels: map[string]string{modelIdentifier: "llama"}},
+			Status: v1.PodStatus{PodIP: "10.0.0.1",
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default", Labels: map[string]string{modelIdentifier: "llama"}},
+			Status:     v1.PodStatus{PodIP: "10.0.0.2"},
+		},
+	}
+	defer func(timeout time.Duration) { adapterLoadTimeout = timeout }(adapterLoadTimeout)
+	adapterLoadTimeout = time.Second
+
+	tests := []struct {
+		name     string
+		status   modelv1alpha1.ModelAdapterStatus
+		expected bool
+	}{
+		{
+			name: "loaded on a routable pod",
+			status: modelv1alpha1.ModelAdapterStatus{ReadyReplicas: 1, Instances: []string{"p1"},
+				InstanceStatuses: []modelv1alpha1.ModelAdapterInstanceStatus{{PodName: "p1", Ready: true}}},
+			expected: true,
+		},
+		{
+			name: "scheduled to a pod and not ready",
+			status: modelv1alpha1.ModelAdapterStatus{Instances: []string{"p1"},
+				InstanceStatuses: []modelv1alpha1.ModelAdapterInstanceStatus{{PodName: "p1", Ready: false}}},
+			expected: false,
+		},
+		{
+			name: "ready on a pod that is not routable",
+			status: modelv1alpha1.ModelAdapterStatus{ReadyReplicas: 1, Instances: []string{"p1", "p2"},
+				InstanceStatuses: []modelv1alpha1.ModelAdapterInstanceStatus{{PodName: "p1", Ready: false}, {PodName: "p2", Ready: true}}},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &modelv1alpha1.ModelAdapter{
+				ObjectMeta: metav1.ObjectMeta{Name: "llama-lora", Namespace: "default"},
+				Spec:       modelv1alpha1.ModelAdapterSpec{Autoscaling: &modelv1alpha1.ModelAdapterAutoscaling{MaxReplicas: 2}},
+				Status:     tt.status,
+			}
+			s := &Server{cache: cache.NewTestCacheWithModelAdapters(pods, []*modelv1alpha1.ModelAdapter{adapter})}
+			assert.True(t, s.isAutoscaledAdapter("llama-lora"))
+			assert.True(t, s.awaitModelAdapter(context.Background(), nil, "r1", "llama"))
+
+			start := time.Now()
+			assert.Equal(t, tt.expected, s.awaitModelAdapter(context.Background(), nil, "r2", "llama-lora"))
+			if !tt.expected {
+				assert.GreaterOrEqual(t, time.Since(start), adapterLoadTimeout)
+			}
+		})
+	}
+}
+
+func Test_adapterLoadWait(t *testing.T) {
+	defer func(timeout, max time.Duration) { adapterLoadTimeout, maxMessageTimeout = timeout, max }(adapterLoadTimeout, maxMessageTimeout)
+	adapterLoadTimeout, maxMessageTimeout = 60*time.Second, 120*time.Second
+	assert.Equal(t, 60*time.Second, adapterLoadWait())
+
+	// The extended message timeout of the wait stays within max_message_timeout.
+	adapterLoadTimeout = 300 * time.Second
+	assert.Equal(t, 119*time.Second, adapterLoadWait())
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const headerAuthorization = "authorization"
+
+var (
+	// apiKeyAuthEnabled authenticates users by the bearer api key instead of trusting the user header.
+	// The authorization header is removed before the request is forwarded to the model.
+	apiKeyAuthEnabled = utils.LoadEnvBool("AIBRIX_GATEWAY_API_KEY_AUTH_ENABLED", false)
+)
+
+// authenticate returns the user of the bearer api key in the authorization header.
+func (s *Server) authenticate(ctx context.Context, requestID string, headers []*configPb.HeaderValue) (utils.User, *extProcPb.ProcessingResponse) {
+	key, ok := getBearerToken(headers)
+	if !ok {
+		klog.ErrorS(nil, "no bearer api key in the request", "requestID", requestID)
+		return utils.User{}, buildErrorResponse(envoyTypePb.StatusCode_Unauthorized, "missing bearer api key", HeaderErrorAuthentication, "true")
+	}
+
+	user, err := utils.AuthenticateAPIKey(ctx, key, s.redisClient)
+	if err != nil {
+		if errors.Is(err, utils.ErrAPIKeyNotFound) || errors.Is(err, utils.ErrAPIKeyExpired) || errors.Is(err, redis.Nil) {
+			klog.ErrorS(err, "invalid api key", "requestID", requestID)
+			return utils.User{}, buildErrorResponse(envoyTypePb.StatusCode_Unauthorized, "invalid api key", HeaderErrorAuthentication, "true")
+		}
+		klog.ErrorS(err, "unable to authenticate api key", "requestID", requestID)
+		return utils.User{}, buildErrorResponse(envoyTypePb.StatusCode_InternalServerError, "unable to authenticate api key", HeaderErrorUser, "true")
+	}
+	return user, nil
+}
+
+// getBearerToken returns the token of the "Authorization: Bearer <token>" header.
+func getBearerToken(headers []*configPb.HeaderValue) (string, bool) {
+	for _, header := range headers {
+		if strings.ToLower(header.Key) != headerAuthorization {
+			continue
+		}
+		value := strings.TrimSpace(string(header.RawValue))
+		if len(value) > len("bearer ") && strings.EqualFold(value[:len("bearer ")], "bearer ") {
+			token := strings.TrimSpace(value[len("bearer "):])
+			return token, token != ""
+		}
+		return "", false
+	}
+	return "", false
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+)
+
+// OpenAI error types of the errors returned by the gateway.
+const (
+	errorTypeInvalidRequest    = "invalid_request_error"
+	errorTypeRequests          = "requests"
+	errorTypeTokens            = "tokens"
+	errorTypeInsufficientQuota = "insufficient_quota"
+	errorTypeServer            = "server_error"
+)
+
+// Stable codes of the errors returned by the gateway, clients can rely on them unlike on the messages.
+const (
+	errorCodeInvalidRequest         = "invalid_request"
+	errorCodeInvalidRequestBody     = "invalid_request_body"
+	errorCodeInvalidStreamOptions   = "invalid_stream_options"
+	errorCodeInvalidRoutingStrategy = "invalid_routing_strategy"
+	errorCodeInvalidAPIKey          = "invalid_api_key"
+	errorCodeUnknownURL             = "unknown_url"
+	errorCodeMethodNotAllowed       = "method_not_allowed"
+	errorCodeNotFound               = "not_found"
+	errorCodeModelNotFound          = "model_not_found"
+	errorCodeModelNotAllowed        = "model_not_allowed"
+	errorCodeRateLimitExceeded      = "rate_limit_exceeded"
+	errorCodeInsufficientQuota      = "insufficient_quota"
+	errorCodeQueueFull              = "queue_full"
+	errorCodeQueueTimeout           = "queue_timeout"
+	errorCodeNoHealthyUpstream      = "no_healthy_upstream"
+	errorCodeInternalError          = "internal_error"
+)
+
+// errorHeaderPrefix is the prefix of the headers naming the cause of a rejection.
+const errorHeaderPrefix = "x-error-"
+
+// openAIError returns the OpenAI error type and the stable error code of a rejection, given its x-error-* header.
+// The status code takes precedence, e.g. a failure of the rate limiter is an internal error, not a rate limit.
+func openAIError(statusCode envoyTypePb.StatusCode, errorHeader string) (string, string) {
+	switch {
+	case statusCode >= 500:
+		switch errorHeader {
+		case HeaderErrorNoModelBackends, HeaderErrorRouting:
+			return errorTypeServer, errorCodeNoHealthyUpstream
+		case HeaderErrorQueueTimeout:
+			return errorTypeServer, errorCodeQueueTimeout
+		}
+		return errorTypeServer, errorCodeInternalError
+	case statusCode == envoyTypePb.StatusCode_TooManyRequests:
+		switch errorHeader {
+		case HeaderErrorTPMExceeded:
+			return errorTypeTokens, errorCodeRateLimitExceeded
+		case HeaderErrorQuotaExceeded:
+			return errorTypeInsufficientQuota, errorCodeInsufficientQuota
+		case HeaderErrorQueueFull:
+			return errorTypeRequests, errorCodeQueueFull
+		}
+		return errorTypeRequests, errorCodeRateLimitExceeded
+	}
+
+	switch errorHeader {
+	case HeaderErrorInvalidRouting:
+		return errorTypeInvalidRequest, errorCodeInvalidRoutingStrategy
+	case HeaderErrorAuthentication:
+		return errorTypeInvalidRequest, errorCodeInvalidAPIKey
+	case HeaderErrorModelNotAllowed:
+		return errorTypeInvalidRequest, errorCodeModelNotAllowed
+	case HeaderErrorModelNotFound, HeaderErrorNoModelBackends:
+		return errorTypeInvalidRequest, errorCodeModelNotFound
+	case HeaderErrorStream, HeaderErrorStreamOptionsIncludeUsage:
+		return errorTypeInvalidRequest, errorCodeInvalidStreamOptions
+	case HeaderErrorRequestBodyProcessing, HeaderErrorNoModelInRequest:
+		return errorTypeInvalidRequest, errorCodeInvalidRequestBody
+	}
+	switch statusCode {
+	case envoyTypePb.StatusCode_Unauthorized:
+		return errorTypeInvalidRequest, errorCodeInvalidAPIKey
+	case envoyTypePb.StatusCode_NotFound:
+		return errorTypeInvalidRequest, errorCodeNotFound
+	case envoyTypePb.StatusCode_MethodNotAllowed:
+		return errorTypeInvalidRequest, errorCodeMethodNotAllowed
+	}
+	return errorTypeInvalidRequest, errorCodeInvalidRequest
+}
+
+// errorHeader returns the first x-error-* header of a response, which names the cause of a rejection.
+func errorHeader(headers []*configPb.HeaderValueOption) string {
+	for _, header := range headers {
+		if strings.HasPrefix(header.GetHeader().GetKey(), errorHeaderPrefix) {
+			return header.GetHeader().GetKey()
+		}
+	}
+	return ""
+}
+
+// openAIErrorBody returns an OpenAI error object.
+func openAIErrorBody(message, errType, code string) string {
+	body, _ := json.Marshal(map[string]any{
+		"error": map[string]any{
+			"message": message,
+			"type":    errType,
+			"param":   nil,
+			"code":    code,
+		},
+	})
+	return string(body)
+}
+
+// openAIErrorResponse rejects a request with an OpenAI error of the given type and code.
+func openAIErrorResponse(statusCode envoyTypePb.StatusCode, message, errType, code string, headers ...string) *extProcPb.ProcessingResponse {
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extProcPb.ImmediateResponse{
+				Status: &envoyTypePb.HttpStatus{
+					Code: statusCode,
+				},
+				Headers: &extProcPb.HeaderMutation{
+					SetHeaders: buildEnvoyProxyHeaders([]*configPb.HeaderValueOption{},
+						append([]string{"Content-Type", "application/json"}, headers...)...),
+				},
+				Body: openAIErrorBody(message, errType, code),
+			},
+		},
+	}
+}
+
+// withRetryAfter sets the Retry-After header of a rejection, in whole seconds and at least one second.
+func withRetryAfter(errRes *extProcPb.ProcessingResponse, after time.Duration) *extProcPb.ProcessingResponse {
+	immediateResponse := errRes.GetImmediateResponse()
+	if immediateResponse == nil {
+		return errRes
+	}
+	if immediateResponse.Headers == nil {
+		immediateResponse.Headers = &extProcPb.HeaderMutation{}
+	}
+	immediateResponse.Headers.SetHeaders = buildEnvoyProxyHeaders(immediateResponse.Headers.SetHeaders,
+		HeaderRetryAfter, retryAfterSeconds(after))
+	return errRes
+}
+
+func retryAfterSeconds(after time.Duration) string {
+	seconds := int64(math.Ceil(after.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
+
+// formatReset formats the time until a rate limit resets like OpenAI, e.g. "1s", "6m0s" or "20ms".
+func formatReset(reset time.Duration) string {
+	if reset <= 0 {
+		return "0s"
+	}
+	return reset.Round(time.Millisecond).String()
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+type testOpenAIError struct {
+	Error struct {
+		Message string  `json:"message"`
+		Type    string  `json:"type"`
+		Param   *string `json:"param"`
+		Code    string  `json:"code"`
+	} `json:"error"`
+}
+
+func immediateResponseHeaders(resp *extProcPb.ProcessingResponse) map[string]string {
+	headers := map[string]string{}
+	for _, header := range resp.GetImmediateResponse().GetHeaders().GetSetHeaders() {
+		value := header.GetHeader().GetValue()
+		if value == "" {
+			value = string(header.GetHeader().GetRawValue())
+		}
+		headers[header.GetHeader().GetKey()] = value
+	}
+	return headers
+}
+
+func Test_openAIError(t *testing.T) {
+	testCases := []struct {
+		statusCode envoyTypePb.StatusCode
+		header     string
+		errType    string
+		code       string
+	}{
+		{envoyTypePb.StatusCode_TooManyRequests, HeaderErrorRPMExceeded, errorTypeRequests, errorCodeRateLimitExceeded},
+		{envoyTypePb.StatusCode_TooManyRequests, HeaderErrorTPMExceeded, errorTypeTokens, errorCodeRateLimitExceeded},
+		{envoyTypePb.StatusCode_TooManyRequests, HeaderErrorQuotaExceeded, errorTypeInsufficientQuota, errorCodeInsufficientQuota},
+		{envoyTypePb.StatusCode_TooManyRequests, HeaderErrorConcurrencyExceeded, errorTypeRequests, errorCodeRateLimitExceeded},
+		{envoyTypePb.StatusCode_TooManyRequests, HeaderErrorQueueFull, errorTypeRequests, errorCodeQueueFull},
+		// A failure of the rate limiter is not a rate limit.
+		{envoyTypePb.StatusCode_InternalServerError, HeaderErrorTPMExceeded, errorTypeServer, errorCodeInternalError},
+		{envoyTypePb.StatusCode_BadRequest, HeaderErrorNoModelBackends, errorTypeInvalidRequest, errorCodeModelNotFound},
+		{envoyTypePb.StatusCode_ServiceUnavailable, HeaderErrorNoModelBackends, errorTypeServer, errorCodeNoHealthyUpstream},
+		{envoyTypePb.StatusCode_ServiceUnavailable, HeaderErrorRouting, errorTypeServer, errorCodeNoHealthyUpstream},
+		{envoyTypePb.StatusCode_ServiceUnavailable, HeaderErrorQueueTimeout, errorTypeServer, errorCodeQueueTimeout},
+		{envoyTypePb.StatusCode_NotFound, HeaderErrorModelNotFound, errorTypeInvalidRequest, errorCodeModelNotFound},
+		{envoyTypePb.StatusCode_Forbidden, HeaderErrorModelNotAllowed, errorTypeInvalidRequest, errorCodeModelNotAllowed},
+		{envoyTypePb.StatusCode_Unauthorized, HeaderErrorAuthentication, errorTypeInvalidRequest, errorCodeInvalidAPIKey},
+		{envoyTypePb.StatusCode_BadRequest, HeaderErrorInvalidRouting, errorTypeInvalidRequest, errorCodeInvalidRoutingStrategy},
+		{envoyTypePb.StatusCode_BadRequest, HeaderErrorStream, errorTypeInvalidRequest, errorCodeInvalidStreamOptions},
+		{envoyTypePb.StatusCode_BadRequest, HeaderErrorRequestBodyProcessing, errorTypeInvalidRequest, errorCodeInvalidRequestBody},
+		{envoyTypePb.StatusCode_MethodNotAllowed, "", errorTypeInvalidRequest, errorCodeMethodNotAllowed},
+		{envoyTypePb.StatusCode_BadGateway, "", errorTypeServer, errorCodeInternalError},
+	}
+	for _, tc := range testCases {
+		errType, code := openAIError(tc.statusCode, tc.header)
+		assert.Equal(t, tc.errType, errType, "%d %s", tc.statusCode, tc.header)
+		assert.Equal(t, tc.code, code, "%d %s", tc.statusCode, tc.header)
+	}
+}
+
+func Test_errorResponses(t *testing.T) {
+	resp := generateErrorResponse(envoyTypePb.StatusCode_TooManyRequests,
+		[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{Key: HeaderErrorRPMExceeded, RawValue: []byte("true")}}},
+		"user: u1 has exceeded RPM: 100")
+	var body testOpenAIError
+	assert.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().GetBody()), &body))
+	assert.Equal(t, "user: u1 has exceeded RPM: 100", body.Error.Message)
+	assert.Equal(t, errorTypeRequests, body.Error.Type)
+	assert.Nil(t, body.Error.Param)
+	assert.Equal(t, errorCodeRateLimitExceeded, body.Error.Code)
+	headers := immediateResponseHeaders(resp)
+	assert.Equal(t, "true", headers[HeaderErrorRPMExceeded])
+	assert.Equal(t, "application/json", headers["Content-Type"])
+
+	resp = withRetryAfter(resp, 1500*time.Millisecond)
+	assert.Equal(t, "2", immediateResponseHeaders(resp)[HeaderRetryAfter])
+
+	resp = buildErrorResponse(envoyTypePb.StatusCode_Unauthorized, "invalid api key", HeaderErrorAuthentication, "true")
+	assert.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().GetBody()), &body))
+	assert.Equal(t, "invalid api key", body.Error.Message)
+	assert.Equal(t, errorCodeInvalidAPIKey, body.Error.Code)
+	headers = immediateResponseHeaders(resp)
+	assert.Equal(t, "true", headers[HeaderErrorAuthentication])
+	assert.Equal(t, "application/json", headers["Content-Type"])
+
+	// Failures of the limiters are not to be retried after a reset.
+	resp = withRetryAfter429(generateErrorResponse(envoyTypePb.StatusCode_InternalServerError, nil, "fail to get RPM"), time.Minute)
+	assert.NotContains(t, immediateResponseHeaders(resp), HeaderRetryAfter)
+}
+
+func Test_rateLimitResets(t *testing.T) {
+	now := time.Date(2025, 1, 31, 10, 20, 45, 500*int(time.Millisecond), time.UTC)
+	assert.Equal(t, 14500*time.Millisecond, fixedWindowReset(now))
+	assert.Equal(t, 13*time.Hour+39*time.Minute+14500*time.Millisecond, quotaReset(now, false))
+	assert.Equal(t, 13*time.Hour+39*time.Minute+14500*time.Millisecond, quotaReset(now, true))
+	assert.Equal(t, 24*time.Hour, quotaReset(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), false))
+	assert.Equal(t, 28*24*time.Hour, quotaReset(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), true))
+
+	assert.Equal(t, 6*time.Second, tokenBucketRefill(10, 100))
+	assert.Equal(t, time.Duration(0), tokenBucketRefill(-5, 100))
+
+	assert.Equal(t, "14.5s", formatReset(14500*time.Millisecond))
+	assert.Equal(t, "6m0s", formatReset(6*time.Minute))
+	assert.Equal(t, "0s", formatReset(-time.Second))
+	assert.Equal(t, "1", retryAfterSeconds(0))
+}
+
+func Test_rateLimitHeaders(t *testing.T) {
+	now := time.Date(2025, 1, 31, 10, 20, 30, 0, time.UTC)
+	headers := rateLimitHeaders(utils.User{Name: "u1", Rpm: 100, Tpm: 1000}, 40, 1200, now)
+	assert.Equal(t, []string{
+		HeaderRateLimitLimitRequests, "100",
+		HeaderRateLimitRemainingRequests, "60",
+		HeaderRateLimitResetRequests, "30s",
+		HeaderRateLimitLimitTokens, "1000",
+		HeaderRateLimitRemainingTokens, "0",
+		HeaderRateLimitResetTokens, "30s",
+	}, headers)
+
+	headers = rateLimitHeaders(utils.User{Name: "u1", Rpm: 60, Tpm: 600, Burst: 10, RateLimiter: utils.TokenBucketRateLimiter}, 4, 300, now)
+	assert.Equal(t, []string{
+		HeaderRateLimitLimitRequests, "10",
+		HeaderRateLimitRemainingRequests, "6",
+		HeaderRateLimitResetRequests, "4s",
+		HeaderRateLimitLimitTokens, "600",
+		HeaderRateLimitRemainingTokens, "300",
+		HeaderRateLimitResetTokens, "30s",
+	}, headers)
+}
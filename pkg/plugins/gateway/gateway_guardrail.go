@@ -0,0 +1,472 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/guardrail"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	guardrailErrorCodeBlocked     = "guardrail_blocked"
+	guardrailErrorCodeUnavailable = "guardrail_unavailable"
+)
+
+var (
+	// guardrailConfigPath is the file declaring the guardrail filters and policies, see guardrail.Config.
+	guardrailConfigPath = utils.LoadEnv("AIBRIX_GUARDRAIL_CONFIG", "")
+	// guardrailStreamWindowBytes is the tail of the text of each choice of a streaming response held back from the
+	// client, so that matches split across events are found before they are sent.
+	guardrailStreamWindowBytes = utils.LoadEnvInt("AIBRIX_GUARDRAIL_STREAM_WINDOW_BYTES", 256)
+
+	// guardrailStreams tracks the guardrailStream of streaming responses checked by guardrails by request ID.
+	guardrailStreams sync.Map
+)
+
+// guardrailStream holds back the incomplete event at the end of a streaming response chunk, so that guardrails
+// check complete events, and the tail of the text of each choice, so that guardrails check the text across events.
+type guardrailStream struct {
+	pending []byte
+	// held is the text of each choice not sent to the client yet, by choice index.
+	held map[string]*heldChoice
+	// findings are the findings reported so far, the held text is checked again with the next events.
+	findings map[guardrail.Finding]struct{}
+	// blocked is set once the stream is cut by a guardrail, the rest of the response is dropped.
+	blocked bool
+}
+
+// heldChoice is the text of a choice of a streaming response held back from the client.
+type heldChoice struct {
+	index any
+	delta bool // Whether the text is the delta content of a chat completion chunk, or the text of a completion chunk.
+	text  string
+	// chunk is the last chunk of the choice without choices and usage, to release the text held at the end of the
+	// stream in a chunk of its own.
+	chunk map[string]any
+}
+
+// newGuardrail loads the guardrail config, nil if guardrails are not configured. An invalid config is fatal,
+// since requests would pass unchecked.
+func newGuardrail() *guardrail.Guardrail {
+	if guardrailConfigPath == "" {
+		return nil
+	}
+	config, err := guardrail.LoadConfig(guardrailConfigPath)
+	if err != nil {
+		panic(err)
+	}
+	g, err := guardrail.New(config)
+	if err != nil {
+		panic(fmt.Errorf("invalid guardrail config %s: %w", guardrailConfigPath, err))
+	}
+	klog.InfoS("guardrails enabled", "path", guardrailConfigPath, "filters", len(config.Filters), "policies", len(config.Policies))
+	return g
+}
+
+// textField is a text of a JSON request or response body.
+type textField struct {
+	text string
+	set  func(text string)
+}
+
+// appendTextFields appends the texts of a field holding a string, an array of strings or an array of content parts.
+func appendTextFields(fields []textField, parent map[string]any, key string) []textField {
+	switch value := parent[key].(type) {
+	case string:
+		fields = append(fields, textField{text: value, set: func(text string) { parent[key] = text }})
+	case []any:
+		for i, item := range value {
+			switch item := item.(type) {
+			case string:
+				fields = append(fields, textField{text: item, set: func(text string) { value[i] = text }})
+			case map[string]any:
+				fields = appendTextFields(fields, item, "text")
+			}
+		}
+	}
+	return fields
+}
+
+// requestTextFields returns the prompt texts of chat completion, completion and embedding requests.
+func requestTextFields(doc map[string]any) []textField {
+	var fields []textField
+	if messages, ok := doc["messages"].([]any); ok {
+		for _, message := range messages {
+			if message, ok := message.(map[string]any); ok {
+				fields = appendTextFields(fields, message, "content")
+			}
+		}
+	}
+	fields = appendTextFields(fields, doc, "prompt")
+	return appendTextFields(fields, doc, "input")
+}
+
+// responseTextFields returns the generated texts of chat completion and completion responses and streaming chunks.
+func responseTextFields(doc map[string]any) []textField {
+	var fields []textField
+	choices, _ := doc["choices"].([]any)
+	for _, choice := range choices {
+		choice, ok := choice.(map[string]any)
+		if !ok {
+			continue
+		}
+		fields = appendTextFields(fields, choice, "text")
+		for _, key := range []string{"message", "delta"} {
+			if content, ok := choice[key].(map[string]any); ok {
+				fields = appendTextFields(fields, content, "content")
+			}
+		}
+	}
+	return fields
+}
+
+func decodeJSONObject(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// guardBody checks the texts of a JSON request or response body, the body is rewritten only if texts are redacted.
+func (s *Server) guardBody(ctx context.Context, stage guardrail.Stage, model, user string, body []byte) ([]byte, *guardrail.Result, error) {
+	doc, err := decodeJSONObject(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields := requestTextFields
+	if stage == guardrail.StageResponse {
+		fields = responseTextFields
+	}
+	textFields := fields(doc)
+	texts := make([]string, len(textFields))
+	for i, field := range textFields {
+		texts[i] = field.text
+	}
+	result, err := s.guardrail.Check(ctx, stage, model, user, texts)
+	if err != nil || !result.Redacted {
+		return body, result, err
+	}
+	for i, field := range textFields {
+		field.set(result.Texts[i])
+	}
+	body, err = json.Marshal(doc)
+	return body, result, err
+}
+
+// guardRequestBody applies the request guardrails to a request body. It returns the body to send upstream, the
+// findings, or an error response if the request is blocked.
+func (s *Server) guardRequestBody(ctx context.Context, requestID, model string, user utils.User, body []byte) ([]byte, []guardrail.Finding, *extProcPb.ProcessingResponse) {
+	guarded, result, err := s.guardBody(ctx, guardrail.StageRequest, model, user.Name, body)
+	if err != nil {
+		klog.ErrorS(err, "failed to apply request guardrails", "requestID", requestID, "model", model)
+		return nil, nil, guardrailErrorResponse(envoyTypePb.StatusCode_ServiceUnavailable, "", "guardrails are unavailable",
+			errorTypeServer, guardrailErrorCodeUnavailable)
+	}
+	if result.Blocked != nil {
+		klog.InfoS("request blocked by guardrail", "requestID", requestID, "model", model, "username", user.Name, "finding", result.Blocked)
+		return nil, nil, guardrailErrorResponse(envoyTypePb.StatusCode_BadRequest, result.Blocked.Filter,
+			fmt.Sprintf("request blocked by guardrail %s", result.Blocked), errorTypeInvalidRequest, guardrailErrorCodeBlocked)
+	}
+	logFindings(requestID, guardrail.StageRequest, result.Findings)
+	return guarded, result.Findings, nil
+}
+
+// guardResponseBody applies the response guardrails to a complete response body. It returns the body to send to
+// the client, the findings, or an error response if a non-streaming response is blocked. A blocked stream is cut
+// with an error event instead, since its status is sent already.
+func (s *Server) guardResponseBody(ctx context.Context, requestID, model string, user utils.User, stream bool, body []byte) ([]byte, []guardrail.Finding, *extProcPb.ProcessingResponse) {
+	if stream {
+		guarded, findings := s.guardStreamChunk(ctx, requestID, model, user.Name, body, true)
+		return guarded, findings, nil
+	}
+	guarded, result, err := s.guardBody(ctx, guardrail.StageResponse, model, user.Name, body)
+	if err != nil {
+		klog.ErrorS(err, "failed to apply response guardrails", "requestID", requestID, "model", model)
+		return nil, nil, guardrailErrorResponse(envoyTypePb.StatusCode_ServiceUnavailable, "", "guardrails are unavailable",
+			errorTypeServer, guardrailErrorCodeUnavailable)
+	}
+	if result.Blocked != nil {
+		klog.InfoS("response blocked by guardrail", "requestID", requestID, "model", model, "username", user.Name, "finding", result.Blocked)
+		return nil, nil, guardrailErrorResponse(envoyTypePb.StatusCode_BadRequest, result.Blocked.Filter,
+			fmt.Sprintf("response blocked by guardrail %s", result.Blocked), errorTypeInvalidRequest, guardrailErrorCodeBlocked)
+	}
+	logFindings(requestID, guardrail.StageResponse, result.Findings)
+	return guarded, result.Findings, nil
+}
+
+// sseDataLine is a JSON data line of a server-sent event.
+type sseDataLine struct {
+	event, line int
+	doc         map[string]any
+	modified    bool
+}
+
+// choiceTextField returns the text of a streaming chunk choice, the delta content of chat completions or the text
+// of completions, false if the choice has neither.
+func choiceTextField(choice map[string]any) (textField, bool, bool) {
+	if delta, ok := choice["delta"].(map[string]any); ok {
+		text, _ := delta["content"].(string)
+		return textField{text: text, set: func(text string) { delta["content"] = text }}, true, true
+	}
+	if text, ok := choice["text"].(string); ok {
+		return textField{text: text, set: func(text string) { choice["text"] = text }}, false, true
+	}
+	return textField{}, false, false
+}
+
+// guardStreamChunk applies the response guardrails to the complete events of a streaming response chunk, an event
+// split across chunks is checked once the rest arrives. The text of each choice is accumulated and checked as a
+// whole, its last guardrailStreamWindowBytes are held back until the next events of the choice, or until the choice
+// finishes, so that a match split across events is blocked or redacted before it is sent. The texts released are
+// sent with the last event of their choice in the chunk. A blocked stream ends with an error event.
+func (s *Server) guardStreamChunk(ctx context.Context, requestID, model, user string, chunk []byte, endOfStream bool) ([]byte, []guardrail.Finding) {
+	value, _ := guardrailStreams.LoadOrStore(requestID, &guardrailStream{})
+	state := value.(*guardrailStream)
+	if endOfStream {
+		guardrailStreams.Delete(requestID)
+	}
+	if state.blocked {
+		return []byte{}, nil
+	}
+	if state.held == nil {
+		state.held, state.findings = map[string]*heldChoice{}, map[guardrail.Finding]struct{}{}
+	}
+
+	data := chunk
+	if len(state.pending) > 0 {
+		data = append(state.pending, chunk...)
+		state.pending = nil
+	}
+	var events [][]string
+	for {
+		event, rest, found := bytes.Cut(data, sseEventDelimiter)
+		if !found {
+			break
+		}
+		events = append(events, strings.Split(string(event), "\n"))
+		data = rest
+	}
+	// The tail of the stream is an event without delimiter.
+	tail := len(data) > 0 && endOfStream
+	if tail {
+		events = append(events, strings.Split(string(data), "\n"))
+	} else if len(data) > 0 {
+		state.pending = bytes.Clone(data)
+	}
+
+	// The text of the choices is moved to the held text, the last event of a choice carries the text released.
+	var lines []*sseDataLine
+	lastFields := map[string]*textField{}
+	lastLines := map[string]*sseDataLine{}
+	finished := map[string]bool{}
+	done := -1 // The event of data: [DONE].
+	for i, event := range events {
+		for j, line := range event {
+			payload, ok := bytes.CutPrefix(bytes.TrimSpace([]byte(line)), sseDataPrefix)
+			if !ok {
+				continue
+			}
+			payload = bytes.TrimSpace(payload)
+			if bytes.Equal(payload, sseDone) && done < 0 {
+				done = i
+				continue
+			}
+			doc, err := decodeJSONObject(payload)
+			if err != nil {
+				continue
+			}
+			dataLine := &sseDataLine{event: i, line: j, doc: doc}
+			choices, _ := doc["choices"].([]any)
+			for k, choice := range choices {
+				choice, ok := choice.(map[string]any)
+				if !ok {
+					continue
+				}
+				field, delta, ok := choiceTextField(choice)
+				if !ok {
+					continue
+				}
+				index, ok := choice["index"]
+				if !ok {
+					index = k
+				}
+				key := fmt.Sprint(index)
+				held, ok := state.held[key]
+				if !ok {
+					held = &heldChoice{index: index, delta: delta}
+					state.held[key] = held
+				}
+				held.text += field.text
+				held.chunk = map[string]any{}
+				for name, value := range doc {
+					if name != "choices" && name != "usage" {
+						held.chunk[name] = value
+					}
+				}
+				if field.text != "" {
+					field.set("")
+					dataLine.modified = true
+				}
+				lastFields[key], lastLines[key] = &field, dataLine
+				if reason, _ := choice["finish_reason"].(string); reason != "" {
+					finished[key] = true
+				}
+			}
+			lines = append(lines, dataLine)
+		}
+	}
+
+	keys := make([]string, 0, len(lastFields))
+	for key := range state.held {
+		if _, ok := lastFields[key]; ok || endOfStream {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	texts := make([]string, len(keys))
+	for i, key := range keys {
+		texts[i] = state.held[key].text
+	}
+	result, err := s.guardrail.Check(ctx, guardrail.StageResponse, model, user, texts)
+	if err != nil || result.Blocked != nil {
+		state.blocked = true
+		message := "guardrails are unavailable"
+		errType, code := errorTypeServer, guardrailErrorCodeUnavailable
+		if err != nil {
+			klog.ErrorS(err, "failed to apply response guardrails", "requestID", requestID, "model", model)
+		} else {
+			klog.InfoS("response blocked by guardrail", "requestID", requestID, "model", model, "username", user, "finding", result.Blocked)
+			message = fmt.Sprintf("response blocked by guardrail %s", result.Blocked)
+			errType, code = errorTypeInvalidRequest, guardrailErrorCodeBlocked
+		}
+		return []byte(fmt.Sprintf("data: %s\n\ndata: [DONE]\n\n", openAIErrorBody(message, errType, code))), nil
+	}
+	var findings []guardrail.Finding
+	for _, finding := range result.Findings {
+		if _, ok := state.findings[finding]; !ok {
+			state.findings[finding] = struct{}{}
+			findings = append(findings, finding)
+		}
+	}
+	logFindings(requestID, guardrail.StageResponse, findings)
+
+	// Events carrying the text released at the end of the stream, for choices without an event in the chunk.
+	var released []string
+	for i, key := range keys {
+		held := state.held[key]
+		held.text = result.Texts[i]
+		cut := len(held.text)
+		if !finished[key] && !endOfStream {
+			cut = max(cut-guardrailStreamWindowBytes, 0)
+			for cut > 0 && !utf8.RuneStart(held.text[cut]) {
+				cut--
+			}
+		}
+		if cut == 0 {
+			continue
+		}
+		text := held.text[:cut]
+		held.text = held.text[cut:]
+		if field, ok := lastFields[key]; ok {
+			field.set(text)
+			lastLines[key].modified = true
+			continue
+		}
+		choice := map[string]any{"index": held.index, "text": text}
+		if held.delta {
+			choice = map[string]any{"index": held.index, "delta": map[string]any{"content": text}}
+		}
+		held.chunk["choices"] = []any{choice}
+		if payload, err := json.Marshal(held.chunk); err == nil {
+			released = append(released, string(sseDataPrefix)+" "+string(payload))
+		}
+	}
+
+	for _, dataLine := range lines {
+		if !dataLine.modified {
+			continue
+		}
+		payload, err := json.Marshal(dataLine.doc)
+		if err != nil {
+			continue
+		}
+		events[dataLine.event][dataLine.line] = string(sseDataPrefix) + " " + string(payload)
+	}
+
+	var out bytes.Buffer
+	for i, event := range events {
+		if i == done {
+			for _, line := range released {
+				out.WriteString(line)
+				out.Write(sseEventDelimiter)
+			}
+			released = nil
+		}
+		out.WriteString(strings.Join(event, "\n"))
+		if !tail || i < len(events)-1 {
+			out.Write(sseEventDelimiter)
+		}
+	}
+	if len(released) > 0 && tail {
+		out.Write(sseEventDelimiter)
+	}
+	for _, line := range released {
+		out.WriteString(line)
+		out.Write(sseEventDelimiter)
+	}
+	return out.Bytes(), findings
+}
+
+func logFindings(requestID string, stage guardrail.Stage, findings []guardrail.Finding) {
+	if len(findings) > 0 {
+		klog.InfoS("guardrail findings", "requestID", requestID, "stage", stage, "findings", findingsHeader(findings))
+	}
+}
+
+// findingsHeader formats the findings as the value of the guardrail header, e.g. "pii/email,injection/jailbreak".
+func findingsHeader(findings []guardrail.Finding) string {
+	values := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		values = append(values, finding.String())
+	}
+	return strings.Join(values, ",")
+}
+
+// guardrailErrorResponse rejects a request or a response with an OpenAI error, the guardrail header names the
+// blocking filter.
+func guardrailErrorResponse(statusCode envoyTypePb.StatusCode, filter, message, errType, code string) *extProcPb.ProcessingResponse {
+	if filter == "" {
+		filter = "true"
+	}
+	return openAIErrorResponse(statusCode, message, errType, code, HeaderErrorGuardrail, filter)
+}
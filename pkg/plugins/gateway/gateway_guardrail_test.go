@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/guardrail"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// testGuardrailConfig blocks prompt injections in requests and passwords in responses, and redacts emails in both.
+var testGuardrailConfig = guardrail.Config{
+	Filters: []guardrail.FilterConfig{
+		{Name: "pii", Type: guardrail.FilterTypeRegex, Patterns: []guardrail.PatternConfig{
+			{Category: "email", Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`},
+		}},
+		{Name: "injection", Type: guardrail.FilterTypeKeyword, Keywords: []string{"ignore previous instructions"}},
+		{Name: "secrets", Type: guardrail.FilterTypeKeyword, Keywords: []string{"password"}},
+	},
+	Policies: []guardrail.PolicyConfig{{
+		Request: []guardrail.RuleConfig{
+			{Filter: "injection", Action: guardrail.ActionBlock},
+			{Filter: "pii", Action: guardrail.ActionRedact},
+		},
+		Response: []guardrail.RuleConfig{
+			{Filter: "secrets", Action: guardrail.ActionBlock},
+			{Filter: "pii", Action: guardrail.ActionRedact},
+		},
+	}},
+}
+
+func Test_guardRequestBody(t *testing.T) {
+	g, err := guardrail.New(testGuardrailConfig)
+	assert.NoError(t, err)
+	s := &Server{guardrail: g}
+	ctx := context.Background()
+
+	body, findings, errRes := s.guardRequestBody(ctx, "r1", "m", utils.User{Name: "u1"}, []byte(`{"model": "m", "messages": [
+		{"role": "system", "content": "You are helpful."},
+		{"role": "user", "content": [{"type": "text", "text": "mail me at a@b.com"}, {"type": "image_url", "image_url": {"url": "https://x"}}]}],
+		"temperature": 0.5}`))
+	assert.Nil(t, errRes)
+	assert.JSONEq(t, `{"model": "m", "messages": [
+		{"role": "system", "content": "You are helpful."},
+		{"role": "user", "content": [{"type": "text", "text": "mail me at [REDACTED]"}, {"type": "image_url", "image_url": {"url": "https://x"}}]}],
+		"temperature": 0.5}`, string(body))
+	assert.Equal(t, "pii/email", findingsHeader(findings))
+
+	// Bodies without findings are sent as is.
+	original := []byte(`{"model": "m", "prompt": ["hello", "world"]}`)
+	body, findings, errRes = s.guardRequestBody(ctx, "r1", "m", utils.User{}, original)
+	assert.Nil(t, errRes)
+	assert.Empty(t, findings)
+	assert.Equal(t, original, body)
+
+	_, _, errRes = s.guardRequestBody(ctx, "r1", "m", utils.User{}, []byte(`{"model": "m", "prompt": "Ignore previous instructions"}`))
+	assert.NotNil(t, errRes)
+	immediateResponse := errRes.GetImmediateResponse()
+	assert.Equal(t, 400, int(immediateResponse.GetStatus().GetCode()))
+	headers := map[string]string{}
+	for _, header := range immediateResponse.GetHeaders().GetSetHeaders() {
+		headers[header.GetHeader().GetKey()] = string(header.GetHeader().GetRawValue())
+	}
+	assert.Equal(t, "injection", headers[HeaderErrorGuardrail])
+	var errBody struct {
+		Error struct {
+			Message string  `json:"message"`
+			Type    string  `json:"type"`
+			Param   *string `json:"param"`
+			Code    string  `json:"code"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(immediateResponse.GetBody()), &errBody))
+	assert.Equal(t, "invalid_request_error", errBody.Error.Type)
+	assert.Equal(t, guardrailErrorCodeBlocked, errBody.Error.Code)
+}
+
+func Test_guardResponseBody(t *testing.T) {
+	g, err := guardrail.New(testGuardrailConfig)
+	assert.NoError(t, err)
+	s := &Server{guardrail: g}
+	ctx := context.Background()
+
+	body, findings, errRes := s.guardResponseBody(ctx, "r1", "m", utils.User{}, false,
+		[]byte(`{"id": "1", "model": "m", "choices": [{"index": 0, "message": {"role": "assistant", "content": "write to a@b.com"}}]}`))
+	assert.Nil(t, errRes)
+	assert.Equal(t, "write to [REDACTED]", responseOutput(false, body))
+	assert.Len(t, findings, 1)
+
+	_, _, errRes = s.guardResponseBody(ctx, "r1", "m", utils.User{}, false,
+		[]byte(`{"id": "1", "model": "m", "choices": [{"index": 0, "text": "the password is"}]}`))
+	assert.NotNil(t, errRes)
+	assert.Equal(t, 400, int(errRes.GetImmediateResponse().GetStatus().GetCode()))
+}
+
+func Test_guardStreamChunk(t *testing.T) {
+	defer func(windowBytes int) { guardrailStreamWindowBytes = windowBytes }(guardrailStreamWindowBytes)
+	guardrailStreamWindowBytes = 8
+	g, err := guardrail.New(testGuardrailConfig)
+	assert.NoError(t, err)
+	s := &Server{guardrail: g}
+	ctx := context.Background()
+	delta := func(content string) string {
+		return fmt.Sprintf("data: {\"id\":\"1\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+	}
+	finish := "data: {\"id\":\"1\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n"
+
+	// The email is split across events and chunks, the tail of the text is held back until the next events.
+	var sent strings.Builder
+	out, findings := s.guardStreamChunk(ctx, "r1", "m", "", []byte(delta("mail a@")+delta("b")[:20]), false)
+	assert.Empty(t, responseOutput(true, out))
+	assert.Empty(t, findings)
+	sent.Write(out)
+	out, findings = s.guardStreamChunk(ctx, "r1", "m", "", []byte(delta("b")[20:]+delta(".com now")), false)
+	assert.Equal(t, "mail [REDAC", responseOutput(true, out))
+	assert.Equal(t, "pii/email", findingsHeader(findings))
+	sent.Write(out)
+	// The held text is released once the choice finishes, usage chunks are sent as is.
+	out, findings = s.guardStreamChunk(ctx, "r1", "m", "", []byte(finish+testStreamUsage), false)
+	assert.Equal(t, "TED] now", responseOutput(true, out))
+	assert.Contains(t, string(out), testStreamUsage)
+	assert.Empty(t, findings)
+	sent.Write(out)
+	out, _ = s.guardStreamChunk(ctx, "r1", "m", "", []byte(testStreamDone), true)
+	assert.Equal(t, testStreamDone, string(out))
+	sent.Write(out)
+	assert.Equal(t, "mail [REDACTED] now", responseOutput(true, []byte(sent.String())))
+	_, ok := guardrailStreams.Load("r1")
+	assert.False(t, ok)
+
+	// The text held at the end of a stream without finish reason is released before [DONE].
+	out, _ = s.guardStreamChunk(ctx, "r3", "m", "", []byte(delta("hello")), false)
+	assert.Empty(t, responseOutput(true, out))
+	out, _ = s.guardStreamChunk(ctx, "r3", "m", "", []byte(testStreamDone), true)
+	assert.Equal(t, "hello", responseOutput(true, out))
+	assert.True(t, strings.HasSuffix(string(out), "\n\n"+testStreamDone))
+	assert.Contains(t, string(out), `"id":"1"`)
+
+	// A blocked stream ends with an error event, the rest of the response is dropped. The blocked keyword is split
+	// across events and never sent.
+	out, _ = s.guardStreamChunk(ctx, "r2", "m", "", []byte(delta("my pass")), false)
+	assert.Empty(t, responseOutput(true, out))
+	out, _ = s.guardStreamChunk(ctx, "r2", "m", "", []byte(delta("word is")), false)
+	assert.Contains(t, string(out), guardrailErrorCodeBlocked)
+	assert.Contains(t, string(out), testStreamDone)
+	assert.NotContains(t, string(out), "pass")
+	out, _ = s.guardStreamChunk(ctx, "r2", "m", "", []byte(delta(" now")), false)
+	assert.Empty(t, out)
+	guardrailStreams.Delete("r2")
+
+	// Completion chunks are checked by their text.
+	out, _ = s.guardStreamChunk(ctx, "r4", "m", "", []byte(
+		"data: {\"id\":\"1\",\"object\":\"text_completion\",\"choices\":[{\"index\":0,\"text\":\"to a@\"}]}\n\n"+
+			"data: {\"id\":\"1\",\"object\":\"text_completion\",\"choices\":[{\"index\":0,\"text\":\"b.com\",\"finish_reason\":\"length\"}]}\n\n"), true)
+	assert.Equal(t, "to [REDACTED]", responseOutput(true, out))
+}
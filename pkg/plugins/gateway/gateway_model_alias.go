@@ -0,0 +1,273 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
+	crdinformers "github.com/vllm-project/aibrix/pkg/client/informers/externalversions"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// modelAlias is a validated ModelAlias.
+type modelAlias struct {
+	key          string // namespace/name of the ModelAlias
+	name         string
+	created      int64
+	backends     []modelv1alpha1.ModelAliasBackend // Backends with a positive weight.
+	totalWeight  int64
+	stickiness   modelv1alpha1.ModelAliasStickiness
+	stickyHeader string
+}
+
+// aliasStore holds the ModelAliases by alias name. ModelAliases of the same name are kept in the order they are added,
+// the last one serves the alias and shadows the others until it is deleted.
+type aliasStore struct {
+	mu      sync.RWMutex
+	aliases map[string][]*modelAlias
+}
+
+var modelAliases = &aliasStore{aliases: map[string][]*modelAlias{}}
+
+// WatchModelAliases watches ModelAliases until stopCh is closed. Invalid aliases are logged and ignored.
+func WatchModelAliases(client versioned.Interface, stopCh <-chan struct{}) error {
+	factory := crdinformers.NewSharedInformerFactoryWithOptions(client, 0)
+	informer := factory.Model().V1alpha1().ModelAliases().Informer()
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    modelAliases.addOrUpdate,
+		UpdateFunc: modelAliases.update,
+		DeleteFunc: modelAliases.delete,
+	}); err != nil {
+		return err
+	}
+	factory.Start(stopCh)
+	if !toolscache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return errors.New("timed out waiting for model aliases to sync")
+	}
+	return nil
+}
+
+func newModelAlias(obj *modelv1alpha1.ModelAlias) (*modelAlias, error) {
+	alias := &modelAlias{
+		key:          fmt.Sprintf("%s/%s", obj.Namespace, obj.Name),
+		name:         obj.AliasName(),
+		created:      obj.CreationTimestamp.Unix(),
+		stickiness:   obj.Spec.Stickiness,
+		stickyHeader: strings.ToLower(obj.Spec.StickyHeader),
+	}
+	for _, backend := range obj.Spec.Backends {
+		if backend.Model == alias.name {
+			return nil, fmt.Errorf("backend %s is the alias itself", backend.Model)
+		}
+		if backend.Weight > 0 {
+			alias.backends = append(alias.backends, backend)
+			alias.totalWeight += int64(backend.Weight)
+		}
+	}
+	if alias.totalWeight == 0 {
+		return nil, fmt.Errorf("no backend with a positive weight")
+	}
+	if alias.stickiness == modelv1alpha1.ModelAliasStickinessHeader && alias.stickyHeader == "" {
+		return nil, fmt.Errorf("stickyHeader is required by stickiness %s", alias.stickiness)
+	}
+	return alias, nil
+}
+
+func (s *aliasStore) addOrUpdate(obj interface{}) {
+	s.update(nil, obj)
+}
+
+// update replaces the ModelAlias under a single lock. An invalid ModelAlias is ignored, the previous spec keeps
+// serving the alias.
+func (s *aliasStore) update(oldObj, newObj interface{}) {
+	ma, ok := newObj.(*modelv1alpha1.ModelAlias)
+	if !ok {
+		return
+	}
+	alias, err := newModelAlias(ma)
+	if err != nil {
+		klog.ErrorS(err, "ignored invalid model alias", "modelAlias", fmt.Sprintf("%s/%s", ma.Namespace, ma.Name))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The alias may be renamed.
+	if old, ok := oldObj.(*modelv1alpha1.ModelAlias); ok && old.AliasName() != alias.name {
+		s.removeLocked(old.AliasName(), alias.key)
+	}
+	aliases := s.aliases[alias.name]
+	for i, existing := range aliases {
+		if existing.key == alias.key {
+			aliases[i] = alias
+			klog.InfoS("model alias updated", "modelAlias", alias.key, "alias", alias.name, "backends", len(alias.backends), "shadowed", i < len(aliases)-1)
+			return
+		}
+	}
+	if len(aliases) > 0 {
+		klog.InfoS("model alias overrides another alias of the same name", "modelAlias", alias.key, "overridden", aliases[len(aliases)-1].key, "alias", alias.name)
+	}
+	s.aliases[alias.name] = append(aliases, alias)
+	klog.InfoS("model alias updated", "modelAlias", alias.key, "alias", alias.name, "backends", len(alias.backends))
+}
+
+func (s *aliasStore) delete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	ma, ok := obj.(*modelv1alpha1.ModelAlias)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(ma.AliasName(), fmt.Sprintf("%s/%s", ma.Namespace, ma.Name))
+}
+
+// removeLocked removes a ModelAlias from an alias name, the alias it shadowed is restored.
+func (s *aliasStore) removeLocked(name, key string) {
+	aliases := s.aliases[name]
+	for i, alias := range aliases {
+		if alias.key != key {
+			continue
+		}
+		klog.InfoS("model alias deleted", "modelAlias", key, "alias", name)
+		aliases = append(aliases[:i:i], aliases[i+1:]...)
+		if len(aliases) == 0 {
+			delete(s.aliases, name)
+			return
+		}
+		s.aliases[name] = aliases
+		if i == len(aliases) {
+			klog.InfoS("model alias restored", "modelAlias", aliases[i-1].key, "alias", name)
+		}
+		return
+	}
+}
+
+func (s *aliasStore) get(name string) *modelAlias {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	aliases := s.aliases[name]
+	if len(aliases) == 0 {
+		return nil
+	}
+	return aliases[len(aliases)-1]
+}
+
+// names returns the alias names, sorted.
+func (s *aliasStore) names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.aliases))
+	for name := range s.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveModelAlias returns the backend model of an alias by weight, false if the model is not an alias.
+// Requests with the same sticky key are assigned to the same backend as long as the backends are unchanged.
+func resolveModelAlias(model string, user utils.User, headers []*configPb.HeaderValue) (string, bool) {
+	alias := modelAliases.get(model)
+	if alias == nil {
+		return model, false
+	}
+	return alias.pick(alias.stickyKey(user, headers)), true
+}
+
+func (a *modelAlias) stickyKey(user utils.User, headers []*configPb.HeaderValue) string {
+	switch a.stickiness {
+	case modelv1alpha1.ModelAliasStickinessUser:
+		return user.Name
+	case modelv1alpha1.ModelAliasStickinessHeader:
+		for _, header := range headers {
+			if strings.ToLower(header.Key) == a.stickyHeader {
+				return string(header.RawValue)
+			}
+		}
+	}
+	return ""
+}
+
+// pick selects a backend by weight, by the hash of the key if set, otherwise at random.
+func (a *modelAlias) pick(key string) string {
+	var point int64
+	if key != "" {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(a.name + "/" + key))
+		point = int64(h.Sum64() % uint64(a.totalWeight))
+	} else {
+		point = rand.Int63n(a.totalWeight)
+	}
+	for _, backend := range a.backends {
+		if point < int64(backend.Weight) {
+			return backend.Model
+		}
+		point -= int64(backend.Weight)
+	}
+	return a.backends[len(a.backends)-1].Model
+}
+
+// resolveRequestModelAlias rewrites the model of a json request body to the backend model if it is an alias, and
+// returns the alias. The alias is empty if the body sets no alias, a malformed body is left to the request parsers.
+func resolveRequestModelAlias(requestBody []byte, user utils.User, headers []*configPb.HeaderValue) ([]byte, string, error) {
+	var body struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(requestBody, &body); err != nil || body.Model == "" {
+		return requestBody, "", nil
+	}
+	backend, ok := resolveModelAlias(body.Model, user, headers)
+	if !ok {
+		return requestBody, "", nil
+	}
+	rewritten, err := rewriteRequestModel(requestBody, backend)
+	if err != nil {
+		return nil, body.Model, err
+	}
+	return rewritten, body.Model, nil
+}
+
+// rewriteRequestModel sets the model of a json request body, other fields are left untouched.
+func rewriteRequestModel(requestBody []byte, model string) ([]byte, error) {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(requestBody, &jsonMap); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	jsonMap["model"] = raw
+	return json.Marshal(jsonMap)
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"fmt"
+	"testing"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+func Test_resolveModelAlias(t *testing.T) {
+	prod := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-prod", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v7", Weight: 90},
+				{Model: "llama-v8", Weight: 10},
+				{Model: "llama-v9", Weight: 0},
+			},
+			Stickiness: modelv1alpha1.ModelAliasStickinessUser,
+		},
+	}
+	modelAliases.addOrUpdate(prod)
+	defer modelAliases.delete(toolscache.DeletedFinalStateUnknown{Obj: prod})
+
+	model, ok := resolveModelAlias("llama-v7", utils.User{Name: "u1"}, nil)
+	assert.False(t, ok)
+	assert.Equal(t, "llama-v7", model)
+
+	// Requests are split by weight, backends without weight are never picked.
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		model, ok = resolveModelAlias("llama-prod", utils.User{Name: fmt.Sprintf("user-%d", i)}, nil)
+		assert.True(t, ok)
+		counts[model]++
+	}
+	assert.Equal(t, 0, counts["llama-v9"])
+	assert.InDelta(t, 900, counts["llama-v7"], 60)
+	assert.InDelta(t, 100, counts["llama-v8"], 60)
+
+	// The requests of a user stick to the same backend.
+	first, _ := resolveModelAlias("llama-prod", utils.User{Name: "u1"}, nil)
+	for i := 0; i < 20; i++ {
+		model, _ = resolveModelAlias("llama-prod", utils.User{Name: "u1"}, nil)
+		assert.Equal(t, first, model)
+	}
+
+	// Header stickiness keys the assignment by the header, case-insensitively.
+	session := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-session", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v7", Weight: 1},
+				{Model: "llama-v8", Weight: 1},
+			},
+			Stickiness:   modelv1alpha1.ModelAliasStickinessHeader,
+			StickyHeader: "X-Session-ID",
+		},
+	}
+	modelAliases.addOrUpdate(session)
+	defer modelAliases.delete(session)
+	headers := []*configPb.HeaderValue{{Key: "x-session-id", RawValue: []byte("s1")}}
+	first, _ = resolveModelAlias("llama-session", utils.User{}, headers)
+	for i := 0; i < 20; i++ {
+		model, _ = resolveModelAlias("llama-session", utils.User{Name: fmt.Sprintf("user-%d", i)}, headers)
+		assert.Equal(t, first, model)
+	}
+
+	// Invalid aliases are ignored.
+	invalid := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-invalid", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v7", Weight: 0},
+			},
+		},
+	}
+	modelAliases.addOrUpdate(invalid)
+	_, ok = resolveModelAlias("llama-invalid", utils.User{}, nil)
+	assert.False(t, ok)
+
+	// Deleting an overridden ModelAlias keeps the alias of the same name.
+	override := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-prod-override", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Name: "llama-session",
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v8", Weight: 1},
+			},
+		},
+	}
+	modelAliases.addOrUpdate(override)
+	modelAliases.delete(session)
+	model, ok = resolveModelAlias("llama-session", utils.User{}, nil)
+	assert.True(t, ok)
+	assert.Equal(t, "llama-v8", model)
+	modelAliases.delete(override)
+	_, ok = resolveModelAlias("llama-session", utils.User{}, nil)
+	assert.False(t, ok)
+}
+
+func Test_aliasStoreUpdate(t *testing.T) {
+	store := &aliasStore{aliases: map[string][]*modelAlias{}}
+	resolve := func(name string) string {
+		alias := store.get(name)
+		if alias == nil {
+			return ""
+		}
+		return alias.pick("key")
+	}
+	v7 := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-prod", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v7", Weight: 1},
+			},
+		},
+	}
+	store.addOrUpdate(v7)
+
+	// An invalid update keeps the previous spec.
+	invalid := v7.DeepCopy()
+	invalid.Spec.Backends = []modelv1alpha1.ModelAliasBackend{{Model: "llama-v8", Weight: 0}}
+	store.update(v7, invalid)
+	assert.Equal(t, "llama-v7", resolve("llama-prod"))
+
+	v8 := v7.DeepCopy()
+	v8.Spec.Backends = []modelv1alpha1.ModelAliasBackend{{Model: "llama-v8", Weight: 1}}
+	store.update(invalid, v8)
+	assert.Equal(t, "llama-v8", resolve("llama-prod"))
+
+	// Another ModelAlias of the same name shadows it, updating the shadowed one does not take the alias back.
+	override := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-prod-override", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Name: "llama-prod",
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v9", Weight: 1},
+			},
+		},
+	}
+	store.addOrUpdate(override)
+	assert.Equal(t, "llama-v9", resolve("llama-prod"))
+	store.update(v8, v7)
+	assert.Equal(t, "llama-v9", resolve("llama-prod"))
+
+	// Deleting the shadowing ModelAlias restores the shadowed one.
+	store.delete(override)
+	assert.Equal(t, "llama-v7", resolve("llama-prod"))
+
+	// A renamed alias leaves its previous name.
+	renamed := v7.DeepCopy()
+	renamed.Spec.Name = "llama-next"
+	store.update(v7, renamed)
+	assert.Equal(t, "", resolve("llama-prod"))
+	assert.Equal(t, "llama-v7", resolve("llama-next"))
+	store.delete(renamed)
+	assert.Empty(t, store.aliases)
+}
+
+func Test_resolveRequestModelAlias(t *testing.T) {
+	alias := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-prod", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v7", Weight: 1},
+			},
+		},
+	}
+	modelAliases.addOrUpdate(alias)
+	defer modelAliases.delete(alias)
+
+	body, name, err := resolveRequestModelAlias([]byte(`{"model": "llama-prod", "prompt": "hi"}`), utils.User{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "llama-prod", name)
+	assert.JSONEq(t, `{"model": "llama-v7", "prompt": "hi"}`, string(body))
+
+	// Requests to a model or with a malformed body are left untouched.
+	for _, requestBody := range []string{`{"model": "llama-v7", "prompt": "hi"}`, `not json`} {
+		body, name, err = resolveRequestModelAlias([]byte(requestBody), utils.User{}, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, name)
+		assert.Equal(t, requestBody, string(body))
+	}
+}
+
+func Test_rewriteRequestModel(t *testing.T) {
+	body, err := rewriteRequestModel([]byte(`{"model": "llama-prod", "messages": [{"role": "user", "content": "hi"}], "stream": true}`), "llama-v7")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"model": "llama-v7", "messages": [{"role": "user", "content": "hi"}], "stream": true}`, string(body))
+
+	_, err = rewriteRequestModel([]byte(`not json`), "llama-v7")
+	assert.Error(t, err)
+}
+
+func Test_aliasModelCard(t *testing.T) {
+	s := &Server{cache: cache.NewTestCacheWithModelAdapters(
+		[]*v1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default",
+					Labels: map[string]string{modelIdentifier: "llama-v7", modelMaxLenIdentifier: "8192"}},
+				Status: v1.PodStatus{PodIP: "10.0.0.1", Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default",
+					Labels: map[string]string{modelIdentifier: "llama-v8", modelMaxLenIdentifier: "4096"}},
+				Status: v1.PodStatus{PodIP: "10.0.0.2", Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}},
+			},
+		}, nil)}
+	alias := &modelv1alpha1.ModelAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-prod", Namespace: "default", CreationTimestamp: metav1.Unix(500, 0)},
+		Spec: modelv1alpha1.ModelAliasSpec{
+			Backends: []modelv1alpha1.ModelAliasBackend{
+				{Model: "llama-v7", Weight: 1},
+				{Model: "llama-v8", Weight: 1},
+				{Model: "llama-v9", Weight: 1},
+			},
+		},
+	}
+	modelAliases.addOrUpdate(alias)
+	defer modelAliases.delete(alias)
+
+	list := s.listModelCards(utils.User{})
+	assert.Len(t, list.Data, 3)
+	assert.Equal(t, modelCard{ID: "llama-prod", Object: "model", Created: 500, OwnedBy: "aibrix", Root: "llama-prod",
+		MaxModelLen: 4096, ReadyReplicas: 2, Ready: true}, list.Data[0])
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// modelMaxLenIdentifier is the pod label of the max context length of the model served by the pod.
+	modelMaxLenIdentifier = "model.aibrix.ai/max-model-len"
+	modelOwner            = "aibrix"
+)
+
+// modelCard is an OpenAI model object, extended with the fields of vLLM and the readiness of the model.
+type modelCard struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+	// Root is the base model, Parent is set only for LoRA adapters.
+	Root          string `json:"root"`
+	Parent        string `json:"parent,omitempty"`
+	MaxModelLen   int    `json:"max_model_len,omitempty"`
+	ReadyReplicas int    `json:"ready_replicas"`
+	Ready         bool   `json:"ready"`
+}
+
+type modelList struct {
+	Object string      `json:"object"`
+	Data   []modelCard `json:"data"`
+}
+
+// isModelsRequestPath returns true for /v1/models and /v1/models/{id}, the query string is ignored.
+func isModelsRequestPath(requestPath string) bool {
+	requestPath, _, _ = strings.Cut(requestPath, "?")
+	return requestPath == PathModels || strings.HasPrefix(requestPath, PathModels+"/")
+}
+
+// handleModelsRequest answers /v1/models with the models the user is allowed to request, and /v1/models/{id}
+// with the model. Models not allowed for the user are reported as not found.
+func (s *Server) handleModelsRequest(requestID, requestPath, method string, user utils.User) *extProcPb.ProcessingResponse {
+	if method != "" && method != http.MethodGet {
+		return generateErrorResponse(envoyTypePb.StatusCode_MethodNotAllowed, nil,
+			fmt.Sprintf("method %s is not allowed for %s", method, PathModels))
+	}
+
+	var body any
+	requestPath, _, _ = strings.Cut(requestPath, "?")
+	if modelID, ok := strings.CutPrefix(requestPath, PathModels+"/"); ok {
+		card, exists := s.getModelCard(modelID)
+		if !exists || !user.AllowsModel(modelID) {
+			klog.V(4).InfoS("model not found", "requestID", requestID, "model", modelID, "username", user.Name)
+			return generateErrorResponse(envoyTypePb.StatusCode_NotFound,
+				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+					Key: HeaderErrorModelNotFound, RawValue: []byte(modelID)}}},
+				fmt.Sprintf("the model %s does not exist", modelID))
+		}
+		body = card
+	} else {
+		body = s.listModelCards(user)
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		klog.ErrorS(err, "failed to marshal models", "requestID", requestID)
+		return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError, nil, err.Error())
+	}
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extProcPb.ImmediateResponse{
+				Status: &envoyTypePb.HttpStatus{Code: envoyTypePb.StatusCode_OK},
+				Headers: &extProcPb.HeaderMutation{
+					SetHeaders: []*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+						Key: "Content-Type", RawValue: []byte("application/json")}}},
+				},
+				Body: string(jsonBody),
+			},
+		},
+	}
+}
+
+// listModelCards lists the models served by pods, the LoRA adapters and the model aliases, sorted by id.
+func (s *Server) listModelCards(user utils.User) modelList {
+	list := modelList{Object: "list", Data: []modelCard{}}
+	modelIDs := s.cache.ListModels()
+	for _, alias := range modelAliases.names() {
+		if !slices.Contains(modelIDs, alias) {
+			modelIDs = append(modelIDs, alias)
+		}
+	}
+	for _, modelID := range modelIDs {
+		if !user.AllowsModel(modelID) {
+			continue
+		}
+		if card, ok := s.getModelCard(modelID); ok {
+			list.Data = append(list.Data, card)
+		}
+	}
+	slices.SortFunc(list.Data, func(a, b modelCard) int { return strings.Compare(a.ID, b.ID) })
+	return list
+}
+
+// getModelCard returns false if the model is neither served by any pod nor a LoRA adapter nor a model alias.
+func (s *Server) getModelCard(modelID string) (modelCard, bool) {
+	if alias := modelAliases.get(modelID); alias != nil {
+		return s.getAliasModelCard(alias), true
+	}
+	return s.getServedModelCard(modelID)
+}
+
+// getServedModelCard returns false if the model is neither served by any pod nor a LoRA adapter.
+func (s *Server) getServedModelCard(modelID string) (modelCard, bool) {
+	card := modelCard{ID: modelID, Object: "model", OwnedBy: modelOwner, Root: modelID}
+	adapter, isAdapter := s.getModelAdapter(modelID)
+	if isAdapter {
+		card.Created = adapter.CreationTimestamp.Unix()
+		if adapter.Spec.BaseModel != nil {
+			card.Root, card.Parent = *adapter.Spec.BaseModel, *adapter.Spec.BaseModel
+		}
+	}
+
+	var pods []*v1.Pod
+	if podList, err := s.cache.ListPodsByModel(modelID); err == nil {
+		pods = podList.All()
+	} else if !isAdapter {
+		return card, false
+	}
+	for _, pod := range pods {
+		if !isAdapter && (card.Created == 0 || pod.CreationTimestamp.Unix() < card.Created) {
+			card.Created = pod.CreationTimestamp.Unix()
+		}
+		if isAdapter && card.Parent == "" {
+			// The adapter is loaded to pods of its base model.
+			card.Root, card.Parent = pod.Labels[modelIdentifier], pod.Labels[modelIdentifier]
+		}
+		// The pods may be configured differently, the smallest context length is guaranteed.
+		if maxModelLen, err := strconv.Atoi(pod.Labels[modelMaxLenIdentifier]); err == nil && maxModelLen > 0 &&
+			(card.MaxModelLen == 0 || maxModelLen < card.MaxModelLen) {
+			card.MaxModelLen = maxModelLen
+		}
+	}
+	card.ReadyReplicas = utils.CountRoutablePods(pods)
+	card.Ready = card.ReadyReplicas > 0
+	return card, true
+}
+
+// getAliasModelCard aggregates the backends of the alias, the alias is ready if any backend is ready.
+// Backends are not resolved as aliases, as the gateway does not resolve them either.
+func (s *Server) getAliasModelCard(alias *modelAlias) modelCard {
+	card := modelCard{ID: alias.name, Object: "model", Created: alias.created, OwnedBy: modelOwner, Root: alias.name}
+	for _, backend := range alias.backends {
+		backendCard, ok := s.getServedModelCard(backend.Model)
+		if !ok {
+			continue
+		}
+		card.ReadyReplicas += backendCard.ReadyReplicas
+		if backendCard.MaxModelLen > 0 && (card.MaxModelLen == 0 || backendCard.MaxModelLen < card.MaxModelLen) {
+			card.MaxModelLen = backendCard.MaxModelLen
+		}
+	}
+	card.Ready = card.ReadyReplicas > 0
+	return card
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// pdDisaggProxyVLLM tells the PD proxy of vLLM on the decode pod the prefill pod by the x-prefiller-host-port header.
+	pdDisaggProxyVLLM = "vllm"
+	// pdDisaggProxySGLang sends the request to both pods, the decode pod fetches the KV cache from the bootstrap
+	// server of the prefill pod given in the body.
+	pdDisaggProxySGLang = "sglang"
+
+	// podBootstrapPortLabel is the label of the port of the bootstrap server of a prefill pod of SGLang.
+	podBootstrapPortLabel = "model.aibrix.ai/bootstrap-port"
+	defaultBootstrapPort  = 8998
+
+	prefillRequestIDSuffix = "-prefill"
+)
+
+var (
+	pdDisaggProxy  = utils.LoadEnv("AIBRIX_PD_DISAGG_PROXY", pdDisaggProxyVLLM)
+	prefillTimeout = time.Duration(utils.LoadEnvInt("AIBRIX_PD_DISAGG_PREFILL_TIMEOUT_SECONDS", 120)) * time.Second
+
+	// pdPrefills tracks the prefills of disaggregated requests by request id, to a routing context of the prefill pod.
+	pdPrefills sync.Map
+)
+
+// prefillRequestTracker counts the prefills of disaggregated requests to the running requests of their prefill pods.
+type prefillRequestTracker interface {
+	AddPrefillRequestCount(ctx *types.RoutingContext, requestID string)
+	DonePrefillRequestCount(ctx *types.RoutingContext, requestID string)
+}
+
+var _ prefillRequestTracker = (*cache.Store)(nil)
+
+// disaggregatedRequest returns the headers and the body of a request whose prefill and decode are disaggregated,
+// telling the decode pod where the request is prefilled in the format of the PD proxy of the engine. The body is nil
+// if it is unchanged.
+func disaggregatedRequest(routingCtx *types.RoutingContext, requestBody []byte) ([]string, []byte, error) {
+	if pdDisaggProxy != pdDisaggProxySGLang {
+		return []string{HeaderPrefillerHostPort, routingCtx.PrefillAddress()}, nil, nil
+	}
+
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(requestBody, &jsonMap); err != nil {
+		return nil, nil, err
+	}
+	prefillPod := routingCtx.PrefillPod()
+	bootstrapPort := defaultBootstrapPort
+	if port, err := strconv.Atoi(prefillPod.Labels[podBootstrapPortLabel]); err == nil {
+		bootstrapPort = port
+	}
+	fields := map[string]any{
+		"bootstrap_host": prefillPod.Status.PodIP,
+		"bootstrap_port": bootstrapPort,
+		"bootstrap_room": rand.Int63(),
+	}
+	for key, value := range fields {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		jsonMap[key] = raw
+	}
+	body, err := json.Marshal(jsonMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, body, nil
+}
+
+// startPrefill tracks the prefill of a disaggregated request on its prefill pod. With SGLang, the gateway also sends
+// the request to the prefill pod, which is done once the prefill pod responds.
+func (s *Server) startPrefill(routingCtx *types.RoutingContext, requestPath string, headers []*configPb.HeaderValue, requestBody []byte) {
+	prefillPod := routingCtx.PrefillPod()
+	if prefillPod == nil {
+		return
+	}
+	var user string
+	if routingCtx.User != nil {
+		user = *routingCtx.User
+	}
+	requestID := routingCtx.RequestID
+	prefillCtx := types.NewRoutingContext(context.Background(), routingCtx.Algorithm, routingCtx.Model, "", requestID, user)
+	prefillCtx.SetPrefillPod(prefillPod)
+	if tracker, ok := s.cache.(prefillRequestTracker); ok {
+		tracker.AddPrefillRequestCount(prefillCtx, requestID)
+	}
+	pdPrefills.Store(requestID, prefillCtx)
+
+	if pdDisaggProxy != pdDisaggProxySGLang {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), prefillTimeout)
+	forwardCtx := types.NewRoutingContext(ctx, routingCtx.Algorithm, routingCtx.Model, "", requestID+prefillRequestIDSuffix, user)
+	forwardCtx.SetTargetPod(prefillPod)
+	go func() {
+		defer cancel()
+		statusCode, _, _, err := s.forwardRequest(forwardCtx, &retryRequest{
+			requestPath: requestPath,
+			headers:     headers,
+			body:        requestBody,
+		})
+		forwardCtx.Delete()
+		s.donePrefill(requestID)
+		if err != nil {
+			klog.ErrorS(err, "prefill request failed", "requestID", requestID, "prefillPod", prefillPod.Name)
+		} else if statusCode != http.StatusOK {
+			klog.ErrorS(nil, "prefill request failed", "requestID", requestID, "prefillPod", prefillPod.Name, "statusCode", statusCode)
+		}
+	}()
+}
+
+// donePrefill completes tracking the prefill of a disaggregated request, it is a no-op if already done.
+func (s *Server) donePrefill(requestID string) {
+	value, ok := pdPrefills.LoadAndDelete(requestID)
+	if !ok {
+		return
+	}
+	prefillCtx := value.(*types.RoutingContext)
+	if tracker, ok := s.cache.(prefillRequestTracker); ok {
+		tracker.DonePrefillRequestCount(prefillCtx, requestID)
+	}
+	prefillCtx.Delete()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/types"
+)
+
+func Test_disaggregatedRequest(t *testing.T) {
+	prefillPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default",
+			Labels: map[string]string{routing.PodRoleLabel: routing.PodRolePrefill, podBootstrapPortLabel: "9000"}},
+		Status: v1.PodStatus{PodIP: "10.0.0.1"},
+	}
+	decodePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default",
+			Labels: map[string]string{routing.PodRoleLabel: routing.PodRoleDecode}},
+		Status: v1.PodStatus{PodIP: "10.0.0.2"},
+	}
+	requestBody := []byte(`{"model":"m1","messages":[{"role":"user","content":"hi"}]}`)
+
+	tests := []struct {
+		proxy   string
+		headers []string
+		body    map[string]any
+	}{
+		{
+			proxy:   pdDisaggProxyVLLM,
+			headers: []string{HeaderPrefillerHostPort, "10.0.0.1:8000"},
+		},
+		{
+			proxy: pdDisaggProxySGLang,
+			body:  map[string]any{"model": "m1", "bootstrap_host": "10.0.0.1", "bootstrap_port": float64(9000)},
+		},
+	}
+	defer func(proxy string) { pdDisaggProxy = proxy }(pdDisaggProxy)
+	for _, tt := range tests {
+		t.Run(tt.proxy, func(t *testing.T) {
+			pdDisaggProxy = tt.proxy
+			ctx := types.NewRoutingContext(context.Background(), routing.RouterPDDisagg, "m1", "hi", "r1", "")
+			ctx.SetPrefillPod(prefillPod)
+			ctx.SetTargetPod(decodePod)
+
+			headers, body, err := disaggregatedRequest(ctx, requestBody)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.headers, headers)
+			if tt.body == nil {
+				assert.Nil(t, body)
+				return
+			}
+			var jsonMap map[string]any
+			assert.NoError(t, json.Unmarshal(body, &jsonMap))
+			for key, value := range tt.body {
+				assert.Equal(t, value, jsonMap[key])
+			}
+			assert.Contains(t, jsonMap, "bootstrap_room")
+		})
+	}
+}
+
+func Test_prefillTracking(t *testing.T) {
+	prefillPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"},
+		Status:     v1.PodStatus{PodIP: "10.0.0.1"},
+	}
+	decodePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"},
+		Status:     v1.PodStatus{PodIP: "10.0.0.2"},
+	}
+	c := cache.NewTestCacheWithPods([]*v1.Pod{prefillPod, decodePod}, "m1")
+	routingCtx := types.NewRoutingContext(context.Background(), routing.RouterPDDisagg, "m1", "hi", "r1", "")
+	routingCtx.SetPrefillPod(prefillPod)
+	routingCtx.SetTargetPod(decodePod)
+	s := &Server{cache: c}
+
+	s.startPrefill(routingCtx, PathChatCompletions, nil, nil)
+	running, err := c.GetMetricValueByPod("p1", "default", metrics.RealtimeNumRequestsRunning)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, running.GetSimpleValue())
+
+	// The prefill is done once, on the response of the decode pod or at the end of the request.
+	s.donePrefill("r1")
+	s.donePrefill("r1")
+	running, err = c.GetMetricValueByPod("p1", "default", metrics.RealtimeNumRequestsRunning)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, running.GetSimpleValue())
+}
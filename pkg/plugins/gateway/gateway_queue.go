@@ -0,0 +1,385 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultQueueMaxSize                = 1000
+	defaultQueueMaxRunningPerPod       = 0
+	defaultQueueMaxWaitingPerPod       = 0
+	defaultQueueDispatchIntervalInMS   = 20
+	defaultQueueTimeoutHighInSeconds   = 60
+	defaultQueueTimeoutNormalInSeconds = 30
+	defaultQueueTimeoutLowInSeconds    = 10
+)
+
+var (
+	// queueEnabled holds requests in the gateway while all pods of their model are saturated.
+	queueEnabled = utils.LoadEnvBool("AIBRIX_GATEWAY_QUEUE_ENABLED", false)
+	// queueMaxSize bounds the queued requests of a model, requests beyond it are rejected with 429.
+	queueMaxSize = utils.LoadEnvInt("AIBRIX_GATEWAY_QUEUE_MAX_SIZE", defaultQueueMaxSize)
+	// queueMaxRunningPerPod saturates a pod once it runs as many requests, 0 only checks the waiting requests.
+	queueMaxRunningPerPod = utils.LoadEnvInt("AIBRIX_GATEWAY_QUEUE_MAX_RUNNING_PER_POD", defaultQueueMaxRunningPerPod)
+	// queueMaxWaitingPerPod saturates a pod once more requests are waiting in the engine.
+	queueMaxWaitingPerPod = utils.LoadEnvInt("AIBRIX_GATEWAY_QUEUE_MAX_WAITING_PER_POD", defaultQueueMaxWaitingPerPod)
+	// queueDispatchInterval is how often queued requests are admitted to pods with capacity.
+	queueDispatchInterval = time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_QUEUE_DISPATCH_INTERVAL_MS", defaultQueueDispatchIntervalInMS)) * time.Millisecond
+	// queueTimeouts is how long a request of each priority class is queued before it is rejected with 503.
+	queueTimeouts = map[string]time.Duration{
+		utils.PriorityClassHigh:   time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_QUEUE_TIMEOUT_HIGH_SECONDS", defaultQueueTimeoutHighInSeconds)) * time.Second,
+		utils.PriorityClassNormal: time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_QUEUE_TIMEOUT_NORMAL_SECONDS", defaultQueueTimeoutNormalInSeconds)) * time.Second,
+		utils.PriorityClassLow:    time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_QUEUE_TIMEOUT_LOW_SECONDS", defaultQueueTimeoutLowInSeconds)) * time.Second,
+	}
+
+	errQueueFull    = errors.New("admission queue is full")
+	errQueueTimeout = errors.New("timed out in admission queue")
+)
+
+// priorityClasses are ordered from the highest priority, requests are admitted in this order.
+var priorityClasses = []string{utils.PriorityClassHigh, utils.PriorityClassNormal, utils.PriorityClassLow}
+
+// getPriorityClass returns the priority class of the user record, or of the priority-class header if the user has none.
+// Unknown classes are treated as normal.
+func getPriorityClass(user utils.User, headers []*configPb.HeaderValue) string {
+	class := user.PriorityClass
+	if class == "" {
+		for _, header := range headers {
+			if strings.ToLower(header.Key) == HeaderPriorityClass {
+				class = strings.ToLower(strings.TrimSpace(string(header.RawValue)))
+				break
+			}
+		}
+	}
+	for _, c := range priorityClasses {
+		if c == class {
+			return c
+		}
+	}
+	return utils.PriorityClassNormal
+}
+
+func priorityIndex(class string) int {
+	for i, c := range priorityClasses {
+		if c == class {
+			return i
+		}
+	}
+	return priorityIndex(utils.PriorityClassNormal)
+}
+
+type queuedRequest struct {
+	requestID string
+	// admitted is closed once the request can be routed.
+	admitted chan struct{}
+}
+
+// modelQueue holds the queued requests of a model, FIFO within each priority class.
+type modelQueue struct {
+	waiting [3][]*queuedRequest
+}
+
+func (q *modelQueue) len() int {
+	n := 0
+	for _, requests := range q.waiting {
+		n += len(requests)
+	}
+	return n
+}
+
+func (q *modelQueue) pop() *queuedRequest {
+	for i, requests := range q.waiting {
+		if len(requests) > 0 {
+			q.waiting[i] = requests[1:]
+			return requests[0]
+		}
+	}
+	return nil
+}
+
+func (q *modelQueue) remove(r *queuedRequest) bool {
+	for i, requests := range q.waiting {
+		for j, queued := range requests {
+			if queued == r {
+				q.waiting[i] = append(requests[:j:j], requests[j+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modelCapacity is how many requests the pods of a model are busy with, and how many more they accept before they are
+// saturated, as of their last metrics.
+type modelCapacity struct {
+	busy     int
+	headroom int
+}
+
+// requestQueue is the admission queue of the gateway. A request is admitted immediately if its model has no queued
+// requests and a pod with capacity, otherwise it waits until the dispatcher admits it or its class times out.
+// Admitted requests hold their capacity until they are done, so that requests admitted since the last metrics of the
+// pods are not admitted again.
+type requestQueue struct {
+	mu     sync.Mutex
+	models map[string]*modelQueue
+	// admitted counts the admitted requests of each model which are not done.
+	admitted map[string]int
+	// admittedModels is the model of each admitted request which is not done.
+	admittedModels map[string]string
+	// capacity returns the capacity of the pods of a model.
+	capacity func(model string) modelCapacity
+	maxSize  int
+	timeouts map[string]time.Duration
+}
+
+func newRequestQueue(capacity func(model string) modelCapacity, maxSize int, timeouts map[string]time.Duration) *requestQueue {
+	return &requestQueue{
+		models:         map[string]*modelQueue{},
+		admitted:       map[string]int{},
+		admittedModels: map[string]string{},
+		capacity:       capacity,
+		maxSize:        maxSize,
+		timeouts:       timeouts,
+	}
+}
+
+// available returns how many more requests of the model can be admitted. The admitted requests the pods are not busy
+// with yet are not reflected by their metrics, they are taken from the headroom. q.mu must be held.
+func (q *requestQueue) available(model string) int {
+	capacity := q.capacity(model)
+	return capacity.headroom - max(q.admitted[model]-capacity.busy, 0)
+}
+
+// admit records an admitted request until it is done. q.mu must be held.
+func (q *requestQueue) admit(model, requestID string) {
+	q.admitted[model]++
+	q.admittedModels[requestID] = model
+}
+
+// done releases the capacity held by an admitted request, it is a no-op for requests which were not admitted.
+func (q *requestQueue) done(requestID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	model, ok := q.admittedModels[requestID]
+	if !ok {
+		return
+	}
+	delete(q.admittedModels, requestID)
+	if q.admitted[model]--; q.admitted[model] <= 0 {
+		delete(q.admitted, model)
+	}
+}
+
+// run admits queued requests every interval until stopCh is closed.
+func (q *requestQueue) run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			q.dispatch()
+		}
+	}
+}
+
+// dispatch admits the queued requests of each model in priority order, as many as its pods have capacity for.
+func (q *requestQueue) dispatch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for model, mq := range q.models {
+		if mq.len() == 0 {
+			delete(q.models, model)
+			continue
+		}
+		for slots := q.available(model); slots > 0; slots-- {
+			r := mq.pop()
+			if r == nil {
+				break
+			}
+			q.admit(model, r.requestID)
+			close(r.admitted)
+		}
+		setQueueDepth(model, mq.len())
+	}
+}
+
+// wait holds a request until a pod of the model has capacity. onQueued is called with the queue timeout of the request
+// before it starts waiting, it is not called if the request is admitted immediately.
+func (q *requestQueue) wait(ctx context.Context, model, priorityClass, requestID string, onQueued func(timeout time.Duration)) error {
+	q.mu.Lock()
+	mq, ok := q.models[model]
+	if (!ok || mq.len() == 0) && q.available(model) > 0 {
+		q.admit(model, requestID)
+		q.mu.Unlock()
+		return nil
+	}
+	if !ok {
+		mq = &modelQueue{}
+		q.models[model] = mq
+	}
+	if mq.len() >= q.maxSize {
+		q.mu.Unlock()
+		return errQueueFull
+	}
+	r := &queuedRequest{requestID: requestID, admitted: make(chan struct{})}
+	class := priorityIndex(priorityClass)
+	mq.waiting[class] = append(mq.waiting[class], r)
+	setQueueDepth(model, mq.len())
+	q.mu.Unlock()
+
+	timeout := q.timeouts[priorityClasses[class]]
+	if onQueued != nil {
+		onQueued(timeout)
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-r.admitted:
+		return nil
+	case <-timer.C:
+		err = errQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !mq.remove(r) {
+		// The request was admitted while timing out, route it anyway.
+		return nil
+	}
+	setQueueDepth(model, mq.len())
+	return err
+}
+
+func setQueueDepth(model string, depth int) {
+	metrics.SetGaugeMetric(
+		metrics.GatewayQueueDepth,
+		metrics.GetMetricHelp(metrics.GatewayQueueDepth),
+		float64(depth),
+		[]string{"model"},
+		model,
+	)
+}
+
+// queueCapacity returns the requests the routable pods of a model are busy with, and how many more they accept before
+// they are saturated. A pod is saturated once its waiting requests exceed queueMaxWaitingPerPod, or its running
+// requests reach queueMaxRunningPerPod if set. Pods without metrics are considered idle.
+func (s *Server) queueCapacity(model string) modelCapacity {
+	capacity := modelCapacity{}
+	podsArr, err := s.cache.ListPodsByModel(model)
+	if err != nil || podsArr == nil {
+		return capacity
+	}
+	pods := utils.FilterRoutablePods(podsArr.All())
+	healthyPods := s.filterHealthyPods(pods)
+	for _, pod := range pods {
+		waiting := int(s.getPodModelMetric(pod, model, metrics.NumRequestsWaiting))
+		running := int(s.getPodModelMetric(pod, model, metrics.NumRequestsRunning))
+		// The realtime count of the gateway includes requests routed since the last metrics refresh.
+		if realtime, err := s.cache.GetMetricValueByPod(pod.Name, pod.Namespace, metrics.RealtimeNumRequestsRunning); err == nil {
+			running = max(running, int(realtime.GetSimpleValue()))
+		}
+		capacity.busy += running + waiting
+		if waiting > queueMaxWaitingPerPod || !slices.Contains(healthyPods, pod) {
+			continue
+		}
+		if queueMaxRunningPerPod <= 0 {
+			// The running requests are not bounded, admit one more request per tolerated waiting request until the engine
+			// starts queuing them.
+			capacity.headroom += queueMaxWaitingPerPod - waiting + 1
+			continue
+		}
+		capacity.headroom += max(queueMaxRunningPerPod-running, 0)
+	}
+	return capacity
+}
+
+func (s *Server) getPodModelMetric(pod *v1.Pod, model, metricName string) float64 {
+	value, err := s.cache.GetMetricValueByPodModel(pod.Name, pod.Namespace, model, metricName)
+	if err != nil {
+		return 0
+	}
+	return value.GetSimpleValue()
+}
+
+// admitRequest holds the request in the admission queue of its model, it returns an error response if the request
+// is rejected. The ext_proc message timeout is extended while the request is queued, up to the max_message_timeout of
+// the ext_proc filter. An admitted request holds the capacity of the model until doneAdmission is called.
+func (s *Server) admitRequest(ctx context.Context, srv extProcPb.ExternalProcessor_ProcessServer, requestID, model, priorityClass string) *extProcPb.ProcessingResponse {
+	if s.requestQueue == nil {
+		return nil
+	}
+	start := time.Now()
+	err := s.requestQueue.wait(ctx, model, priorityClass, requestID, func(timeout time.Duration) {
+		klog.InfoS("pods are saturated, request is queued", "requestID", requestID, "model", model, "priorityClass", priorityClass)
+		if srv == nil {
+			return
+		}
+		if err := srv.Send(&extProcPb.ProcessingResponse{OverrideMessageTimeout: durationpb.New(timeout + time.Second)}); err != nil {
+			klog.ErrorS(err, "failed to extend message timeout for queued request", "requestID", requestID)
+		}
+	})
+	if err == nil {
+		if waited := time.Since(start); waited > queueDispatchInterval {
+			klog.InfoS("request is admitted", "requestID", requestID, "model", model, "priorityClass", priorityClass, "queueTime", waited)
+		}
+		return nil
+	}
+
+	klog.ErrorS(err, "request is rejected by admission queue", "requestID", requestID, "model", model, "priorityClass", priorityClass)
+	if errors.Is(err, errQueueFull) {
+		return withRetryAfter(generateErrorResponse(envoyTypePb.StatusCode_TooManyRequests,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorQueueFull, RawValue: []byte("true")}}},
+			fmt.Sprintf("too many queued requests for model %s", model)), defaultRetryAfter)
+	}
+	return generateErrorResponse(envoyTypePb.StatusCode_ServiceUnavailable,
+		[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+			Key: HeaderErrorQueueTimeout, RawValue: []byte("true")}}},
+		fmt.Sprintf("pods of model %s are saturated, request timed out in queue", model))
+}
+
+// doneAdmission releases the capacity held by the request in the admission queue.
+func (s *Server) doneAdmission(requestID string) {
+	if s.requestQueue != nil {
+		s.requestQueue.done(requestID)
+	}
+}
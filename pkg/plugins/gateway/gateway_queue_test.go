@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+func Test_getPriorityClass(t *testing.T) {
+	headers := []*configPb.HeaderValue{{Key: "Priority-Class", RawValue: []byte("High")}}
+
+	assert.Equal(t, utils.PriorityClassNormal, getPriorityClass(utils.User{}, nil))
+	assert.Equal(t, utils.PriorityClassHigh, getPriorityClass(utils.User{}, headers))
+	assert.Equal(t, utils.PriorityClassLow, getPriorityClass(utils.User{PriorityClass: utils.PriorityClassLow}, headers),
+		"user record takes priority over the header")
+	assert.Equal(t, utils.PriorityClassNormal, getPriorityClass(utils.User{},
+		[]*configPb.HeaderValue{{Key: "priority-class", RawValue: []byte("urgent")}}))
+}
+
+func Test_requestQueue(t *testing.T) {
+	var capacity atomic.Int64
+	timeouts := map[string]time.Duration{
+		utils.PriorityClassHigh:   time.Minute,
+		utils.PriorityClassNormal: time.Minute,
+		utils.PriorityClassLow:    50 * time.Millisecond,
+	}
+	q := newRequestQueue(func(string) modelCapacity { return modelCapacity{headroom: int(capacity.Load())} }, 3, timeouts)
+
+	capacity.Store(1)
+	assert.NoError(t, q.wait(context.Background(), "m", utils.PriorityClassNormal, "r0", nil), "admitted immediately")
+	q.done("r0")
+
+	capacity.Store(0)
+	assert.ErrorIs(t, q.wait(context.Background(), "m", utils.PriorityClassLow, "r1", nil), errQueueTimeout)
+
+	var mu sync.Mutex
+	var admitted []string
+	var wg sync.WaitGroup
+	enqueue := func(class, requestID string) {
+		queued := make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := q.wait(context.Background(), "m", class, requestID, func(time.Duration) { close(queued) })
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				admitted = append(admitted, requestID)
+			}
+		}()
+		<-queued
+	}
+	enqueue(utils.PriorityClassNormal, "r2")
+	enqueue(utils.PriorityClassNormal, "r3")
+	enqueue(utils.PriorityClassHigh, "r4")
+	assert.ErrorIs(t, q.wait(context.Background(), "m", utils.PriorityClassHigh, "r5", nil), errQueueFull)
+
+	// Requests are admitted in priority order, FIFO within a class, as pods get capacity.
+	capacity.Store(2)
+	q.dispatch()
+	capacity.Store(0)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(admitted) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{"r4", "r2"}, admitted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, q.wait(ctx, "m", utils.PriorityClassNormal, "r6", nil), context.Canceled)
+
+	q.done("r4")
+	q.done("r2")
+	capacity.Store(1)
+	q.dispatch()
+	wg.Wait()
+	assert.Equal(t, []string{"r3"}, admitted[2:])
+	q.dispatch()
+	assert.Empty(t, q.models, "empty queues are released")
+	q.done("r3")
+	assert.Empty(t, q.admitted, "done requests release their capacity")
+}
+
+func Test_requestQueueAdmitted(t *testing.T) {
+	var busy atomic.Int64
+	timeouts := map[string]time.Duration{utils.PriorityClassNormal: 50 * time.Millisecond}
+	q := newRequestQueue(func(string) modelCapacity { return modelCapacity{busy: int(busy.Load()), headroom: 2} }, 10, timeouts)
+
+	// Requests admitted since the last metrics hold the headroom until the pods are busy with them.
+	assert.NoError(t, q.wait(context.Background(), "m", utils.PriorityClassNormal, "r1", nil))
+	assert.NoError(t, q.wait(context.Background(), "m", utils.PriorityClassNormal, "r2", nil))
+	assert.ErrorIs(t, q.wait(context.Background(), "m", utils.PriorityClassNormal, "r3", nil), errQueueTimeout)
+
+	busy.Store(2)
+	assert.NoError(t, q.wait(context.Background(), "m", utils.PriorityClassNormal, "r3", nil))
+
+	// Done requests release their capacity, requests which were not admitted are ignored.
+	busy.Store(0)
+	q.done("r1")
+	q.done("r2")
+	q.done("unknown")
+	assert.Equal(t, 1, q.available("m"))
+	q.done("r3")
+	assert.Equal(t, 2, q.available("m"))
+}
+
+func Test_requestQueueStop(t *testing.T) {
+	q := newRequestQueue(func(string) modelCapacity { return modelCapacity{} }, 1, map[string]time.Duration{})
+	s := &Server{requestQueue: q, stopCh: make(chan struct{})}
+	stopped := make(chan struct{})
+	go func() {
+		q.run(time.Millisecond, s.stopCh)
+		close(stopped)
+	}()
+	s.Close()
+	s.Close()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher is not stopped on close")
+	}
+}
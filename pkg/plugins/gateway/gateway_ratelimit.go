@@ -19,29 +19,103 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
 
 	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms/vtc"
 	"github.com/vllm-project/aibrix/pkg/utils"
 )
 
+const (
+	defaultConcurrencySlotTTLInSeconds = 600
+	rateLimitReleaseTimeout            = 5 * time.Second
+
+	// rateLimitWindow is the window of the fixed window rate limiter.
+	rateLimitWindow = time.Minute
+	// defaultRetryAfter is the Retry-After of rejections without a known reset time, e.g. over max concurrency.
+	defaultRetryAfter = time.Second
+)
+
+var (
+	// concurrencySlotTTL reclaims concurrency slots leaked by a crashed gateway once the user is idle for the ttl.
+	concurrencySlotTTL = time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_CONCURRENCY_SLOT_TTL_SECONDS", defaultConcurrencySlotTTLInSeconds)) * time.Second
+
+	// promptTokenEstimator estimates prompt tokens to pre-charge before the actual usage is known.
+	promptTokenEstimator = vtc.NewSimpleTokenEstimator()
+)
+
 func (s *Server) checkLimits(ctx context.Context, user utils.User) (int64, *extProcPb.ProcessingResponse, error) {
+	user = withDefaultLimits(user)
+
+	code, reset, err := s.checkTokenQuotas(ctx, user)
+	if err != nil {
+		return 0, withRetryAfter429(generateErrorResponse(
+			code,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorQuotaExceeded, RawValue: []byte("true"),
+			}}},
+			err.Error()), reset), err
+	}
+
+	var rpm int64
+	var errRes *extProcPb.ProcessingResponse
+	if user.RateLimiter == utils.TokenBucketRateLimiter {
+		rpm, errRes, err = s.checkTokenBucketLimits(ctx, user)
+	} else {
+		rpm, errRes, err = s.checkFixedWindowLimits(ctx, user)
+	}
+	if errRes != nil {
+		return 0, errRes, err
+	}
+
+	// Acquire the concurrency slot last, so that it is never held by a rejected request.
+	if user.MaxConcurrency > 0 {
+		code, err := s.acquireConcurrency(ctx, user.Name, user.MaxConcurrency)
+		if err != nil {
+			header := HeaderErrorConcurrencyExceeded
+			if code != envoyTypePb.StatusCode_TooManyRequests {
+				header = HeaderErrorAcquireConcurrency
+			}
+			return 0, withRetryAfter429(generateErrorResponse(
+				code,
+				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+					Key: header, RawValue: []byte("true"),
+				}}},
+				err.Error()), defaultRetryAfter), err
+		}
+	}
+
+	return rpm, nil, nil
+}
+
+// withDefaultLimits fills in the default rpm and tpm of a user without limits.
+func withDefaultLimits(user utils.User) utils.User {
 	if user.Rpm == 0 {
 		user.Rpm = int64(DefaultRPM)
 	}
 	if user.Tpm == 0 {
 		user.Tpm = user.Rpm * int64(DefaultTPMMultiplier)
 	}
+	if user.Burst == 0 {
+		user.Burst = user.Rpm
+	}
+	return user
+}
 
+func (s *Server) checkFixedWindowLimits(ctx context.Context, user utils.User) (int64, *extProcPb.ProcessingResponse, error) {
 	code, err := s.checkRPM(ctx, user.Name, user.Rpm)
 	if err != nil {
-		return 0, generateErrorResponse(
+		return 0, withRetryAfter429(generateErrorResponse(
 			code,
 			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 				Key: HeaderErrorRPMExceeded, RawValue: []byte("true"),
 			}}},
-			err.Error()), err
+			err.Error()), fixedWindowReset(time.Now())), err
 	}
 
 	rpm, code, err := s.incrRPM(ctx, user.Name)
@@ -56,17 +130,64 @@ func (s *Server) checkLimits(ctx context.Context, user utils.User) (int64, *extP
 
 	code, err = s.checkTPM(ctx, user.Name, user.Tpm)
 	if err != nil {
-		return 0, generateErrorResponse(
+		return 0, withRetryAfter429(generateErrorResponse(
 			code,
 			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 				Key: HeaderErrorTPMExceeded, RawValue: []byte("true"),
 			}}},
-			err.Error()), err
+			err.Error()), fixedWindowReset(time.Now())), err
 	}
 
 	return rpm, nil, nil
 }
 
+// checkTokenBucketLimits takes a request token from the user's request bucket and checks the token bucket is not in debt.
+// The returned rpm is the number of request tokens in use, i.e. burst minus the tokens left.
+func (s *Server) checkTokenBucketLimits(ctx context.Context, user utils.User) (int64, *extProcPb.ProcessingResponse, error) {
+	taken, tokens, err := s.tokenBucketLimiter.Take(ctx, fmt.Sprintf("%v_RPM_BUCKET", user.Name), 1, perSecond(user.Rpm), user.Burst)
+	if err != nil {
+		err = fmt.Errorf("fail to take RPM token for user: %v", user.Name)
+		return 0, generateErrorResponse(
+			envoyTypePb.StatusCode_InternalServerError,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorIncrRPM, RawValue: []byte("true"),
+			}}},
+			err.Error()), err
+	}
+	if !taken {
+		err = fmt.Errorf("user: %v has exceeded RPM: %v, burst: %v", user.Name, user.Rpm, user.Burst)
+		return 0, withRetryAfter(generateErrorResponse(
+			envoyTypePb.StatusCode_TooManyRequests,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorRPMExceeded, RawValue: []byte("true"),
+			}}},
+			err.Error()), tokenBucketRefill(1-tokens, user.Rpm)), err
+	}
+
+	// Tokens are charged once the request is routed, only peek into the bucket here.
+	_, tpmTokens, err := s.tokenBucketLimiter.Take(ctx, fmt.Sprintf("%v_TPM_BUCKET", user.Name), 0, perSecond(user.Tpm), user.Tpm)
+	if err != nil {
+		err = fmt.Errorf("fail to get TPM for user: %v", user.Name)
+		return 0, generateErrorResponse(
+			envoyTypePb.StatusCode_InternalServerError,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorTPMExceeded, RawValue: []byte("true"),
+			}}},
+			err.Error()), err
+	}
+	if tpmTokens <= 0 {
+		err = fmt.Errorf("user: %v has exceeded TPM: %v", user.Name, user.Tpm)
+		return 0, withRetryAfter(generateErrorResponse(
+			envoyTypePb.StatusCode_TooManyRequests,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorTPMExceeded, RawValue: []byte("true"),
+			}}},
+			err.Error()), tokenBucketRefill(1-tpmTokens, user.Tpm)), err
+	}
+
+	return user.Burst - tokens, nil, nil
+}
+
 func (s *Server) checkRPM(ctx context.Context, username string, rpmLimit int64) (envoyTypePb.StatusCode, error) {
 	rpmCurrent, err := s.ratelimiter.Get(ctx, fmt.Sprintf("%v_RPM_CURRENT", username))
 	if err != nil {
@@ -101,3 +222,171 @@ func (s *Server) checkTPM(ctx context.Context, username string, tpmLimit int64)
 
 	return envoyTypePb.StatusCode_OK, nil
 }
+
+// checkTokenQuotas rejects the request if the user has used up its daily or monthly token quota.
+// Returns the time until the exceeded quota resets along with the rejection.
+func (s *Server) checkTokenQuotas(ctx context.Context, user utils.User) (envoyTypePb.StatusCode, time.Duration, error) {
+	if user.DailyTokenQuota == 0 && user.MonthlyTokenQuota == 0 {
+		return envoyTypePb.StatusCode_OK, 0, nil
+	}
+
+	now := time.Now()
+	daily, monthly, err := utils.GetTokenUsage(ctx, user.Name, now, s.redisClient)
+	if err != nil {
+		return envoyTypePb.StatusCode_InternalServerError, 0, fmt.Errorf("fail to get token usage for user: %v", user.Name)
+	}
+
+	// The monthly quota is checked first, since it resets last.
+	if user.MonthlyTokenQuota > 0 && monthly >= user.MonthlyTokenQuota {
+		return envoyTypePb.StatusCode_TooManyRequests, quotaReset(now, true),
+			fmt.Errorf("user: %v has exceeded monthly token quota: %v", user.Name, user.MonthlyTokenQuota)
+	}
+	if user.DailyTokenQuota > 0 && daily >= user.DailyTokenQuota {
+		return envoyTypePb.StatusCode_TooManyRequests, quotaReset(now, false),
+			fmt.Errorf("user: %v has exceeded daily token quota: %v", user.Name, user.DailyTokenQuota)
+	}
+
+	return envoyTypePb.StatusCode_OK, 0, nil
+}
+
+// chargeTPM charges tokens to the user's TPM limiter and token quotas, a negative value refunds pre-charged tokens.
+// Returns the tokens in use of the current window, or of the bucket for the token-bucket limiter.
+func (s *Server) chargeTPM(ctx context.Context, user utils.User, tokens int64) (int64, error) {
+	user = withDefaultLimits(user)
+	if user.DailyTokenQuota > 0 || user.MonthlyTokenQuota > 0 {
+		if err := utils.IncrTokenUsage(ctx, user.Name, tokens, time.Now(), s.redisClient); err != nil {
+			klog.ErrorS(err, "failed to charge token quota", "username", user.Name)
+		}
+	}
+	if user.RateLimiter == utils.TokenBucketRateLimiter {
+		left, err := s.tokenBucketLimiter.Charge(ctx, fmt.Sprintf("%v_TPM_BUCKET", user.Name), tokens, perSecond(user.Tpm), user.Tpm)
+		if err != nil {
+			return 0, err
+		}
+		return user.Tpm - left, nil
+	}
+	// A refund crossing the window boundary lowers the new window, which is bounded by the pre-charge of a single request.
+	return s.ratelimiter.Incr(ctx, fmt.Sprintf("%v_TPM_CURRENT", user.Name), tokens)
+}
+
+// preChargeTPM charges the estimated prompt tokens of a routed request, so that concurrent requests of a user
+// can't overshoot the TPM limit before their usage is known. The charge is reconciled with the actual usage
+// once the response completes. Returns the pre-charged tokens.
+func (s *Server) preChargeTPM(ctx context.Context, requestID string, user utils.User, message string) int64 {
+	if user.Name == "" {
+		return 0
+	}
+	tokens := int64(promptTokenEstimator.EstimateInputTokens(message))
+	if tokens == 0 {
+		return 0
+	}
+	if _, err := s.chargeTPM(ctx, user, tokens); err != nil {
+		klog.ErrorS(err, "failed to pre-charge TPM", "requestID", requestID, "username", user.Name)
+		return 0
+	}
+	return tokens
+}
+
+func (s *Server) acquireConcurrency(ctx context.Context, username string, maxConcurrency int64) (envoyTypePb.StatusCode, error) {
+	acquired, err := s.concurrencyLimiter.Acquire(ctx, fmt.Sprintf("%v_CONCURRENCY", username), maxConcurrency)
+	if err != nil {
+		return envoyTypePb.StatusCode_InternalServerError, fmt.Errorf("fail to acquire concurrency for user: %v", username)
+	}
+
+	if !acquired {
+		return envoyTypePb.StatusCode_TooManyRequests, fmt.Errorf("user: %v has exceeded max concurrency: %v", username, maxConcurrency)
+	}
+
+	return envoyTypePb.StatusCode_OK, nil
+}
+
+// doneLimits is called once the request ends. It releases the concurrency slot of the user
+// and refunds the pre-charged tokens if the request ended before they were reconciled with the usage.
+func (s *Server) doneLimits(requestID string, user utils.User, preChargedTokens int64, reconciled bool) {
+	if user.Name == "" {
+		return
+	}
+	// The stream context is done by now.
+	ctx, cancel := context.WithTimeout(context.Background(), rateLimitReleaseTimeout)
+	defer cancel()
+
+	if user.MaxConcurrency > 0 {
+		if err := s.concurrencyLimiter.Release(ctx, fmt.Sprintf("%v_CONCURRENCY", user.Name)); err != nil {
+			klog.ErrorS(err, "failed to release concurrency", "requestID", requestID, "username", user.Name)
+		}
+	}
+	if preChargedTokens != 0 && !reconciled {
+		if _, err := s.chargeTPM(ctx, user, -preChargedTokens); err != nil {
+			klog.ErrorS(err, "failed to refund pre-charged TPM", "requestID", requestID, "username", user.Name)
+		}
+	}
+}
+
+// tokensInUse returns the tokens in use of the current window, or of the bucket for the token-bucket limiter.
+func (s *Server) tokensInUse(ctx context.Context, user utils.User) (int64, error) {
+	user = withDefaultLimits(user)
+	if user.RateLimiter == utils.TokenBucketRateLimiter {
+		_, left, err := s.tokenBucketLimiter.Take(ctx, fmt.Sprintf("%v_TPM_BUCKET", user.Name), 0, perSecond(user.Tpm), user.Tpm)
+		if err != nil {
+			return 0, err
+		}
+		return user.Tpm - left, nil
+	}
+	return s.ratelimiter.Get(ctx, fmt.Sprintf("%v_TPM_CURRENT", user.Name))
+}
+
+// rateLimitHeaders returns the x-ratelimit-* headers of a user with rpm requests and tpm tokens in use, as key
+// value pairs. The request limit of the token-bucket limiter is the burst.
+func rateLimitHeaders(user utils.User, rpm, tpm int64, now time.Time) []string {
+	user = withDefaultLimits(user)
+	requestLimit, requestReset, tokenReset := user.Rpm, fixedWindowReset(now), fixedWindowReset(now)
+	if user.RateLimiter == utils.TokenBucketRateLimiter {
+		requestLimit = user.Burst
+		requestReset, tokenReset = tokenBucketRefill(rpm, user.Rpm), tokenBucketRefill(tpm, user.Tpm)
+	}
+	return []string{
+		HeaderRateLimitLimitRequests, strconv.FormatInt(requestLimit, 10),
+		HeaderRateLimitRemainingRequests, strconv.FormatInt(max(requestLimit-rpm, 0), 10),
+		HeaderRateLimitResetRequests, formatReset(requestReset),
+		HeaderRateLimitLimitTokens, strconv.FormatInt(user.Tpm, 10),
+		HeaderRateLimitRemainingTokens, strconv.FormatInt(max(user.Tpm-tpm, 0), 10),
+		HeaderRateLimitResetTokens, formatReset(tokenReset),
+	}
+}
+
+// withRetryAfter429 sets the Retry-After header of a rejection if it is a 429, failures of the limiters are not
+// to be retried after the reset.
+func withRetryAfter429(errRes *extProcPb.ProcessingResponse, after time.Duration) *extProcPb.ProcessingResponse {
+	if errRes.GetImmediateResponse().GetStatus().GetCode() != envoyTypePb.StatusCode_TooManyRequests {
+		return errRes
+	}
+	return withRetryAfter(errRes, after)
+}
+
+// fixedWindowReset returns the time until the fixed window of now ends, windows are aligned on the unix epoch.
+func fixedWindowReset(now time.Time) time.Duration {
+	return now.Truncate(rateLimitWindow).Add(rateLimitWindow).Sub(now)
+}
+
+// tokenBucketRefill returns the time a bucket refilled at perMinute tokens per minute takes to refill tokens.
+func tokenBucketRefill(tokens, perMinute int64) time.Duration {
+	if tokens <= 0 || perMinute <= 0 {
+		return 0
+	}
+	return time.Duration(float64(tokens) / perSecond(perMinute) * float64(time.Second))
+}
+
+// quotaReset returns the time until the token quotas of now reset, at the start of the next UTC day or month.
+func quotaReset(now time.Time, monthly bool) time.Duration {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	if monthly {
+		next = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return next.Sub(now)
+}
+
+// perSecond converts a per minute limit into the refill rate of a token bucket.
+func perSecond(perMinute int64) float64 {
+	return float64(perMinute) / 60
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package gateway
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -26,23 +29,55 @@ import (
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/guardrail"
 	"github.com/vllm-project/aibrix/pkg/types"
 	"github.com/vllm-project/aibrix/pkg/utils"
 )
 
-func (s *Server) HandleRequestBody(ctx context.Context, requestID string, requestPath string, req *extProcPb.ProcessingRequest,
-	user utils.User, routingAlgorithm types.RoutingAlgorithm) (*extProcPb.ProcessingResponse, string, *types.RoutingContext, bool, int64) {
+func (s *Server) HandleRequestBody(ctx context.Context, srv extProcPb.ExternalProcessor_ProcessServer, requestID string, requestPath string,
+	req *extProcPb.ProcessingRequest, user utils.User, requestHeaders []*configPb.HeaderValue, routingAlgorithm types.RoutingAlgorithm) (*extProcPb.ProcessingResponse, string, *types.RoutingContext, bool, int64) {
 	var routingCtx *types.RoutingContext
 	var term int64 // Identify the trace window
+	var err error
 
 	body := req.Request.(*extProcPb.ProcessingRequest_RequestBody)
-	model, message, stream, errRes := validateRequestBody(requestID, requestPath, body.RequestBody.GetBody(), user)
+
+	// Requests to a model alias are served by one of its backend models, the alias is resolved before the body is
+	// parsed so that the prompt is rendered by the chat template of the backend. The allowlist applies to the alias.
+	// Multipart audio requests are not rewritten.
+	requestBody := body.RequestBody.GetBody()
+	bodyMutated := false
+	var alias string
+	if !strings.HasPrefix(requestPath, PathAudioPrefix) {
+		if requestBody, alias, err = resolveRequestModelAlias(requestBody, user, requestHeaders); err != nil {
+			klog.ErrorS(err, "failed to rewrite model of request", "requestID", requestID, "alias", alias)
+			return generateErrorResponse(envoyTypePb.StatusCode_BadRequest,
+				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+					Key: HeaderErrorRequestBodyProcessing, RawValue: []byte("true")}}},
+				"error processing request body"), alias, routingCtx, false, term
+		}
+		bodyMutated = alias != ""
+	}
+
+	model, message, stream, errRes := validateRequestBody(requestID, requestPath, requestBody, user)
 	if errRes != nil {
 		return errRes, model, routingCtx, stream, term
 	}
+	requestedModel := model
+	if alias != "" {
+		klog.V(4).InfoS("model alias resolved", "requestID", requestID, "alias", alias, "model", model)
+		requestedModel = alias
+	}
+	if !user.AllowsModel(requestedModel) {
+		klog.ErrorS(nil, "model is not allowed for user", "requestID", requestID, "username", user.Name, "model", requestedModel)
+		return generateErrorResponse(envoyTypePb.StatusCode_Forbidden,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorModelNotAllowed, RawValue: []byte(requestedModel)}}},
+			fmt.Sprintf("model %s is not allowed for user %s", requestedModel, user.Name)), model, routingCtx, stream, term
+	}
 
-	// early reject the request if model doesn't exist.
-	if !s.cache.HasModel(model) {
+	// early reject the request if model doesn't exist, autoscaled model adapters are loaded on no pod while idle.
+	if !s.cache.HasModel(model) && !s.isAutoscaledAdapter(model) {
 		klog.ErrorS(nil, "model doesn't exist in cache, probably wrong model name", "requestID", requestID, "model", model)
 		return generateErrorResponse(envoyTypePb.StatusCode_BadRequest,
 			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
@@ -50,6 +85,36 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, reques
 			fmt.Sprintf("model %s does not exist", model)), model, routingCtx, stream, term
 	}
 
+	// Guardrails check the prompt before it reaches the engine, redactions rewrite the body sent upstream.
+	var guardrailFindings []guardrail.Finding
+	if !strings.HasPrefix(requestPath, PathAudioPrefix) && s.guardrail.Enabled(guardrail.StageRequest, model, user.Name) {
+		guardedBody, findings, errRes := s.guardRequestBody(ctx, requestID, model, user, requestBody)
+		if errRes != nil {
+			return errRes, model, routingCtx, stream, term
+		}
+		if !bytes.Equal(guardedBody, requestBody) {
+			requestBody, bodyMutated = guardedBody, true
+		}
+		guardrailFindings = findings
+	}
+
+	// Deterministic requests are answered from the response cache, even if no pod of the model is ready.
+	cacheKey, cacheable := s.responseCacheKey(user, requestPath, model, requestBody)
+	if cacheable {
+		if cachedResp := s.serveCachedResponse(ctx, requestID, user, model, cacheKey); cachedResp != nil {
+			return cachedResp, model, routingCtx, stream, term
+		}
+	}
+
+	// Idle autoscaled model adapters are loaded on demand before the request is routed.
+	if !s.awaitModelAdapter(ctx, srv, requestID, model) {
+		klog.ErrorS(nil, "model adapter is not loaded in time", "requestID", requestID, "model", model, "timeout", adapterLoadTimeout)
+		return generateErrorResponse(envoyTypePb.StatusCode_ServiceUnavailable,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorNoModelBackends, RawValue: []byte("true")}}},
+			fmt.Sprintf("model adapter %s is not loaded yet", model)), model, routingCtx, stream, term
+	}
+
 	// early reject if no pods are ready to accept request for a model
 	podsArr, err := s.cache.ListPodsByModel(model)
 	if err != nil || podsArr == nil || podsArr.Len() == 0 || utils.CountRoutablePods(podsArr.All()) == 0 {
@@ -60,7 +125,21 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, reques
 			fmt.Sprintf("error on getting pods for model %s", model)), model, routingCtx, stream, term
 	}
 
+	// Hold the request while all pods are saturated, so that it queues in the gateway by priority instead of in the engine.
+	if errRes := s.admitRequest(ctx, srv, requestID, model, getPriorityClass(user, requestHeaders)); errRes != nil {
+		return errRes, model, routingCtx, stream, term
+	}
+
+	// The routing-strategy header takes precedence over the RoutingPolicy of the model, which overrides ROUTING_ALGORITHM.
+	if !hasRoutingStrategyHeader(requestHeaders) {
+		if policyAlgorithm, ok := routing.PolicyAlgorithm(model); ok {
+			routingAlgorithm = policyAlgorithm
+		}
+	}
+
 	routingCtx = types.NewRoutingContext(ctx, routingAlgorithm, model, message, requestID, user.Name)
+	// session-affinity may also run as a fallback of a RoutingPolicy or a composite step.
+	routingCtx.SessionID = getSessionID(requestHeaders, requestBody)
 	headers := []*configPb.HeaderValueOption{}
 	if routingAlgorithm == routing.RouterNotSet {
 		headers = buildEnvoyProxyHeaders(headers, HeaderModel, model)
@@ -78,10 +157,56 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, reques
 		headers = buildEnvoyProxyHeaders(headers,
 			HeaderRoutingStrategy, string(routingAlgorithm),
 			HeaderTargetPod, targetPodIP)
+		if routingCtx.PrefillPod() != nil {
+			pdHeaders, pdBody, err := disaggregatedRequest(routingCtx, requestBody)
+			if err != nil {
+				klog.ErrorS(err, "failed to disaggregate request", "requestID", requestID, "model", model)
+				return generateErrorResponse(
+					envoyTypePb.StatusCode_InternalServerError,
+					[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+						Key: HeaderErrorRequestBodyProcessing, RawValue: []byte("true")}}},
+					"error on disaggregating request"), model, routingCtx, stream, term
+			}
+			headers = buildEnvoyProxyHeaders(headers, pdHeaders...)
+			if pdBody != nil {
+				requestBody, bodyMutated = pdBody, true
+			}
+		}
 		klog.InfoS("request start", "requestID", requestID, "requestPath", requestPath, "model", model, "stream", stream, "routingAlgorithm", routingAlgorithm, "targetPodIP", targetPodIP)
 	}
 
+	if len(guardrailFindings) > 0 {
+		headers = buildEnvoyProxyHeaders(headers, HeaderGuardrail, findingsHeader(guardrailFindings))
+	}
+
+	if stream {
+		state := &streamUsage{routedAt: time.Now()}
+		if requestPath == PathChatCompletions && streamUsageInjectionEnabled {
+			// Ask the engine for the usage so that the tokens are accounted, the usage chunk is stripped from the response
+			// if the client did not ask for it.
+			if injectedBody, injected, err := injectStreamUsage(requestBody); err != nil {
+				klog.ErrorS(err, "failed to inject stream usage option", "requestID", requestID)
+			} else if injected {
+				requestBody, bodyMutated = injectedBody, true
+				state.stripUsage = true
+			}
+		}
+		streamUsages.Store(requestID, state)
+	} else {
+		routedAts.Store(requestID, time.Now())
+	}
+
+	var bodyMutation *extProcPb.BodyMutation
+	if bodyMutated {
+		bodyMutation = &extProcPb.BodyMutation{Mutation: &extProcPb.BodyMutation_Body{Body: requestBody}}
+	}
+
 	term = s.cache.AddRequestCount(routingCtx, requestID, model)
+	s.startPrefill(routingCtx, requestPath, requestHeaders, requestBody)
+	s.mirrorRequest(routingCtx, requestPath, requestHeaders, requestBody, stream)
+	if cacheable {
+		captureResponse(requestID, cacheKey, stream)
+	}
 
 	return &extProcPb.ProcessingResponse{
 		Response: &extProcPb.ProcessingResponse_RequestBody{
@@ -90,6 +215,7 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, reques
 					HeaderMutation: &extProcPb.HeaderMutation{
 						SetHeaders: headers,
 					},
+					BodyMutation: bodyMutation,
 				},
 			},
 		},
@@ -31,7 +31,7 @@ import (
 )
 
 func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest) (*extProcPb.ProcessingResponse, utils.User, int64, types.RoutingAlgorithm, string) {
-	var username, requestPath string
+	var username, requestPath, method string
 	var user utils.User
 	var rpm int64
 	var err error
@@ -49,6 +49,9 @@ func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req
 		if strings.ToLower(n.Key) == ":path" {
 			requestPath = string(n.RawValue)
 		}
+		if strings.ToLower(n.Key) == ":method" {
+			method = string(n.RawValue)
+		}
 	}
 
 	routingStrategy, routingStrategyEnabled := getRoutingStrategy(h.RequestHeaders.Headers.Headers)
@@ -62,7 +65,12 @@ func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req
 			}}}, "incorrect routing strategy"), utils.User{}, rpm, routingAlgorithm, requestPath
 	}
 
-	if username != "" {
+	if apiKeyAuthEnabled {
+		user, errRes = s.authenticate(ctx, requestID, h.RequestHeaders.Headers.Headers)
+		if errRes != nil {
+			return errRes, utils.User{}, rpm, routingAlgorithm, requestPath
+		}
+	} else if username != "" {
 		user, err = utils.GetUser(ctx, utils.User{Name: username}, s.redisClient)
 		if err != nil {
 			klog.ErrorS(err, "unable to process user info", "requestID", requestID, "username", username)
@@ -73,14 +81,27 @@ func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req
 				}}},
 				err.Error()), utils.User{}, rpm, routingAlgorithm, requestPath
 		}
+	}
 
+	// Listing models is answered by the gateway and not rate limited, so no limit of the user is to be released.
+	if isModelsRequestPath(requestPath) {
+		return s.handleModelsRequest(requestID, requestPath, method, user), utils.User{}, rpm, routingAlgorithm, requestPath
+	}
+
+	if user.Name != "" {
 		rpm, errRes, err = s.checkLimits(ctx, user)
 		if errRes != nil {
-			klog.ErrorS(err, "error on checking limits", "requestID", requestID, "username", username)
+			klog.ErrorS(err, "error on checking limits", "requestID", requestID, "username", user.Name)
 			return errRes, utils.User{}, rpm, routingAlgorithm, requestPath
 		}
 	}
 
+	var removeHeaders []string
+	if apiKeyAuthEnabled {
+		// The api key is for the gateway only, do not leak it to the model.
+		removeHeaders = append(removeHeaders, headerAuthorization)
+	}
+
 	return &extProcPb.ProcessingResponse{
 		Response: &extProcPb.ProcessingResponse_RequestHeaders{
 			RequestHeaders: &extProcPb.HeadersResponse{
@@ -94,6 +115,7 @@ func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req
 								},
 							},
 						},
+						RemoveHeaders: removeHeaders,
 					},
 					ClearRouteCache: true,
 				},
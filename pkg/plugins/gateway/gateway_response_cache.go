@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	responseCacheStoreMemory = "memory"
+	responseCacheStoreRedis  = "redis"
+
+	// responseCacheStoreTimeout bounds the store operations, a slow store degrades to cache misses.
+	responseCacheStoreTimeout = time.Second
+
+	responseCacheHitHeader = "hit"
+)
+
+var (
+	// responseCacheStore is the store of the response cache, either memory or redis, empty disables the cache.
+	responseCacheStore    = utils.LoadEnv("AIBRIX_RESPONSE_CACHE_STORE", "")
+	responseCacheTTL      = time.Duration(utils.LoadEnvInt("AIBRIX_RESPONSE_CACHE_TTL_SECONDS", 600)) * time.Second
+	responseCacheCapacity = utils.LoadEnvInt("AIBRIX_RESPONSE_CACHE_CAPACITY", 10000)
+	// responseCacheMaxBodyBytes skips caching larger responses.
+	responseCacheMaxBodyBytes = utils.LoadEnvInt("AIBRIX_RESPONSE_CACHE_MAX_BODY_BYTES", 1<<20)
+	// responseCacheTPMDiscount is the fraction of the tokens of a cached response not charged to the TPM of the user.
+	responseCacheTPMDiscount = utils.LoadEnvFloat("AIBRIX_RESPONSE_CACHE_TPM_DISCOUNT", 0.5)
+	// responseCacheAnonymous enables the response cache for requests without a user, users opt in individually.
+	responseCacheAnonymous = utils.LoadEnvBool("AIBRIX_RESPONSE_CACHE_ANONYMOUS", false)
+
+	// responseCaptures tracks the responseCapture of requests missing the cache by request ID.
+	responseCaptures sync.Map
+	// responseCacheHits tracks the cached response served to a request by request ID, until it is recorded to the
+	// usage event of the request.
+	responseCacheHits sync.Map
+)
+
+// responseCapture buffers the response of a request missing the cache, the response is cached once the request
+// ends if it succeeded.
+type responseCapture struct {
+	key      string
+	stream   bool
+	body     bytes.Buffer // The response body sent to the client.
+	ended    bool
+	oversize bool
+}
+
+func newResponseCache(redisClient *redis.Client) responsecache.Store {
+	switch responseCacheStore {
+	case "":
+		return nil
+	case responseCacheStoreMemory:
+		klog.InfoS("response cache enabled", "store", responseCacheStore, "capacity", responseCacheCapacity, "ttl", responseCacheTTL)
+		return responsecache.NewMemoryStore(responseCacheCapacity, responseCacheTTL)
+	case responseCacheStoreRedis:
+		if redisClient == nil {
+			klog.ErrorS(nil, "redis client is required by the response cache", "store", responseCacheStore)
+			return nil
+		}
+		klog.InfoS("response cache enabled", "store", responseCacheStore, "ttl", responseCacheTTL)
+		return responsecache.NewRedisStore("aibrix", redisClient, responseCacheTTL)
+	default:
+		klog.ErrorS(nil, "unknown response cache store, the response cache is disabled", "store", responseCacheStore)
+		return nil
+	}
+}
+
+// responseCacheKey returns the cache key of a request, false if the response of the request can't be cached.
+// Completions are cached for deterministic requests of users opted in to the cache, the cache of a user is not shared.
+func (s *Server) responseCacheKey(user utils.User, requestPath, model string, body []byte) (string, bool) {
+	if s.responseCache == nil || (requestPath != PathChatCompletions && requestPath != PathCompletions) {
+		return "", false
+	}
+	if !user.ResponseCache && (user.Name != "" || !responseCacheAnonymous) {
+		return "", false
+	}
+	return responsecache.Key(user.Name, model, body)
+}
+
+// serveCachedResponse returns an immediate response replaying the cached response of a request, nil on cache miss.
+// The tokens of the cached response are charged to the user at the configured discount.
+func (s *Server) serveCachedResponse(ctx context.Context, requestID string, user utils.User, model, key string) *extProcPb.ProcessingResponse {
+	storeCtx, cancel := context.WithTimeout(ctx, responseCacheStoreTimeout)
+	defer cancel()
+	response, ok, err := s.responseCache.Get(storeCtx, key)
+	if err != nil {
+		klog.ErrorS(err, "failed to get cached response", "requestID", requestID, "model", model)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	contentType := "application/json"
+	if response.Stream {
+		contentType = "text/event-stream"
+	}
+	headers := buildEnvoyProxyHeaders([]*configPb.HeaderValueOption{},
+		"Content-Type", contentType,
+		HeaderResponseCache, responseCacheHitHeader,
+		HeaderRequestID, requestID)
+	if tokens := discountedTokens(response.PromptTokens + response.CompletionTokens); user.Name != "" && tokens > 0 {
+		if tpm, err := s.chargeTPM(ctx, user, tokens); err != nil {
+			klog.ErrorS(err, "failed to increment TPM for cached response", "requestID", requestID)
+		} else {
+			headers = buildEnvoyProxyHeaders(headers, HeaderUpdateTPM, fmt.Sprintf("%d", tpm))
+		}
+	}
+	responseCacheHits.Store(requestID, response)
+	klog.InfoS("request end", "requestID", requestID, "model", model, "responseCache", responseCacheHitHeader)
+
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extProcPb.ImmediateResponse{
+				Status: &envoyTypePb.HttpStatus{
+					Code: envoyTypePb.StatusCode_OK,
+				},
+				Headers: &extProcPb.HeaderMutation{
+					SetHeaders: headers,
+				},
+				Body: string(response.Body),
+			},
+		},
+	}
+}
+
+// discountedTokens returns the tokens charged for a cached response.
+func discountedTokens(tokens int64) int64 {
+	discount := min(max(responseCacheTPMDiscount, 0), 1)
+	return int64(math.Round(float64(tokens) * (1 - discount)))
+}
+
+// recordResponseCacheHit records the tokens of the cached response served to a request to its usage event.
+func recordResponseCacheHit(event *usagelog.Event, requestID string) {
+	value, ok := responseCacheHits.LoadAndDelete(requestID)
+	if !ok {
+		return
+	}
+	response := value.(*responsecache.Response)
+	event.CacheHit = true
+	event.PromptTokens, event.CompletionTokens = response.PromptTokens, response.CompletionTokens
+}
+
+// captureResponse starts buffering the response of a request missing the cache.
+func captureResponse(requestID, key string, stream bool) {
+	responseCaptures.Store(requestID, &responseCapture{key: key, stream: stream})
+}
+
+// recordResponseCapture buffers a response body chunk sent to the client of a request missing the cache.
+func recordResponseCapture(requestID string, chunk []byte, end bool) {
+	value, ok := responseCaptures.Load(requestID)
+	if !ok {
+		return
+	}
+	// A request is processed by a single goroutine.
+	capture := value.(*responseCapture)
+	if capture.oversize {
+		return
+	}
+	if capture.body.Len()+len(chunk) > responseCacheMaxBodyBytes {
+		capture.oversize = true
+		capture.body = bytes.Buffer{}
+		return
+	}
+	capture.body.Write(chunk)
+	capture.ended = capture.ended || end
+}
+
+// doneResponseCapture caches the response of a request missing the cache once the request ends, if the complete
+// response was sent to the client successfully.
+func (s *Server) doneResponseCapture(requestID string, event *usagelog.Event) {
+	value, ok := responseCaptures.LoadAndDelete(requestID)
+	if !ok {
+		return
+	}
+	capture := value.(*responseCapture)
+	if !capture.ended || capture.oversize || event.StatusCode != http.StatusOK || event.ErrorHeader != "" ||
+		event.CompletionTokens == 0 {
+		return
+	}
+
+	// The stream context is done by now.
+	ctx, cancel := context.WithTimeout(context.Background(), responseCacheStoreTimeout)
+	defer cancel()
+	if err := s.responseCache.Put(ctx, capture.key, &responsecache.Response{
+		Body:             capture.body.Bytes(),
+		Stream:           capture.stream,
+		PromptTokens:     event.PromptTokens,
+		CompletionTokens: event.CompletionTokens,
+	}); err != nil {
+		klog.ErrorS(err, "failed to cache response", "requestID", requestID)
+	}
+}
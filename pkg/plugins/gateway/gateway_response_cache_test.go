@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const testDeterministicBody = `{"model": "m", "temperature": 0, "messages": [{"role": "user", "content": "hi"}], "stream": true}`
+
+func Test_responseCacheKey(t *testing.T) {
+	s := &Server{}
+	_, ok := s.responseCacheKey(utils.User{Name: "u1", ResponseCache: true}, PathChatCompletions, "m", []byte(testDeterministicBody))
+	assert.False(t, ok, "the response cache is disabled")
+
+	s.responseCache = responsecache.NewMemoryStore(10, time.Minute)
+	_, ok = s.responseCacheKey(utils.User{Name: "u1", ResponseCache: true}, PathChatCompletions, "m", []byte(testDeterministicBody))
+	assert.True(t, ok)
+	_, ok = s.responseCacheKey(utils.User{Name: "u1"}, PathChatCompletions, "m", []byte(testDeterministicBody))
+	assert.False(t, ok, "the user did not opt in")
+	_, ok = s.responseCacheKey(utils.User{}, PathChatCompletions, "m", []byte(testDeterministicBody))
+	assert.False(t, ok, "anonymous requests are not cached by default")
+	_, ok = s.responseCacheKey(utils.User{Name: "u1", ResponseCache: true}, PathEmbeddings, "m", []byte(testDeterministicBody))
+	assert.False(t, ok, "only completions are cached")
+}
+
+func Test_responseCache(t *testing.T) {
+	s := &Server{responseCache: responsecache.NewMemoryStore(10, time.Minute)}
+	key, ok := s.responseCacheKey(utils.User{Name: "u1", ResponseCache: true}, PathChatCompletions, "m", []byte(testDeterministicBody))
+	assert.True(t, ok)
+	assert.Nil(t, s.serveCachedResponse(context.Background(), "r1", utils.User{}, "m", key))
+
+	// Incomplete and failed responses are not cached.
+	captureResponse("r1", key, true)
+	recordResponseCapture("r1", []byte(testStreamChunk1), false)
+	s.doneResponseCapture("r1", &usagelog.Event{StatusCode: 200, PromptTokens: 3, CompletionTokens: 1})
+	captureResponse("r2", key, true)
+	recordResponseCapture("r2", []byte(testStreamChunk1+testStreamDone), true)
+	s.doneResponseCapture("r2", &usagelog.Event{StatusCode: 500})
+	assert.Nil(t, s.serveCachedResponse(context.Background(), "r3", utils.User{}, "m", key))
+
+	// The response sent to the client is replayed with its content type.
+	captureResponse("r4", key, true)
+	recordResponseCapture("r4", []byte(testStreamChunk1), false)
+	recordResponseCapture("r4", []byte(testStreamChunk2+testStreamDone), true)
+	s.doneResponseCapture("r4", &usagelog.Event{StatusCode: 200, PromptTokens: 3, CompletionTokens: 4})
+	_, ok = responseCaptures.Load("r4")
+	assert.False(t, ok)
+
+	resp := s.serveCachedResponse(context.Background(), "r5", utils.User{}, "m", key)
+	assert.NotNil(t, resp)
+	immediateResponse := resp.GetImmediateResponse()
+	assert.Equal(t, 200, int(immediateResponse.GetStatus().GetCode()))
+	assert.Equal(t, testStreamChunk1+testStreamChunk2+testStreamDone, immediateResponse.GetBody())
+	headers := map[string]string{}
+	for _, header := range immediateResponse.GetHeaders().GetSetHeaders() {
+		headers[header.GetHeader().GetKey()] = string(header.GetHeader().GetRawValue())
+	}
+	assert.Equal(t, "text/event-stream", headers["Content-Type"])
+	assert.Equal(t, "hit", headers[HeaderResponseCache])
+
+	event := &usagelog.Event{}
+	recordResponseCacheHit(event, "r5")
+	assert.Equal(t, usagelog.Event{CacheHit: true, PromptTokens: 3, CompletionTokens: 4}, *event)
+}
+
+func Test_discountedTokens(t *testing.T) {
+	defer func(discount float64) { responseCacheTPMDiscount = discount }(responseCacheTPMDiscount)
+	responseCacheTPMDiscount = 0.5
+	assert.Equal(t, int64(50), discountedTokens(100))
+	responseCacheTPMDiscount = 1
+	assert.Equal(t, int64(0), discountedTokens(100))
+	responseCacheTPMDiscount = -1
+	assert.Equal(t, int64(100), discountedTokens(100))
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go"
+	"google.golang.org/protobuf/types/known/durationpb"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/guardrail"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultRetryMaxAttempts                  = 0
+	defaultRetryTimeoutInSeconds             = 120
+	defaultRetryMaxResponseBytes             = 16 << 20
+	defaultUnhealthyPodCooldownInSeconds     = 10
+	retryableResponseStatusCodeLowerBoundary = 500
+)
+
+var (
+	// retryMaxAttempts is the retry budget of a request failed on its selected pod, 0 disables the retry.
+	retryMaxAttempts = utils.LoadEnvInt("AIBRIX_GATEWAY_RETRY_MAX_ATTEMPTS", defaultRetryMaxAttempts)
+	// retryTimeout bounds each retried request, it is also used to extend the ext_proc message timeout.
+	retryTimeout = time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_RETRY_TIMEOUT_SECONDS", defaultRetryTimeoutInSeconds)) * time.Second
+	// retryMaxResponseBytes bounds the response of a retried request, which is buffered to be sent as an immediate response.
+	retryMaxResponseBytes = utils.LoadEnvInt("AIBRIX_GATEWAY_RETRY_MAX_RESPONSE_BYTES", defaultRetryMaxResponseBytes)
+	// unhealthyPodCooldown is how long a failed pod is excluded from routing.
+	unhealthyPodCooldown = time.Duration(utils.LoadEnvInt("AIBRIX_GATEWAY_UNHEALTHY_POD_COOLDOWN_SECONDS", defaultUnhealthyPodCooldownInSeconds)) * time.Second
+
+	errRetryResponseTooLarge = errors.New("response of retried request is too large")
+)
+
+// podHealthTracker excludes failed pods from routing for a cooldown period.
+type podHealthTracker interface {
+	MarkPodUnhealthy(podName, podNamespace string, cooldown time.Duration)
+	IsPodHealthy(podName, podNamespace string) bool
+}
+
+var _ podHealthTracker = (*cache.Store)(nil)
+
+// retryRequest is a snapshot of a routed request, which is enough to replay it on another pod.
+// It is taken before the routing context is released on response error.
+type retryRequest struct {
+	requestID   string
+	requestPath string
+	headers     []*configPb.HeaderValue
+	body        []byte
+	user        utils.User
+	rpm         int64
+	algorithm   types.RoutingAlgorithm
+	model       string
+	message     string
+	sessionID   string
+	stream      bool
+	failedPod   *v1.Pod
+}
+
+// newRetryRequest returns nil if the request was not routed to a specific pod by the gateway.
+func newRetryRequest(routerCtx *types.RoutingContext, requestPath string, headers []*configPb.HeaderValue, body []byte,
+	user utils.User, rpm int64, stream bool) *retryRequest {
+	// A disaggregated request is not retried, its prefill pod would be lost.
+	if routerCtx == nil || !routerCtx.HasRouted() || routerCtx.PrefillPod() != nil {
+		return nil
+	}
+	return &retryRequest{
+		requestID:   routerCtx.RequestID,
+		requestPath: requestPath,
+		headers:     headers,
+		body:        body,
+		user:        user,
+		rpm:         rpm,
+		algorithm:   routerCtx.Algorithm,
+		model:       routerCtx.Model,
+		message:     routerCtx.Message,
+		sessionID:   routerCtx.SessionID,
+		stream:      stream,
+		failedPod:   routerCtx.TargetPod(),
+	}
+}
+
+// markPodUnhealthy excludes a failed pod from routing for unhealthyPodCooldown, if the cache tracks pod health.
+func (s *Server) markPodUnhealthy(pod *v1.Pod) {
+	if tracker, ok := s.cache.(podHealthTracker); ok && unhealthyPodCooldown > 0 {
+		tracker.MarkPodUnhealthy(pod.Name, pod.Namespace, unhealthyPodCooldown)
+	}
+}
+
+// retryOnAnotherPod marks the failed pod unhealthy and replays the request on other routable pods within the retry budget.
+// The upstream response of a successful retry is returned as an immediate response, and recorded to the usage event.
+// Streaming requests are not retried, their response would be buffered and sent at once instead of streamed.
+func (s *Server) retryOnAnotherPod(srv extProcPb.ExternalProcessor_ProcessServer, retry *retryRequest, respErrorCode int, event *usagelog.Event) (*extProcPb.ProcessingResponse, bool) {
+	if retry == nil || respErrorCode < retryableResponseStatusCodeLowerBoundary {
+		return nil, false
+	}
+	s.markPodUnhealthy(retry.failedPod)
+	if retryMaxAttempts <= 0 || retry.stream {
+		return nil, false
+	}
+
+	// The retried request is served while envoy waits for the response headers message, extend its timeout up to the
+	// max_message_timeout of the ext_proc filter.
+	if err := srv.Send(&extProcPb.ProcessingResponse{OverrideMessageTimeout: durationpb.New(retryTimeout)}); err != nil {
+		klog.ErrorS(err, "failed to extend message timeout for retry", "requestID", retry.requestID)
+		return nil, false
+	}
+
+	excludedPods := map[string]struct{}{
+		utils.GeneratePodKey(retry.failedPod.Namespace, retry.failedPod.Name): {},
+	}
+	for attempt := 1; attempt <= retryMaxAttempts; attempt++ {
+		podsArr, err := s.cache.ListPodsByModel(retry.model)
+		if err != nil || podsArr == nil {
+			klog.ErrorS(err, "no pods to retry request", "requestID", retry.requestID, "model", retry.model)
+			return nil, false
+		}
+		candidates := utils.ExcludePods(podsArr.All(), excludedPods)
+		if utils.CountRoutablePods(candidates) == 0 {
+			klog.InfoS("no routable pod left to retry request", "requestID", retry.requestID, "model", retry.model, "attempt", attempt)
+			return nil, false
+		}
+
+		routingCtx := types.NewRoutingContext(srv.Context(), retry.algorithm, retry.model, retry.message, retry.requestID, retry.user.Name)
+		routingCtx.SessionID = retry.sessionID
+		if _, err := s.selectTargetPod(routingCtx, &utils.PodArray{Pods: candidates}); err != nil {
+			klog.ErrorS(err, "failed to select target pod for retry", "requestID", retry.requestID, "model", retry.model, "attempt", attempt)
+			routingCtx.Delete()
+			return nil, false
+		}
+		targetPod := routingCtx.TargetPod()
+		traceTerm := s.cache.AddRequestCount(routingCtx, retry.requestID, retry.model)
+
+		statusCode, contentType, body, err := s.forwardRequest(routingCtx, retry)
+		if errors.Is(err, errRetryResponseTooLarge) {
+			// The pod served the request, its response can't be sent as an immediate response.
+			klog.ErrorS(err, "retried request is dropped", "requestID", retry.requestID, "targetPod", targetPod.Name, "maxResponseBytes", retryMaxResponseBytes)
+			s.cache.DoneRequestCount(routingCtx, retry.requestID, retry.model, traceTerm)
+			routingCtx.Delete()
+			return nil, false
+		}
+		if err != nil || statusCode >= retryableResponseStatusCodeLowerBoundary {
+			klog.ErrorS(err, "retried request failed", "requestID", retry.requestID, "targetPod", targetPod.Name, "statusCode", statusCode, "attempt", attempt)
+			s.cache.DoneRequestCount(routingCtx, retry.requestID, retry.model, traceTerm)
+			routingCtx.Delete()
+			s.markPodUnhealthy(targetPod)
+			excludedPods[utils.GeneratePodKey(targetPod.Namespace, targetPod.Name)] = struct{}{}
+			continue
+		}
+
+		usage := getResponseUsage(body)
+		s.cache.DoneRequestTrace(routingCtx, retry.requestID, retry.model, usage.PromptTokens, usage.CompletionTokens, traceTerm)
+		recordRouting(event, retry.model, routingCtx)
+		event.PromptTokens, event.CompletionTokens = usage.PromptTokens, usage.CompletionTokens
+		headers := buildEnvoyProxyHeaders([]*configPb.HeaderValueOption{},
+			"Content-Type", contentType,
+			HeaderTargetPod, routingCtx.TargetAddress(),
+			HeaderRequestID, retry.requestID,
+			HeaderRetryAttempts, fmt.Sprintf("%d", attempt))
+		routingCtx.Delete()
+
+		if retry.user.Name != "" && usage.TotalTokens != 0 {
+			// The pre-charge of the request is refunded once the request ends, charge the full usage here.
+			tpm, err := s.chargeTPM(srv.Context(), retry.user, usage.TotalTokens)
+			if err != nil {
+				klog.ErrorS(err, "failed to increment TPM for retried request", "requestID", retry.requestID)
+			} else {
+				headers = buildEnvoyProxyHeaders(headers,
+					HeaderUpdateRPM, fmt.Sprintf("%d", retry.rpm),
+					HeaderUpdateTPM, fmt.Sprintf("%d", tpm))
+				headers = buildEnvoyProxyHeaders(headers, rateLimitHeaders(retry.user, retry.rpm, tpm, time.Now())...)
+			}
+		}
+
+		if statusCode == http.StatusOK && !isAudioRequestPath(retry.requestPath) &&
+			s.guardrail.Enabled(guardrail.StageResponse, retry.model, retry.user.Name) {
+			guardedBody, findings, errRes := s.guardResponseBody(srv.Context(), retry.requestID, retry.model, retry.user, false, body)
+			if errRes != nil {
+				return errRes, true
+			}
+			body = guardedBody
+			if len(findings) > 0 {
+				headers = buildEnvoyProxyHeaders(headers, HeaderGuardrail, findingsHeader(findings))
+			}
+		}
+
+		klog.InfoS("request end", "requestID", retry.requestID, "targetPod", targetPod.Name, "retryAttempts", attempt)
+		return &extProcPb.ProcessingResponse{
+			Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+				ImmediateResponse: &extProcPb.ImmediateResponse{
+					Status: &envoyTypePb.HttpStatus{
+						Code: envoyTypePb.StatusCode(statusCode),
+					},
+					Headers: &extProcPb.HeaderMutation{
+						SetHeaders: headers,
+					},
+					Body: string(body),
+				},
+			},
+		}, true
+	}
+	return nil, false
+}
+
+// forwardRequest sends the original request to the target pod of the routing context and reads the whole response, up to
+// retryMaxResponseBytes.
+func (s *Server) forwardRequest(ctx *types.RoutingContext, retry *retryRequest) (int, string, []byte, error) {
+	method := http.MethodPost
+	for _, header := range retry.headers {
+		if header.Key == ":method" {
+			method = string(header.RawValue)
+		}
+	}
+
+	url := fmt.Sprintf("http://%s%s", ctx.TargetAddress(), retry.requestPath)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(retry.body))
+	if err != nil {
+		return 0, "", nil, err
+	}
+	for _, header := range retry.headers {
+		// Skip pseudo headers and headers recalculated by the http client.
+		if strings.HasPrefix(header.Key, ":") || strings.EqualFold(header.Key, "content-length") || strings.EqualFold(header.Key, "host") {
+			continue
+		}
+		// Same as the request forwarded by envoy, the gateway api key is not sent to the model.
+		if apiKeyAuthEnabled && strings.EqualFold(header.Key, headerAuthorization) {
+			continue
+		}
+		req.Header.Add(header.Key, string(header.RawValue))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(retryMaxResponseBytes)+1))
+	if err != nil {
+		return resp.StatusCode, "", nil, err
+	}
+	if len(body) > retryMaxResponseBytes {
+		return resp.StatusCode, "", nil, errRetryResponseTooLarge
+	}
+	return resp.StatusCode, resp.Header.Get("Content-Type"), body, nil
+}
+
+// getResponseUsage returns token usage of a non-streaming response body, usage is empty if it can't be parsed.
+func getResponseUsage(body []byte) openai.CompletionUsage {
+	var res openai.ChatCompletion
+	if err := json.Unmarshal(body, &res); err != nil {
+		return openai.CompletionUsage{}
+	}
+	return res.Usage
+}
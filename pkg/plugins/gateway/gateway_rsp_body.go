@@ -21,21 +21,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/packages/ssestream"
 	"k8s.io/klog/v2"
 
 	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/guardrail"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
 	"github.com/vllm-project/aibrix/pkg/types"
 	"github.com/vllm-project/aibrix/pkg/utils"
 )
 
-func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest, user utils.User, rpm int64, model string, stream bool, traceTerm int64, hasCompleted bool) (*extProcPb.ProcessingResponse, bool) {
+func (s *Server) HandleResponseBody(ctx context.Context, requestID string, requestPath string, req *extProcPb.ProcessingRequest, user utils.User, rpm, preChargedTokens int64, model string, stream bool, traceTerm int64, hasCompleted bool, event *usagelog.Event) (*extProcPb.ProcessingResponse, bool) {
 	b := req.Request.(*extProcPb.ProcessingRequest_ResponseBody)
 
 	var res openai.ChatCompletion
@@ -49,29 +48,24 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		// Wrapped in a function to delay the evaluation of parameters. Using complete to make sure DoneRequestTrace only call once for a request.
 		if !hasCompleted && complete {
 			s.cache.DoneRequestTrace(routerCtx, requestID, model, promptTokens, completionTokens, traceTerm)
+			event.PromptTokens, event.CompletionTokens = promptTokens, completionTokens
 			if routerCtx != nil {
 				routerCtx.Delete()
 			}
 		}
 	}()
 
+	var bodyMutation *extProcPb.BodyMutation
+	// Guardrail findings are reported in a header, a response blocked by a guardrail is replaced by guardrailResp.
+	var guardrailFindings []guardrail.Finding
+	var guardrailResp *extProcPb.ProcessingResponse
+	guarded := !isAudioRequestPath(requestPath) && s.guardrail.Enabled(guardrail.StageResponse, model, user.Name)
 	if stream {
-		t := &http.Response{
-			Body: io.NopCloser(bytes.NewReader(b.ResponseBody.GetBody())),
-		}
-		streaming := ssestream.NewStream[openai.ChatCompletionChunk](ssestream.NewDecoder(t), nil)
-		defer func() {
-			_ = streaming.Close()
-		}()
-		for streaming.Next() {
-			evt := streaming.Current()
-			if len(evt.Choices) == 0 {
-				// Do not overwrite model, res can be empty.
-				usage = evt.Usage
-			}
-		}
-		if err := streaming.Err(); err != nil {
+		state := loadStreamUsage(requestID)
+		body, err := state.process(b.ResponseBody.GetBody(), b.ResponseBody.EndOfStream)
+		if err != nil {
 			klog.ErrorS(err, "error to unmarshal response", "requestID", requestID, "responseBody", string(b.ResponseBody.GetBody()))
+			streamUsages.Delete(requestID)
 			complete = true
 			return generateErrorResponse(
 				envoyTypePb.StatusCode_InternalServerError,
@@ -80,6 +74,32 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 				}}},
 				err.Error()), complete
 		}
+		if guarded {
+			body, guardrailFindings = s.guardStreamChunk(ctx, requestID, model, user.Name, body, b.ResponseBody.EndOfStream)
+		}
+		if !bytes.Equal(body, b.ResponseBody.GetBody()) {
+			bodyMutation = &extProcPb.BodyMutation{Mutation: &extProcPb.BodyMutation_Body{Body: body}}
+		}
+		if !hasCompleted {
+			usage = state.usage
+		}
+		if b.ResponseBody.EndOfStream {
+			streamUsages.Delete(requestID)
+			if !hasCompleted && usage.TotalTokens == 0 && state.completionTokens > 0 && routerCtx != nil {
+				// The engine did not report the usage, account the estimated tokens instead.
+				usage.PromptTokens = int64(promptTokenEstimator.EstimateInputTokens(routerCtx.Message))
+				usage.CompletionTokens = state.completionTokens
+				usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+			}
+		}
+		if !hasCompleted {
+			// TPOT is observed once the completion tokens are known at the end of the stream.
+			var observedTokens int64
+			if b.ResponseBody.EndOfStream {
+				observedTokens = usage.CompletionTokens
+			}
+			state.observeLatency(s.cache, routerCtx, observedTokens)
+		}
 	} else {
 		// Use request ID as a key to store per-request buffer
 		// Retrieve or create buffer
@@ -90,10 +110,14 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 
 		if !b.ResponseBody.EndOfStream {
 			// Partial data received, wait for more chunks, we just return a common response here.
+			// The chunks are held back if guardrails check the response, which is sent with the last chunk.
+			if guarded {
+				bodyMutation = &extProcPb.BodyMutation{Mutation: &extProcPb.BodyMutation_ClearBody{ClearBody: true}}
+			}
 			return &extProcPb.ProcessingResponse{
 				Response: &extProcPb.ProcessingResponse_ResponseBody{
 					ResponseBody: &extProcPb.BodyResponse{
-						Response: &extProcPb.CommonResponse{},
+						Response: &extProcPb.CommonResponse{BodyMutation: bodyMutation},
 					},
 				},
 			}, complete
@@ -104,7 +128,10 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		// Clean up the buffer after final processing
 		requestBuffers.Delete(requestID)
 
-		if err := json.Unmarshal(finalBody, &res); err != nil {
+		if isAudioRequestPath(requestPath) {
+			// Audio responses can be json, text, srt or vtt formatted and carry neither model nor token usage.
+			klog.V(4).InfoS("audio response received", "requestID", requestID, "responseLength", len(finalBody))
+		} else if err := json.Unmarshal(finalBody, &res); err != nil {
 			klog.ErrorS(err, "error to unmarshal response", "requestID", requestID, "responseBody", string(b.ResponseBody.GetBody()))
 			complete = true
 			return generateErrorResponse(
@@ -130,6 +157,16 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		}
 		// Do not overwrite model, res can be empty.
 		usage = res.Usage
+		if !hasCompleted {
+			observeRequestLatency(s.cache, routerCtx, requestID, usage.CompletionTokens)
+		}
+
+		if guarded {
+			var guardedBody []byte
+			if guardedBody, guardrailFindings, guardrailResp = s.guardResponseBody(ctx, requestID, model, user, false, finalBody); guardrailResp == nil {
+				bodyMutation = &extProcPb.BodyMutation{Mutation: &extProcPb.BodyMutation_Body{Body: guardedBody}}
+			}
+		}
 	}
 
 	var requestEnd string
@@ -138,9 +175,9 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		// Update promptTokens and completeTokens
 		promptTokens = usage.PromptTokens
 		completionTokens = usage.CompletionTokens
-		// Count token per user.
+		// Count token per user, the estimated prompt tokens were pre-charged on routing.
 		if user.Name != "" {
-			tpm, err := s.ratelimiter.Incr(ctx, fmt.Sprintf("%v_TPM_CURRENT", user.Name), res.Usage.TotalTokens)
+			tpm, err := s.chargeTPM(ctx, user, usage.TotalTokens-preChargedTokens)
 			if err != nil {
 				return generateErrorResponse(
 					envoyTypePb.StatusCode_InternalServerError,
@@ -191,6 +228,18 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		complete = true
 	}
 
+	if guardrailResp != nil {
+		return guardrailResp, complete
+	}
+	if len(guardrailFindings) > 0 {
+		headers = append(headers, &configPb.HeaderValueOption{
+			Header: &configPb.HeaderValue{
+				Key:      HeaderGuardrail,
+				RawValue: []byte(findingsHeader(guardrailFindings)),
+			},
+		})
+	}
+
 	return &extProcPb.ProcessingResponse{
 		Response: &extProcPb.ProcessingResponse_ResponseBody{
 			ResponseBody: &extProcPb.BodyResponse{
@@ -198,6 +247,7 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 					HeaderMutation: &extProcPb.HeaderMutation{
 						SetHeaders: headers,
 					},
+					BodyMutation: bodyMutation,
 				},
 			},
 		},
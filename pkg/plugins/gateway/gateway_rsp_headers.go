@@ -19,13 +19,17 @@ package gateway
 import (
 	"context"
 	"strconv"
+	"time"
 
 	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"k8s.io/klog/v2"
 )
 
-func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, model string, req *extProcPb.ProcessingRequest) (*extProcPb.ProcessingResponse, bool, int) {
+func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, model string, req *extProcPb.ProcessingRequest,
+	user utils.User, rpm int64) (*extProcPb.ProcessingResponse, bool, int) {
 	b := req.Request.(*extProcPb.ProcessingRequest_ResponseHeaders)
 	routerCtx, _ := ctx.(*types.RoutingContext)
 
@@ -58,6 +62,14 @@ func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, mo
 		}
 	}
 
+	if !isProcessingError && user.Name != "" {
+		if tpm, err := s.tokensInUse(ctx, user); err != nil {
+			klog.ErrorS(err, "failed to get TPM for rate limit headers", "requestID", requestID, "username", user.Name)
+		} else {
+			headers = buildEnvoyProxyHeaders(headers, rateLimitHeaders(user, rpm, tpm, time.Now())...)
+		}
+	}
+
 	return &extProcPb.ProcessingResponse{
 		Response: &extProcPb.ProcessingResponse_ResponseHeaders{
 			ResponseHeaders: &extProcPb.HeadersResponse{
@@ -0,0 +1,364 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/openai/openai-go"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const shadowRequestIDSuffix = "-shadow"
+
+var (
+	// shadowSinks is a comma separated list of sinks to export shadow events to, empty disables shadow events.
+	// Requests are mirrored to shadow models regardless.
+	shadowSinks             = utils.LoadEnv("AIBRIX_SHADOW_SINKS", "")
+	shadowFilePath          = utils.LoadEnv("AIBRIX_SHADOW_FILE_PATH", "/var/log/aibrix/shadow.jsonl")
+	shadowRedisStream       = utils.LoadEnv("AIBRIX_SHADOW_REDIS_STREAM", "aibrix:shadow")
+	shadowWebhookURL        = utils.LoadEnv("AIBRIX_SHADOW_WEBHOOK_URL", "")
+	shadowWebhookAuthHeader = utils.LoadEnv("AIBRIX_SHADOW_WEBHOOK_AUTHORIZATION", "")
+	shadowTimeout           = time.Duration(utils.LoadEnvInt("AIBRIX_SHADOW_TIMEOUT_SECONDS", 120)) * time.Second
+	// shadowMaxOutputBytes truncates the outputs recorded to shadow events.
+	shadowMaxOutputBytes = utils.LoadEnvInt("AIBRIX_SHADOW_MAX_OUTPUT_BYTES", 65536)
+	// shadowMaxPrimaryBytes bounds the response of a shadowed request buffered to extract its output, which takes
+	// more bytes than the output with the json encoding and the other fields of the response.
+	shadowMaxPrimaryBytes = shadowMaxOutputBytes * 8
+
+	// shadowComparisons tracks the shadowComparison of shadowed requests by request ID.
+	shadowComparisons sync.Map
+)
+
+// shadowRequestTracker counts shadow requests to the running requests of their target pods.
+type shadowRequestTracker interface {
+	AddShadowRequestCount(ctx *types.RoutingContext, requestID string)
+	DoneShadowRequestCount(ctx *types.RoutingContext, requestID string)
+}
+
+var _ shadowRequestTracker = (*cache.Store)(nil)
+
+// shadowComparison collects the responses of a request and of its shadow request, the shadow event is emitted
+// once both end.
+type shadowComparison struct {
+	mu     sync.Mutex
+	event  usagelog.ShadowEvent // Not modified once both the request and the shadow request end.
+	stream bool
+	// response is the response of the request to the client, or the incomplete event of a streaming response.
+	response bytes.Buffer
+	// output is the text streamed to the client so far, up to shadowMaxOutputBytes.
+	output strings.Builder
+	// truncated is set once the output is complete up to shadowMaxOutputBytes or the response is too large,
+	// the rest of the response is not buffered.
+	truncated   bool
+	primaryDone bool
+	shadowDone  bool
+}
+
+func newShadowExporter(redisClient *redis.Client) *usagelog.Exporter[usagelog.ShadowEvent] {
+	return newExporter[usagelog.ShadowEvent](redisClient, sinkConfig{
+		env:               "AIBRIX_SHADOW_SINKS",
+		sinks:             shadowSinks,
+		filePath:          shadowFilePath,
+		redisStream:       shadowRedisStream,
+		webhookURL:        shadowWebhookURL,
+		webhookAuthHeader: shadowWebhookAuthHeader,
+	})
+}
+
+// mirrorRequest sends a copy of a routed request to the shadow model of its RoutingPolicy, by the percentage of the
+// policy. The shadow response is discarded and recorded to a shadow event once the request ends.
+// The shadow request counts to the running requests of its pod, but not to the requests of the shadow model.
+func (s *Server) mirrorRequest(routingCtx *types.RoutingContext, requestPath string, headers []*configPb.HeaderValue,
+	requestBody []byte, stream bool) {
+	shadow, ok := routing.PolicyShadow(routingCtx.Model)
+	if !ok || rand.Int31n(100) >= shadow.Percentage || isAudioRequestPath(requestPath) {
+		return
+	}
+	// The routing context of the request is released once the request ends, before the shadow request does.
+	requestID, message := routingCtx.RequestID, routingCtx.Message
+	body, err := rewriteRequestModel(requestBody, shadow.Model)
+	if err != nil {
+		klog.ErrorS(err, "failed to rewrite model of shadow request", "requestID", requestID, "shadowModel", shadow.Model)
+		return
+	}
+	podsArr, err := s.cache.ListPodsByModel(shadow.Model)
+	if err != nil || podsArr == nil || utils.CountRoutablePods(podsArr.All()) == 0 {
+		klog.V(4).InfoS("no ready pod for shadow model", "requestID", requestID, "shadowModel", shadow.Model)
+		return
+	}
+
+	// The shadow model is routed by its own policy, or like the request if the request is routed by the gateway.
+	algorithm, ok := routing.PolicyAlgorithm(shadow.Model)
+	if !ok {
+		algorithm = routingCtx.Algorithm
+	}
+	if algorithm == routing.RouterNotSet {
+		algorithm = routing.RouterRandom
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shadowTimeout)
+	var user string
+	if routingCtx.User != nil {
+		user = *routingCtx.User
+	}
+	shadowCtx := types.NewRoutingContext(ctx, algorithm, shadow.Model, message, requestID+shadowRequestIDSuffix, user)
+	if _, err := s.selectTargetPod(shadowCtx, podsArr); err != nil {
+		klog.ErrorS(err, "failed to select target pod for shadow request", "requestID", requestID, "shadowModel", shadow.Model)
+		shadowCtx.Delete()
+		cancel()
+		return
+	}
+	targetPod := shadowCtx.TargetPod()
+
+	var comparison *shadowComparison
+	if s.shadowExporter != nil {
+		promptHash := sha256.Sum256([]byte(message))
+		comparison = &shadowComparison{
+			event: usagelog.ShadowEvent{
+				Timestamp:  time.Now(),
+				RequestID:  requestID,
+				User:       user,
+				PromptHash: hex.EncodeToString(promptHash[:]),
+				Primary:    usagelog.ShadowResponse{Model: routingCtx.Model},
+				Shadow:     usagelog.ShadowResponse{Model: shadow.Model, TargetPod: targetPod.Name},
+			},
+			stream: stream,
+		}
+		shadowComparisons.Store(requestID, comparison)
+	}
+
+	tracker, tracked := s.cache.(shadowRequestTracker)
+	if tracked {
+		tracker.AddShadowRequestCount(shadowCtx, shadowCtx.RequestID)
+	}
+	klog.V(4).InfoS("request mirrored", "requestID", requestID, "shadowModel", shadow.Model, "shadowPod", targetPod.Name)
+	go func() {
+		defer cancel()
+		start := time.Now()
+		statusCode, _, respBody, err := s.forwardRequest(shadowCtx, &retryRequest{
+			requestPath: requestPath,
+			headers:     headers,
+			body:        body,
+		})
+		latency := time.Since(start)
+		if tracked {
+			tracker.DoneShadowRequestCount(shadowCtx, shadowCtx.RequestID)
+		}
+		shadowCtx.Delete()
+		if err != nil {
+			klog.ErrorS(err, "shadow request failed", "requestID", requestID, "shadowModel", shadow.Model, "shadowPod", targetPod.Name)
+		}
+		if comparison != nil {
+			s.doneShadowRequest(comparison, statusCode, latency, respBody, err, message)
+		}
+	}()
+}
+
+// doneShadowRequest records the response of a shadow request once it ends.
+func (s *Server) doneShadowRequest(comparison *shadowComparison, statusCode int, latency time.Duration, body []byte, err error, prompt string) {
+	comparison.mu.Lock()
+	response := &comparison.event.Shadow
+	response.StatusCode, response.LatencyMs = statusCode, latency.Milliseconds()
+	if err != nil {
+		response.Error = err.Error()
+	} else {
+		recordShadowResponse(response, comparison.stream, body, prompt)
+	}
+	comparison.shadowDone = true
+	ended := comparison.primaryDone
+	comparison.mu.Unlock()
+	// The event is emitted by whichever of the request and the shadow request ends last.
+	if ended {
+		s.shadowExporter.Emit(comparison.event)
+	}
+}
+
+// recordShadowPrimary buffers a response body chunk of a shadowed request. The events of a streaming response are
+// consumed as they complete, only their output is kept.
+func recordShadowPrimary(requestID string, chunk []byte) {
+	value, ok := shadowComparisons.Load(requestID)
+	if !ok {
+		return
+	}
+	comparison := value.(*shadowComparison)
+	comparison.mu.Lock()
+	defer comparison.mu.Unlock()
+	if comparison.truncated {
+		return
+	}
+	if shadowMaxOutputBytes > 0 && comparison.response.Len()+len(chunk) > shadowMaxPrimaryBytes {
+		// The output of a non-streaming response can't be extracted from a partial body, it is recorded as empty.
+		comparison.truncated = true
+		comparison.response = bytes.Buffer{}
+		return
+	}
+	comparison.response.Write(chunk)
+	if !comparison.stream {
+		return
+	}
+
+	events := comparison.response.Bytes()
+	end := bytes.LastIndex(events, sseEventDelimiter)
+	if end < 0 {
+		return
+	}
+	comparison.output.WriteString(responseOutput(true, events[:end]))
+	comparison.response.Next(end + len(sseEventDelimiter))
+	if shadowMaxOutputBytes > 0 && comparison.output.Len() >= shadowMaxOutputBytes {
+		comparison.truncated = true
+		comparison.response = bytes.Buffer{}
+	}
+}
+
+// doneShadowPrimary records the response of a shadowed request once the request ends, from its usage event.
+func (s *Server) doneShadowPrimary(requestID string, event *usagelog.Event, start time.Time) {
+	value, ok := shadowComparisons.LoadAndDelete(requestID)
+	if !ok {
+		return
+	}
+	comparison := value.(*shadowComparison)
+	comparison.mu.Lock()
+	response := &comparison.event.Primary
+	response.TargetPod = event.TargetPod
+	response.StatusCode = event.StatusCode
+	response.LatencyMs = time.Since(start).Milliseconds()
+	response.PromptTokens, response.CompletionTokens = event.PromptTokens, event.CompletionTokens
+	if comparison.stream {
+		// The last event may not be delimited.
+		comparison.output.WriteString(responseOutput(true, comparison.response.Bytes()))
+		response.Output = truncateOutput(comparison.output.String())
+	} else {
+		response.Output = truncateOutput(responseOutput(false, comparison.response.Bytes()))
+	}
+	comparison.response, comparison.output = bytes.Buffer{}, strings.Builder{}
+	comparison.primaryDone = true
+	ended := comparison.shadowDone
+	comparison.mu.Unlock()
+	if ended {
+		s.shadowExporter.Emit(comparison.event)
+	}
+}
+
+// recordShadowResponse records the output and the tokens of a complete response, the tokens are estimated if the
+// engine did not report the usage.
+func recordShadowResponse(response *usagelog.ShadowResponse, stream bool, body []byte, prompt string) {
+	output := responseOutput(stream, body)
+	response.Output = truncateOutput(output)
+	if usage := shadowResponseUsage(stream, body); usage.TotalTokens != 0 {
+		response.PromptTokens, response.CompletionTokens = usage.PromptTokens, usage.CompletionTokens
+		return
+	}
+	if output != "" {
+		response.PromptTokens = int64(promptTokenEstimator.EstimateInputTokens(prompt))
+		response.CompletionTokens = int64(promptTokenEstimator.EstimateInputTokens(output))
+	}
+}
+
+// outputChoices holds the generated text of chat completion, completion and streaming chunk responses.
+type outputChoices struct {
+	Choices []struct {
+		Text    string `json:"text"`
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+func (o *outputChoices) text() string {
+	if len(o.Choices) == 0 {
+		return ""
+	}
+	choice := o.Choices[0]
+	return choice.Text + choice.Message.Content + choice.Delta.Content
+}
+
+// responseOutput returns the text generated for the first choice of a complete response, empty if the response
+// can't be parsed.
+func responseOutput(stream bool, body []byte) string {
+	if !stream {
+		var choices outputChoices
+		if err := json.Unmarshal(body, &choices); err != nil {
+			return ""
+		}
+		return choices.text()
+	}
+
+	var output strings.Builder
+	forEachSSEData(body, func(payload []byte) {
+		var choices outputChoices
+		if err := json.Unmarshal(payload, &choices); err == nil {
+			output.WriteString(choices.text())
+		}
+	})
+	return output.String()
+}
+
+// shadowResponseUsage returns the usage reported in a complete response, the usage chunk of a streaming response.
+func shadowResponseUsage(stream bool, body []byte) openai.CompletionUsage {
+	if !stream {
+		return getResponseUsage(body)
+	}
+	var usage openai.CompletionUsage
+	forEachSSEData(body, func(payload []byte) {
+		var evt streamEvent
+		if err := json.Unmarshal(payload, &evt); err == nil && evt.isUsage() {
+			usage = *evt.Usage
+		}
+	})
+	return usage
+}
+
+// forEachSSEData calls fn with the data of each event of a streaming response, except the final [DONE] event.
+func forEachSSEData(body []byte, fn func(payload []byte)) {
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		payload, ok := bytes.CutPrefix(bytes.TrimSpace(line), sseDataPrefix)
+		if !ok {
+			continue
+		}
+		payload = bytes.TrimSpace(payload)
+		if len(payload) == 0 || bytes.Equal(payload, sseDone) {
+			continue
+		}
+		fn(payload)
+	}
+}
+
+func truncateOutput(output string) string {
+	if shadowMaxOutputBytes > 0 && len(output) > shadowMaxOutputBytes {
+		return output[:shadowMaxOutputBytes]
+	}
+	return output
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+)
+
+type shadowEventSink struct {
+	mu     sync.Mutex
+	events []usagelog.ShadowEvent
+}
+
+func (s *shadowEventSink) Export(events []usagelog.ShadowEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *shadowEventSink) Close() error {
+	return nil
+}
+
+func Test_responseOutput(t *testing.T) {
+	assert.Equal(t, "Hello", responseOutput(false,
+		[]byte(`{"id":"1","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"}}]}`)))
+	assert.Equal(t, "Hello", responseOutput(false, []byte(`{"id":"1","model":"m","choices":[{"index":0,"text":"Hello"}]}`)))
+	assert.Equal(t, "", responseOutput(false, []byte(`not json`)))
+	assert.Equal(t, "Hello world, bye", responseOutput(true,
+		[]byte(testStreamChunk1+testStreamChunk2+testStreamUsage+testStreamDone)))
+}
+
+func Test_recordShadowResponse(t *testing.T) {
+	var response usagelog.ShadowResponse
+	recordShadowResponse(&response, true, []byte(testStreamChunk1+testStreamChunk2+testStreamUsage+testStreamDone), "hi")
+	assert.Equal(t, usagelog.ShadowResponse{Output: "Hello world, bye", PromptTokens: 3, CompletionTokens: 4}, response)
+
+	// The tokens are estimated without usage.
+	response = usagelog.ShadowResponse{}
+	recordShadowResponse(&response, true, []byte(testStreamChunk1+testStreamDone), "hi")
+	assert.Equal(t, "Hello", response.Output)
+	assert.Positive(t, response.PromptTokens)
+	assert.Positive(t, response.CompletionTokens)
+}
+
+func Test_shadowComparison(t *testing.T) {
+	sink := &shadowEventSink{}
+	s := &Server{shadowExporter: usagelog.NewExporter([]usagelog.Sink[usagelog.ShadowEvent]{sink}, 10, 1, time.Hour)}
+
+	// The event is emitted once both the request and the shadow request end, in any order.
+	for _, primaryFirst := range []bool{true, false} {
+		comparison := &shadowComparison{
+			event: usagelog.ShadowEvent{
+				RequestID: "r1",
+				Primary:   usagelog.ShadowResponse{Model: "m"},
+				Shadow:    usagelog.ShadowResponse{Model: "m-candidate"},
+			},
+			stream: true,
+		}
+		shadowComparisons.Store("r1", comparison)
+		recordShadowPrimary("r1", []byte(testStreamChunk1))
+		recordShadowPrimary("r1", []byte(testStreamChunk2+testStreamDone))
+		event := &usagelog.Event{TargetPod: "p1", StatusCode: 200, PromptTokens: 3, CompletionTokens: 4}
+
+		if primaryFirst {
+			s.doneShadowPrimary("r1", event, time.Now())
+			s.doneShadowRequest(comparison, 500, time.Second, nil, errors.New("connection refused"), "hi")
+		} else {
+			s.doneShadowRequest(comparison, 500, time.Second, nil, errors.New("connection refused"), "hi")
+			s.doneShadowPrimary("r1", event, time.Now())
+		}
+		_, ok := shadowComparisons.Load("r1")
+		assert.False(t, ok)
+	}
+	assert.NoError(t, s.shadowExporter.Close())
+
+	assert.Len(t, sink.events, 2)
+	for _, event := range sink.events {
+		event.Primary.LatencyMs = 0
+		assert.Equal(t, usagelog.ShadowResponse{Model: "m", TargetPod: "p1", Output: "Hello world, bye",
+			PromptTokens: 3, CompletionTokens: 4, StatusCode: 200}, event.Primary)
+		assert.Equal(t, usagelog.ShadowResponse{Model: "m-candidate", LatencyMs: 1000, StatusCode: 500,
+			Error: "connection refused"}, event.Shadow)
+	}
+}
+
+func Test_recordShadowPrimaryBounded(t *testing.T) {
+	defer func(maxOutputBytes, maxPrimaryBytes int) {
+		shadowMaxOutputBytes, shadowMaxPrimaryBytes = maxOutputBytes, maxPrimaryBytes
+	}(shadowMaxOutputBytes, shadowMaxPrimaryBytes)
+	shadowMaxOutputBytes, shadowMaxPrimaryBytes = 8, 400
+	defer shadowComparisons.Delete("r1")
+
+	// A stream is buffered until the output reaches the cap.
+	comparison := &shadowComparison{stream: true}
+	shadowComparisons.Store("r1", comparison)
+	for i := 0; i < 100; i++ {
+		recordShadowPrimary("r1", []byte(testStreamChunk1))
+	}
+	assert.True(t, comparison.truncated)
+	assert.Equal(t, 0, comparison.response.Len())
+	assert.Equal(t, "HelloHello", comparison.output.String())
+	s := &Server{}
+	s.doneShadowPrimary("r1", &usagelog.Event{}, time.Now())
+	assert.Equal(t, "HelloHel", comparison.event.Primary.Output)
+
+	// An event split across chunks is recorded once complete.
+	comparison = &shadowComparison{stream: true}
+	shadowComparisons.Store("r1", comparison)
+	recordShadowPrimary("r1", []byte(testStreamChunk1[:20]))
+	assert.Equal(t, "", comparison.output.String())
+	recordShadowPrimary("r1", []byte(testStreamChunk1[20:]))
+	assert.Equal(t, "Hello", comparison.output.String())
+
+	// A non-streaming response larger than the bound is not buffered.
+	comparison = &shadowComparison{}
+	shadowComparisons.Store("r1", comparison)
+	for i := 0; i < 100; i++ {
+		recordShadowPrimary("r1", []byte(`{"choices": [{"message": {"content": "Hello"}}]}`))
+	}
+	assert.True(t, comparison.truncated)
+	assert.Equal(t, 0, comparison.response.Len())
+	s.doneShadowPrimary("r1", &usagelog.Event{}, time.Now())
+	assert.Equal(t, "", comparison.event.Primary.Output)
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+var (
+	// streamUsageInjectionEnabled makes the gateway ask the engine for the usage of streaming chat completions,
+	// so that clients not setting stream_options.include_usage are accounted as well.
+	streamUsageInjectionEnabled = utils.LoadEnvBool("AIBRIX_GATEWAY_STREAM_USAGE_INJECTION_ENABLED", true)
+
+	// streamUsages tracks the streamUsage of streaming requests by request ID.
+	streamUsages sync.Map
+	// routedAts tracks when non-streaming requests are routed by request ID, to observe their latency.
+	routedAts sync.Map
+
+	sseEventDelimiter = []byte("\n\n")
+	sseDataPrefix     = []byte("data:")
+	sseDone           = []byte("[DONE]")
+)
+
+// streamUsage accounts the tokens of a streaming response across response body chunks.
+type streamUsage struct {
+	// stripUsage drops the usage chunk from the response, since the client did not ask for it.
+	stripUsage bool
+	// started is set once the first response chunk is received.
+	started bool
+	// routedAt is when the request is routed, firstTokenAt and lastTokenAt are when the first and last content is received.
+	routedAt     time.Time
+	firstTokenAt time.Time
+	lastTokenAt  time.Time
+	ttftObserved bool
+	// pending is the incomplete event at the end of the last chunk.
+	pending []byte
+	// completionTokens is estimated from the content streamed so far.
+	completionTokens int64
+	// usage is reported by the engine in the last chunk.
+	usage openai.CompletionUsage
+}
+
+// streamEvent is the accounted part of a chat completion or completion chunk, or of an error event of the engine.
+type streamEvent struct {
+	Object  string `json:"object"`
+	Choices []struct {
+		// Text is the content of a completion chunk.
+		Text  string `json:"text"`
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Function struct {
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *openai.CompletionUsage `json:"usage"`
+	Error json.RawMessage         `json:"error"`
+}
+
+// isUsage returns whether the event is the usage chunk, which carries the usage and no choices.
+func (e *streamEvent) isUsage() bool {
+	return e.Usage != nil && len(e.Choices) == 0 && e.Object != "error" && len(e.Error) == 0
+}
+
+// latencyObserver records the latency of requests measured by the gateway.
+type latencyObserver interface {
+	ObserveTTFT(ctx *types.RoutingContext, ttft time.Duration)
+	ObserveTPOT(ctx *types.RoutingContext, tpot time.Duration)
+}
+
+var _ latencyObserver = (*cache.Store)(nil)
+
+// observeLatency records the time to first token once the first token is received, and the time per output token
+// once the completion tokens are known.
+func (s *streamUsage) observeLatency(c cache.Cache, routerCtx *types.RoutingContext, completionTokens int64) {
+	observer, ok := c.(latencyObserver)
+	if !ok || routerCtx == nil || s.routedAt.IsZero() || s.firstTokenAt.IsZero() {
+		return
+	}
+	if !s.ttftObserved {
+		s.ttftObserved = true
+		observer.ObserveTTFT(routerCtx, s.firstTokenAt.Sub(s.routedAt))
+	}
+	if completionTokens > 1 {
+		observer.ObserveTPOT(routerCtx, s.lastTokenAt.Sub(s.firstTokenAt)/time.Duration(completionTokens-1))
+	}
+}
+
+// observeRequestLatency records the latency of a non-streaming request. Its first token is not observable, the
+// end-to-end latency per output token is recorded as the time per output token instead.
+func observeRequestLatency(c cache.Cache, routerCtx *types.RoutingContext, requestID string, completionTokens int64) {
+	value, ok := routedAts.LoadAndDelete(requestID)
+	observer, observed := c.(latencyObserver)
+	if !ok || !observed || routerCtx == nil || completionTokens <= 0 {
+		return
+	}
+	observer.ObserveTPOT(routerCtx, time.Since(value.(time.Time))/time.Duration(completionTokens))
+}
+
+func loadStreamUsage(requestID string) *streamUsage {
+	state, _ := streamUsages.LoadOrStore(requestID, &streamUsage{})
+	return state.(*streamUsage)
+}
+
+// injectStreamUsage sets stream_options.include_usage of a streaming request body.
+// It returns false if the client already asks for usage, in which case the body is left untouched.
+func injectStreamUsage(requestBody []byte) ([]byte, bool, error) {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(requestBody, &jsonMap); err != nil {
+		return nil, false, err
+	}
+	var streamOptions map[string]json.RawMessage
+	if raw, ok := jsonMap["stream_options"]; ok {
+		if err := json.Unmarshal(raw, &streamOptions); err != nil {
+			return nil, false, err
+		}
+	}
+	if streamOptions == nil {
+		streamOptions = map[string]json.RawMessage{}
+	}
+	var includeUsage bool
+	if raw, ok := streamOptions["include_usage"]; ok {
+		if err := json.Unmarshal(raw, &includeUsage); err != nil {
+			return nil, false, err
+		}
+	}
+	if includeUsage {
+		return nil, false, nil
+	}
+
+	streamOptions["include_usage"] = json.RawMessage("true")
+	raw, err := json.Marshal(streamOptions)
+	if err != nil {
+		return nil, false, err
+	}
+	jsonMap["stream_options"] = raw
+	body, err := json.Marshal(jsonMap)
+	if err != nil {
+		return nil, false, err
+	}
+	return body, true, nil
+}
+
+// process parses the complete server-sent events of a response chunk, an event split across chunks is parsed
+// once the rest arrives. It returns the chunk to send to the client, which differs from the input only if
+// the usage chunk is stripped.
+func (s *streamUsage) process(chunk []byte, endOfStream bool) ([]byte, error) {
+	s.started = true
+	data := chunk
+	if len(s.pending) > 0 {
+		data = append(s.pending, chunk...)
+		s.pending = nil
+	}
+
+	var events [][]byte
+	for {
+		event, rest, found := bytes.Cut(data, sseEventDelimiter)
+		if !found {
+			break
+		}
+		events = append(events, event)
+		data = rest
+	}
+	if len(data) > 0 {
+		if endOfStream {
+			events = append(events, data)
+		} else {
+			s.pending = bytes.Clone(data)
+		}
+	}
+
+	var out bytes.Buffer
+	for _, event := range events {
+		isUsage, err := s.processEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		if isUsage && s.stripUsage {
+			continue
+		}
+		out.Write(event)
+		out.Write(sseEventDelimiter)
+	}
+	if !s.stripUsage {
+		return chunk, nil
+	}
+	return out.Bytes(), nil
+}
+
+// processEvent counts the tokens of an event, it returns true if the event is the usage chunk. Other events without
+// choices, e.g. errors of the engine, are passed through.
+func (s *streamUsage) processEvent(event []byte) (bool, error) {
+	for _, line := range bytes.Split(event, []byte("\n")) {
+		payload, ok := bytes.CutPrefix(bytes.TrimSpace(line), sseDataPrefix)
+		if !ok {
+			continue
+		}
+		payload = bytes.TrimSpace(payload)
+		if len(payload) == 0 || bytes.Equal(payload, sseDone) {
+			continue
+		}
+
+		var evt streamEvent
+		if err := json.Unmarshal(payload, &evt); err != nil {
+			return false, err
+		}
+		if evt.Usage != nil && evt.Usage.TotalTokens != 0 {
+			s.usage = *evt.Usage
+		}
+		if evt.isUsage() {
+			return true, nil
+		}
+		tokens := int64(0)
+		for _, choice := range evt.Choices {
+			tokens += int64(promptTokenEstimator.EstimateInputTokens(choice.Text))
+			tokens += int64(promptTokenEstimator.EstimateInputTokens(choice.Delta.Content))
+			for _, toolCall := range choice.Delta.ToolCalls {
+				tokens += int64(promptTokenEstimator.EstimateInputTokens(toolCall.Function.Arguments))
+			}
+		}
+		if tokens > 0 {
+			s.completionTokens += tokens
+			s.lastTokenAt = time.Now()
+			if s.firstTokenAt.IsZero() {
+				s.firstTokenAt = s.lastTokenAt
+			}
+		}
+	}
+	return false, nil
+}
+
+// doneAbortedStream accounts a streaming request which ended before the engine reported the usage, e.g. the client
+// went away mid-stream. The tokens streamed so far are charged and the request trace is done with the estimated tokens.
+// The estimated tokens are recorded to the usage event. Returns false if no response chunk of the request was received.
+func (s *Server) doneAbortedStream(routerCtx *types.RoutingContext, requestID string, user utils.User, model string, traceTerm int64, event *usagelog.Event) bool {
+	value, ok := streamUsages.LoadAndDelete(requestID)
+	if !ok || routerCtx == nil || !value.(*streamUsage).started {
+		return false
+	}
+	state := value.(*streamUsage)
+
+	promptTokens := int64(promptTokenEstimator.EstimateInputTokens(routerCtx.Message))
+	s.cache.DoneRequestTrace(routerCtx, requestID, model, promptTokens, state.completionTokens, traceTerm)
+	routerCtx.Delete()
+	event.PromptTokens, event.CompletionTokens = promptTokens, state.completionTokens
+
+	// The estimated prompt tokens are pre-charged on routing, charge the streamed completion tokens.
+	if user.Name != "" && state.completionTokens > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), rateLimitReleaseTimeout)
+		defer cancel()
+		if _, err := s.chargeTPM(ctx, user, state.completionTokens); err != nil {
+			klog.ErrorS(err, "failed to charge TPM for aborted stream", "requestID", requestID, "username", user.Name)
+		}
+	}
+	klog.InfoS("request aborted", "requestID", requestID, "promptTokens", promptTokens, "completionTokens", state.completionTokens)
+	return true
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_injectStreamUsage(t *testing.T) {
+	body, injected, err := injectStreamUsage([]byte(`{"model": "m1", "stream": true, "messages": []}`))
+	assert.NoError(t, err)
+	assert.True(t, injected)
+	assert.JSONEq(t, `{"model": "m1", "stream": true, "messages": [], "stream_options": {"include_usage": true}}`, string(body))
+
+	body, injected, err = injectStreamUsage([]byte(`{"model": "m1", "stream": true, "stream_options": {"include_usage": false, "continuous_usage_stats": true}}`))
+	assert.NoError(t, err)
+	assert.True(t, injected)
+	assert.JSONEq(t, `{"model": "m1", "stream": true, "stream_options": {"include_usage": true, "continuous_usage_stats": true}}`, string(body))
+
+	_, injected, err = injectStreamUsage([]byte(`{"model": "m1", "stream": true, "stream_options": {"include_usage": true}}`))
+	assert.NoError(t, err)
+	assert.False(t, injected)
+
+	_, _, err = injectStreamUsage([]byte(`{"model": "m1", "stream_options": "yes"}`))
+	assert.Error(t, err)
+}
+
+const (
+	testStreamChunk1 = "data: {\"id\":\"1\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello\"}}]}\n\n"
+	testStreamChunk2 = "data: {\"id\":\"1\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\" world, bye\"}}]}\n\n"
+	testStreamUsage  = "data: {\"id\":\"1\",\"model\":\"m\",\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":4,\"total_tokens\":7}}\n\n"
+	testStreamDone   = "data: [DONE]\n\n"
+)
+
+func Test_streamUsage(t *testing.T) {
+	// Events split across chunks are counted once complete, the response is passed through.
+	state := &streamUsage{}
+	split := len(testStreamChunk2) / 2
+	for _, chunk := range []string{testStreamChunk1 + testStreamChunk2[:split], testStreamChunk2[split:]} {
+		body, err := state.process([]byte(chunk), false)
+		assert.NoError(t, err)
+		assert.Equal(t, chunk, string(body))
+	}
+	assert.Equal(t, int64(5), state.completionTokens)
+	assert.Equal(t, int64(0), state.usage.TotalTokens)
+	assert.False(t, state.firstTokenAt.IsZero())
+	assert.False(t, state.lastTokenAt.Before(state.firstTokenAt))
+
+	body, err := state.process([]byte(testStreamUsage+testStreamDone), true)
+	assert.NoError(t, err)
+	assert.Equal(t, testStreamUsage+testStreamDone, string(body))
+	assert.Equal(t, int64(7), state.usage.TotalTokens)
+
+	// The usage chunk is stripped if the client did not ask for it.
+	state = &streamUsage{stripUsage: true}
+	body, err = state.process([]byte(testStreamChunk1+testStreamUsage[:10]), false)
+	assert.NoError(t, err)
+	assert.Equal(t, testStreamChunk1, string(body))
+	body, err = state.process([]byte(testStreamUsage[10:]+testStreamDone), true)
+	assert.NoError(t, err)
+	assert.Equal(t, testStreamDone, string(body))
+	assert.Equal(t, int64(3), state.usage.PromptTokens)
+	assert.Equal(t, int64(2), state.completionTokens)
+
+	_, err = (&streamUsage{}).process([]byte("data: not json\n\n"), false)
+	assert.Error(t, err)
+}
+
+func Test_streamUsageEvents(t *testing.T) {
+	// Error events of the engine have no choices, they are passed through even if the usage chunk is stripped.
+	errorEvents := "data: {\"object\":\"error\",\"message\":\"out of memory\",\"type\":\"InternalServerError\",\"code\":500}\n\n" +
+		"data: {\"error\":{\"message\":\"aborted\"},\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":1,\"total_tokens\":4}}\n\n"
+	state := &streamUsage{stripUsage: true}
+	body, err := state.process([]byte(testStreamChunk1+errorEvents+testStreamDone), true)
+	assert.NoError(t, err)
+	assert.Equal(t, testStreamChunk1+errorEvents+testStreamDone, string(body))
+
+	// Chunks of chat completions carry a null usage unless it is the usage chunk.
+	chunk := "data: {\"id\":\"1\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello\"}}],\"usage\":null}\n\n"
+	state = &streamUsage{stripUsage: true}
+	body, err = state.process([]byte(chunk+testStreamUsage), true)
+	assert.NoError(t, err)
+	assert.Equal(t, chunk, string(body))
+	assert.Equal(t, int64(7), state.usage.TotalTokens)
+
+	// The text of completion chunks is counted.
+	state = &streamUsage{}
+	_, err = state.process([]byte("data: {\"id\":\"1\",\"object\":\"text_completion\",\"model\":\"m\",\"choices\":[{\"index\":0,\"text\":\" world, bye\"}]}\n\n"), false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), state.completionTokens)
+	assert.False(t, state.firstTokenAt.IsZero())
+}
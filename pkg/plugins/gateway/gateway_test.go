@@ -17,14 +17,26 @@ limitations under the License.
 package gateway
 
 import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/stretchr/testify/assert"
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/cache"
 	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+	"github.com/vllm-project/aibrix/pkg/types"
 	"github.com/vllm-project/aibrix/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_ValidateRoutingStrategy(t *testing.T) {
@@ -143,3 +155,190 @@ func Test_buildEnvoyProxyHeaders(t *testing.T) {
 	headers = buildEnvoyProxyHeaders(headers, "key3", "value3")
 	assert.Equal(t, 3, len(headers))
 }
+
+func Test_forwardRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	assert.NoError(t, err)
+
+	s := &Server{httpClient: server.Client()}
+	routingCtx := types.NewRoutingContext(context.Background(), routing.RouterRandom, "m", "", "r1", "")
+	defer routingCtx.Delete()
+	routingCtx.SetTargetPod(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Labels: map[string]string{"model.aibrix.ai/port": port}},
+		Status:     v1.PodStatus{PodIP: host},
+	})
+	retry := &retryRequest{requestPath: PathCompletions, body: []byte(`{"model": "m"}`)}
+
+	statusCode, contentType, body, err := s.forwardRequest(routingCtx, retry)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, `{"id": "1"}`, string(body))
+
+	// Responses beyond the limit are not buffered.
+	defer func(limit int) { retryMaxResponseBytes = limit }(retryMaxResponseBytes)
+	retryMaxResponseBytes = 4
+	_, _, _, err = s.forwardRequest(routingCtx, retry)
+	assert.ErrorIs(t, err, errRetryResponseTooLarge)
+}
+
+func Test_getResponseUsage(t *testing.T) {
+	usage := getResponseUsage([]byte(`{"id": "1", "model": "m", "choices": [], "usage": {"prompt_tokens": 3, "completion_tokens": 4, "total_tokens": 7}}`))
+	assert.Equal(t, int64(7), usage.TotalTokens)
+
+	usage = getResponseUsage([]byte("not json"))
+	assert.Equal(t, int64(0), usage.TotalTokens)
+}
+
+func Test_withDefaultLimits(t *testing.T) {
+	var tests = []struct {
+		message  string
+		user     utils.User
+		expected utils.User
+	}{
+		{
+			message:  "user without limits",
+			user:     utils.User{Name: "u"},
+			expected: utils.User{Name: "u", Rpm: DefaultRPM, Tpm: DefaultRPM * DefaultTPMMultiplier, Burst: DefaultRPM},
+		},
+		{
+			message:  "burst defaults to rpm",
+			user:     utils.User{Name: "u", Rpm: 10, Tpm: 20, RateLimiter: utils.TokenBucketRateLimiter},
+			expected: utils.User{Name: "u", Rpm: 10, Tpm: 20, Burst: 10, RateLimiter: utils.TokenBucketRateLimiter},
+		},
+		{
+			message:  "limits are kept",
+			user:     utils.User{Name: "u", Rpm: 10, Tpm: 20, Burst: 30, MaxConcurrency: 2},
+			expected: utils.User{Name: "u", Rpm: 10, Tpm: 20, Burst: 30, MaxConcurrency: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, withDefaultLimits(tt.user), tt.message)
+	}
+}
+
+func Test_getBearerToken(t *testing.T) {
+	var tests = []struct {
+		message  string
+		headers  []*configPb.HeaderValue
+		expected string
+		ok       bool
+	}{
+		{
+			message: "no authorization header",
+			headers: []*configPb.HeaderValue{{Key: "user", RawValue: []byte("u")}},
+		},
+		{
+			message:  "bearer token",
+			headers:  []*configPb.HeaderValue{{Key: "authorization", RawValue: []byte("Bearer sk-123")}},
+			expected: "sk-123",
+			ok:       true,
+		},
+		{
+			message:  "case insensitive scheme and header",
+			headers:  []*configPb.HeaderValue{{Key: "Authorization", RawValue: []byte("bearer  sk-123 ")}},
+			expected: "sk-123",
+			ok:       true,
+		},
+		{
+			message: "basic scheme",
+			headers: []*configPb.HeaderValue{{Key: "authorization", RawValue: []byte("Basic dTpw")}},
+		},
+		{
+			message: "empty token",
+			headers: []*configPb.HeaderValue{{Key: "authorization", RawValue: []byte("Bearer ")}},
+		},
+	}
+
+	for _, tt := range tests {
+		token, ok := getBearerToken(tt.headers)
+		assert.Equal(t, tt.expected, token, tt.message)
+		assert.Equal(t, tt.ok, ok, tt.message)
+	}
+}
+
+func Test_recordUsage(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Labels: map[string]string{modelIdentifier: "llama"}}}
+	routerCtx := types.NewRoutingContext(context.Background(), "random", "llama-lora", "", "r1", "")
+	routerCtx.SetTargetPod(pod)
+
+	event := &usagelog.Event{}
+	recordRouting(event, "llama-lora", routerCtx)
+	assert.Equal(t, usagelog.Event{Model: "llama", Adapter: "llama-lora", TargetPod: "p1", RoutingAlgorithm: "random"}, *event)
+
+	event = &usagelog.Event{}
+	recordRouting(event, "llama", routerCtx)
+	assert.Equal(t, "llama", event.Model)
+	assert.Empty(t, event.Adapter)
+
+	recordImmediateResponse(event, generateErrorResponse(envoyTypePb.StatusCode_TooManyRequests,
+		[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{Key: HeaderErrorRPMExceeded, RawValue: []byte("true")}}}, "rpm exceeded"))
+	assert.Equal(t, 429, event.StatusCode)
+	assert.Equal(t, HeaderErrorRPMExceeded, event.ErrorHeader)
+}
+
+func Test_handleModelsRequest(t *testing.T) {
+	newPod := func(name string, created int64, ready bool, labels map[string]string) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels, CreationTimestamp: metav1.Unix(created, 0)},
+			Status:     v1.PodStatus{PodIP: "10.0.0.1"},
+		}
+		if ready {
+			pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+		}
+		return pod
+	}
+	baseModel := "llama"
+	s := &Server{cache: cache.NewTestCacheWithModelAdapters(
+		[]*v1.Pod{
+			newPod("p1", 200, true, map[string]string{modelIdentifier: "llama", modelMaxLenIdentifier: "8192"}),
+			newPod("p2", 100, false, map[string]string{modelIdentifier: "llama", modelMaxLenIdentifier: "4096"}),
+			newPod("p3", 300, false, map[string]string{modelIdentifier: "qwen"}),
+		},
+		[]*modelv1alpha1.ModelAdapter{{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama-lora", Namespace: "default", CreationTimestamp: metav1.Unix(400, 0)},
+			Spec:       modelv1alpha1.ModelAdapterSpec{BaseModel: &baseModel},
+			Status: modelv1alpha1.ModelAdapterStatus{Instances: []string{"p1"},
+				InstanceStatuses: []modelv1alpha1.ModelAdapterInstanceStatus{{PodName: "p1", Ready: true}}},
+		}},
+	)}
+
+	var list modelList
+	resp := s.handleModelsRequest("r1", PathModels+"?limit=10", "GET", utils.User{})
+	assert.Equal(t, envoyTypePb.StatusCode_OK, resp.GetImmediateResponse().GetStatus().GetCode())
+	assert.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().GetBody()), &list))
+	assert.Equal(t, modelList{Object: "list", Data: []modelCard{
+		{ID: "llama", Object: "model", Created: 100, OwnedBy: "aibrix", Root: "llama", MaxModelLen: 4096, ReadyReplicas: 1, Ready: true},
+		{ID: "llama-lora", Object: "model", Created: 400, OwnedBy: "aibrix", Root: "llama", Parent: "llama", MaxModelLen: 8192, ReadyReplicas: 1, Ready: true},
+		{ID: "qwen", Object: "model", Created: 300, OwnedBy: "aibrix", Root: "qwen"},
+	}}, list)
+
+	// Models not in the allowlist of the user are hidden.
+	user := utils.User{Name: "u1", Models: []string{"qwen"}}
+	resp = s.handleModelsRequest("r2", PathModels, "GET", user)
+	assert.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().GetBody()), &list))
+	assert.Len(t, list.Data, 1)
+	assert.Equal(t, "qwen", list.Data[0].ID)
+
+	var card modelCard
+	resp = s.handleModelsRequest("r3", PathModels+"/qwen", "GET", user)
+	assert.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().GetBody()), &card))
+	assert.Equal(t, "qwen", card.ID)
+	assert.False(t, card.Ready)
+
+	for _, modelID := range []string{"llama", "unknown"} {
+		resp = s.handleModelsRequest("r4", PathModels+"/"+modelID, "GET", user)
+		assert.Equal(t, envoyTypePb.StatusCode_NotFound, resp.GetImmediateResponse().GetStatus().GetCode(), modelID)
+	}
+	resp = s.handleModelsRequest("r5", PathModels, "POST", user)
+	assert.Equal(t, envoyTypePb.StatusCode_MethodNotAllowed, resp.GetImmediateResponse().GetStatus().GetCode())
+
+	assert.True(t, isModelsRequestPath("/v1/models/llama"))
+	assert.False(t, isModelsRequestPath("/v1/modelsx"))
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"strings"
+	"time"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/usagelog"
+	"github.com/vllm-project/aibrix/pkg/types"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	usageSinkFile    = "file"
+	usageSinkRedis   = "redis"
+	usageSinkWebhook = "webhook"
+
+	modelIdentifier = "model.aibrix.ai/name"
+)
+
+var (
+	// usageSinks is a comma separated list of sinks to export usage events to, empty disables usage events.
+	usageSinks              = utils.LoadEnv("AIBRIX_USAGE_SINKS", "")
+	usageBufferSize         = utils.LoadEnvInt("AIBRIX_USAGE_BUFFER_SIZE", 10000)
+	usageBatchSize          = utils.LoadEnvInt("AIBRIX_USAGE_BATCH_SIZE", 100)
+	usageFlushInterval      = time.Duration(utils.LoadEnvInt("AIBRIX_USAGE_FLUSH_INTERVAL_SECONDS", 5)) * time.Second
+	usageFilePath           = utils.LoadEnv("AIBRIX_USAGE_FILE_PATH", "/var/log/aibrix/usage.jsonl")
+	usageFileMaxSizeMB      = utils.LoadEnvInt("AIBRIX_USAGE_FILE_MAX_SIZE_MB", 100)
+	usageFileMaxBackups     = utils.LoadEnvInt("AIBRIX_USAGE_FILE_MAX_BACKUPS", 5)
+	usageRedisStream        = utils.LoadEnv("AIBRIX_USAGE_REDIS_STREAM", "aibrix:usage")
+	usageRedisMaxLen        = utils.LoadEnvInt("AIBRIX_USAGE_REDIS_MAX_LEN", 1000000)
+	usageWebhookURL         = utils.LoadEnv("AIBRIX_USAGE_WEBHOOK_URL", "")
+	usageWebhookAuthHeader  = utils.LoadEnv("AIBRIX_USAGE_WEBHOOK_AUTHORIZATION", "")
+	usageWebhookTimeoutSecs = utils.LoadEnvInt("AIBRIX_USAGE_WEBHOOK_TIMEOUT_SECONDS", 10)
+)
+
+// sinkConfig configures the sinks of an exporter, settings not listed are shared by all exporters.
+type sinkConfig struct {
+	// env is the env var setting sinks, a comma separated list of sinks.
+	env               string
+	sinks             string
+	filePath          string
+	redisStream       string
+	webhookURL        string
+	webhookAuthHeader string
+}
+
+// newUsageExporter returns the exporter of the sinks configured by AIBRIX_USAGE_SINKS, nil if no sink is configured.
+func newUsageExporter(redisClient *redis.Client) *usagelog.Exporter[usagelog.Event] {
+	return newExporter[usagelog.Event](redisClient, sinkConfig{
+		env:               "AIBRIX_USAGE_SINKS",
+		sinks:             usageSinks,
+		filePath:          usageFilePath,
+		redisStream:       usageRedisStream,
+		webhookURL:        usageWebhookURL,
+		webhookAuthHeader: usageWebhookAuthHeader,
+	})
+}
+
+// newExporter returns the exporter of the configured sinks, nil if no sink is configured.
+// Sinks failed to initialize are logged and skipped.
+func newExporter[T any](redisClient *redis.Client, config sinkConfig) *usagelog.Exporter[T] {
+	var sinks []usagelog.Sink[T]
+	for _, name := range strings.Split(config.sinks, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case usageSinkFile:
+			sink, err := usagelog.NewFileSink[T](config.filePath, int64(usageFileMaxSizeMB)<<20, usageFileMaxBackups)
+			if err != nil {
+				klog.ErrorS(err, "failed to create file sink", "env", config.env, "path", config.filePath)
+				continue
+			}
+			sinks = append(sinks, sink)
+		case usageSinkRedis:
+			sinks = append(sinks, usagelog.NewRedisStreamSink[T](redisClient, config.redisStream, int64(usageRedisMaxLen)))
+		case usageSinkWebhook:
+			if config.webhookURL == "" {
+				klog.ErrorS(nil, "webhook url is not set, webhook sink is skipped", "env", config.env)
+				continue
+			}
+			headers := map[string]string{}
+			if config.webhookAuthHeader != "" {
+				headers[headerAuthorization] = config.webhookAuthHeader
+			}
+			sinks = append(sinks, usagelog.NewWebhookSink[T](config.webhookURL, headers, time.Duration(usageWebhookTimeoutSecs)*time.Second))
+		default:
+			klog.ErrorS(nil, "unknown sink", "env", config.env, "sink", name)
+		}
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	klog.InfoS("event sinks enabled", "env", config.env, "sinks", config.sinks)
+	return usagelog.NewExporter(sinks, usageBufferSize, usageBatchSize, usageFlushInterval)
+}
+
+// recordRouting records the model and target pod of a routed request. A model served by a pod of another base model
+// is a LoRA adapter of the base model.
+func recordRouting(event *usagelog.Event, model string, routerCtx *types.RoutingContext) {
+	event.Model = model
+	if routerCtx == nil {
+		return
+	}
+	event.RoutingAlgorithm = string(routerCtx.Algorithm)
+	if !routerCtx.HasRouted() {
+		return
+	}
+	pod := routerCtx.TargetPod()
+	event.TargetPod = pod.Name
+	if baseModel := pod.Labels[modelIdentifier]; baseModel != "" && baseModel != model {
+		event.Model, event.Adapter = baseModel, model
+	}
+}
+
+// recordImmediateResponse records the status and the error header of a response generated by the gateway.
+func recordImmediateResponse(event *usagelog.Event, resp *extProcPb.ProcessingResponse) {
+	immediateResponse := resp.GetImmediateResponse()
+	if immediateResponse == nil {
+		return
+	}
+	event.StatusCode = int(immediateResponse.GetStatus().GetCode())
+	event.ErrorHeader = errorHeader(immediateResponse.GetHeaders().GetSetHeaders())
+}
+
+// emitUsage emits the usage event of a request once it ends.
+func (s *Server) emitUsage(event *usagelog.Event, start time.Time) {
+	if s.usageExporter == nil {
+		return
+	}
+	event.Timestamp = start
+	event.TotalLatencyMs = time.Since(start).Milliseconds()
+	s.usageExporter.Emit(*event)
+}
+
+// Close stops the admission queue dispatcher and flushes the usage and shadow events.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		if s.stopCh != nil {
+			close(s.stopCh)
+		}
+	})
+	if s.usageExporter != nil {
+		if err := s.usageExporter.Close(); err != nil {
+			klog.ErrorS(err, "failed to close usage exporter")
+		}
+	}
+	if s.shadowExporter != nil {
+		if err := s.shadowExporter.Close(); err != nil {
+			klog.ErrorS(err, "failed to close shadow exporter")
+		}
+	}
+}
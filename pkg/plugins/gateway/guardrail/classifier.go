@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ClassifyMethod is the full name of the gRPC method called by grpc classifier filters. The messages are
+// ClassifyRequest and ClassifyResponse encoded in JSON, with the "json" content subtype.
+const ClassifyMethod = "/aibrix.guardrail.v1.Classifier/Classify"
+
+// ClassifyRequest is the request to an external classifier.
+type ClassifyRequest struct {
+	Texts []string `json:"texts"`
+}
+
+// ClassifyResponse holds the matches of each text of the request, in the order of the texts.
+type ClassifyResponse struct {
+	Results []ClassifyResult `json:"results"`
+}
+
+// ClassifyResult holds the matches of a text, a match without a span flags the whole text.
+type ClassifyResult struct {
+	Matches []Match `json:"matches,omitempty"`
+}
+
+func (r *ClassifyResponse) matches(texts int) ([][]Match, error) {
+	if len(r.Results) != texts {
+		return nil, fmt.Errorf("classifier returned %d results for %d texts", len(r.Results), texts)
+	}
+	matches := make([][]Match, texts)
+	for i, result := range r.Results {
+		matches[i] = result.Matches
+	}
+	return matches, nil
+}
+
+// httpClassifier posts the texts to a classifier as a ClassifyRequest.
+type httpClassifier struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPClassifier(url string, timeout time.Duration) *httpClassifier {
+	return &httpClassifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (c *httpClassifier) Scan(ctx context.Context, texts []string) ([][]Match, error) {
+	body, err := json.Marshal(ClassifyRequest{Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("classifier returned status %d", resp.StatusCode)
+	}
+	var response ClassifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid classifier response: %w", err)
+	}
+	return response.matches(len(texts))
+}
+
+// jsonCodec encodes gRPC messages in JSON, so that classifiers don't depend on generated protobuf code.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+// grpcClassifier calls ClassifyMethod of a classifier.
+type grpcClassifier struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+func newGRPCClassifier(address string, timeout time.Duration) (*grpcClassifier, error) {
+	// The connection is established lazily on the first call.
+	conn, err := grpc.NewClient(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClassifier{conn: conn, timeout: timeout}, nil
+}
+
+func (c *grpcClassifier) Scan(ctx context.Context, texts []string) ([][]Match, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	var response ClassifyResponse
+	if err := c.conn.Invoke(ctx, ClassifyMethod, &ClassifyRequest{Texts: texts}, &response); err != nil {
+		return nil, err
+	}
+	return response.matches(len(texts))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	FilterTypeRegex   = "regex"
+	FilterTypeKeyword = "keyword"
+	FilterTypeHTTP    = "http"
+	FilterTypeGRPC    = "grpc"
+
+	defaultClassifierTimeoutMs = 1000
+)
+
+// Action is what a guardrail does with the texts matched by a filter.
+type Action string
+
+const (
+	// ActionBlock rejects the request or the response.
+	ActionBlock Action = "block"
+	// ActionRedact replaces the matches with RedactedText.
+	ActionRedact Action = "redact"
+	// ActionAnnotate lets the text pass, the findings are reported in a header.
+	ActionAnnotate Action = "annotate"
+)
+
+// Config declares the filters and the policies applying them to prompts and completions.
+type Config struct {
+	Filters  []FilterConfig `json:"filters,omitempty"`
+	Policies []PolicyConfig `json:"policies,omitempty"`
+}
+
+// FilterConfig declares a filter, either a regex, keyword, http or grpc filter.
+type FilterConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Patterns are the regular expressions of a regex filter.
+	Patterns []PatternConfig `json:"patterns,omitempty"`
+	// Keywords are matched case-insensitively by a keyword filter, the matches are of the category of the filter.
+	Keywords []string `json:"keywords,omitempty"`
+	// Category of the matches of a keyword filter, defaults to the name of the filter.
+	Category string `json:"category,omitempty"`
+	// URL is the endpoint of an http classifier.
+	URL string `json:"url,omitempty"`
+	// Address is the host:port of a grpc classifier.
+	Address string `json:"address,omitempty"`
+	// TimeoutMs bounds a classifier call, default 1000.
+	TimeoutMs int `json:"timeoutMs,omitempty"`
+	// FailOpen lets the texts pass if the classifier fails, they are rejected by default.
+	FailOpen bool `json:"failOpen,omitempty"`
+}
+
+// PatternConfig is a regular expression matching texts of a category, e.g. email.
+type PatternConfig struct {
+	Category string `json:"category"`
+	Pattern  string `json:"pattern"`
+}
+
+// PolicyConfig applies filters to the requests of models and users. The first policy selecting a request applies.
+type PolicyConfig struct {
+	Name string `json:"name,omitempty"`
+	// Models and Users select the requests of the policy by the model serving the request and the user,
+	// empty selects all.
+	Models []string `json:"models,omitempty"`
+	Users  []string `json:"users,omitempty"`
+	// Request rules apply to prompts, Response rules apply to completions, in order.
+	Request  []RuleConfig `json:"request,omitempty"`
+	Response []RuleConfig `json:"response,omitempty"`
+}
+
+// RuleConfig applies the action to the matches of a filter.
+type RuleConfig struct {
+	Filter string `json:"filter"`
+	Action Action `json:"action"`
+}
+
+// LoadConfig reads a guardrail config file in YAML or JSON.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("invalid guardrail config %s: %w", path, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Match is a text span matched by a filter.
+type Match struct {
+	Category string `json:"category"`
+	// Start and End are the byte offsets of the match, a match without a valid span covers the whole text.
+	Start int `json:"start,omitempty"`
+	End   int `json:"end,omitempty"`
+}
+
+// Filter finds matches in texts, e.g. PII or prompt injections.
+type Filter interface {
+	// Scan returns the matches in each of the texts, in the order of the texts.
+	Scan(ctx context.Context, texts []string) ([][]Match, error)
+}
+
+type pattern struct {
+	category string
+	re       *regexp.Regexp
+}
+
+type regexFilter struct {
+	patterns []pattern
+}
+
+func newRegexFilter(configs []PatternConfig) (*regexFilter, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no pattern")
+	}
+	f := &regexFilter{}
+	for _, config := range configs {
+		if config.Category == "" {
+			return nil, fmt.Errorf("no category for pattern %q", config.Pattern)
+		}
+		re, err := regexp.Compile(config.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of category %s: %w", config.Category, err)
+		}
+		f.patterns = append(f.patterns, pattern{category: config.Category, re: re})
+	}
+	return f, nil
+}
+
+// newKeywordFilter matches any of the keywords case-insensitively.
+func newKeywordFilter(category string, keywords []string) (*regexFilter, error) {
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("no keyword")
+	}
+	quoted := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword == "" {
+			return nil, fmt.Errorf("empty keyword")
+		}
+		quoted = append(quoted, regexp.QuoteMeta(keyword))
+	}
+	return &regexFilter{patterns: []pattern{{
+		category: category,
+		re:       regexp.MustCompile("(?i)" + strings.Join(quoted, "|")),
+	}}}, nil
+}
+
+func (f *regexFilter) Scan(_ context.Context, texts []string) ([][]Match, error) {
+	matches := make([][]Match, len(texts))
+	for i, text := range texts {
+		for _, p := range f.patterns {
+			for _, loc := range p.re.FindAllStringIndex(text, -1) {
+				if loc[1] > loc[0] {
+					matches[i] = append(matches[i], Match{Category: p.category, Start: loc[0], End: loc[1]})
+				}
+			}
+		}
+	}
+	return matches, nil
+}
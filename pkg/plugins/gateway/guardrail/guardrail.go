@@ -0,0 +1,276 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package guardrail applies filter chains to the prompts and the completions of the requests served by the gateway,
+// to block, redact or annotate texts matched by the filters, e.g. PII and prompt injections.
+package guardrail
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// RedactedText replaces the redacted matches.
+const RedactedText = "[REDACTED]"
+
+// Stage is the side of a request the filters apply to.
+type Stage string
+
+const (
+	StageRequest  Stage = "request"
+	StageResponse Stage = "response"
+)
+
+// Finding is a category of texts matched by a filter.
+type Finding struct {
+	Filter   string
+	Category string
+	Action   Action
+}
+
+func (f Finding) String() string {
+	return f.Filter + "/" + f.Category
+}
+
+// Result is the outcome of the filter chain of a policy.
+type Result struct {
+	// Texts are the checked texts, with the redactions applied.
+	Texts    []string
+	Redacted bool
+	// Blocked is the finding blocking the texts, nil if the texts pass.
+	Blocked *Finding
+	// Findings are the distinct findings redacted or annotated.
+	Findings []Finding
+}
+
+type filter struct {
+	Filter
+	failOpen bool
+}
+
+type rule struct {
+	filter string
+	action Action
+}
+
+type policy struct {
+	name     string
+	models   []string
+	users    []string
+	request  []rule
+	response []rule
+}
+
+func (p *policy) selects(model, user string) bool {
+	return (len(p.models) == 0 || slices.Contains(p.models, model)) && (len(p.users) == 0 || slices.Contains(p.users, user))
+}
+
+func (p *policy) rules(stage Stage) []rule {
+	if stage == StageRequest {
+		return p.request
+	}
+	return p.response
+}
+
+// Guardrail applies the filters of the policies selecting requests.
+type Guardrail struct {
+	filters  map[string]filter
+	policies []*policy
+}
+
+// New builds the filters and the policies of a config, a config referring to unknown filters is invalid.
+func New(config Config) (*Guardrail, error) {
+	g := &Guardrail{filters: map[string]filter{}}
+	for _, fc := range config.Filters {
+		if fc.Name == "" {
+			return nil, fmt.Errorf("filter without name")
+		}
+		if _, ok := g.filters[fc.Name]; ok {
+			return nil, fmt.Errorf("duplicated filter %s", fc.Name)
+		}
+		f, err := newFilter(fc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %s: %w", fc.Name, err)
+		}
+		g.filters[fc.Name] = filter{Filter: f, failOpen: fc.FailOpen}
+	}
+
+	for i, pc := range config.Policies {
+		p := &policy{name: pc.Name, models: pc.Models, users: pc.Users}
+		if p.name == "" {
+			p.name = fmt.Sprintf("policy-%d", i)
+		}
+		var err error
+		if p.request, err = g.newRules(pc.Request); err != nil {
+			return nil, fmt.Errorf("invalid request rules of policy %s: %w", p.name, err)
+		}
+		if p.response, err = g.newRules(pc.Response); err != nil {
+			return nil, fmt.Errorf("invalid response rules of policy %s: %w", p.name, err)
+		}
+		g.policies = append(g.policies, p)
+	}
+	return g, nil
+}
+
+func newFilter(config FilterConfig) (Filter, error) {
+	timeout := time.Duration(config.TimeoutMs) * time.Millisecond
+	if config.TimeoutMs <= 0 {
+		timeout = defaultClassifierTimeoutMs * time.Millisecond
+	}
+	switch config.Type {
+	case FilterTypeRegex:
+		return newRegexFilter(config.Patterns)
+	case FilterTypeKeyword:
+		category := config.Category
+		if category == "" {
+			category = config.Name
+		}
+		return newKeywordFilter(category, config.Keywords)
+	case FilterTypeHTTP:
+		if config.URL == "" {
+			return nil, fmt.Errorf("no url")
+		}
+		return newHTTPClassifier(config.URL, timeout), nil
+	case FilterTypeGRPC:
+		if config.Address == "" {
+			return nil, fmt.Errorf("no address")
+		}
+		return newGRPCClassifier(config.Address, timeout)
+	default:
+		return nil, fmt.Errorf("unknown type %q, supported: %s, %s, %s, %s", config.Type,
+			FilterTypeRegex, FilterTypeKeyword, FilterTypeHTTP, FilterTypeGRPC)
+	}
+}
+
+func (g *Guardrail) newRules(configs []RuleConfig) ([]rule, error) {
+	rules := make([]rule, 0, len(configs))
+	for _, rc := range configs {
+		if _, ok := g.filters[rc.Filter]; !ok {
+			return nil, fmt.Errorf("unknown filter %s", rc.Filter)
+		}
+		switch rc.Action {
+		case ActionBlock, ActionRedact, ActionAnnotate:
+		default:
+			return nil, fmt.Errorf("unknown action %q of filter %s, supported: %s, %s, %s", rc.Action, rc.Filter,
+				ActionBlock, ActionRedact, ActionAnnotate)
+		}
+		rules = append(rules, rule{filter: rc.Filter, action: rc.Action})
+	}
+	return rules, nil
+}
+
+func (g *Guardrail) policy(model, user string) *policy {
+	if g == nil {
+		return nil
+	}
+	for _, p := range g.policies {
+		if p.selects(model, user) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Enabled returns true if a policy applies filters to the stage of the requests of the model and the user.
+func (g *Guardrail) Enabled(stage Stage, model, user string) bool {
+	p := g.policy(model, user)
+	return p != nil && len(p.rules(stage)) > 0
+}
+
+// Check runs the filter chain of the policy selecting the model and the user on the texts of a stage, the rules
+// apply in order and the chain stops at the first blocking finding. It returns an error if a filter failed and
+// is not failing open.
+func (g *Guardrail) Check(ctx context.Context, stage Stage, model, user string, texts []string) (*Result, error) {
+	result := &Result{Texts: texts}
+	p := g.policy(model, user)
+	if p == nil || len(texts) == 0 {
+		return result, nil
+	}
+	seen := map[Finding]struct{}{}
+	for _, r := range p.rules(stage) {
+		f := g.filters[r.filter]
+		matches, err := f.Scan(ctx, result.Texts)
+		if err == nil && len(matches) != len(result.Texts) {
+			err = fmt.Errorf("filter returned %d results for %d texts", len(matches), len(result.Texts))
+		}
+		if err != nil {
+			if f.failOpen {
+				klog.ErrorS(err, "guardrail filter failed, texts pass", "filter", r.filter, "policy", p.name)
+				continue
+			}
+			return nil, fmt.Errorf("guardrail filter %s failed: %w", r.filter, err)
+		}
+
+		for i, textMatches := range matches {
+			if len(textMatches) == 0 {
+				continue
+			}
+			for _, match := range textMatches {
+				finding := Finding{Filter: r.filter, Category: match.Category, Action: r.action}
+				if r.action == ActionBlock {
+					result.Blocked = &finding
+					return result, nil
+				}
+				if _, ok := seen[finding]; !ok {
+					seen[finding] = struct{}{}
+					result.Findings = append(result.Findings, finding)
+				}
+			}
+			if r.action == ActionRedact {
+				if !result.Redacted {
+					// The texts of the caller are not modified.
+					result.Texts = slices.Clone(result.Texts)
+					result.Redacted = true
+				}
+				result.Texts[i] = redact(result.Texts[i], textMatches)
+			}
+		}
+	}
+	return result, nil
+}
+
+// redact replaces the spans of the matches in the text, overlapping spans are merged.
+func redact(text string, matches []Match) string {
+	spans := make([]Match, 0, len(matches))
+	for _, match := range matches {
+		if match.Start < 0 || match.End <= match.Start || match.End > len(text) {
+			return RedactedText
+		}
+		spans = append(spans, match)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	var out strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span.End <= last {
+			continue
+		}
+		if span.Start >= last {
+			out.WriteString(text[last:span.Start])
+			out.WriteString(RedactedText)
+		}
+		last = span.End
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ConcurrencyLimiter defines an interface for limiting in-flight requests.
+type ConcurrencyLimiter interface {
+	// Acquire takes a slot of the given key if less than limit slots are taken.
+	// Returns whether the slot is taken and an error if the operation fails.
+	Acquire(ctx context.Context, key string, limit int64) (bool, error)
+
+	// Release returns a slot taken by Acquire for the given key.
+	Release(ctx context.Context, key string) error
+}
+
+var acquireScript = redis.NewScript(`
+local current = redis.call("INCR", KEYS[1])
+if current > tonumber(ARGV[1]) then
+	redis.call("DECR", KEYS[1])
+	return 0
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1
+`)
+
+var releaseScript = redis.NewScript(`
+local current = redis.call("DECR", KEYS[1])
+if current <= 0 then
+	redis.call("DEL", KEYS[1])
+end
+return current
+`)
+
+type redisConcurrencyLimiter struct {
+	client *redis.Client
+	name   string
+	ttl    time.Duration
+}
+
+// NewRedisConcurrencyLimiter is a counting semaphore shared by all gateway replicas.
+// Slots leaked by a crashed replica are reclaimed once the key is not acquired for ttl.
+func NewRedisConcurrencyLimiter(name string, client *redis.Client, ttl time.Duration) ConcurrencyLimiter {
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+
+	return &redisConcurrencyLimiter{
+		name:   name,
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (rcl redisConcurrencyLimiter) Acquire(ctx context.Context, key string, limit int64) (bool, error) {
+	acquired, err := acquireScript.Run(ctx, rcl.client, []string{rcl.genKey(key)}, limit, rcl.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+func (rcl redisConcurrencyLimiter) Release(ctx context.Context, key string) error {
+	return releaseScript.Run(ctx, rcl.client, []string{rcl.genKey(key)}).Err()
+}
+
+func (rcl redisConcurrencyLimiter) genKey(key string) string {
+	return fmt.Sprintf("%s:%s", rcl.name, key)
+}
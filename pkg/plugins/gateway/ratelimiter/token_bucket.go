@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenBucketRateLimiter defines an interface for token bucket rate limiting.
+// A bucket holds at most burst tokens and refills at rate tokens per second.
+type TokenBucketRateLimiter interface {
+	// Take removes n tokens from the bucket of the given key if enough tokens are left.
+	// Returns whether the tokens are taken, the tokens left in the bucket and an error if the operation fails.
+	Take(ctx context.Context, key string, n int64, rate float64, burst int64) (bool, int64, error)
+
+	// Charge removes n tokens from the bucket of the given key regardless of the tokens left,
+	// so the bucket can go into debt. A negative n returns tokens to the bucket.
+	// Returns the tokens left in the bucket and an error if the operation fails.
+	Charge(ctx context.Context, key string, n int64, rate float64, burst int64) (int64, error)
+}
+
+// tokenBucketScript refills the bucket by the elapsed time and then takes tokens atomically.
+// The bucket expires once it would have been refilled to burst, which is the same as a missing bucket.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+local force = ARGV[5] == "1"
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+	ts = now
+end
+
+local taken = 0
+if force or tokens >= n then
+	tokens = math.min(burst, tokens - n)
+	taken = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", ts)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {taken, math.floor(tokens)}
+`)
+
+type redisTokenBucketRateLimiter struct {
+	client *redis.Client
+	name   string
+}
+
+// NewRedisTokenBucketRateLimiter is a token bucket rate limiter which allows bursts of up to burst tokens.
+func NewRedisTokenBucketRateLimiter(name string, client *redis.Client) TokenBucketRateLimiter {
+	return &redisTokenBucketRateLimiter{
+		name:   name,
+		client: client,
+	}
+}
+
+func (rtb redisTokenBucketRateLimiter) Take(ctx context.Context, key string, n int64, rate float64, burst int64) (bool, int64, error) {
+	return rtb.take(ctx, key, n, rate, burst, false)
+}
+
+func (rtb redisTokenBucketRateLimiter) Charge(ctx context.Context, key string, n int64, rate float64, burst int64) (int64, error) {
+	_, tokens, err := rtb.take(ctx, key, n, rate, burst, true)
+	return tokens, err
+}
+
+func (rtb redisTokenBucketRateLimiter) take(ctx context.Context, key string, n int64, rate float64, burst int64, force bool) (bool, int64, error) {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) || burst <= 0 {
+		return false, 0, fmt.Errorf("invalid token bucket, rate: %v, burst: %v", rate, burst)
+	}
+
+	forceArg := "0"
+	if force {
+		forceArg = "1"
+	}
+	res, err := tokenBucketScript.Run(ctx, rtb.client, []string{rtb.genKey(key)},
+		rate, burst, time.Now().UnixMilli(), n, forceArg).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket result: %v", res)
+	}
+	return res[0] == 1, res[1], nil
+}
+
+func (rtb redisTokenBucketRateLimiter) genKey(key string) string {
+	return fmt.Sprintf("%s:%s", rtb.name, key)
+}
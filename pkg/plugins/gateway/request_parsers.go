@@ -0,0 +1,283 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/openai/openai-go"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"github.com/vllm-project/aibrix/pkg/utils/chattemplate"
+	"k8s.io/klog/v2"
+)
+
+// requestBodyParser extracts the model, the routable message and the stream flag from a request body.
+// The message is the text used by routers (e.g. prefix-cache) to make routing decisions.
+type requestBodyParser func(requestID string, requestBody []byte, user utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse)
+
+var requestBodyParsers = map[string]requestBodyParser{}
+
+func init() {
+	registerRequestBodyParser(PathChatCompletions, parseChatCompletionsRequest)
+	registerRequestBodyParser(PathCompletions, parseCompletionsRequest)
+	registerRequestBodyParser(PathEmbeddings, parseEmbeddingsRequest)
+	registerRequestBodyParser(PathRerank, parseRerankRequest)
+	registerRequestBodyParser(PathScore, parseScoreRequest)
+	registerRequestBodyParser(PathAudioTranscriptions, parseAudioRequest)
+	registerRequestBodyParser(PathAudioTranslations, parseAudioRequest)
+}
+
+// registerRequestBodyParser registers the body parser for an OpenAI-compatible request path.
+func registerRequestBodyParser(requestPath string, parser requestBodyParser) {
+	requestBodyParsers[requestPath] = parser
+}
+
+// isAudioRequestPath returns true if the request path is an audio api, whose response carries neither model nor token usage.
+func isAudioRequestPath(requestPath string) bool {
+	return strings.HasPrefix(requestPath, PathAudioPrefix)
+}
+
+// nolint:nakedret
+func parseChatCompletionsRequest(requestID string, requestBody []byte, user utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse) {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(requestBody, &jsonMap); err != nil {
+		klog.ErrorS(err, "error to unmarshal request body", "requestID", requestID, "requestBody", string(requestBody))
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+
+	chatCompletionObj := openai.ChatCompletionNewParams{}
+	if err := json.Unmarshal(requestBody, &chatCompletionObj); err != nil {
+		klog.ErrorS(err, "error to unmarshal chat completions object", "requestID", requestID, "requestBody", string(requestBody))
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+	model = chatCompletionObj.Model
+	if message, errRes = getChatCompletionsMessage(requestID, chatCompletionObj); errRes != nil {
+		return
+	}
+	if prompt, ok := renderChatTemplate(requestID, model, requestBody); ok {
+		message = prompt
+	}
+	errRes = validateStreamOptions(requestID, user, &stream, chatCompletionObj.StreamOptions, jsonMap)
+	return
+}
+
+// renderChatTemplate renders the prompt with the chat template of the model, so routers such as prefix-cache
+// see the same prompt as the engine, including role markers, system prompt and tool definitions.
+// It returns false if the model has no chat template or the rendering fails. Templates using constructs the
+// evaluator does not support fail on every request, they are logged at V(4) only.
+func renderChatTemplate(requestID, model string, requestBody []byte) (string, bool) {
+	template, ok := routing.LookupChatTemplate(model)
+	if !ok {
+		return "", false
+	}
+	prompt, err := template.RenderRequest(requestBody)
+	if errors.Is(err, chattemplate.ErrUnsupported) {
+		klog.V(4).InfoS("chat template is not supported, use the flattened messages", "requestID", requestID, "model", model, "error", err)
+		return "", false
+	}
+	if err != nil {
+		klog.ErrorS(err, "failed to render chat template, use the flattened messages", "requestID", requestID, "model", model)
+		return "", false
+	}
+	return prompt, true
+}
+
+// nolint:nakedret
+func parseCompletionsRequest(requestID string, requestBody []byte, _ utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse) {
+	// openai.CompletionsNewParams does not support json unmarshal for CompletionNewParamsPromptUnion in release v0.1.0-beta.10
+	// once supported, input request will be directly unmarshal into openai.CompletionsNewParams
+	type Completion struct {
+		Prompt string `json:"prompt"`
+		Model  string `json:"model"`
+	}
+	completionObj := Completion{}
+	if err := json.Unmarshal(requestBody, &completionObj); err != nil {
+		klog.ErrorS(err, "error to unmarshal chat completions object", "requestID", requestID, "requestBody", string(requestBody))
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_InternalServerError, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+	model = completionObj.Model
+	message = completionObj.Prompt
+	return
+}
+
+// nolint:nakedret
+func parseEmbeddingsRequest(requestID string, requestBody []byte, _ utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse) {
+	type Embedding struct {
+		Model string          `json:"model"`
+		Input json.RawMessage `json:"input"`
+	}
+	embeddingObj := Embedding{}
+	if err := json.Unmarshal(requestBody, &embeddingObj); err != nil {
+		klog.ErrorS(err, "error to unmarshal embeddings object", "requestID", requestID, "requestBody", string(requestBody))
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+	if len(embeddingObj.Input) == 0 {
+		klog.ErrorS(nil, "no input in the request body", "requestID", requestID)
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "no input in the request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+	model = embeddingObj.Model
+	message = getTextOrTextArray(embeddingObj.Input)
+	return
+}
+
+// nolint:nakedret
+func parseRerankRequest(requestID string, requestBody []byte, _ utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse) {
+	type Rerank struct {
+		Model     string            `json:"model"`
+		Query     string            `json:"query"`
+		Documents []json.RawMessage `json:"documents"`
+	}
+	rerankObj := Rerank{}
+	if err := json.Unmarshal(requestBody, &rerankObj); err != nil {
+		klog.ErrorS(err, "error to unmarshal rerank object", "requestID", requestID, "requestBody", string(requestBody))
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+	if rerankObj.Query == "" || len(rerankObj.Documents) == 0 {
+		klog.ErrorS(nil, "no query or documents in the request body", "requestID", requestID)
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "no query or documents in the request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+
+	// Query goes first so that requests sharing the same query share the same prefix.
+	texts := []string{rerankObj.Query}
+	for _, document := range rerankObj.Documents {
+		// A document is either a plain string or an object with a text field.
+		var doc struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(document, &doc); err == nil {
+			texts = append(texts, doc.Text)
+		} else {
+			texts = append(texts, getTextOrTextArray(document))
+		}
+	}
+	model = rerankObj.Model
+	message = strings.Join(texts, " ")
+	return
+}
+
+// nolint:nakedret
+func parseScoreRequest(requestID string, requestBody []byte, _ utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse) {
+	type Score struct {
+		Model string          `json:"model"`
+		Text1 json.RawMessage `json:"text_1"`
+		Text2 json.RawMessage `json:"text_2"`
+	}
+	scoreObj := Score{}
+	if err := json.Unmarshal(requestBody, &scoreObj); err != nil {
+		klog.ErrorS(err, "error to unmarshal score object", "requestID", requestID, "requestBody", string(requestBody))
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+	if len(scoreObj.Text1) == 0 || len(scoreObj.Text2) == 0 {
+		klog.ErrorS(nil, "no text_1 or text_2 in the request body", "requestID", requestID)
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "no text_1 or text_2 in the request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+	model = scoreObj.Model
+	message = getTextOrTextArray(scoreObj.Text1) + " " + getTextOrTextArray(scoreObj.Text2)
+	return
+}
+
+// parseAudioRequest parses multipart/form-data audio transcription and translation requests.
+// Only form fields are read, the audio file part is skipped.
+// nolint:nakedret
+func parseAudioRequest(requestID string, requestBody []byte, _ utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse) {
+	boundary, ok := getMultipartBoundary(requestBody)
+	if !ok {
+		klog.ErrorS(nil, "audio request body is not multipart/form-data", "requestID", requestID)
+		errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(requestBody), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			klog.ErrorS(err, "error to read multipart form", "requestID", requestID)
+			errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+			return
+		}
+		if part.FileName() != "" {
+			continue
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			klog.ErrorS(err, "error to read multipart form field", "requestID", requestID, "field", part.FormName())
+			errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
+			return
+		}
+		switch part.FormName() {
+		case "model":
+			model = string(value)
+		case "prompt":
+			message = string(value)
+		case "stream":
+			if stream, err = strconv.ParseBool(string(value)); err != nil {
+				klog.ErrorS(err, "stream incorrectly set", "requestID", requestID)
+				errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "stream incorrectly set", HeaderErrorStream, "stream incorrectly set")
+				return
+			}
+		}
+	}
+	return
+}
+
+// getMultipartBoundary extracts the boundary from the first delimiter line of a multipart body.
+// Request headers are not passed to body parsers, so the boundary is read from the body instead,
+// which always starts with "--<boundary>".
+func getMultipartBoundary(body []byte) (string, bool) {
+	line, _, _ := bytes.Cut(body, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) <= 2 || !bytes.HasPrefix(line, []byte("--")) {
+		return "", false
+	}
+	return string(line[2:]), true
+}
+
+// getTextOrTextArray returns the text of a json value that is either a string or an array of strings.
+// Other values, e.g. token ids, are returned as raw json.
+func getTextOrTextArray(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var texts []string
+	if err := json.Unmarshal(raw, &texts); err == nil {
+		return strings.Join(texts, " ")
+	}
+	return string(raw)
+}
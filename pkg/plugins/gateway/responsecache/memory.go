@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"context"
+	"time"
+
+	lrustore "github.com/vllm-project/aibrix/pkg/utils/lrustore"
+)
+
+type memoryEntry struct {
+	response  *Response
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	store *lrustore.LRUStore[string, memoryEntry]
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewMemoryStore returns a store caching up to capacity responses in the memory of the gateway, the least recently
+// cached responses are evicted first.
+func NewMemoryStore(capacity int, ttl time.Duration) Store {
+	return newMemoryStore(capacity, ttl, lrustore.DefaultGetCurrentTime)
+}
+
+func newMemoryStore(capacity int, ttl time.Duration, now func() time.Time) *memoryStore {
+	// Expired entries are never returned, the store evicts them in the background to free the memory.
+	return &memoryStore{
+		store: lrustore.NewLRUStore[string, memoryEntry](capacity, ttl, ttl, now),
+		ttl:   ttl,
+		now:   now,
+	}
+}
+
+func (m *memoryStore) Get(_ context.Context, key string) (*Response, bool, error) {
+	entry, ok := m.store.Get(key)
+	if !ok || !m.now().Before(entry.expiresAt) {
+		return nil, false, nil
+	}
+	return entry.response, true, nil
+}
+
+func (m *memoryStore) Put(_ context.Context, key string, response *Response) error {
+	m.store.Put(key, memoryEntry{response: response, expiresAt: m.now().Add(m.ttl)})
+	return nil
+}
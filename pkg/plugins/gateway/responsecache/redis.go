@@ -0,0 +1,65 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStore struct {
+	client *redis.Client
+	name   string
+	ttl    time.Duration
+}
+
+// NewRedisStore returns a store caching responses in redis, shared by all gateway replicas.
+func NewRedisStore(name string, client *redis.Client, ttl time.Duration) Store {
+	return &redisStore{client: client, name: name, ttl: ttl}
+}
+
+func (r *redisStore) Get(ctx context.Context, key string) (*Response, bool, error) {
+	value, err := r.client.Get(ctx, r.genKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	var response Response
+	if err := json.Unmarshal(value, &response); err != nil {
+		return nil, false, err
+	}
+	return &response, true, nil
+}
+
+func (r *redisStore) Put(ctx context.Context, key string, response *Response) error {
+	value, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.genKey(key), value, r.ttl).Err()
+}
+
+func (r *redisStore) genKey(key string) string {
+	return fmt.Sprintf("%s:response_cache:%s", r.name, key)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package responsecache caches the responses of deterministic requests served by the gateway, so that identical
+// requests are answered without reaching the engine.
+package responsecache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Response is a cached response, as sent to the client.
+type Response struct {
+	Body []byte `json:"body"`
+	// Stream is set if the body is a server-sent events stream.
+	Stream           bool  `json:"stream"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// Store stores cached responses by key, entries expire after the TTL of the store.
+type Store interface {
+	// Get returns the response cached for the key, false if there is none.
+	Get(ctx context.Context, key string) (*Response, bool, error)
+
+	// Put caches a response for the key.
+	Put(ctx context.Context, key string, response *Response) error
+}
+
+// ignoredFields are request fields that do not change the response.
+var ignoredFields = []string{"user"}
+
+// Key returns the cache key of a request body, false if the request is not deterministic, i.e. it does not sample
+// with an explicit zero temperature. Requests of different scopes, e.g. users, never share responses.
+// The key is a hash of the normalized body, so that the field order and formatting of the body don't matter.
+func Key(scope, model string, body []byte) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var request map[string]any
+	if err := decoder.Decode(&request); err != nil {
+		return "", false
+	}
+	temperature, ok := request["temperature"].(json.Number)
+	if !ok {
+		return "", false
+	}
+	if value, err := temperature.Float64(); err != nil || value != 0 {
+		return "", false
+	}
+	request["temperature"] = 0
+	for _, field := range ignoredFields {
+		delete(request, field)
+	}
+	// Maps are marshaled with sorted keys.
+	normalized, err := json.Marshal(request)
+	if err != nil {
+		return "", false
+	}
+
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(scope), []byte(model), normalized} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	key, ok := Key("u1", "m1", []byte(`{"model": "m1", "temperature": 0, "messages": [{"role": "user", "content": "hi"}]}`))
+	assert.True(t, ok)
+
+	// The field order, formatting and the end-user identifier don't change the key.
+	same, ok := Key("u1", "m1", []byte(`{"messages":[{"content":"hi","role":"user"}],"temperature":0.0,"model":"m1","user":"end-user"}`))
+	assert.True(t, ok)
+	assert.Equal(t, key, same)
+
+	for _, other := range []struct{ scope, model, body string }{
+		{"u2", "m1", `{"model": "m1", "temperature": 0, "messages": [{"role": "user", "content": "hi"}]}`},
+		{"u1", "m2", `{"model": "m1", "temperature": 0, "messages": [{"role": "user", "content": "hi"}]}`},
+		{"u1", "m1", `{"model": "m1", "temperature": 0, "messages": [{"role": "user", "content": "hello"}]}`},
+		{"u1", "m1", `{"model": "m1", "temperature": 0, "messages": [{"role": "user", "content": "hi"}], "stream": true}`},
+	} {
+		otherKey, ok := Key(other.scope, other.model, []byte(other.body))
+		assert.True(t, ok)
+		assert.NotEqual(t, key, otherKey)
+	}
+
+	// Only requests with an explicit zero temperature are deterministic.
+	for _, body := range []string{
+		`{"model": "m1", "messages": [{"role": "user", "content": "hi"}]}`,
+		`{"model": "m1", "temperature": 0.7, "messages": [{"role": "user", "content": "hi"}]}`,
+		`{"model": "m1", "temperature": "0", "messages": [{"role": "user", "content": "hi"}]}`,
+		`not json`,
+	} {
+		_, ok := Key("u1", "m1", []byte(body))
+		assert.False(t, ok, body)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := newMemoryStore(2, time.Minute, func() time.Time { return now })
+	ctx := context.Background()
+
+	_, ok, err := store.Get(ctx, "k1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	response := &Response{Body: []byte(`{"id":"1"}`), PromptTokens: 3, CompletionTokens: 4}
+	assert.NoError(t, store.Put(ctx, "k1", response))
+	cached, ok, err := store.Get(ctx, "k1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, response, cached)
+
+	// The least recently cached response is evicted over capacity.
+	assert.NoError(t, store.Put(ctx, "k2", response))
+	assert.NoError(t, store.Put(ctx, "k3", response))
+	_, ok, _ = store.Get(ctx, "k1")
+	assert.False(t, ok)
+	_, ok, _ = store.Get(ctx, "k3")
+	assert.True(t, ok)
+
+	// Responses expire after the TTL.
+	now = now.Add(time.Minute)
+	_, ok, _ = store.Get(ctx, "k3")
+	assert.False(t, ok)
+}
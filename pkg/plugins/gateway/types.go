@@ -26,6 +26,7 @@ const (
 
 	// General Error Headers
 	HeaderErrorUser                  = "x-error-user"
+	HeaderErrorAuthentication        = "x-error-authentication"
 	HeaderErrorRouting               = "x-error-routing"
 	HeaderErrorRequestBodyProcessing = "x-error-request-body-processing"
 	HeaderErrorResponseUnmarshal     = "x-error-response-unmarshal"
@@ -34,6 +35,8 @@ const (
 	// Model & Deployment Headers
 	HeaderErrorNoModelInRequest = "x-error-no-model-in-request"
 	HeaderErrorNoModelBackends  = "x-error-no-model-backends"
+	HeaderErrorModelNotAllowed  = "x-error-model-not-allowed"
+	HeaderErrorModelNotFound    = "x-error-model-not-found"
 
 	// Streaming Headers
 	HeaderErrorStream                    = "x-error-stream"
@@ -46,19 +49,56 @@ const (
 	HeaderRoutingStrategy    = "routing-strategy"
 	HeaderRequestID          = "request-id"
 	HeaderModel              = "model"
+	HeaderRetryAttempts      = "x-retry-attempts"
+	HeaderResponseCache      = "x-aibrix-response-cache"
+	HeaderPrefillerHostPort  = "x-prefiller-host-port"
 
 	// RPM & TPM Update Errors
-	HeaderUpdateTPM        = "x-update-tpm"
-	HeaderUpdateRPM        = "x-update-rpm"
-	HeaderErrorRPMExceeded = "x-error-rpm-exceeded"
-	HeaderErrorTPMExceeded = "x-error-tpm-exceeded"
-	HeaderErrorIncrRPM     = "x-error-incr-rpm"
-	HeaderErrorIncrTPM     = "x-error-incr-tpm"
+	HeaderUpdateTPM          = "x-update-tpm"
+	HeaderUpdateRPM          = "x-update-rpm"
+	HeaderErrorRPMExceeded   = "x-error-rpm-exceeded"
+	HeaderErrorTPMExceeded   = "x-error-tpm-exceeded"
+	HeaderErrorIncrRPM       = "x-error-incr-rpm"
+	HeaderErrorIncrTPM       = "x-error-incr-tpm"
+	HeaderErrorQuotaExceeded = "x-error-quota-exceeded"
+
+	// Rate Limit Headers, the same as returned by OpenAI
+	HeaderRetryAfter                 = "retry-after"
+	HeaderRateLimitLimitRequests     = "x-ratelimit-limit-requests"
+	HeaderRateLimitLimitTokens       = "x-ratelimit-limit-tokens"
+	HeaderRateLimitRemainingRequests = "x-ratelimit-remaining-requests"
+	HeaderRateLimitRemainingTokens   = "x-ratelimit-remaining-tokens"
+	HeaderRateLimitResetRequests     = "x-ratelimit-reset-requests"
+	HeaderRateLimitResetTokens       = "x-ratelimit-reset-tokens"
+
+	// Concurrency Errors
+	HeaderErrorConcurrencyExceeded = "x-error-concurrency-exceeded"
+	HeaderErrorAcquireConcurrency  = "x-error-acquire-concurrency"
+
+	// Admission Queue Headers
+	HeaderPriorityClass     = "priority-class"
+	HeaderErrorQueueFull    = "x-error-queue-full"
+	HeaderErrorQueueTimeout = "x-error-queue-timeout"
+
+	// Guardrail Headers
+	HeaderGuardrail      = "x-aibrix-guardrail"
+	HeaderErrorGuardrail = "x-error-guardrail"
 
 	// Rate Limiting defaults
 	DefaultRPM           = 100
 	DefaultTPMMultiplier = 1000
 
+	// OpenAI-compatible request paths
+	PathModels              = "/v1/models"
+	PathChatCompletions     = "/v1/chat/completions"
+	PathCompletions         = "/v1/completions"
+	PathEmbeddings          = "/v1/embeddings"
+	PathRerank              = "/v1/rerank"
+	PathScore               = "/v1/score"
+	PathAudioPrefix         = "/v1/audio/"
+	PathAudioTranscriptions = "/v1/audio/transcriptions"
+	PathAudioTranslations   = "/v1/audio/translations"
+
 	// Envs
 	EnvRoutingAlgorithm = "ROUTING_ALGORITHM"
 )
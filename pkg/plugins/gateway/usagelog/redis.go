@@ -0,0 +1,64 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usagelog
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisExportTimeout = 5 * time.Second
+
+// RedisStreamSink appends events to a Redis stream, each entry has a single "event" field holding the json event.
+// The stream is approximately trimmed to maxLen entries, 0 keeps all entries.
+type RedisStreamSink[T any] struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamSink[T any](client *redis.Client, stream string, maxLen int64) *RedisStreamSink[T] {
+	return &RedisStreamSink[T]{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (s *RedisStreamSink[T]) Export(events []T) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisExportTimeout)
+	defer cancel()
+
+	pipe := s.client.Pipeline()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.stream,
+			MaxLen: s.maxLen,
+			Approx: s.maxLen > 0,
+			Values: map[string]interface{}{"event": data},
+		})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisStreamSink[T]) Close() error {
+	return nil
+}
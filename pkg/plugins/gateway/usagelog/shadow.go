@@ -0,0 +1,46 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usagelog
+
+import "time"
+
+// ShadowEvent compares the response of a request with the response of its copy mirrored to a shadow model,
+// emitted once both end.
+type ShadowEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"request_id"`
+	User      string    `json:"user,omitempty"`
+	// PromptHash is the sha256 of the prompt, so that responses to the same prompt can be compared
+	// without exporting the prompt.
+	PromptHash string         `json:"prompt_hash"`
+	Primary    ShadowResponse `json:"primary"`
+	Shadow     ShadowResponse `json:"shadow"`
+}
+
+// ShadowResponse is the response of a model to a shadowed request.
+type ShadowResponse struct {
+	Model     string `json:"model"`
+	TargetPod string `json:"target_pod,omitempty"`
+	// Output is the generated text, truncated to the configured max size.
+	Output           string `json:"output"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	LatencyMs        int64  `json:"latency_ms"`
+	StatusCode       int    `json:"status_code"`
+	// Error is set if the request failed before a response was received.
+	Error string `json:"error,omitempty"`
+}
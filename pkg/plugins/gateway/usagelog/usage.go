@@ -0,0 +1,164 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package usagelog exports structured events of the requests served by the gateway, e.g. usage events for billing
+// and chargeback, and shadow events comparing the responses of a model and a candidate model.
+package usagelog
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Event is the usage record of a request, emitted once the request ends.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"request_id"`
+	User      string    `json:"user,omitempty"`
+	// Model is the base model serving the request, Adapter is set if the request targets a LoRA adapter of the model.
+	Model            string `json:"model"`
+	Adapter          string `json:"adapter,omitempty"`
+	TargetPod        string `json:"target_pod,omitempty"`
+	RoutingAlgorithm string `json:"routing_algorithm,omitempty"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	// TTFTMs is the time to the first response body chunk, TotalLatencyMs is the time to the end of the request.
+	TTFTMs         int64 `json:"ttft_ms"`
+	TotalLatencyMs int64 `json:"total_latency_ms"`
+	StatusCode     int   `json:"status_code"`
+	// ErrorHeader is the x-error-* header of a request rejected or failed by the gateway.
+	ErrorHeader string `json:"error_header,omitempty"`
+	// CacheHit is set if the request was served from the response cache, the tokens are the ones of the cached
+	// response.
+	CacheHit bool `json:"cache_hit,omitempty"`
+}
+
+// Sink exports events of type T.
+type Sink[T any] interface {
+	// Export exports a batch of events, it is called by a single goroutine.
+	Export(events []T) error
+
+	// Close flushes buffered events and releases the resources of the sink.
+	Close() error
+}
+
+// Exporter exports events to sinks asynchronously, so that slow sinks never block requests.
+// Events are dropped if the buffer is full.
+type Exporter[T any] struct {
+	sinks         []Sink[T]
+	events        chan T
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Int64
+	done          chan struct{}
+	// mu guards closed, events emitted once the exporter is closed are dropped.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewExporter starts exporting events to the sinks in batches of up to batchSize events,
+// a partial batch is exported after flushInterval.
+func NewExporter[T any](sinks []Sink[T], bufferSize, batchSize int, flushInterval time.Duration) *Exporter[T] {
+	e := &Exporter[T]{
+		sinks:         sinks,
+		events:        make(chan T, bufferSize),
+		batchSize:     max(batchSize, 1),
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Emit queues the event for export, it never blocks. It is safe to call after Close, e.g. by requests still in flight.
+func (e *Exporter[T]) Emit(event T) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		e.dropped.Add(1)
+		return
+	}
+	select {
+	case e.events <- event:
+	default:
+		if dropped := e.dropped.Add(1); dropped%1000 == 1 {
+			klog.Warningf("%T buffer is full, %d events dropped", event, dropped)
+		}
+	}
+}
+
+// Dropped returns the number of events dropped since the exporter started.
+func (e *Exporter[T]) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Close exports the queued events and closes the sinks.
+func (e *Exporter[T]) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.events)
+	e.mu.Unlock()
+	<-e.done
+	var errs []error
+	for _, sink := range e.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Exporter[T]) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, e.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		for _, sink := range e.sinks {
+			if err := sink.Export(batch); err != nil {
+				klog.ErrorS(err, "failed to export events", "sink", fmt.Sprintf("%T", sink), "events", len(batch))
+			}
+		}
+		batch = make([]T, 0, e.batchSize)
+	}
+
+	for {
+		select {
+		case event, ok := <-e.events:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= e.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
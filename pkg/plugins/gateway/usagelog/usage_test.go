@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usagelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Event
+	closed  bool
+}
+
+func (s *memorySink) Export(events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, events)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestExporter(t *testing.T) {
+	sink := &memorySink{}
+	e := NewExporter([]Sink[Event]{sink}, 10, 2, time.Hour)
+	for i := 0; i < 5; i++ {
+		e.Emit(Event{RequestID: fmt.Sprintf("r%d", i)})
+	}
+	assert.NoError(t, e.Close())
+	assert.True(t, sink.closed)
+
+	// Full batches are exported once filled, the partial batch on close.
+	var sizes []int
+	for _, batch := range sink.batches {
+		sizes = append(sizes, len(batch))
+	}
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.Equal(t, "r4", sink.batches[2][0].RequestID)
+
+	// Events emitted after close, e.g. by detached shadow requests, are dropped.
+	e.Emit(Event{RequestID: "r5"})
+	assert.Equal(t, int64(1), e.Dropped())
+	assert.NoError(t, e.Close())
+	assert.Len(t, sink.batches, 3)
+}
+
+func TestExporterFlushInterval(t *testing.T) {
+	sink := &memorySink{}
+	e := NewExporter([]Sink[Event]{sink}, 10, 100, 10*time.Millisecond)
+	defer func() {
+		_ = e.Close()
+	}()
+	e.Emit(Event{RequestID: "r1"})
+	assert.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.batches) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage", "usage.jsonl")
+	event := Event{RequestID: "r1", Model: "m1", PromptTokens: 3, CompletionTokens: 4}
+	line, _ := json.Marshal(event)
+
+	// Each export exceeds half of the max size, so every export after the first rotates the file.
+	sink, err := NewFileSink[Event](path, int64(len(line)+1)*3/2, 2)
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, sink.Export([]Event{event}))
+	}
+	assert.NoError(t, sink.Close())
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		file, err := os.Open(p)
+		assert.NoError(t, err)
+		scanner := bufio.NewScanner(file)
+		assert.True(t, scanner.Scan())
+		var got Event
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &got))
+		assert.Equal(t, event, got)
+		assert.False(t, scanner.Scan())
+		_ = file.Close()
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []Event
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink[Event](server.URL, map[string]string{"Authorization": "Bearer token"}, time.Second)
+	assert.NoError(t, sink.Export([]Event{{RequestID: "r1"}, {RequestID: "r2"}}))
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Len(t, received, 2)
+
+	sink = NewWebhookSink[Event](server.URL+"/404", nil, time.Second)
+	assert.Error(t, sink.Export([]Event{{RequestID: "r1"}}))
+}
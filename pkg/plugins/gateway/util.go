@@ -28,53 +28,20 @@ import (
 	"k8s.io/klog/v2"
 )
 
-// validateRequestBody validates input by unmarshaling request body into respective openai-golang struct based on requestpath.
-// nolint:nakedret
+// validateRequestBody validates input by dispatching the request body to the parser registered for the request path.
 func validateRequestBody(requestID, requestPath string, requestBody []byte, user utils.User) (model, message string, stream bool, errRes *extProcPb.ProcessingResponse) {
-	var streamOptions openai.ChatCompletionStreamOptionsParam
-	if requestPath == "/v1/chat/completions" {
-		var jsonMap map[string]json.RawMessage
-		if err := json.Unmarshal(requestBody, &jsonMap); err != nil {
-			klog.ErrorS(err, "error to unmarshal request body", "requestID", requestID, "requestBody", string(requestBody))
-			errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
-			return
-		}
+	parser, ok := requestBodyParsers[requestPath]
+	if !ok {
+		errRes = openAIErrorResponse(envoyTypePb.StatusCode_NotImplemented, "unknown request path", errorTypeInvalidRequest, errorCodeUnknownURL,
+			HeaderErrorRequestBodyProcessing, "true")
+		return
+	}
 
-		chatCompletionObj := openai.ChatCompletionNewParams{}
-		if err := json.Unmarshal(requestBody, &chatCompletionObj); err != nil {
-			klog.ErrorS(err, "error to unmarshal chat completions object", "requestID", requestID, "requestBody", string(requestBody))
-			errRes = buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
-			return
-		}
-		model, streamOptions = chatCompletionObj.Model, chatCompletionObj.StreamOptions
-		if message, errRes = getChatCompletionsMessage(requestID, chatCompletionObj); errRes != nil {
-			return
-		}
-		if errRes = validateStreamOptions(requestID, user, &stream, streamOptions, jsonMap); errRes != nil {
-			return
-		}
-	} else if requestPath == "/v1/completions" {
-		// openai.CompletionsNewParams does not support json unmarshal for CompletionNewParamsPromptUnion in release v0.1.0-beta.10
-		// once supported, input request will be directly unmarshal into openai.CompletionsNewParams
-		type Completion struct {
-			Prompt string `json:"prompt"`
-			Model  string `json:"model"`
-		}
-		completionObj := Completion{}
-		err := json.Unmarshal(requestBody, &completionObj)
-		if err != nil {
-			klog.ErrorS(err, "error to unmarshal chat completions object", "requestID", requestID, "requestBody", string(requestBody))
-			errRes = buildErrorResponse(envoyTypePb.StatusCode_InternalServerError, "error processing request body", HeaderErrorRequestBodyProcessing, "true")
-			return
-		}
-		model = completionObj.Model
-		message = completionObj.Prompt
-	} else {
-		errRes = buildErrorResponse(envoyTypePb.StatusCode_NotImplemented, "unknown request path", HeaderErrorRequestBodyProcessing, "true")
+	if model, message, stream, errRes = parser(requestID, requestBody, user); errRes != nil {
 		return
 	}
 
-	klog.V(4).InfoS("validateRequestBody", "requestID", requestID, "requestPath", requestPath, "model", model, "message", message, "stream", stream, "streamOptions", streamOptions)
+	klog.V(4).InfoS("validateRequestBody", "requestID", requestID, "requestPath", requestPath, "model", model, "message", message, "stream", stream)
 	return
 }
 
@@ -90,7 +57,8 @@ func validateStreamOptions(requestID string, user utils.User, stream *bool, stre
 		return buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "stream incorrectly set", HeaderErrorStream, "stream incorrectly set")
 	}
 
-	if *stream && user.Tpm > 0 {
+	// Without the usage injected by the gateway, the tokens of a streaming request are unknown unless the client asks for them.
+	if *stream && user.Tpm > 0 && !streamUsageInjectionEnabled {
 		if !streamOptions.IncludeUsage.Value {
 			klog.ErrorS(nil, "no stream with usage option available", "requestID", requestID, "streamOption", streamOptions)
 			return buildErrorResponse(envoyTypePb.StatusCode_BadRequest, "include usage for stream options not set",
@@ -116,6 +84,35 @@ func getRoutingStrategy(headers []*configPb.HeaderValue) (string, bool) {
 	return defaultRoutingStrategy, defaultRoutingStrategyEnabled
 }
 
+// sessionAffinityHeader is the header identifying the session of a request for session-affinity routing.
+var sessionAffinityHeader = strings.ToLower(utils.LoadEnv("AIBRIX_SESSION_AFFINITY_HEADER", "x-session-id"))
+
+// getSessionID returns the session of the request from the session affinity header, or the OpenAI user field of the body.
+func getSessionID(headers []*configPb.HeaderValue, requestBody []byte) string {
+	for _, header := range headers {
+		if strings.ToLower(header.Key) == sessionAffinityHeader && len(header.RawValue) > 0 {
+			return string(header.RawValue)
+		}
+	}
+	var body struct {
+		User string `json:"user"`
+	}
+	if err := json.Unmarshal(requestBody, &body); err != nil {
+		return ""
+	}
+	return body.User
+}
+
+// hasRoutingStrategyHeader returns true if the request sets the routing strategy by header
+func hasRoutingStrategyHeader(headers []*configPb.HeaderValue) bool {
+	for _, header := range headers {
+		if strings.ToLower(header.Key) == HeaderRoutingStrategy {
+			return true
+		}
+	}
+	return false
+}
+
 // getChatCompletionsMessage returns message for chat completions object
 func getChatCompletionsMessage(requestID string, chatCompletionObj openai.ChatCompletionNewParams) (string, *extProcPb.ProcessingResponse) {
 	if len(chatCompletionObj.Messages) == 0 {
@@ -142,58 +139,29 @@ func getChatCompletionsMessage(requestID string, chatCompletionObj openai.ChatCo
 	return builder.String(), nil
 }
 
-// generateErrorResponse construct envoy proxy error response
+// generateErrorResponse construct envoy proxy error response with an OpenAI error body, the type and the code
+// of the error are derived from the x-error-* header.
 // deprecated: use buildErrorResponse
-func generateErrorResponse(statusCode envoyTypePb.StatusCode, headers []*configPb.HeaderValueOption, body string) *extProcPb.ProcessingResponse {
-	// Set the Content-Type header to application/json
-	headers = append(headers, &configPb.HeaderValueOption{
-		Header: &configPb.HeaderValue{
-			Key:   "Content-Type",
-			Value: "application/json",
-		},
-	})
-
-	return &extProcPb.ProcessingResponse{
-		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
-			ImmediateResponse: &extProcPb.ImmediateResponse{
-				Status: &envoyTypePb.HttpStatus{
-					Code: statusCode,
-				},
-				Headers: &extProcPb.HeaderMutation{
-					SetHeaders: headers,
-				},
-				Body: generateErrorMessage(body, int(statusCode)),
-			},
-		},
-	}
-}
-
-// generateErrorMessage constructs a JSON error message
-func generateErrorMessage(message string, code int) string {
-	errorStruct := map[string]interface{}{
-		"error": map[string]interface{}{
-			"message": message,
-			"code":    code,
-		},
-	}
-	jsonData, _ := json.Marshal(errorStruct)
-	return string(jsonData)
+func generateErrorResponse(statusCode envoyTypePb.StatusCode, headers []*configPb.HeaderValueOption, message string) *extProcPb.ProcessingResponse {
+	errType, code := openAIError(statusCode, errorHeader(headers))
+	errRes := openAIErrorResponse(statusCode, message, errType, code)
+	immediateResponse := errRes.GetImmediateResponse()
+	immediateResponse.Headers.SetHeaders = append(headers, immediateResponse.Headers.SetHeaders...)
+	return errRes
 }
 
-func buildErrorResponse(statusCode envoyTypePb.StatusCode, errBody string, headers ...string) *extProcPb.ProcessingResponse {
-	return &extProcPb.ProcessingResponse{
-		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
-			ImmediateResponse: &extProcPb.ImmediateResponse{
-				Status: &envoyTypePb.HttpStatus{
-					Code: statusCode,
-				},
-				Headers: &extProcPb.HeaderMutation{
-					SetHeaders: buildEnvoyProxyHeaders([]*configPb.HeaderValueOption{}, headers...),
-				},
-				Body: errBody,
-			},
-		},
+// buildErrorResponse rejects a request with an OpenAI error, the type and the code of the error are derived from
+// the x-error-* header.
+func buildErrorResponse(statusCode envoyTypePb.StatusCode, message string, headers ...string) *extProcPb.ProcessingResponse {
+	var errHeader string
+	for i := 0; i+1 < len(headers); i += 2 {
+		if strings.HasPrefix(headers[i], errorHeaderPrefix) {
+			errHeader = headers[i]
+			break
+		}
 	}
+	errType, code := openAIError(statusCode, errHeader)
+	return openAIErrorResponse(statusCode, message, errType, code, headers...)
 }
 
 func buildEnvoyProxyHeaders(headers []*configPb.HeaderValueOption, keyValues ...string) []*configPb.HeaderValueOption {
@@ -19,6 +19,7 @@ package gateway
 import (
 	"testing"
 
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/stretchr/testify/assert"
@@ -104,18 +105,20 @@ func Test_ValidateRequestBody(t *testing.T) {
 			statusCode:  envoyTypePb.StatusCode_BadRequest,
 		},
 		{
-			message:     "/v1/chat/completions json unmarshal stream options is null",
+			message:     "/v1/chat/completions stream options is null with user.TPM >= 1 is OK, usage is injected by the gateway",
 			requestPath: "/v1/chat/completions",
 			user:        utils.User{Tpm: 1},
 			requestBody: []byte(`{"model": "llama2-7b", "stream": true, "messages": [{"role": "system", "content": "this is system"}]}`),
-			statusCode:  envoyTypePb.StatusCode_BadRequest,
+			stream:      true,
+			statusCode:  envoyTypePb.StatusCode_OK,
 		},
 		{
-			message:     "/v1/chat/completions stream_options.include_usage == false with user.TPM >= 1 is NOT OK",
+			message:     "/v1/chat/completions stream_options.include_usage == false with user.TPM >= 1 is OK, usage is injected by the gateway",
 			user:        utils.User{Tpm: 1},
 			requestPath: "/v1/chat/completions",
 			requestBody: []byte(`{"model": "llama2-7b", "stream": true, "stream_options": {"include_usage": false},  "messages": [{"role": "system", "content": "this is system"}]}`),
-			statusCode:  envoyTypePb.StatusCode_BadRequest,
+			stream:      true,
+			statusCode:  envoyTypePb.StatusCode_OK,
 		},
 		{
 			message:     "/v1/chat/completions stream_options.include_usage == false with user.TPM == 0 is OK",
@@ -133,6 +136,78 @@ func Test_ValidateRequestBody(t *testing.T) {
 			messages:    "this is system say this is test",
 			statusCode:  envoyTypePb.StatusCode_OK,
 		},
+		{
+			message:     "/v1/embeddings valid string input",
+			requestPath: "/v1/embeddings",
+			requestBody: []byte(`{"model": "bge-m3", "input": "say this is test"}`),
+			model:       "bge-m3",
+			messages:    "say this is test",
+			statusCode:  envoyTypePb.StatusCode_OK,
+		},
+		{
+			message:     "/v1/embeddings valid array input",
+			requestPath: "/v1/embeddings",
+			requestBody: []byte(`{"model": "bge-m3", "input": ["say this", "is test"]}`),
+			model:       "bge-m3",
+			messages:    "say this is test",
+			statusCode:  envoyTypePb.StatusCode_OK,
+		},
+		{
+			message:     "/v1/embeddings valid token ids input",
+			requestPath: "/v1/embeddings",
+			requestBody: []byte(`{"model": "bge-m3", "input": [1, 2, 3]}`),
+			model:       "bge-m3",
+			messages:    "[1, 2, 3]",
+			statusCode:  envoyTypePb.StatusCode_OK,
+		},
+		{
+			message:     "/v1/embeddings no input",
+			requestPath: "/v1/embeddings",
+			requestBody: []byte(`{"model": "bge-m3"}`),
+			statusCode:  envoyTypePb.StatusCode_BadRequest,
+		},
+		{
+			message:     "/v1/rerank valid documents",
+			requestPath: "/v1/rerank",
+			requestBody: []byte(`{"model": "bge-reranker", "query": "what is test", "documents": ["this is test", {"text": "this is not test"}]}`),
+			model:       "bge-reranker",
+			messages:    "what is test this is test this is not test",
+			statusCode:  envoyTypePb.StatusCode_OK,
+		},
+		{
+			message:     "/v1/rerank no documents",
+			requestPath: "/v1/rerank",
+			requestBody: []byte(`{"model": "bge-reranker", "query": "what is test"}`),
+			statusCode:  envoyTypePb.StatusCode_BadRequest,
+		},
+		{
+			message:     "/v1/score valid texts",
+			requestPath: "/v1/score",
+			requestBody: []byte(`{"model": "bge-reranker", "text_1": "what is test", "text_2": ["this is test", "this is not test"]}`),
+			model:       "bge-reranker",
+			messages:    "what is test this is test this is not test",
+			statusCode:  envoyTypePb.StatusCode_OK,
+		},
+		{
+			message:     "/v1/audio/transcriptions valid multipart form",
+			requestPath: "/v1/audio/transcriptions",
+			requestBody: []byte("--boundary\r\n" +
+				"Content-Disposition: form-data; name=\"file\"; filename=\"test.wav\"\r\n\r\nRIFF\r\n" +
+				"--boundary\r\nContent-Disposition: form-data; name=\"model\"\r\n\r\nwhisper\r\n" +
+				"--boundary\r\nContent-Disposition: form-data; name=\"prompt\"\r\n\r\nsay this is test\r\n" +
+				"--boundary\r\nContent-Disposition: form-data; name=\"stream\"\r\n\r\ntrue\r\n" +
+				"--boundary--\r\n"),
+			model:      "whisper",
+			messages:   "say this is test",
+			stream:     true,
+			statusCode: envoyTypePb.StatusCode_OK,
+		},
+		{
+			message:     "/v1/audio/transcriptions not multipart form",
+			requestPath: "/v1/audio/transcriptions",
+			requestBody: []byte(`{"model": "whisper"}`),
+			statusCode:  envoyTypePb.StatusCode_BadRequest,
+		},
 	}
 
 	for _, tt := range testCases {
@@ -156,3 +231,10 @@ func Test_ValidateRequestBody(t *testing.T) {
 		}
 	}
 }
+
+func Test_GetSessionID(t *testing.T) {
+	body := []byte(`{"model": "m1", "user": "alice"}`)
+	assert.Equal(t, "s1", getSessionID([]*configPb.HeaderValue{{Key: "X-Session-Id", RawValue: []byte("s1")}}, body))
+	assert.Equal(t, "alice", getSessionID([]*configPb.HeaderValue{{Key: "x-session-id"}}, body))
+	assert.Equal(t, "", getSessionID(nil, []byte(`{"model": "m1"}`)))
+}
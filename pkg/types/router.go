@@ -16,6 +16,12 @@ limitations under the License.
 
 package types
 
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
 // Router defines the interface for routing logic to select target pods.
 type Router interface {
 	// Route selects a target pod from the provided list of pods.
@@ -37,3 +43,54 @@ type RouterProviderRegistrationFunc func() RouterProviderFunc
 
 // RouterConstructor defines a constructor for a router.
 type RouterConstructor func() (Router, error)
+
+// RouterParameters are the algorithm specific parameters of a router, e.g. set by a RoutingPolicy.
+type RouterParameters map[string]string
+
+// ParameterizedRouterConstructor defines a constructor for a router with parameters.
+// Parameters not set fall back to the defaults of the router.
+type ParameterizedRouterConstructor func(params RouterParameters) (Router, error)
+
+// Validate returns an error if a parameter is not one of the supported keys.
+func (p RouterParameters) Validate(keys ...string) error {
+	for key := range p {
+		if !slices.Contains(keys, key) {
+			return fmt.Errorf("unknown parameter %q, supported: %v", key, keys)
+		}
+	}
+	return nil
+}
+
+// String returns the parameter of the key, or the default value if it is not set.
+func (p RouterParameters) String(key string, defaultValue string) string {
+	if value, ok := p[key]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// Int returns the parameter of the key as an integer, or the default value if it is not set.
+func (p RouterParameters) Int(key string, defaultValue int) (int, error) {
+	value, ok := p[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer parameter %s: %q", key, value)
+	}
+	return i, nil
+}
+
+// Float returns the parameter of the key as a float, or the default value if it is not set.
+func (p RouterParameters) Float(key string, defaultValue float64) (float64, error) {
+	value, ok := p[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float parameter %s: %q", key, value)
+	}
+	return f, nil
+}
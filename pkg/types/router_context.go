@@ -41,10 +41,15 @@ type RoutingContext struct {
 	Message   string
 	RequestID string
 	User      *string
+	// SessionID identifies the conversation of the request for session affinity, empty if not set.
+	SessionID string
 
 	targetPodSet chan struct{}
 	targetPod    atomic.Pointer[v1.Pod]
 	debugDelay   time.Duration
+
+	// prefillPod prefills the request if prefill and decode are disaggregated, the target pod decodes it.
+	prefillPod atomic.Pointer[v1.Pod]
 }
 
 var requestPool = sync.Pool{
@@ -91,6 +96,25 @@ func (r *RoutingContext) HasRouted() bool {
 	return r.targetPod.Load() != nilPod
 }
 
+// SetPrefillPod sets the pod prefilling the request, for requests whose prefill and decode are disaggregated.
+func (r *RoutingContext) SetPrefillPod(pod *v1.Pod) {
+	r.prefillPod.Store(pod)
+}
+
+// PrefillPod returns the pod prefilling the request, nil if prefill and decode are not disaggregated.
+func (r *RoutingContext) PrefillPod() *v1.Pod {
+	return r.prefillPod.Load()
+}
+
+// PrefillAddress returns the address of the pod prefilling the request, empty if there is none.
+func (r *RoutingContext) PrefillAddress() string {
+	pod := r.PrefillPod()
+	if pod == nil {
+		return ""
+	}
+	return r.targetAddress(pod)
+}
+
 func (r *RoutingContext) targetAddress(pod *v1.Pod) string {
 	return fmt.Sprintf("%v:%v", pod.Status.PodIP, utils.GetModelPortForPod(r.RequestID, pod))
 }
@@ -102,8 +126,10 @@ func (r *RoutingContext) reset(ctx context.Context, algorithms RoutingAlgorithm,
 	r.Message = message
 	r.RequestID = requestID
 	r.User = user
+	r.SessionID = ""
 	r.targetPodSet = make(chan struct{}) // Initialize channel
 	r.targetPod.Store(nilPod)
+	r.prefillPod.Store(nil)
 }
 
 func (r *RoutingContext) debugWait() {
@@ -0,0 +1,45 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Filter drops the pods not suitable for a request before scoring.
+type Filter interface {
+	// Filter returns the pods suitable for the request, the result may be empty.
+	Filter(ctx *RoutingContext, pods []*v1.Pod) []*v1.Pod
+}
+
+// Scorer scores the pods for a request.
+type Scorer interface {
+	// Score returns a score in [0, 1] for each of the pods in order, higher is better.
+	Score(ctx *RoutingContext, pods []*v1.Pod) ([]float64, error)
+}
+
+// RoutedObserver is implemented by filters and scorers tracking the routing decisions, e.g. the prefix cache.
+type RoutedObserver interface {
+	// Routed is called with the pod selected for the request.
+	Routed(ctx *RoutingContext, pod *v1.Pod)
+}
+
+// FilterConstructor defines a constructor for a filter with parameters.
+type FilterConstructor func(params RouterParameters) (Filter, error)
+
+// ScorerConstructor defines a constructor for a scorer with parameters.
+type ScorerConstructor func(params RouterParameters) (Scorer, error)
@@ -0,0 +1,222 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	apiKeyPrefix        = "sk-"
+	apiKeyDisplayLength = 8
+	apiKeyIndexKey      = "aibrix-api-key-index"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyExpired  = errors.New("api key expired")
+)
+
+// APIKey authenticates requests as its user. Only the sha256 hash of the key is stored,
+// the key itself is returned once on creation.
+type APIKey struct {
+	ID   string `json:"id"`
+	User string `json:"user" validate:"required"`
+	Name string `json:"name,omitempty"`
+	// Prefix is the beginning of the key to help users identify it.
+	Prefix string `json:"prefix"`
+	Hash   string `json:"-"`
+	// CreatedAt and ExpiresAt are unix seconds, a zero ExpiresAt never expires.
+	CreatedAt int64 `json:"createdAt"`
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// apiKeyRecord is the stored form of an api key, which keeps the hash hidden from json responses.
+type apiKeyRecord struct {
+	APIKey
+	Hash string `json:"hash"`
+}
+
+// HashAPIKey returns the hex encoded sha256 hash of an api key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey generates a new key for the user of k and stores its hash. Returns the stored api key and the key.
+func CreateAPIKey(ctx context.Context, k APIKey, redisClient *redis.Client) (APIKey, string, error) {
+	if !CheckUser(ctx, User{Name: k.User}, redisClient) {
+		return APIKey{}, "", fmt.Errorf("user: %s does not exist", k.User)
+	}
+	if k.ExpiresAt < 0 {
+		return APIKey{}, "", fmt.Errorf("expiresAt can not negative")
+	}
+
+	secret, err := randomHex(24)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	key := apiKeyPrefix + secret
+	k.ID = "key-" + id
+	k.Prefix = key[:len(apiKeyPrefix)+apiKeyDisplayLength]
+	k.Hash = HashAPIKey(key)
+	k.CreatedAt = time.Now().Unix()
+
+	b, err := json.Marshal(&apiKeyRecord{APIKey: k, Hash: k.Hash})
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	pipe := redisClient.TxPipeline()
+	pipe.Set(ctx, genAPIKeyKey(k.Hash), string(b), 0)
+	pipe.Set(ctx, genAPIKeyIDKey(k.ID), k.Hash, 0)
+	pipe.ZAdd(ctx, apiKeyIndexKey, redis.Z{Member: k.ID})
+	pipe.ZAdd(ctx, genUserAPIKeyIndexKey(k.User), redis.Z{Member: k.ID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return APIKey{}, "", err
+	}
+	return k, key, nil
+}
+
+// AuthenticateAPIKey returns the user of a valid api key.
+func AuthenticateAPIKey(ctx context.Context, key string, redisClient *redis.Client) (User, error) {
+	k, err := getAPIKeyByHash(ctx, HashAPIKey(key), redisClient)
+	if err != nil {
+		return User{}, err
+	}
+	if k.ExpiresAt != 0 && time.Now().Unix() >= k.ExpiresAt {
+		return User{}, ErrAPIKeyExpired
+	}
+	return GetUser(ctx, User{Name: k.User}, redisClient)
+}
+
+// GetAPIKey returns the api key of the given id.
+func GetAPIKey(ctx context.Context, id string, redisClient *redis.Client) (APIKey, error) {
+	hash, err := redisClient.Get(ctx, genAPIKeyIDKey(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return APIKey{}, ErrAPIKeyNotFound
+		}
+		return APIKey{}, err
+	}
+	return getAPIKeyByHash(ctx, hash, redisClient)
+}
+
+// DelAPIKey revokes the api key of the given id.
+func DelAPIKey(ctx context.Context, id string, redisClient *redis.Client) error {
+	k, err := GetAPIKey(ctx, id, redisClient)
+	if err != nil {
+		return err
+	}
+
+	pipe := redisClient.TxPipeline()
+	pipe.Del(ctx, genAPIKeyKey(k.Hash), genAPIKeyIDKey(k.ID))
+	pipe.ZRem(ctx, apiKeyIndexKey, k.ID)
+	pipe.ZRem(ctx, genUserAPIKeyIndexKey(k.User), k.ID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DelUserAPIKeys revokes all api keys of the user.
+func DelUserAPIKeys(ctx context.Context, user string, redisClient *redis.Client) error {
+	ids, err := redisClient.ZRange(ctx, genUserAPIKeyIndexKey(user), 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := DelAPIKey(ctx, id, redisClient); err != nil && !errors.Is(err, ErrAPIKeyNotFound) {
+			return err
+		}
+	}
+	return redisClient.Del(ctx, genUserAPIKeyIndexKey(user)).Err()
+}
+
+// ListAPIKeys returns at most limit api keys ordered by id, starting after the given id.
+// Keys of all users are listed if user is empty. The returned bool is true if there are more keys to list.
+func ListAPIKeys(ctx context.Context, user, after string, limit int64, redisClient *redis.Client) ([]APIKey, bool, error) {
+	index := apiKeyIndexKey
+	if user != "" {
+		index = genUserAPIKeyIndexKey(user)
+	}
+	ids, hasMore, err := listIndex(ctx, index, after, limit, redisClient)
+	if err != nil {
+		return nil, false, err
+	}
+
+	keys := make([]APIKey, 0, len(ids))
+	for _, id := range ids {
+		k, err := GetAPIKey(ctx, id, redisClient)
+		if err != nil {
+			if errors.Is(err, ErrAPIKeyNotFound) {
+				// Revoked after it was listed.
+				continue
+			}
+			return nil, false, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, hasMore, nil
+}
+
+func getAPIKeyByHash(ctx context.Context, hash string, redisClient *redis.Client) (APIKey, error) {
+	val, err := redisClient.Get(ctx, genAPIKeyKey(hash)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return APIKey{}, ErrAPIKeyNotFound
+		}
+		return APIKey{}, err
+	}
+	record := &apiKeyRecord{}
+	if err := json.Unmarshal([]byte(val), record); err != nil {
+		return APIKey{}, err
+	}
+	record.APIKey.Hash = record.Hash
+	return record.APIKey, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func genAPIKeyKey(hash string) string {
+	return fmt.Sprintf("aibrix-api-keys/%s", hash)
+}
+
+func genAPIKeyIDKey(id string) string {
+	return fmt.Sprintf("aibrix-api-key-ids/%s", id)
+}
+
+func genUserAPIKeyIndexKey(user string) string {
+	return fmt.Sprintf("aibrix-user-api-keys/%s", user)
+}
@@ -0,0 +1,874 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chattemplate
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// globals are the functions available to chat templates, including the ones added by HuggingFace.
+var globals = map[string]callable{
+	"range":           rangeFunc,
+	"namespace":       namespaceFunc,
+	"dict":            dictFunc,
+	"raise_exception": raiseException,
+	"strftime_now":    strftimeNow,
+}
+
+// now returns the current time, it is replaced in tests.
+var now = time.Now
+
+func rangeFunc(args []interface{}, _ *Dict) (interface{}, error) {
+	bounds := make([]int, len(args))
+	for i, arg := range args {
+		n, ok := toInt(arg)
+		if !ok {
+			return nil, fmt.Errorf("range() arguments must be integers")
+		}
+		bounds[i] = n
+	}
+	start, stop, step := 0, 0, 1
+	switch len(bounds) {
+	case 1:
+		stop = bounds[0]
+	case 2:
+		start, stop = bounds[0], bounds[1]
+	case 3:
+		start, stop, step = bounds[0], bounds[1], bounds[2]
+	default:
+		return nil, fmt.Errorf("range() expects 1 to 3 arguments")
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("range() step must not be zero")
+	}
+	var items []interface{}
+	for i := start; (step > 0 && i < stop) || (step < 0 && i > stop); i += step {
+		items = append(items, i)
+		if len(items) > maxOutputSize {
+			return nil, fmt.Errorf("range() is too large")
+		}
+	}
+	return items, nil
+}
+
+func namespaceFunc(args []interface{}, kwargs *Dict) (interface{}, error) {
+	ns := &namespace{Dict: NewDict()}
+	for _, arg := range args {
+		if d, ok := arg.(*Dict); ok {
+			for _, k := range d.Keys() {
+				ns.Set(k, d.values[k])
+			}
+		}
+	}
+	for _, k := range kwargs.Keys() {
+		ns.Set(k, kwargs.values[k])
+	}
+	return ns, nil
+}
+
+func dictFunc(args []interface{}, kwargs *Dict) (interface{}, error) {
+	d := NewDict()
+	for _, arg := range args {
+		if src, ok := arg.(*Dict); ok {
+			for _, k := range src.Keys() {
+				d.Set(k, src.values[k])
+			}
+		}
+	}
+	for _, k := range kwargs.Keys() {
+		d.Set(k, kwargs.values[k])
+	}
+	return d, nil
+}
+
+func raiseException(args []interface{}, _ *Dict) (interface{}, error) {
+	message := "raise_exception"
+	if len(args) > 0 {
+		message = toString(args[0])
+	}
+	return nil, &TemplateError{Message: message}
+}
+
+// TemplateError is raised by raise_exception() of a template, e.g. for unsupported roles.
+type TemplateError struct {
+	Message string
+}
+
+func (e *TemplateError) Error() string {
+	return e.Message
+}
+
+// strftimeNow supports the common directives of python strftime.
+func strftimeNow(args []interface{}, _ *Dict) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("strftime_now() expects 1 argument")
+	}
+	format := toString(args[0])
+	t := now()
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 >= len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 'b':
+			b.WriteString(t.Month().String()[:3])
+		case 'B':
+			b.WriteString(t.Month().String())
+		case 'a':
+			b.WriteString(t.Weekday().String()[:3])
+		case 'A':
+			b.WriteString(t.Weekday().String())
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// getMethod returns the bound python method of strings and dicts.
+func getMethod(object interface{}, name string) (callable, bool) {
+	switch o := object.(type) {
+	case string:
+		return stringMethod(o, name)
+	case *Dict:
+		return dictMethod(o, name)
+	}
+	return nil, false
+}
+
+func stringArg(args []interface{}, i int) (string, bool) {
+	if i >= len(args) || args[i] == nil {
+		return "", false
+	}
+	return toString(args[i]), true
+}
+
+func stringMethod(s, name string) (callable, bool) {
+	var fn callable
+	switch name {
+	case "strip", "lstrip", "rstrip":
+		fn = func(args []interface{}, _ *Dict) (interface{}, error) {
+			chars, ok := stringArg(args, 0)
+			if !ok {
+				switch name {
+				case "strip":
+					return strings.TrimSpace(s), nil
+				case "lstrip":
+					return strings.TrimLeftFunc(s, unicode.IsSpace), nil
+				default:
+					return strings.TrimRightFunc(s, unicode.IsSpace), nil
+				}
+			}
+			switch name {
+			case "strip":
+				return strings.Trim(s, chars), nil
+			case "lstrip":
+				return strings.TrimLeft(s, chars), nil
+			default:
+				return strings.TrimRight(s, chars), nil
+			}
+		}
+	case "split", "rsplit":
+		fn = func(args []interface{}, kwargs *Dict) (interface{}, error) {
+			sep, hasSep := stringArg(args, 0)
+			if v, ok := kwargs.Get("sep"); ok && v != nil {
+				sep, hasSep = toString(v), true
+			}
+			maxSplit := -1
+			if len(args) > 1 {
+				maxSplit, _ = toInt(args[1])
+			}
+			if v, ok := kwargs.Get("maxsplit"); ok {
+				maxSplit, _ = toInt(v)
+			}
+			var parts []string
+			switch {
+			case !hasSep:
+				parts = strings.Fields(s)
+				if maxSplit >= 0 && len(parts) > maxSplit+1 {
+					// Approximation of split() with maxsplit, the rest keeps the original separators.
+					rest := s
+					for i := 0; i < maxSplit; i++ {
+						rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+						rest = rest[len(parts[i]):]
+					}
+					parts = append(parts[:maxSplit:maxSplit], strings.TrimLeftFunc(rest, unicode.IsSpace))
+				}
+			case name == "rsplit" && maxSplit >= 0:
+				parts = strings.Split(s, sep)
+				if len(parts) > maxSplit+1 {
+					head := strings.Join(parts[:len(parts)-maxSplit], sep)
+					parts = append([]string{head}, parts[len(parts)-maxSplit:]...)
+				}
+			default:
+				parts = strings.SplitN(s, sep, maxSplit+1)
+				if maxSplit < 0 {
+					parts = strings.Split(s, sep)
+				}
+			}
+			items := make([]interface{}, len(parts))
+			for i, p := range parts {
+				items[i] = p
+			}
+			return items, nil
+		}
+	case "startswith", "endswith":
+		fn = func(args []interface{}, _ *Dict) (interface{}, error) {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("%s() expects 1 argument", name)
+			}
+			candidates := []interface{}{args[0]}
+			if list, ok := args[0].([]interface{}); ok {
+				candidates = list
+			}
+			for _, c := range candidates {
+				affix := toString(c)
+				if (name == "startswith" && strings.HasPrefix(s, affix)) || (name == "endswith" && strings.HasSuffix(s, affix)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	case "upper":
+		fn = func([]interface{}, *Dict) (interface{}, error) { return strings.ToUpper(s), nil }
+	case "lower":
+		fn = func([]interface{}, *Dict) (interface{}, error) { return strings.ToLower(s), nil }
+	case "title":
+		fn = func([]interface{}, *Dict) (interface{}, error) { return title(s), nil }
+	case "capitalize":
+		fn = func([]interface{}, *Dict) (interface{}, error) { return capitalize(s), nil }
+	case "replace":
+		fn = func(args []interface{}, _ *Dict) (interface{}, error) {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("replace() expects 2 arguments")
+			}
+			count := -1
+			if len(args) > 2 {
+				count, _ = toInt(args[2])
+			}
+			return strings.Replace(s, toString(args[0]), toString(args[1]), count), nil
+		}
+	case "find":
+		fn = func(args []interface{}, _ *Dict) (interface{}, error) {
+			sub, _ := stringArg(args, 0)
+			i := strings.Index(s, sub)
+			if i < 0 {
+				return -1, nil
+			}
+			return len([]rune(s[:i])), nil
+		}
+	case "count":
+		fn = func(args []interface{}, _ *Dict) (interface{}, error) {
+			sub, _ := stringArg(args, 0)
+			return strings.Count(s, sub), nil
+		}
+	case "join":
+		fn = func(args []interface{}, _ *Dict) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("join() expects 1 argument")
+			}
+			items, err := iterate(args[0])
+			if err != nil {
+				return nil, err
+			}
+			parts := make([]string, len(items))
+			for i, item := range items {
+				parts[i] = toString(item)
+			}
+			return strings.Join(parts, s), nil
+		}
+	case "isdigit", "isalpha", "isspace":
+		fn = func([]interface{}, *Dict) (interface{}, error) {
+			if s == "" {
+				return false, nil
+			}
+			for _, r := range s {
+				if (name == "isdigit" && !unicode.IsDigit(r)) || (name == "isalpha" && !unicode.IsLetter(r)) ||
+					(name == "isspace" && !unicode.IsSpace(r)) {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+	default:
+		return nil, false
+	}
+	return fn, true
+}
+
+func dictMethod(d *Dict, name string) (callable, bool) {
+	var fn callable
+	switch name {
+	case "items":
+		fn = func([]interface{}, *Dict) (interface{}, error) { return dictItems(d), nil }
+	case "keys":
+		fn = func([]interface{}, *Dict) (interface{}, error) {
+			items := make([]interface{}, 0, d.Len())
+			for _, k := range d.Keys() {
+				items = append(items, k)
+			}
+			return items, nil
+		}
+	case "values":
+		fn = func([]interface{}, *Dict) (interface{}, error) {
+			items := make([]interface{}, 0, d.Len())
+			for _, k := range d.Keys() {
+				items = append(items, d.values[k])
+			}
+			return items, nil
+		}
+	case "get":
+		fn = func(args []interface{}, _ *Dict) (interface{}, error) {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("get() expects at least 1 argument")
+			}
+			if k, ok := args[0].(string); ok {
+				if v, ok := d.Get(k); ok {
+					return v, nil
+				}
+			}
+			if len(args) > 1 {
+				return args[1], nil
+			}
+			return nil, nil
+		}
+	default:
+		return nil, false
+	}
+	return fn, true
+}
+
+func dictItems(d *Dict) []interface{} {
+	items := make([]interface{}, 0, d.Len())
+	for _, k := range d.Keys() {
+		items = append(items, []interface{}{k, d.values[k]})
+	}
+	return items
+}
+
+func title(s string) string {
+	var b strings.Builder
+	prevLetter := false
+	for _, r := range s {
+		if prevLetter {
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(unicode.ToTitle(r))
+		}
+		prevLetter = unicode.IsLetter(r)
+	}
+	return b.String()
+}
+
+func capitalize(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + strings.ToLower(s[i+len(string(r)):])
+	}
+	return s
+}
+
+// filterArg returns a positional argument or keyword argument of a filter.
+func filterArg(args []interface{}, kwargs *Dict, i int, name string) (interface{}, bool) {
+	if i < len(args) {
+		return args[i], true
+	}
+	return kwargs.Get(name)
+}
+
+func (r *renderer) applyFilter(f *filterExpr, value interface{}, s *scope) (interface{}, error) {
+	args, err := r.evalList(f.args, s)
+	if err != nil {
+		return nil, err
+	}
+	kwargs, err := r.evalKwargs(f.kwargs, nil, s)
+	if err != nil {
+		return nil, err
+	}
+	return applyFilter(f.name, value, args, kwargs)
+}
+
+func applyFilter(name string, value interface{}, args []interface{}, kwargs *Dict) (interface{}, error) {
+	switch name {
+	case "tojson":
+		indent := ""
+		if v, ok := filterArg(args, kwargs, 0, "indent"); ok && v != nil {
+			if n, ok := toInt(v); ok {
+				indent = strings.Repeat(" ", n)
+			} else {
+				indent = toString(v)
+			}
+		}
+		sortKeys := false
+		if v, ok := kwargs.Get("sort_keys"); ok {
+			sortKeys = isTrue(v)
+		}
+		return toJSON(unwrapNamespace(value), indent, sortKeys)
+	case "trim":
+		if chars, ok := filterArg(args, kwargs, 0, "chars"); ok && chars != nil {
+			return strings.Trim(toString(value), toString(chars)), nil
+		}
+		return strings.TrimSpace(toString(value)), nil
+	case "length", "count":
+		return length(value)
+	case "upper":
+		return strings.ToUpper(toString(value)), nil
+	case "lower":
+		return strings.ToLower(toString(value)), nil
+	case "title":
+		return title(toString(value)), nil
+	case "capitalize":
+		return capitalize(toString(value)), nil
+	case "string":
+		return toString(value), nil
+	case "safe":
+		return value, nil
+	case "e", "escape":
+		return html.EscapeString(toString(value)), nil
+	case "default", "d":
+		def, _ := filterArg(args, kwargs, 0, "default_value")
+		if def == nil && len(args) == 0 {
+			def = ""
+		}
+		boolean := false
+		if v, ok := filterArg(args, kwargs, 1, "boolean"); ok {
+			boolean = isTrue(v)
+		}
+		if isUndefined(value) || (boolean && !isTrue(value)) {
+			return def, nil
+		}
+		return value, nil
+	case "join":
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		sep := ""
+		if v, ok := filterArg(args, kwargs, 0, "d"); ok {
+			sep = toString(v)
+		}
+		attribute, hasAttribute := filterArg(args, kwargs, 1, "attribute")
+		parts := make([]string, len(items))
+		for i, item := range items {
+			if hasAttribute {
+				item = getAttr(item, toString(attribute))
+			}
+			parts[i] = toString(item)
+		}
+		return strings.Join(parts, sep), nil
+	case "first", "last":
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return undefined{name: name}, nil
+		}
+		if name == "first" {
+			return items[0], nil
+		}
+		return items[len(items)-1], nil
+	case "list":
+		return iterate(value)
+	case "reverse":
+		if s, ok := value.(string); ok {
+			runes := []rune(s)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return string(runes), nil
+		}
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		reversed := make([]interface{}, len(items))
+		for i, item := range items {
+			reversed[len(items)-1-i] = item
+		}
+		return reversed, nil
+	case "items":
+		if d, ok := unwrapNamespace(value).(*Dict); ok {
+			return dictItems(d), nil
+		}
+		if isUndefined(value) {
+			return []interface{}{}, nil
+		}
+		return nil, fmt.Errorf("items filter expects a dict, got %s", typeName(value))
+	case "dictsort":
+		d, ok := unwrapNamespace(value).(*Dict)
+		if !ok {
+			return nil, fmt.Errorf("dictsort filter expects a dict, got %s", typeName(value))
+		}
+		items := dictItems(d)
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].([]interface{})[0].(string) < items[j].([]interface{})[0].(string)
+		})
+		return items, nil
+	case "int":
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			return int(v), nil
+		case bool:
+			n, _ := toInt(v)
+			return n, nil
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return n, nil
+			}
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return int(f), nil
+			}
+		}
+		def, _ := filterArg(args, kwargs, 0, "default")
+		if def == nil {
+			def = 0
+		}
+		return def, nil
+	case "float":
+		if f, ok := toNumber(value); ok {
+			return f, nil
+		}
+		if s, ok := value.(string); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return f, nil
+			}
+		}
+		def, _ := filterArg(args, kwargs, 0, "default")
+		if def == nil {
+			def = 0.0
+		}
+		return def, nil
+	case "abs":
+		switch v := value.(type) {
+		case int:
+			if v < 0 {
+				return -v, nil
+			}
+			return v, nil
+		case float64:
+			return math.Abs(v), nil
+		}
+		return nil, fmt.Errorf("bad operand type for abs(): %s", typeName(value))
+	case "round":
+		f, ok := toNumber(value)
+		if !ok {
+			return nil, fmt.Errorf("round filter expects a number, got %s", typeName(value))
+		}
+		precision := 0
+		if v, ok := filterArg(args, kwargs, 0, "precision"); ok {
+			precision, _ = toInt(v)
+		}
+		scale := math.Pow(10, float64(precision))
+		return math.Round(f*scale) / scale, nil
+	case "replace":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("replace filter expects 2 arguments")
+		}
+		count := -1
+		if v, ok := filterArg(args, kwargs, 2, "count"); ok && v != nil {
+			count, _ = toInt(v)
+		}
+		return strings.Replace(toString(value), toString(args[0]), toString(args[1]), count), nil
+	case "indent":
+		width := "    "
+		if v, ok := filterArg(args, kwargs, 0, "width"); ok {
+			if n, ok := toInt(v); ok {
+				width = strings.Repeat(" ", n)
+			} else {
+				width = toString(v)
+			}
+		}
+		first, blank := false, false
+		if v, ok := filterArg(args, kwargs, 1, "first"); ok {
+			first = isTrue(v)
+		}
+		if v, ok := filterArg(args, kwargs, 2, "blank"); ok {
+			blank = isTrue(v)
+		}
+		lines := strings.Split(toString(value), "\n")
+		for i, line := range lines {
+			if (i > 0 || first) && (blank || strings.TrimSpace(line) != "") {
+				lines[i] = width + line
+			}
+		}
+		return strings.Join(lines, "\n"), nil
+	case "unique":
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		var result []interface{}
+		for _, item := range items {
+			found, _ := contains(result, item)
+			if !found {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	case "map":
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, len(items))
+		if attribute, ok := kwargs.Get("attribute"); ok {
+			def, hasDefault := kwargs.Get("default")
+			for i, item := range items {
+				result[i] = getAttr(item, toString(attribute))
+				if hasDefault && isUndefined(result[i]) {
+					result[i] = def
+				}
+			}
+			return result, nil
+		}
+		if len(args) == 0 {
+			return nil, fmt.Errorf("map filter expects a filter name or attribute")
+		}
+		for i, item := range items {
+			if result[i], err = applyFilter(toString(args[0]), item, args[1:], NewDict()); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case "select", "reject", "selectattr", "rejectattr":
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		byAttr := strings.HasSuffix(name, "attr")
+		if byAttr && len(args) == 0 {
+			return nil, fmt.Errorf("%s filter expects an attribute", name)
+		}
+		testArgs := args
+		if byAttr {
+			testArgs = args[1:]
+		}
+		var result []interface{}
+		for _, item := range items {
+			subject := item
+			if byAttr {
+				subject = getAttr(item, toString(args[0]))
+			}
+			matched := isTrue(subject)
+			if len(testArgs) > 0 {
+				if matched, err = applyTest(toString(testArgs[0]), subject, testArgs[1:]); err != nil {
+					return nil, err
+				}
+			}
+			if matched == strings.HasPrefix(name, "select") {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	case "sort":
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		sorted := append([]interface{}{}, items...)
+		reverse := false
+		if v, ok := filterArg(args, kwargs, 0, "reverse"); ok {
+			reverse = isTrue(v)
+		}
+		attribute, hasAttribute := kwargs.Get("attribute")
+		var sortErr error
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			if hasAttribute {
+				a, b = getAttr(a, toString(attribute)), getAttr(b, toString(attribute))
+			}
+			c, err := compare(a, b)
+			if err != nil {
+				sortErr = err
+			}
+			if reverse {
+				return c > 0
+			}
+			return c < 0
+		})
+		return sorted, sortErr
+	case "sum", "min", "max":
+		items, err := iterate(value)
+		if err != nil {
+			return nil, err
+		}
+		if name == "sum" {
+			var total interface{} = 0
+			for _, item := range items {
+				if total, err = arithmetic("+", total, item); err != nil {
+					return nil, err
+				}
+			}
+			return total, nil
+		}
+		if len(items) == 0 {
+			return undefined{name: name}, nil
+		}
+		best := items[0]
+		for _, item := range items[1:] {
+			c, err := compare(item, best)
+			if err != nil {
+				return nil, err
+			}
+			if (name == "min" && c < 0) || (name == "max" && c > 0) {
+				best = item
+			}
+		}
+		return best, nil
+	case "wordcount":
+		return len(strings.Fields(toString(value))), nil
+	}
+	return nil, fmt.Errorf("%w filter: %s", ErrUnsupported, name)
+}
+
+func unwrapNamespace(v interface{}) interface{} {
+	if ns, ok := v.(*namespace); ok {
+		return ns.Dict
+	}
+	return v
+}
+
+func applyTest(name string, value interface{}, args []interface{}) (bool, error) {
+	switch name {
+	case "defined":
+		return !isUndefined(value), nil
+	case "undefined":
+		return isUndefined(value), nil
+	case "none":
+		return value == nil, nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok, nil
+	case "true":
+		return value == true, nil
+	case "false":
+		return value == false, nil
+	case "string":
+		_, ok := value.(string)
+		return ok, nil
+	case "number":
+		switch value.(type) {
+		case int, float64:
+			return true, nil
+		}
+		return false, nil
+	case "integer":
+		_, ok := value.(int)
+		return ok, nil
+	case "float":
+		_, ok := value.(float64)
+		return ok, nil
+	case "mapping":
+		switch value.(type) {
+		case *Dict, *namespace:
+			return true, nil
+		}
+		return false, nil
+	case "sequence", "iterable":
+		switch value.(type) {
+		case string, []interface{}, *Dict:
+			return true, nil
+		}
+		return false, nil
+	case "callable":
+		_, ok := value.(callable)
+		return ok, nil
+	case "odd", "even":
+		n, ok := toInt(value)
+		if !ok {
+			return false, fmt.Errorf("%s test expects an integer", name)
+		}
+		return (n%2 != 0) == (name == "odd"), nil
+	case "divisibleby":
+		n, ok1 := toInt(value)
+		if len(args) != 1 {
+			return false, fmt.Errorf("divisibleby test expects 1 argument")
+		}
+		d, ok2 := toInt(args[0])
+		if !ok1 || !ok2 || d == 0 {
+			return false, fmt.Errorf("divisibleby test expects integers")
+		}
+		return n%d == 0, nil
+	case "eq", "equalto", "==", "ne", "!=", "lt", "<", "le", "<=", "gt", ">", "ge", ">=", "sameas":
+		if len(args) != 1 {
+			return false, fmt.Errorf("%s test expects 1 argument", name)
+		}
+		switch name {
+		case "eq", "equalto", "==", "sameas":
+			return equal(value, args[0]), nil
+		case "ne", "!=":
+			return !equal(value, args[0]), nil
+		}
+		c, err := compare(value, args[0])
+		if err != nil {
+			return false, err
+		}
+		switch name {
+		case "lt", "<":
+			return c < 0, nil
+		case "le", "<=":
+			return c <= 0, nil
+		case "gt", ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "in":
+		if len(args) != 1 {
+			return false, fmt.Errorf("in test expects 1 argument")
+		}
+		return contains(args[0], value)
+	case "lower":
+		s, ok := value.(string)
+		return ok && s == strings.ToLower(s), nil
+	case "upper":
+		s, ok := value.(string)
+		return ok && s == strings.ToUpper(s), nil
+	}
+	return false, fmt.Errorf("%w test: %s", ErrUnsupported, name)
+}
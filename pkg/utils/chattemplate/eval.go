@@ -0,0 +1,742 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chattemplate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	errBreak    = errors.New("break")
+	errContinue = errors.New("continue")
+)
+
+// maxOutputSize bounds the rendered output, templates are user content and may loop.
+const maxOutputSize = 16 << 20
+
+// scope holds variables, for loops and macros create child scopes.
+type scope struct {
+	vars   map[string]interface{}
+	parent *scope
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{vars: map[string]interface{}{}, parent: parent}
+}
+
+func (s *scope) lookup(name string) (interface{}, bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+type renderer struct {
+	out *strings.Builder
+}
+
+func (r *renderer) renderNodes(nodes []node, s *scope) error {
+	for _, n := range nodes {
+		if err := r.renderNode(n, s); err != nil {
+			return err
+		}
+		if r.out.Len() > maxOutputSize {
+			return fmt.Errorf("rendered output exceeds %d bytes", maxOutputSize)
+		}
+	}
+	return nil
+}
+
+func (r *renderer) renderNode(n node, s *scope) error {
+	switch n := n.(type) {
+	case *textNode:
+		r.out.WriteString(n.text)
+	case *outputNode:
+		v, err := r.eval(n.expr, s)
+		if err != nil {
+			return err
+		}
+		r.out.WriteString(toString(v))
+	case *ifNode:
+		for i, condition := range n.conditions {
+			v, err := r.eval(condition, s)
+			if err != nil {
+				return err
+			}
+			if isTrue(v) {
+				return r.renderNodes(n.bodies[i], s)
+			}
+		}
+		return r.renderNodes(n.elseBody, s)
+	case *forNode:
+		return r.renderFor(n, s)
+	case *setNode:
+		return r.renderSet(n, s)
+	case *macroNode:
+		s.vars[n.name] = r.newMacro(n, s)
+	case *callBlockNode:
+		call := n.call.(*callExpr)
+		caller := callable(func(_ []interface{}, _ *Dict) (interface{}, error) {
+			return r.capture(n.body, newScope(s))
+		})
+		inner := newScope(s)
+		inner.vars["caller"] = caller
+		v, err := r.eval(call, inner)
+		if err != nil {
+			return err
+		}
+		r.out.WriteString(toString(v))
+	case *filterBlockNode:
+		content, err := r.capture(n.body, s)
+		if err != nil {
+			return err
+		}
+		v, err := r.applyFilter(n.filter, content, s)
+		if err != nil {
+			return err
+		}
+		r.out.WriteString(toString(v))
+	case *breakNode:
+		return errBreak
+	case *continueNode:
+		return errContinue
+	default:
+		return fmt.Errorf("unknown node %T", n)
+	}
+	return nil
+}
+
+// capture renders nodes into a string instead of the output.
+func (r *renderer) capture(nodes []node, s *scope) (string, error) {
+	out := r.out
+	r.out = &strings.Builder{}
+	defer func() {
+		r.out = out
+	}()
+	if err := r.renderNodes(nodes, s); err != nil {
+		return "", err
+	}
+	return r.out.String(), nil
+}
+
+func (r *renderer) renderFor(n *forNode, s *scope) error {
+	iterable, err := r.eval(n.iterable, s)
+	if err != nil {
+		return err
+	}
+	items, err := iterate(iterable)
+	if err != nil {
+		return err
+	}
+
+	loopScope := newScope(s)
+	if n.filter != nil {
+		filtered := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if err := assignTargets(loopScope, n.targets, item); err != nil {
+				return err
+			}
+			v, err := r.eval(n.filter, loopScope)
+			if err != nil {
+				return err
+			}
+			if isTrue(v) {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+	if len(items) == 0 {
+		return r.renderNodes(n.elseBody, s)
+	}
+
+	for i, item := range items {
+		loopScope = newScope(s)
+		if err := assignTargets(loopScope, n.targets, item); err != nil {
+			return err
+		}
+		loop := NewDict()
+		loop.Set("index", i+1)
+		loop.Set("index0", i)
+		loop.Set("revindex", len(items)-i)
+		loop.Set("revindex0", len(items)-i-1)
+		loop.Set("first", i == 0)
+		loop.Set("last", i == len(items)-1)
+		loop.Set("length", len(items))
+		loop.Set("previtem", undefined{name: "previtem"})
+		loop.Set("nextitem", undefined{name: "nextitem"})
+		if i > 0 {
+			loop.Set("previtem", items[i-1])
+		}
+		if i < len(items)-1 {
+			loop.Set("nextitem", items[i+1])
+		}
+		loop.Set("cycle", callable(func(args []interface{}, _ *Dict) (interface{}, error) {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("loop.cycle requires arguments")
+			}
+			return args[i%len(args)], nil
+		}))
+		loopScope.vars["loop"] = loop
+
+		err := r.renderNodes(n.body, loopScope)
+		if errors.Is(err, errBreak) {
+			break
+		}
+		if err != nil && !errors.Is(err, errContinue) {
+			return err
+		}
+	}
+	return nil
+}
+
+func assignTargets(s *scope, targets []string, item interface{}) error {
+	if len(targets) == 1 {
+		s.vars[targets[0]] = item
+		return nil
+	}
+	values, ok := item.([]interface{})
+	if !ok || len(values) != len(targets) {
+		return fmt.Errorf("can't unpack %s into %d values", typeName(item), len(targets))
+	}
+	for i, target := range targets {
+		s.vars[target] = values[i]
+	}
+	return nil
+}
+
+func (r *renderer) renderSet(n *setNode, s *scope) error {
+	var value interface{}
+	var err error
+	if n.value != nil {
+		value, err = r.eval(n.value, s)
+	} else {
+		value, err = r.capture(n.body, s)
+	}
+	if err != nil {
+		return err
+	}
+	if n.attr == "" {
+		s.vars[n.name] = value
+		return nil
+	}
+	target, _ := s.lookup(n.name)
+	ns, ok := target.(*namespace)
+	if !ok {
+		return fmt.Errorf("can't assign attribute %s of %s, only namespace attributes are assignable", n.attr, n.name)
+	}
+	ns.Set(n.attr, value)
+	return nil
+}
+
+// namespace is the mutable object created by namespace(), it allows assignments across scopes.
+type namespace struct {
+	*Dict
+}
+
+func (r *renderer) newMacro(n *macroNode, s *scope) callable {
+	return func(args []interface{}, kwargs *Dict) (interface{}, error) {
+		macroScope := newScope(s)
+		for i, param := range n.params {
+			switch {
+			case i < len(args):
+				macroScope.vars[param] = args[i]
+			default:
+				if v, ok := kwargs.Get(param); ok {
+					macroScope.vars[param] = v
+				} else if def, ok := n.defaults[param]; ok {
+					v, err := r.eval(def, s)
+					if err != nil {
+						return nil, err
+					}
+					macroScope.vars[param] = v
+				} else {
+					macroScope.vars[param] = undefined{name: param}
+				}
+			}
+		}
+		varargs := []interface{}{}
+		if len(args) > len(n.params) {
+			varargs = args[len(n.params):]
+		}
+		macroScope.vars["varargs"] = varargs
+		macroScope.vars["kwargs"] = kwargs
+		return r.capture(n.body, macroScope)
+	}
+}
+
+func (r *renderer) eval(e expr, s *scope) (interface{}, error) {
+	switch e := e.(type) {
+	case *literalExpr:
+		return e.value, nil
+	case *nameExpr:
+		if v, ok := s.lookup(e.name); ok {
+			return v, nil
+		}
+		if fn, ok := globals[e.name]; ok {
+			return fn, nil
+		}
+		return undefined{name: e.name}, nil
+	case *listExpr:
+		items := make([]interface{}, len(e.items))
+		for i, item := range e.items {
+			v, err := r.eval(item, s)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+	case *dictExpr:
+		d := NewDict()
+		for i := range e.keys {
+			k, err := r.eval(e.keys[i], s)
+			if err != nil {
+				return nil, err
+			}
+			v, err := r.eval(e.values[i], s)
+			if err != nil {
+				return nil, err
+			}
+			d.Set(toString(k), v)
+		}
+		return d, nil
+	case *attrExpr:
+		object, err := r.eval(e.object, s)
+		if err != nil {
+			return nil, err
+		}
+		return getAttr(object, e.name), nil
+	case *itemExpr:
+		object, err := r.eval(e.object, s)
+		if err != nil {
+			return nil, err
+		}
+		key, err := r.eval(e.key, s)
+		if err != nil {
+			return nil, err
+		}
+		return getItem(object, key), nil
+	case *sliceExpr:
+		return r.evalSlice(e, s)
+	case *callExpr:
+		return r.evalCall(e, s)
+	case *filterExpr:
+		value, err := r.eval(e.value, s)
+		if err != nil {
+			return nil, err
+		}
+		return r.applyFilter(e, value, s)
+	case *testExpr:
+		value, err := r.eval(e.value, s)
+		if err != nil {
+			return nil, err
+		}
+		args, err := r.evalList(e.args, s)
+		if err != nil {
+			return nil, err
+		}
+		result, err := applyTest(e.name, value, args)
+		if err != nil {
+			return nil, err
+		}
+		return result != e.negate, nil
+	case *unaryExpr:
+		value, err := r.eval(e.value, s)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "not":
+			return !isTrue(value), nil
+		case "-":
+			switch v := value.(type) {
+			case int:
+				return -v, nil
+			case float64:
+				return -v, nil
+			}
+			return nil, fmt.Errorf("bad operand type for unary -: %s", typeName(value))
+		default:
+			return value, nil
+		}
+	case *binaryExpr:
+		return r.evalBinary(e, s)
+	case *condExpr:
+		condition, err := r.eval(e.condition, s)
+		if err != nil {
+			return nil, err
+		}
+		if isTrue(condition) {
+			return r.eval(e.then, s)
+		}
+		if e.otherwise == nil {
+			return undefined{}, nil
+		}
+		return r.eval(e.otherwise, s)
+	}
+	return nil, fmt.Errorf("unknown expression %T", e)
+}
+
+func (r *renderer) evalList(exprs []expr, s *scope) ([]interface{}, error) {
+	values := make([]interface{}, len(exprs))
+	for i, e := range exprs {
+		v, err := r.eval(e, s)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (r *renderer) evalKwargs(kwargs map[string]expr, names []string, s *scope) (*Dict, error) {
+	d := NewDict()
+	if names == nil {
+		for name := range kwargs {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		v, err := r.eval(kwargs[name], s)
+		if err != nil {
+			return nil, err
+		}
+		d.Set(name, v)
+	}
+	return d, nil
+}
+
+func (r *renderer) evalCall(e *callExpr, s *scope) (interface{}, error) {
+	fn, err := r.eval(e.fn, s)
+	if err != nil {
+		return nil, err
+	}
+	args, err := r.evalList(e.args, s)
+	if err != nil {
+		return nil, err
+	}
+	kwargs, err := r.evalKwargs(e.kwargs, e.kwnames, s)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := fn.(callable)
+	if !ok {
+		if u, ok := fn.(undefined); ok {
+			return nil, fmt.Errorf("%w: %s is undefined", ErrUnsupported, u.name)
+		}
+		return nil, fmt.Errorf("%s is not callable", typeName(fn))
+	}
+	return f(args, kwargs)
+}
+
+func (r *renderer) evalSlice(e *sliceExpr, s *scope) (interface{}, error) {
+	object, err := r.eval(e.object, s)
+	if err != nil {
+		return nil, err
+	}
+	var bounds [3]*int
+	for i, b := range []expr{e.start, e.stop, e.step} {
+		if b == nil {
+			continue
+		}
+		v, err := r.eval(b, s)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		n, ok := toInt(v)
+		if !ok {
+			return nil, fmt.Errorf("slice indices must be integers")
+		}
+		bounds[i] = &n
+	}
+
+	switch v := object.(type) {
+	case []interface{}:
+		indexes, err := sliceIndexes(len(v), bounds)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, len(indexes))
+		for i, index := range indexes {
+			items[i] = v[index]
+		}
+		return items, nil
+	case string:
+		runes := []rune(v)
+		indexes, err := sliceIndexes(len(runes), bounds)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]rune, len(indexes))
+		for i, index := range indexes {
+			result[i] = runes[index]
+		}
+		return string(result), nil
+	}
+	return nil, fmt.Errorf("%s is not subscriptable", typeName(object))
+}
+
+// sliceIndexes returns the indexes selected by a python slice.
+func sliceIndexes(n int, bounds [3]*int) ([]int, error) {
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("slice step cannot be zero")
+	}
+	clamp := func(b *int, def, lower, upper int) int {
+		if b == nil {
+			return def
+		}
+		i := *b
+		if i < 0 {
+			i += n
+		}
+		return max(lower, min(i, upper))
+	}
+	var indexes []int
+	if step > 0 {
+		for i, stop := clamp(bounds[0], 0, 0, n), clamp(bounds[1], n, 0, n); i < stop; i += step {
+			indexes = append(indexes, i)
+		}
+	} else {
+		for i, stop := clamp(bounds[0], n-1, -1, n-1), clamp(bounds[1], -1, -1, n-1); i > stop; i += step {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+func (r *renderer) evalBinary(e *binaryExpr, s *scope) (interface{}, error) {
+	left, err := r.eval(e.left, s)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "and":
+		if !isTrue(left) {
+			return left, nil
+		}
+		return r.eval(e.right, s)
+	case "or":
+		if isTrue(left) {
+			return left, nil
+		}
+		return r.eval(e.right, s)
+	}
+	right, err := r.eval(e.right, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", ">", "<=", ">=":
+		c, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "<":
+			return c < 0, nil
+		case ">":
+			return c > 0, nil
+		case "<=":
+			return c <= 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "in":
+		return contains(right, left)
+	case "not in":
+		found, err := contains(right, left)
+		return !found, err
+	case "~":
+		return toString(left) + toString(right), nil
+	case "+":
+		switch l := left.(type) {
+		case string:
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		case []interface{}:
+			if r, ok := right.([]interface{}); ok {
+				return append(append([]interface{}{}, l...), r...), nil
+			}
+		}
+	case "*":
+		if l, ok := left.(string); ok {
+			if n, ok := toInt(right); ok {
+				return strings.Repeat(l, max(n, 0)), nil
+			}
+		}
+		if l, ok := left.([]interface{}); ok {
+			if n, ok := toInt(right); ok {
+				var items []interface{}
+				for i := 0; i < n; i++ {
+					items = append(items, l...)
+				}
+				return items, nil
+			}
+		}
+	}
+	return arithmetic(e.op, left, right)
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	li, lIsInt := toInt(left)
+	ri, rIsInt := toInt(right)
+	if lIsInt && rIsInt {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "//", "%":
+			if ri == 0 {
+				return nil, fmt.Errorf("integer division or modulo by zero")
+			}
+			q := int(math.Floor(float64(li) / float64(ri)))
+			if op == "//" {
+				return q, nil
+			}
+			return li - q*ri, nil
+		case "**":
+			if ri >= 0 {
+				return int(math.Pow(float64(li), float64(ri))), nil
+			}
+		}
+	}
+	lf, lok := toNumber(left)
+	rf, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("unsupported operand types for %s: %s and %s", op, typeName(left), typeName(right))
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return lf / rf, nil
+	case "//":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Floor(lf / rf), nil
+	case "%":
+		if rf == 0 {
+			return nil, fmt.Errorf("modulo by zero")
+		}
+		return lf - math.Floor(lf/rf)*rf, nil
+	case "**":
+		return math.Pow(lf, rf), nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s", op)
+}
+
+func contains(container, item interface{}) (bool, error) {
+	switch c := container.(type) {
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("'in <string>' requires string as left operand, not %s", typeName(item))
+		}
+		return strings.Contains(c, s), nil
+	case []interface{}:
+		for _, v := range c {
+			if equal(v, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *Dict:
+		key, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := c.Get(key)
+		return found, nil
+	case *namespace:
+		return contains(c.Dict, item)
+	case undefined, nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("argument of type %s is not iterable", typeName(container))
+}
+
+// getAttr returns an attribute of a value, dict keys are accessible as attributes unless shadowed by dict methods.
+func getAttr(object interface{}, name string) interface{} {
+	if method, ok := getMethod(object, name); ok {
+		return method
+	}
+	return getItem(object, name)
+}
+
+// getItem returns an item of a value, missing items are undefined.
+func getItem(object, key interface{}) interface{} {
+	switch o := object.(type) {
+	case *Dict:
+		if k, ok := key.(string); ok {
+			if v, ok := o.Get(k); ok {
+				return v
+			}
+		}
+	case *namespace:
+		return getItem(o.Dict, key)
+	case []interface{}:
+		if i, ok := toInt(key); ok {
+			if i < 0 {
+				i += len(o)
+			}
+			if i >= 0 && i < len(o) {
+				return o[i]
+			}
+		}
+	case string:
+		if i, ok := toInt(key); ok {
+			runes := []rune(o)
+			if i < 0 {
+				i += len(runes)
+			}
+			if i >= 0 && i < len(runes) {
+				return string(runes[i])
+			}
+		}
+	}
+	return undefined{name: toString(key)}
+}
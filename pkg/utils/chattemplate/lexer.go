@@ -0,0 +1,312 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chattemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenVariableBegin
+	tokenVariableEnd
+	tokenBlockBegin
+	tokenBlockEnd
+	tokenName
+	tokenString
+	tokenInteger
+	tokenFloat
+	tokenOperator
+	tokenEOF
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+}
+
+// operators are matched longest first.
+var operators = []string{
+	"**", "//", "==", "!=", "<=", ">=",
+	"+", "-", "*", "/", "%", "~", "<", ">", "=", "(", ")", "[", "]", "{", "}", ".", ",", ":", "|",
+}
+
+// lexer splits a template into tokens. Same as the environment used by HuggingFace chat templates,
+// trim_blocks and lstrip_blocks are enabled.
+type lexer struct {
+	src    string
+	pos    int
+	tokens []token
+	// stripNext strips all leading whitespace of the next text, set by "-}}" and "-%}".
+	stripNext bool
+}
+
+func tokenize(src string) ([]token, error) {
+	l := &lexer{src: src}
+	if err := l.run(); err != nil {
+		return nil, err
+	}
+	return l.tokens, nil
+}
+
+func (l *lexer) run() error {
+	for l.pos < len(l.src) {
+		idx := l.nextTag()
+		if idx < 0 {
+			l.emitText(l.src[l.pos:])
+			l.pos = len(l.src)
+			break
+		}
+
+		kind := l.src[idx : idx+2]
+		text := l.src[l.pos:idx]
+		modifier := byte(0)
+		if idx+2 < len(l.src) {
+			modifier = l.src[idx+2]
+		}
+		if modifier == '-' {
+			text = strings.TrimRight(text, " \t\r\n")
+		} else if kind != "{{" && modifier != '+' {
+			text = lstripBlock(text, l.pos == 0 || l.src[l.pos-1] == '\n')
+		}
+		l.emitText(text)
+		l.pos = idx + 2
+		if modifier == '-' || modifier == '+' {
+			l.pos++
+		}
+
+		switch kind {
+		case "{#":
+			end := strings.Index(l.src[l.pos:], "#}")
+			if end < 0 {
+				return l.errorf("unclosed comment")
+			}
+			comment := l.src[l.pos : l.pos+end]
+			l.pos += end + 2
+			l.afterTag(strings.HasSuffix(comment, "-"), true)
+		case "{{":
+			l.tokens = append(l.tokens, token{kind: tokenVariableBegin, line: l.lineAt(l.pos)})
+			strip, err := l.lexExpression("}}")
+			if err != nil {
+				return err
+			}
+			l.tokens = append(l.tokens, token{kind: tokenVariableEnd, line: l.lineAt(l.pos)})
+			l.afterTag(strip, false)
+		case "{%":
+			l.tokens = append(l.tokens, token{kind: tokenBlockBegin, line: l.lineAt(l.pos)})
+			strip, err := l.lexExpression("%}")
+			if err != nil {
+				return err
+			}
+			l.tokens = append(l.tokens, token{kind: tokenBlockEnd, line: l.lineAt(l.pos)})
+			l.afterTag(strip, true)
+		}
+	}
+	l.tokens = append(l.tokens, token{kind: tokenEOF, line: l.lineAt(l.pos)})
+	return nil
+}
+
+// nextTag returns the index of the next "{{", "{%" or "{#", -1 if there is none.
+func (l *lexer) nextTag() int {
+	for i := l.pos; i+1 < len(l.src); i++ {
+		if l.src[i] == '{' && (l.src[i+1] == '{' || l.src[i+1] == '%' || l.src[i+1] == '#') {
+			return i
+		}
+	}
+	return -1
+}
+
+// afterTag skips whitespace after a tag: all whitespace for "-" tags, or the first newline after blocks (trim_blocks).
+func (l *lexer) afterTag(strip, block bool) {
+	if strip {
+		l.stripNext = true
+		return
+	}
+	if !block {
+		return
+	}
+	if strings.HasPrefix(l.src[l.pos:], "\r\n") {
+		l.pos += 2
+	} else if strings.HasPrefix(l.src[l.pos:], "\n") {
+		l.pos++
+	}
+}
+
+// lstripBlock removes spaces and tabs between the line start and a block tag (lstrip_blocks).
+func lstripBlock(text string, lineStarting bool) string {
+	lineStart := strings.LastIndexByte(text, '\n') + 1
+	if lineStart == 0 && !lineStarting {
+		return text
+	}
+	if strings.TrimLeft(text[lineStart:], " \t") != "" {
+		return text
+	}
+	return text[:lineStart]
+}
+
+func (l *lexer) emitText(text string) {
+	if l.stripNext {
+		text = strings.TrimLeft(text, " \t\r\n")
+		l.stripNext = false
+	}
+	if text != "" {
+		l.tokens = append(l.tokens, token{kind: tokenText, value: text, line: l.lineAt(l.pos)})
+	}
+}
+
+// lineAt returns the line number of a position, it is only used for errors and parsing.
+func (l *lexer) lineAt(pos int) int {
+	return strings.Count(l.src[:pos], "\n") + 1
+}
+
+// lexExpression lexes the tokens of a tag until its end delimiter, it returns true if the tag ends with "-".
+func (l *lexer) lexExpression(end string) (bool, error) {
+	for {
+		for l.pos < len(l.src) && isSpace(l.src[l.pos]) {
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return false, l.errorf("unclosed tag, expected %q", end)
+		}
+		rest := l.src[l.pos:]
+		switch {
+		case strings.HasPrefix(rest, end):
+			l.pos += len(end)
+			return false, nil
+		case strings.HasPrefix(rest, "-"+end):
+			l.pos += len(end) + 1
+			return true, nil
+		case strings.HasPrefix(rest, "+"+end):
+			l.pos += len(end) + 1
+			return false, nil
+		}
+
+		c := rest[0]
+		switch {
+		case isNameStart(c):
+			n := 1
+			for n < len(rest) && isNameChar(rest[n]) {
+				n++
+			}
+			l.tokens = append(l.tokens, token{kind: tokenName, value: rest[:n], line: l.lineAt(l.pos)})
+			l.pos += n
+		case isDigit(c):
+			n := 1
+			kind := tokenInteger
+			for n < len(rest) && (isDigit(rest[n]) || rest[n] == '_') {
+				n++
+			}
+			if n+1 < len(rest) && rest[n] == '.' && isDigit(rest[n+1]) {
+				kind = tokenFloat
+				n++
+				for n < len(rest) && isDigit(rest[n]) {
+					n++
+				}
+			}
+			if n < len(rest) && (rest[n] == 'e' || rest[n] == 'E') {
+				m := n + 1
+				if m < len(rest) && (rest[m] == '+' || rest[m] == '-') {
+					m++
+				}
+				if m < len(rest) && isDigit(rest[m]) {
+					kind = tokenFloat
+					for n = m; n < len(rest) && isDigit(rest[n]); n++ {
+					}
+				}
+			}
+			l.tokens = append(l.tokens, token{kind: kind, value: strings.ReplaceAll(rest[:n], "_", ""), line: l.lineAt(l.pos)})
+			l.pos += n
+		case c == '\'' || c == '"':
+			s, n, err := unquote(rest)
+			if err != nil {
+				return false, l.errorf("%v", err)
+			}
+			l.tokens = append(l.tokens, token{kind: tokenString, value: s, line: l.lineAt(l.pos)})
+			l.pos += n
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(rest, op) {
+					l.tokens = append(l.tokens, token{kind: tokenOperator, value: op, line: l.lineAt(l.pos)})
+					l.pos += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false, l.errorf("unexpected character %q", c)
+			}
+		}
+	}
+}
+
+// unquote parses a python string literal at the start of s, it returns the string and the length of the literal.
+func unquote(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == quote {
+			return b.String(), i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\', '\'', '"':
+			b.WriteByte(s[i])
+		case '\n':
+			// Line continuation.
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", l.lineAt(l.pos), fmt.Sprintf(format, args...))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
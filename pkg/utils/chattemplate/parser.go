@@ -0,0 +1,902 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chattemplate
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// node is a statement of the template.
+type node interface{}
+
+type textNode struct {
+	text string
+}
+
+type outputNode struct {
+	expr expr
+}
+
+type ifNode struct {
+	conditions []expr
+	bodies     [][]node
+	elseBody   []node
+}
+
+type forNode struct {
+	targets  []string
+	iterable expr
+	filter   expr
+	body     []node
+	elseBody []node
+}
+
+// setNode assigns a variable, or an attribute of a namespace if attr is not empty.
+type setNode struct {
+	name  string
+	attr  string
+	value expr
+	// body is the content of a block set, used if value is nil.
+	body []node
+}
+
+type macroNode struct {
+	name     string
+	params   []string
+	defaults map[string]expr
+	body     []node
+}
+
+type callBlockNode struct {
+	call expr
+	body []node
+}
+
+type filterBlockNode struct {
+	filter *filterExpr
+	body   []node
+}
+
+type breakNode struct{}
+
+type continueNode struct{}
+
+// expr is an expression of the template.
+type expr interface{}
+
+type literalExpr struct {
+	value interface{}
+}
+
+type nameExpr struct {
+	name string
+}
+
+type listExpr struct {
+	items []expr
+}
+
+type dictExpr struct {
+	keys   []expr
+	values []expr
+}
+
+type attrExpr struct {
+	object expr
+	name   string
+}
+
+type itemExpr struct {
+	object expr
+	key    expr
+}
+
+type sliceExpr struct {
+	object            expr
+	start, stop, step expr
+}
+
+type callExpr struct {
+	fn     expr
+	args   []expr
+	kwargs map[string]expr
+	// kwnames keeps the order of keyword arguments.
+	kwnames []string
+}
+
+type filterExpr struct {
+	value  expr
+	name   string
+	args   []expr
+	kwargs map[string]expr
+}
+
+type testExpr struct {
+	value  expr
+	name   string
+	args   []expr
+	negate bool
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expr
+}
+
+type unaryExpr struct {
+	op    string
+	value expr
+}
+
+type condExpr struct {
+	condition, then, otherwise expr
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(src string) ([]node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	body, end, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	if end != "" {
+		return nil, p.errorf("unexpected %q", end)
+	}
+	return body, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(op string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && t.value == op
+}
+
+func (p *parser) isName(name string) bool {
+	t := p.peek()
+	return t.kind == tokenName && t.value == name
+}
+
+func (p *parser) skipOperator(op string) bool {
+	if p.isOperator(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipName(name string) bool {
+	if p.isName(name) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectOperator(op string) error {
+	if !p.skipOperator(op) {
+		return p.errorf("expected %q", op)
+	}
+	return nil
+}
+
+func (p *parser) expectName() (string, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return "", p.errorf("expected name")
+	}
+	return t.value, nil
+}
+
+func (p *parser) expectBlockEnd() error {
+	if p.next().kind != tokenBlockEnd {
+		return p.errorf("expected end of block")
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.peek().line, fmt.Sprintf(format, args...))
+}
+
+// parseBody parses nodes until EOF or a block tag which is not a statement start, e.g. "endif".
+// It returns the name of that tag, the tag's remaining tokens are left to the caller.
+func (p *parser) parseBody() ([]node, string, error) {
+	var body []node
+	for {
+		t := p.next()
+		switch t.kind {
+		case tokenEOF:
+			return body, "", nil
+		case tokenText:
+			body = append(body, &textNode{text: t.value})
+		case tokenVariableBegin:
+			e, err := p.parseExpression()
+			if err != nil {
+				return nil, "", err
+			}
+			if p.next().kind != tokenVariableEnd {
+				return nil, "", p.errorf("expected end of variable")
+			}
+			body = append(body, &outputNode{expr: e})
+		case tokenBlockBegin:
+			name, err := p.expectName()
+			if err != nil {
+				return nil, "", err
+			}
+			n, err := p.parseStatement(name)
+			if err != nil {
+				return nil, "", err
+			}
+			if n == nil {
+				return body, name, nil
+			}
+			body = append(body, n)
+		default:
+			return nil, "", p.errorf("unexpected token %q", t.value)
+		}
+	}
+}
+
+// parseStatement parses a statement, it returns nil if the name is not a statement start.
+func (p *parser) parseStatement(name string) (node, error) {
+	switch name {
+	case "if":
+		return p.parseIf()
+	case "for":
+		return p.parseFor()
+	case "set":
+		return p.parseSet()
+	case "macro":
+		return p.parseMacro()
+	case "call":
+		return p.parseCallBlock()
+	case "filter":
+		return p.parseFilterBlock()
+	case "generation":
+		// HuggingFace extension to mark assistant generations, the content is rendered as is.
+		if err := p.expectBlockEnd(); err != nil {
+			return nil, err
+		}
+		body, err := p.parseBlockBody("endgeneration")
+		if err != nil {
+			return nil, err
+		}
+		return &ifNode{conditions: []expr{&literalExpr{value: true}}, bodies: [][]node{body}}, nil
+	case "break":
+		return &breakNode{}, p.expectBlockEnd()
+	case "continue":
+		return &continueNode{}, p.expectBlockEnd()
+	default:
+		return nil, nil
+	}
+}
+
+// parseBlockBody parses nodes until the end tag.
+func (p *parser) parseBlockBody(end string) ([]node, error) {
+	body, tag, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	if tag != end {
+		return nil, p.errorf("expected %q, got %q", end, tag)
+	}
+	return body, p.expectBlockEnd()
+}
+
+func (p *parser) parseIf() (node, error) {
+	n := &ifNode{}
+	for {
+		condition, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectBlockEnd(); err != nil {
+			return nil, err
+		}
+		body, tag, err := p.parseBody()
+		if err != nil {
+			return nil, err
+		}
+		n.conditions = append(n.conditions, condition)
+		n.bodies = append(n.bodies, body)
+		switch tag {
+		case "elif":
+			continue
+		case "else":
+			if err := p.expectBlockEnd(); err != nil {
+				return nil, err
+			}
+			if n.elseBody, err = p.parseBlockBody("endif"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "endif":
+			return n, p.expectBlockEnd()
+		default:
+			return nil, p.errorf("expected endif, got %q", tag)
+		}
+	}
+}
+
+func (p *parser) parseFor() (node, error) {
+	n := &forNode{}
+	for {
+		target, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		n.targets = append(n.targets, target)
+		if !p.skipOperator(",") {
+			break
+		}
+	}
+	if !p.skipName("in") {
+		return nil, p.errorf("expected in")
+	}
+	var err error
+	if n.iterable, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if p.skipName("if") {
+		if n.filter, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectBlockEnd(); err != nil {
+		return nil, err
+	}
+	body, tag, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	n.body = body
+	switch tag {
+	case "else":
+		if err := p.expectBlockEnd(); err != nil {
+			return nil, err
+		}
+		if n.elseBody, err = p.parseBlockBody("endfor"); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case "endfor":
+		return n, p.expectBlockEnd()
+	default:
+		return nil, p.errorf("expected endfor, got %q", tag)
+	}
+}
+
+func (p *parser) parseSet() (node, error) {
+	n := &setNode{}
+	var err error
+	if n.name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if p.skipOperator(".") {
+		if n.attr, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.skipOperator("=") {
+		if n.value, err = p.parseTuple(); err != nil {
+			return nil, err
+		}
+		return n, p.expectBlockEnd()
+	}
+	if err := p.expectBlockEnd(); err != nil {
+		return nil, err
+	}
+	n.body, err = p.parseBlockBody("endset")
+	return n, err
+}
+
+func (p *parser) parseMacro() (node, error) {
+	n := &macroNode{defaults: map[string]expr{}}
+	var err error
+	if n.name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if err := p.expectOperator("("); err != nil {
+		return nil, err
+	}
+	for !p.skipOperator(")") {
+		if len(n.params) > 0 {
+			if err := p.expectOperator(","); err != nil {
+				return nil, err
+			}
+		}
+		param, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		n.params = append(n.params, param)
+		if p.skipOperator("=") {
+			if n.defaults[param], err = p.parseExpression(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := p.expectBlockEnd(); err != nil {
+		return nil, err
+	}
+	n.body, err = p.parseBlockBody("endmacro")
+	return n, err
+}
+
+func (p *parser) parseCallBlock() (node, error) {
+	call, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := call.(*callExpr); !ok {
+		return nil, p.errorf("expected call")
+	}
+	if err := p.expectBlockEnd(); err != nil {
+		return nil, err
+	}
+	body, err := p.parseBlockBody("endcall")
+	return &callBlockNode{call: call, body: body}, err
+}
+
+func (p *parser) parseFilterBlock() (node, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	filter, err := p.parseFilter(nil, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectBlockEnd(); err != nil {
+		return nil, err
+	}
+	body, err := p.parseBlockBody("endfilter")
+	return &filterBlockNode{filter: filter, body: body}, err
+}
+
+// parseTuple parses an expression, or a tuple without parentheses such as "a, b".
+func (p *parser) parseTuple() (expr, error) {
+	e, err := p.parseExpression()
+	if err != nil || !p.isOperator(",") {
+		return e, err
+	}
+	items := []expr{e}
+	for p.skipOperator(",") {
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &listExpr{items: items}, nil
+}
+
+func (p *parser) parseExpression() (expr, error) {
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.skipName("if") {
+		return e, nil
+	}
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	var otherwise expr
+	if p.skipName("else") {
+		if otherwise, err = p.parseExpression(); err != nil {
+			return nil, err
+		}
+	}
+	return &condExpr{condition: condition, then: e, otherwise: otherwise}, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipName("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipName("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.skipName("not") {
+		value, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "not", value: value}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (expr, error) {
+	left, err := p.parseMath1()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		t := p.peek()
+		switch {
+		case t.kind == tokenOperator && (t.value == "==" || t.value == "!=" || t.value == "<" || t.value == ">" || t.value == "<=" || t.value == ">="):
+			op = t.value
+			p.pos++
+		case t.kind == tokenName && t.value == "in":
+			op = "in"
+			p.pos++
+		case t.kind == tokenName && t.value == "not" && p.tokens[p.pos+1].kind == tokenName && p.tokens[p.pos+1].value == "in":
+			op = "not in"
+			p.pos += 2
+		default:
+			return left, nil
+		}
+		right, err := p.parseMath1()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMath1() (expr, error) {
+	left, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+") || p.isOperator("-") {
+		op := p.next().value
+		right, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseConcat() (expr, error) {
+	left, err := p.parseMath2()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipOperator("~") {
+		right, err := p.parseMath2()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "~", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMath2() (expr, error) {
+	left, err := p.parsePow()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*") || p.isOperator("/") || p.isOperator("//") || p.isOperator("%") {
+		op := p.next().value
+		right, err := p.parsePow()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePow() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipOperator("**") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "**", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	var e expr
+	var err error
+	if p.isOperator("-") || p.isOperator("+") {
+		op := p.next().value
+		value, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		e = &unaryExpr{op: op, value: value}
+	} else if e, err = p.parsePrimary(); err != nil {
+		return nil, err
+	}
+	return p.parsePostfix(e)
+}
+
+// parsePostfix parses attributes, items, calls, filters and tests of an expression.
+func (p *parser) parsePostfix(e expr) (expr, error) {
+	for {
+		var err error
+		switch {
+		case p.skipOperator("."):
+			t := p.next()
+			if t.kind != tokenName && t.kind != tokenInteger {
+				return nil, p.errorf("expected attribute name")
+			}
+			if t.kind == tokenInteger {
+				n, _ := strconv.Atoi(t.value)
+				e = &itemExpr{object: e, key: &literalExpr{value: n}}
+			} else {
+				e = &attrExpr{object: e, name: t.value}
+			}
+		case p.skipOperator("["):
+			if e, err = p.parseSubscript(e); err != nil {
+				return nil, err
+			}
+		case p.skipOperator("("):
+			call := &callExpr{fn: e}
+			if call.args, call.kwargs, call.kwnames, err = p.parseArgs(); err != nil {
+				return nil, err
+			}
+			e = call
+		case p.skipOperator("|"):
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if e, err = p.parseFilter(e, name); err != nil {
+				return nil, err
+			}
+		case p.skipName("is"):
+			test := &testExpr{value: e, negate: p.skipName("not")}
+			if test.name, err = p.expectName(); err != nil {
+				return nil, err
+			}
+			if p.skipOperator("(") {
+				if test.args, _, _, err = p.parseArgs(); err != nil {
+					return nil, err
+				}
+			} else if t := p.peek(); t.kind == tokenString || t.kind == tokenInteger || t.kind == tokenFloat ||
+				(t.kind == tokenName && !isKeyword(t.value)) {
+				arg, err := p.parsePrimary()
+				if err != nil {
+					return nil, err
+				}
+				test.args = []expr{arg}
+			}
+			e = test
+		default:
+			return e, nil
+		}
+	}
+}
+
+func (p *parser) parseFilter(value expr, name string) (*filterExpr, error) {
+	filter := &filterExpr{value: value, name: name}
+	if p.skipOperator("(") {
+		var err error
+		if filter.args, filter.kwargs, _, err = p.parseArgs(); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+func (p *parser) parseSubscript(object expr) (expr, error) {
+	var parts [3]expr
+	index := 0
+	isSlice := false
+	for !p.skipOperator("]") {
+		if p.skipOperator(":") {
+			isSlice = true
+			index++
+			if index > 2 {
+				return nil, p.errorf("invalid slice")
+			}
+			continue
+		}
+		e, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		parts[index] = e
+	}
+	if !isSlice {
+		return &itemExpr{object: object, key: parts[0]}, nil
+	}
+	return &sliceExpr{object: object, start: parts[0], stop: parts[1], step: parts[2]}, nil
+}
+
+// parseArgs parses call arguments after "(".
+func (p *parser) parseArgs() ([]expr, map[string]expr, []string, error) {
+	var args []expr
+	kwargs := map[string]expr{}
+	var kwnames []string
+	for !p.skipOperator(")") {
+		if len(args)+len(kwnames) > 0 {
+			if err := p.expectOperator(","); err != nil {
+				return nil, nil, nil, err
+			}
+			if p.skipOperator(")") {
+				break
+			}
+		}
+		if t := p.peek(); t.kind == tokenName && p.tokens[p.pos+1].kind == tokenOperator && p.tokens[p.pos+1].value == "=" {
+			p.pos += 2
+			value, err := p.parseExpression()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			kwargs[t.value] = value
+			kwnames = append(kwnames, t.value)
+			continue
+		}
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, kwargs, kwnames, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenName:
+		switch t.value {
+		case "true", "True":
+			return &literalExpr{value: true}, nil
+		case "false", "False":
+			return &literalExpr{value: false}, nil
+		case "none", "None":
+			return &literalExpr{value: nil}, nil
+		}
+		return &nameExpr{name: t.value}, nil
+	case tokenString:
+		s := t.value
+		// Adjacent strings are concatenated.
+		for p.peek().kind == tokenString {
+			s += p.next().value
+		}
+		return &literalExpr{value: s}, nil
+	case tokenInteger:
+		n, err := strconv.Atoi(t.value)
+		if err != nil {
+			return nil, p.errorf("invalid integer %s", t.value)
+		}
+		return &literalExpr{value: n}, nil
+	case tokenFloat:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, p.errorf("invalid float %s", t.value)
+		}
+		return &literalExpr{value: f}, nil
+	case tokenOperator:
+		switch t.value {
+		case "(":
+			if p.skipOperator(")") {
+				return &listExpr{}, nil
+			}
+			e, err := p.parseTuple()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expectOperator(")")
+		case "[":
+			list := &listExpr{}
+			for !p.skipOperator("]") {
+				if len(list.items) > 0 {
+					if err := p.expectOperator(","); err != nil {
+						return nil, err
+					}
+					if p.skipOperator("]") {
+						break
+					}
+				}
+				item, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+			}
+			return list, nil
+		case "{":
+			dict := &dictExpr{}
+			for !p.skipOperator("}") {
+				if len(dict.keys) > 0 {
+					if err := p.expectOperator(","); err != nil {
+						return nil, err
+					}
+					if p.skipOperator("}") {
+						break
+					}
+				}
+				key, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectOperator(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				dict.keys = append(dict.keys, key)
+				dict.values = append(dict.values, value)
+			}
+			return dict, nil
+		}
+	}
+	return nil, p.errorf("unexpected token %q", t.value)
+}
+
+func isKeyword(name string) bool {
+	switch name {
+	case "and", "or", "not", "in", "is", "if", "else":
+		return true
+	}
+	return false
+}
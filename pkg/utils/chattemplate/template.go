@@ -0,0 +1,302 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package chattemplate renders chat completion requests with the chat templates of HuggingFace models.
+// It implements the subset of Jinja used by chat templates, so the gateway sees the same prompt as the engine.
+package chattemplate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrUnsupported is returned when a template calls a filter, test or function the evaluator does not implement.
+var ErrUnsupported = errors.New("unsupported")
+
+// Template is a parsed Jinja template, it is safe for concurrent use.
+type Template struct {
+	nodes []node
+}
+
+// New parses a Jinja template.
+func New(source string) (*Template, error) {
+	nodes, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &Template{nodes: nodes}, nil
+}
+
+// Render renders the template with variables. Variables are template values, see DecodeJSON.
+func (t *Template) Render(vars map[string]interface{}) (string, error) {
+	s := newScope(nil)
+	for k, v := range vars {
+		s.vars[k] = v
+	}
+	r := &renderer{out: &strings.Builder{}}
+	// Top level statements share the root scope with the variables, same as jinja.
+	if err := r.renderNodes(t.nodes, newScope(s)); err != nil {
+		return "", err
+	}
+	return r.out.String(), nil
+}
+
+// openAIContentRegex matches templates iterating over message content parts, e.g. "for content in message['content']".
+var openAIContentRegex = regexp.MustCompile(`for\s+\w+\s+in\s+[\w.]*(\[\s*['"]content['"]\s*\]|\.content\b)`)
+
+// placeholders replace the multimodal content parts for templates only rendering string contents.
+var placeholders = map[string]string{
+	"image_url":   "<image>",
+	"image":       "<image>",
+	"input_audio": "<audio>",
+	"audio_url":   "<audio>",
+	"audio":       "<audio>",
+	"video_url":   "<video>",
+	"video":       "<video>",
+}
+
+// ChatTemplate renders the messages of chat completion requests into prompts.
+type ChatTemplate struct {
+	template *Template
+	// specialTokens are variables such as bos_token and eos_token.
+	specialTokens map[string]interface{}
+	// openAIContent is true if the template renders content parts, otherwise contents are flattened to strings.
+	openAIContent bool
+}
+
+// NewChatTemplate parses a chat template, special tokens are template variables such as bos_token.
+func NewChatTemplate(source string, specialTokens map[string]string) (*ChatTemplate, error) {
+	t, err := New(source)
+	if err != nil {
+		return nil, err
+	}
+	c := &ChatTemplate{
+		template:      t,
+		specialTokens: map[string]interface{}{},
+		openAIContent: openAIContentRegex.MatchString(source),
+	}
+	for k, v := range specialTokens {
+		c.specialTokens[k] = v
+	}
+	return c, nil
+}
+
+// LoadChatTemplate loads a chat template from a tokenizer_config.json, or from a raw Jinja template.
+func LoadChatTemplate(data []byte) (*ChatTemplate, error) {
+	var config struct {
+		ChatTemplate json.RawMessage `json:"chat_template"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return NewChatTemplate(string(data), nil)
+	}
+	if len(config.ChatTemplate) == 0 {
+		return nil, fmt.Errorf("chat_template not found in tokenizer config")
+	}
+
+	source, err := parseChatTemplateField(config.ChatTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	specialTokens := map[string]string{}
+	for _, name := range []string{"bos_token", "eos_token", "unk_token", "pad_token"} {
+		if token, ok := parseSpecialToken(raw[name]); ok {
+			specialTokens[name] = token
+		}
+	}
+	return NewChatTemplate(source, specialTokens)
+}
+
+// parseChatTemplateField returns the default template of the chat_template field,
+// which is either a template or a list of named templates.
+func parseChatTemplateField(field json.RawMessage) (string, error) {
+	var source string
+	if err := json.Unmarshal(field, &source); err == nil {
+		return source, nil
+	}
+	var named []struct {
+		Name     string `json:"name"`
+		Template string `json:"template"`
+	}
+	if err := json.Unmarshal(field, &named); err != nil || len(named) == 0 {
+		return "", fmt.Errorf("invalid chat_template in tokenizer config")
+	}
+	for _, t := range named {
+		if t.Name == "default" {
+			return t.Template, nil
+		}
+	}
+	return named[0].Template, nil
+}
+
+// parseSpecialToken parses a special token, which is either a string or an added token object.
+func parseSpecialToken(field json.RawMessage) (string, bool) {
+	if len(field) == 0 {
+		return "", false
+	}
+	var token string
+	if err := json.Unmarshal(field, &token); err == nil {
+		return token, true
+	}
+	var addedToken struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(field, &addedToken); err == nil && addedToken.Content != "" {
+		return addedToken.Content, true
+	}
+	return "", false
+}
+
+// RenderRequest renders the prompt of an OpenAI chat completion request body.
+// The generation prompt is added unless the request sets add_generation_prompt to false,
+// chat_template_kwargs of the request are passed to the template.
+func (c *ChatTemplate) RenderRequest(body []byte) (string, error) {
+	decoded, err := DecodeJSON(body)
+	if err != nil {
+		return "", err
+	}
+	request, ok := decoded.(*Dict)
+	if !ok {
+		return "", fmt.Errorf("request body is not a JSON object")
+	}
+	messages, ok := getItem(request, "messages").([]interface{})
+	if !ok || len(messages) == 0 {
+		return "", fmt.Errorf("no messages in the request body")
+	}
+
+	vars := map[string]interface{}{}
+	for k, v := range c.specialTokens {
+		vars[k] = v
+	}
+	if kwargs, ok := getItem(request, "chat_template_kwargs").(*Dict); ok {
+		for _, k := range kwargs.Keys() {
+			vars[k] = kwargs.values[k]
+		}
+	}
+	vars["messages"] = c.normalizeMessages(messages)
+	vars["tools"] = nil
+	if tools, ok := getItem(request, "tools").([]interface{}); ok && len(tools) > 0 {
+		vars["tools"] = tools
+	}
+	vars["documents"] = nil
+	if documents, ok := getItem(request, "documents").([]interface{}); ok && len(documents) > 0 {
+		vars["documents"] = documents
+	}
+	vars["add_generation_prompt"] = true
+	if v, ok := request.Get("add_generation_prompt"); ok {
+		vars["add_generation_prompt"] = isTrue(v)
+	}
+	return c.template.Render(vars)
+}
+
+// normalizeMessages prepares messages the same way as vLLM: contents are flattened or normalized depending on
+// the template, and tool call arguments are parsed into objects.
+func (c *ChatTemplate) normalizeMessages(messages []interface{}) []interface{} {
+	normalized := make([]interface{}, len(messages))
+	for i, m := range messages {
+		message, ok := m.(*Dict)
+		if !ok {
+			normalized[i] = m
+			continue
+		}
+		message = message.copy()
+		if parts, ok := getItem(message, "content").([]interface{}); ok {
+			if c.openAIContent {
+				message.Set("content", normalizeContentParts(parts))
+			} else {
+				message.Set("content", flattenContentParts(parts))
+			}
+		}
+		if toolCalls, ok := getItem(message, "tool_calls").([]interface{}); ok {
+			message.Set("tool_calls", parseToolCallArguments(toolCalls))
+		}
+		normalized[i] = message
+	}
+	return normalized
+}
+
+// normalizeContentParts keeps text parts and replaces multimodal parts with {"type": <modality>}.
+func normalizeContentParts(parts []interface{}) []interface{} {
+	normalized := make([]interface{}, 0, len(parts))
+	for _, p := range parts {
+		part, ok := p.(*Dict)
+		if !ok {
+			continue
+		}
+		partType := toString(getItem(part, "type"))
+		if partType == "text" {
+			normalized = append(normalized, part)
+			continue
+		}
+		if placeholder, ok := placeholders[partType]; ok {
+			modality := NewDict()
+			modality.Set("type", strings.Trim(placeholder, "<>"))
+			normalized = append(normalized, modality)
+		}
+	}
+	return normalized
+}
+
+// flattenContentParts joins text parts and placeholders of multimodal parts with newlines.
+func flattenContentParts(parts []interface{}) string {
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		part, ok := p.(*Dict)
+		if !ok {
+			continue
+		}
+		partType := toString(getItem(part, "type"))
+		if partType == "text" {
+			texts = append(texts, toString(getItem(part, "text")))
+		} else if placeholder, ok := placeholders[partType]; ok {
+			texts = append(texts, placeholder)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func parseToolCallArguments(toolCalls []interface{}) []interface{} {
+	parsed := make([]interface{}, len(toolCalls))
+	for i, tc := range toolCalls {
+		parsed[i] = tc
+		toolCall, ok := tc.(*Dict)
+		if !ok {
+			continue
+		}
+		function, ok := getItem(toolCall, "function").(*Dict)
+		if !ok {
+			continue
+		}
+		arguments, ok := getItem(function, "arguments").(string)
+		if !ok {
+			continue
+		}
+		if value, err := DecodeJSON([]byte(arguments)); err == nil {
+			function = function.copy()
+			function.Set("arguments", value)
+			toolCall = toolCall.copy()
+			toolCall.Set("function", function)
+			parsed[i] = toolCall
+		}
+	}
+	return parsed
+}
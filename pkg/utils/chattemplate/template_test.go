@@ -0,0 +1,543 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chattemplate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// qwenTemplate is the chat template of Qwen2.5 instruct models, which supports tools.
+const qwenTemplate = `{%- if tools %}
+    {{- '<|im_start|>system\n' }}
+    {%- if messages[0]['role'] == 'system' %}
+        {{- messages[0]['content'] }}
+    {%- else %}
+        {{- 'You are Qwen, created by Alibaba Cloud. You are a helpful assistant.' }}
+    {%- endif %}
+    {{- "\n\n# Tools\n\nYou may call one or more functions to assist with the user query.\n\nYou are provided with function signatures within <tools></tools> XML tags:\n<tools>" }}
+    {%- for tool in tools %}
+        {{- "\n" }}
+        {{- tool | tojson }}
+    {%- endfor %}
+    {{- "\n</tools>\n\nFor each function call, return a json object with function name and arguments within <tool_call></tool_call> XML tags:\n<tool_call>\n{\"name\": <function-name>, \"arguments\": <args-json-object>}\n</tool_call><|im_end|>\n" }}
+{%- else %}
+    {%- if messages[0]['role'] == 'system' %}
+        {{- '<|im_start|>system\n' + messages[0]['content'] + '<|im_end|>\n' }}
+    {%- else %}
+        {{- '<|im_start|>system\nYou are Qwen, created by Alibaba Cloud. You are a helpful assistant.<|im_end|>\n' }}
+    {%- endif %}
+{%- endif %}
+{%- for message in messages %}
+    {%- if (message.role == "user") or (message.role == "system" and not loop.first) or (message.role == "assistant" and not message.tool_calls) %}
+        {{- '<|im_start|>' + message.role + '\n' + message.content + '<|im_end|>' + '\n' }}
+    {%- elif message.role == "assistant" %}
+        {{- '<|im_start|>' + message.role }}
+        {%- if message.content %}
+            {{- '\n' + message.content }}
+        {%- endif %}
+        {%- for tool_call in message.tool_calls %}
+            {%- if tool_call.function is defined %}
+                {%- set tool_call = tool_call.function %}
+            {%- endif %}
+            {{- '\n<tool_call>\n{"name": "' }}
+            {{- tool_call.name }}
+            {{- '", "arguments": ' }}
+            {{- tool_call.arguments | tojson }}
+            {{- '}\n</tool_call>' }}
+        {%- endfor %}
+        {{- '<|im_end|>\n' }}
+    {%- elif message.role == "tool" %}
+        {%- if (loop.index0 == 0) or (messages[loop.index0 - 1].role != "tool") %}
+            {{- '<|im_start|>user' }}
+        {%- endif %}
+        {{- '\n<tool_response>\n' }}
+        {{- message.content }}
+        {{- '\n</tool_response>' }}
+        {%- if loop.last or (messages[loop.index0 + 1].role != "tool") %}
+            {{- '<|im_end|>\n' }}
+        {%- endif %}
+    {%- endif %}
+{%- endfor %}
+{%- if add_generation_prompt %}
+    {{- '<|im_start|>assistant\n' }}
+{%- endif %}
+`
+
+// llama31Template is the chat template of Llama 3.1 instruct models, which sets variables inside loops.
+const llama31Template = `{{- bos_token }}
+{%- if custom_tools is defined %}
+    {%- set tools = custom_tools %}
+{%- endif %}
+{%- if not tools_in_user_message is defined %}
+    {%- set tools_in_user_message = true %}
+{%- endif %}
+{%- if not date_string is defined %}
+    {%- set date_string = "26 Jul 2024" %}
+{%- endif %}
+{%- if not tools is defined %}
+    {%- set tools = none %}
+{%- endif %}
+
+{#- This block extracts the system message, so we can slot it into the right place. #}
+{%- if messages[0]['role'] == 'system' %}
+    {%- set system_message = messages[0]['content']|trim %}
+    {%- set messages = messages[1:] %}
+{%- else %}
+    {%- set system_message = "" %}
+{%- endif %}
+
+{#- System message + builtin tools #}
+{{- "<|start_header_id|>system<|end_header_id|>\n\n" }}
+{%- if builtin_tools is defined or tools is not none %}
+    {{- "Environment: ipython\n" }}
+{%- endif %}
+{%- if builtin_tools is defined %}
+    {{- "Tools: " + builtin_tools | reject('equalto', 'code_interpreter') | join(", ") + "\n\n"}}
+{%- endif %}
+{{- "Cutting Knowledge Date: December 2023\n" }}
+{{- "Today Date: " + date_string + "\n\n" }}
+{%- if tools is not none and not tools_in_user_message %}
+    {{- "You have access to the following functions. To call a function, please respond with JSON for a function call." }}
+    {{- 'Respond in the format {"name": function name, "parameters": dictionary of argument name and its value}.' }}
+    {{- "Do not use variables.\n\n" }}
+    {%- for t in tools %}
+        {{- t | tojson(indent=4) }}
+        {{- "\n\n" }}
+    {%- endfor %}
+{%- endif %}
+{{- system_message }}
+{{- "<|eot_id|>" }}
+
+{#- Custom tools are passed in a user message with some extra guidance #}
+{%- if tools_in_user_message and not tools is none %}
+    {#- Extract the first user message so we can plug it in here #}
+    {%- if messages | length != 0 %}
+        {%- set first_user_message = messages[0]['content']|trim %}
+        {%- set messages = messages[1:] %}
+    {%- else %}
+        {{- raise_exception("Cannot put tools in the first user message when there's no first user message!") }}
+{%- endif %}
+    {{- '<|start_header_id|>user<|end_header_id|>\n\n' -}}
+    {{- "Given the following functions, please respond with a JSON for a function call " }}
+    {{- "with its proper arguments that best answers the given prompt.\n\n" }}
+    {{- 'Respond in the format {"name": function name, "parameters": dictionary of argument name and its value}.' }}
+    {{- "Do not use variables.\n\n" }}
+    {%- for t in tools %}
+        {{- t | tojson(indent=4) }}
+        {{- "\n\n" }}
+    {%- endfor %}
+    {{- first_user_message + "<|eot_id|>"}}
+{%- endif %}
+
+{%- for message in messages %}
+    {%- if not (message.role == 'ipython' or message.role == 'tool' or 'tool_calls' in message) %}
+        {{- '<|start_header_id|>' + message['role'] + '<|end_header_id|>\n\n'+ message['content'] | trim + '<|eot_id|>' }}
+    {%- elif 'tool_calls' in message %}
+        {%- if not message.tool_calls|length == 1 %}
+            {{- raise_exception("This model only supports single tool-calls at once!") }}
+        {%- endif %}
+        {%- set tool_call = message.tool_calls[0].function %}
+        {%- if builtin_tools is defined and tool_call.name in builtin_tools %}
+            {{- '<|start_header_id|>assistant<|end_header_id|>\n\n' -}}
+            {{- "<|python_tag|>" + tool_call.name + ".call(" }}
+            {%- for arg_name, arg_val in tool_call.arguments | items %}
+                {{- arg_name + '="' + arg_val + '"' }}
+                {%- if not loop.last %}
+                    {{- ", " }}
+                {%- endif %}
+                {%- endfor %}
+            {{- ")" }}
+        {%- else  %}
+            {{- '<|start_header_id|>assistant<|end_header_id|>\n\n' -}}
+            {{- '{"name": "' + tool_call.name + '", ' }}
+            {{- '"parameters": ' }}
+            {{- tool_call.arguments | tojson }}
+            {{- "}" }}
+        {%- endif %}
+        {%- if builtin_tools is defined %}
+            {#- This means we're in ipython mode #}
+            {{- "<|eom_id|>" }}
+        {%- else %}
+            {{- "<|eot_id|>" }}
+        {%- endif %}
+    {%- elif message.role == "tool" or message.role == "ipython" %}
+        {{- "<|start_header_id|>ipython<|end_header_id|>\n\n" }}
+        {%- if message.content is mapping or message.content is iterable %}
+            {{- message.content | tojson }}
+        {%- else %}
+            {{- message.content }}
+        {%- endif %}
+        {{- "<|eot_id|>" }}
+    {%- endif %}
+{%- endfor %}
+{%- if add_generation_prompt %}
+    {{- '<|start_header_id|>assistant<|end_header_id|>\n\n' }}
+{%- endif %}
+`
+
+// mistralTemplate is the chat template of Mistral 7B instruct v0.3, which counts messages with namespace().
+const mistralTemplate = `{%- if messages[0]["role"] == "system" %}
+    {%- set system_message = messages[0]["content"] %}
+    {%- set loop_messages = messages[1:] %}
+{%- else %}
+    {%- set loop_messages = messages %}
+{%- endif %}
+{%- if not tools is defined %}
+    {%- set tools = none %}
+{%- endif %}
+{%- set user_messages = loop_messages | selectattr("role", "equalto", "user") | list %}
+
+{#- This block checks for alternating user/assistant messages, skipping tool calling messages #}
+{%- set ns = namespace() %}
+{%- set ns.index = 0 %}
+{%- for message in loop_messages %}
+    {%- if not (message.role == "tool" or message.role == "tool_results" or (message.tool_calls is defined and message.tool_calls is not none)) %}
+        {%- if (message["role"] == "user") != (ns.index % 2 == 0) %}
+            {{- raise_exception("After the optional system message, conversation roles must alternate user/assistant/user/assistant/...") }}
+        {%- endif %}
+        {%- set ns.index = ns.index + 1 %}
+    {%- endif %}
+{%- endfor %}
+
+{{- bos_token }}
+{%- for message in loop_messages %}
+    {%- if message["role"] == "user" %}
+        {%- if tools is not none and (message == user_messages[-1]) %}
+            {{- "[AVAILABLE_TOOLS] [" }}
+            {%- for tool in tools %}
+                {%- set tool = tool.function %}
+                {{- '{"type": "function", "function": {' }}
+                {%- for key, val in tool.items() if key != "return" %}
+                    {%- if val is string %}
+                        {{- '"' + key + '": "' + val + '"' }}
+                    {%- else %}
+                        {{- '"' + key + '": ' + val|tojson }}
+                    {%- endif %}
+                    {%- if not loop.last %}
+                        {{- ", " }}
+                    {%- endif %}
+                {%- endfor %}
+                {{- "}}" }}
+                {%- if not loop.last %}
+                    {{- ", " }}
+                {%- else %}
+                    {{- "]" }}
+                {%- endif %}
+            {%- endfor %}
+            {{- "[/AVAILABLE_TOOLS]" }}
+            {%- endif %}
+        {%- if loop.last and system_message is defined %}
+            {{- "[INST] " + system_message + "\n\n" + message["content"] + "[/INST]" }}
+        {%- else %}
+            {{- "[INST] " + message["content"] + "[/INST]" }}
+        {%- endif %}
+    {%- elif message.tool_calls is defined and message.tool_calls is not none %}
+        {{- "[TOOL_CALLS] [" }}
+        {%- for tool_call in message.tool_calls %}
+            {%- set out = tool_call.function|tojson %}
+            {{- out[:-1] }}
+            {%- if not tool_call.id is defined or tool_call.id|length != 9 %}
+                {{- raise_exception("Tool call IDs should be alphanumeric strings with length 9!") }}
+            {%- endif %}
+            {{- ', "id": "' + tool_call.id + '"}' }}
+            {%- if not loop.last %}
+                {{- ", " }}
+            {%- else %}
+                {{- "]" + eos_token }}
+            {%- endif %}
+        {%- endfor %}
+    {%- elif message["role"] == "assistant" %}
+        {{- " " + message["content"]|trim + eos_token}}
+    {%- elif message["role"] == "tool_results" or message["role"] == "tool" %}
+        {%- if message.content is defined and message.content.content is defined %}
+            {%- set content = message.content.content %}
+        {%- else %}
+            {%- set content = message.content %}
+        {%- endif %}
+        {{- '[TOOL_RESULTS] {"content": ' + content|string + ", " }}
+        {%- if not message.tool_call_id is defined or message.tool_call_id|length != 9 %}
+            {{- raise_exception("Tool call IDs should be alphanumeric strings with length 9!") }}
+        {%- endif %}
+        {{- '"call_id": "' + message.tool_call_id + '"}[/TOOL_RESULTS]' }}
+    {%- else %}
+        {{- raise_exception("Only user and assistant roles are supported, with the exception of an initial optional system message!") }}
+    {%- endif %}
+{%- endfor %}
+`
+
+// mistralSmallTemplate is the chat template of Mistral Small 3, which dates its default system prompt with strftime_now.
+const mistralSmallTemplate = `{%- set today = strftime_now("%Y-%m-%d") %}
+{%- set default_system_message = "You are Mistral Small 3, a Large Language Model (LLM) created by Mistral AI, a French startup headquartered in Paris.\nYour knowledge base was last updated on 2023-10-01. The current date is " + today + ".\n\nWhen you're not sure about some information, you say that you don't have the information and don't make up anything.\nIf the user's question is not clear, ambiguous, or does not provide enough context for you to accurately answer the question, you do not try to answer it right away and you rather ask the user to clarify their request (e.g. \"What are some good restaurants around me?\" => \"Where are you?\" or \"When is the next flight to Tokyo\" => \"Where do you travel from?\")" %}
+
+{{- bos_token }}
+
+{%- if messages[0]['role'] == 'system' %}
+    {%- set system_message = messages[0]['content'] %}
+    {%- set loop_messages = messages[1:] %}
+{%- else %}
+    {%- set system_message = default_system_message %}
+    {%- set loop_messages = messages %}
+{%- endif %}
+{{- '[SYSTEM_PROMPT]' + system_message + '[/SYSTEM_PROMPT]' }}
+
+{%- for message in loop_messages %}
+    {%- if message['role'] == 'user' %}
+        {{- '[INST]' + message['content'] + '[/INST]' }}
+    {%- elif message['role'] == 'system' %}
+        {{- '[SYSTEM_PROMPT]' + message['content'] + '[/SYSTEM_PROMPT]' }}
+    {%- elif message['role'] == 'assistant' %}
+        {{- message['content'] + eos_token }}
+    {%- else %}
+        {{- raise_exception('Only user, system and assistant roles are supported!') }}
+    {%- endif %}
+{%- endfor %}`
+
+// llama2Template relies on trim_blocks and lstrip_blocks instead of whitespace control.
+const llama2Template = `{% for message in messages %}
+  {% if message['role'] == 'user' %}
+{{ bos_token + '[INST] ' + message['content'] | trim + ' [/INST]' }}
+  {% elif message['role'] == 'assistant' %}
+{{ message['content'] + eos_token }}
+  {% else %}
+{{ raise_exception('Only user and assistant roles are supported!') }}
+  {% endif %}
+{% endfor %}`
+
+// visionTemplate renders content parts, similar to Qwen2-VL.
+const visionTemplate = `{% for message in messages %}<|im_start|>{{ message['role'] }}
+{% if message['content'] is string %}{{ message['content'] }}{% else %}{% for content in message['content'] %}{% if content['type'] == 'image' %}<|vision_start|><|image_pad|><|vision_end|>{% elif content['type'] == 'text' %}{{ content['text'] }}{% endif %}{% endfor %}{% endif %}<|im_end|>
+{% endfor %}{% if add_generation_prompt %}<|im_start|>assistant
+{% endif %}`
+
+func TestChatTemplateRenderRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		request  string
+		expected string
+	}{
+		{
+			name:     "chatml",
+			template: qwenTemplate,
+			request:  `{"model": "qwen", "messages": [{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}]}`,
+			expected: "<|im_start|>system\nBe brief.<|im_end|>\n<|im_start|>user\nHi<|im_end|>\n<|im_start|>assistant\n",
+		},
+		{
+			name:     "without generation prompt",
+			template: qwenTemplate,
+			request:  `{"messages": [{"role": "user", "content": "Hi"}], "add_generation_prompt": false}`,
+			expected: "<|im_start|>system\nYou are Qwen, created by Alibaba Cloud. You are a helpful assistant.<|im_end|>\n<|im_start|>user\nHi<|im_end|>\n",
+		},
+		{
+			name:     "tools and tool calls",
+			template: qwenTemplate,
+			request: `{"messages": [
+				{"role": "user", "content": "Weather in Paris?"},
+				{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\": \"Paris\"}"}}]},
+				{"role": "tool", "tool_call_id": "call_1", "content": "20°C"}],
+				"tools": [{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object", "properties": {"city": {"type": "string"}}}}}]}`,
+			expected: "<|im_start|>system\nYou are Qwen, created by Alibaba Cloud. You are a helpful assistant.\n\n# Tools\n\n" +
+				"You may call one or more functions to assist with the user query.\n\n" +
+				"You are provided with function signatures within <tools></tools> XML tags:\n<tools>\n" +
+				`{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object", "properties": {"city": {"type": "string"}}}}}` +
+				"\n</tools>\n\nFor each function call, return a json object with function name and arguments within <tool_call></tool_call> XML tags:\n" +
+				"<tool_call>\n{\"name\": <function-name>, \"arguments\": <args-json-object>}\n</tool_call><|im_end|>\n" +
+				"<|im_start|>user\nWeather in Paris?<|im_end|>\n" +
+				"<|im_start|>assistant\n<tool_call>\n{\"name\": \"get_weather\", \"arguments\": {\"city\": \"Paris\"}}\n</tool_call><|im_end|>\n" +
+				"<|im_start|>user\n<tool_response>\n20°C\n</tool_response><|im_end|>\n<|im_start|>assistant\n",
+		},
+		{
+			name:     "trim and lstrip blocks",
+			template: llama2Template,
+			request:  `{"messages": [{"role": "user", "content": " Hi "}, {"role": "assistant", "content": "Hello"}, {"role": "user", "content": "Bye"}]}`,
+			expected: "<s>[INST] Hi [/INST]\nHello</s>\n<s>[INST] Bye [/INST]\n",
+		},
+		{
+			name:     "llama 3.1",
+			template: llama31Template,
+			request:  `{"messages": [{"role": "system", "content": "Be brief."}, {"role": "user", "content": " Hi "}, {"role": "assistant", "content": "Hello"}, {"role": "user", "content": "Bye"}]}`,
+			expected: "<s><|start_header_id|>system<|end_header_id|>\n\nCutting Knowledge Date: December 2023\nToday Date: 26 Jul 2024\n\nBe brief.<|eot_id|>" +
+				"<|start_header_id|>user<|end_header_id|>\n\nHi<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\nHello<|eot_id|>" +
+				"<|start_header_id|>user<|end_header_id|>\n\nBye<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n",
+		},
+		{
+			name:     "llama 3.1 tools and tool calls",
+			template: llama31Template,
+			request: `{"messages": [
+				{"role": "user", "content": "Weather in Paris?"},
+				{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\": \"Paris\"}"}}]},
+				{"role": "tool", "tool_call_id": "call_1", "content": "20°C"}],
+				"tools": [{"type": "function", "function": {"name": "get_weather"}}]}`,
+			expected: "<s><|start_header_id|>system<|end_header_id|>\n\nEnvironment: ipython\nCutting Knowledge Date: December 2023\nToday Date: 26 Jul 2024\n\n<|eot_id|>" +
+				"<|start_header_id|>user<|end_header_id|>\n\nGiven the following functions, please respond with a JSON for a function call " +
+				"with its proper arguments that best answers the given prompt.\n\n" +
+				`Respond in the format {"name": function name, "parameters": dictionary of argument name and its value}.Do not use variables.` + "\n\n" +
+				"{\n    \"type\": \"function\",\n    \"function\": {\n        \"name\": \"get_weather\"\n    }\n}\n\nWeather in Paris?<|eot_id|>" +
+				"<|start_header_id|>assistant<|end_header_id|>\n\n" + `{"name": "get_weather", "parameters": {"city": "Paris"}}<|eot_id|>` +
+				"<|start_header_id|>ipython<|end_header_id|>\n\n\"20°C\"<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n",
+		},
+		{
+			name:     "mistral",
+			template: mistralTemplate,
+			request:  `{"messages": [{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}, {"role": "assistant", "content": " Hello "}, {"role": "user", "content": "Bye"}]}`,
+			expected: "<s>[INST] Hi[/INST] Hello</s>[INST] Be brief.\n\nBye[/INST]",
+		},
+		{
+			name:     "mistral tools and tool calls",
+			template: mistralTemplate,
+			request: `{"messages": [
+				{"role": "user", "content": "Weather in Paris?"},
+				{"role": "assistant", "content": null, "tool_calls": [{"id": "call00001", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\": \"Paris\"}"}}]},
+				{"role": "tool", "tool_call_id": "call00001", "content": "20°C"}],
+				"tools": [{"type": "function", "function": {"name": "get_weather", "description": "Get the weather", "parameters": {"type": "object"}}}]}`,
+			expected: `<s>[AVAILABLE_TOOLS] [{"type": "function", "function": {"name": "get_weather", "description": "Get the weather", "parameters": {"type": "object"}}}][/AVAILABLE_TOOLS]` +
+				`[INST] Weather in Paris?[/INST][TOOL_CALLS] [{"name": "get_weather", "arguments": {"city": "Paris"}, "id": "call00001"}]</s>` +
+				`[TOOL_RESULTS] {"content": 20°C, "call_id": "call00001"}[/TOOL_RESULTS]`,
+		},
+		{
+			name:     "mistral small default system prompt",
+			template: mistralSmallTemplate,
+			request:  `{"messages": [{"role": "user", "content": "Hi"}]}`,
+			expected: "<s>[SYSTEM_PROMPT]You are Mistral Small 3, a Large Language Model (LLM) created by Mistral AI, a French startup headquartered in Paris.\n" +
+				"Your knowledge base was last updated on 2023-10-01. The current date is 2025-03-04.\n\n" +
+				"When you're not sure about some information, you say that you don't have the information and don't make up anything.\n" +
+				"If the user's question is not clear, ambiguous, or does not provide enough context for you to accurately answer the question, " +
+				"you do not try to answer it right away and you rather ask the user to clarify their request " +
+				`(e.g. "What are some good restaurants around me?" => "Where are you?" or "When is the next flight to Tokyo" => "Where do you travel from?")` +
+				"[/SYSTEM_PROMPT][INST]Hi[/INST]",
+		},
+		{
+			name:     "flattened multimodal content",
+			template: qwenTemplate,
+			request:  `{"messages": [{"role": "user", "content": [{"type": "text", "text": "What is this?"}, {"type": "image_url", "image_url": {"url": "https://example.com/a.png"}}]}]}`,
+			expected: "<|im_start|>system\nYou are Qwen, created by Alibaba Cloud. You are a helpful assistant.<|im_end|>\n<|im_start|>user\nWhat is this?\n<image><|im_end|>\n<|im_start|>assistant\n",
+		},
+		{
+			name:     "multimodal content parts",
+			template: visionTemplate,
+			request:  `{"messages": [{"role": "user", "content": [{"type": "text", "text": "What is this?"}, {"type": "image_url", "image_url": {"url": "https://example.com/a.png"}}]}]}`,
+			expected: "<|im_start|>user\nWhat is this?<|vision_start|><|image_pad|><|vision_end|><|im_end|>\n<|im_start|>assistant\n",
+		},
+	}
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC) }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewChatTemplate(tt.template, map[string]string{"bos_token": "<s>", "eos_token": "</s>"})
+			assert.NoError(t, err)
+			prompt, err := c.RenderRequest([]byte(tt.request))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, prompt)
+		})
+	}
+}
+
+func TestChatTemplateRaiseException(t *testing.T) {
+	c, err := NewChatTemplate(llama2Template, nil)
+	assert.NoError(t, err)
+	_, err = c.RenderRequest([]byte(`{"messages": [{"role": "system", "content": "Hi"}]}`))
+	assert.EqualError(t, err, "Only user and assistant roles are supported!")
+
+	c, err = NewChatTemplate(mistralTemplate, nil)
+	assert.NoError(t, err)
+	_, err = c.RenderRequest([]byte(`{"messages": [{"role": "user", "content": "Hi"}, {"role": "user", "content": "Bye"}]}`))
+	var templateErr *TemplateError
+	assert.ErrorAs(t, err, &templateErr)
+}
+
+func TestChatTemplateUnsupported(t *testing.T) {
+	for _, source := range []string{
+		`{{ messages[0].content | wordwrap(10) }}`,
+		`{% if messages is unknowntest %}{% endif %}`,
+		`{{ messages[0].content.swapcase() }}`,
+	} {
+		c, err := NewChatTemplate(source, nil)
+		assert.NoError(t, err, source)
+		_, err = c.RenderRequest([]byte(`{"messages": [{"role": "user", "content": "Hi"}]}`))
+		assert.ErrorIs(t, err, ErrUnsupported, source)
+	}
+}
+
+func TestLoadChatTemplate(t *testing.T) {
+	config := `{
+		"bos_token": {"content": "<s>", "special": true},
+		"eos_token": "</s>",
+		"chat_template": [{"name": "tool_use", "template": "tools"}, {"name": "default", "template": "{{ bos_token }}{{ messages[0].content }}{{ eos_token }}"}]
+	}`
+	c, err := LoadChatTemplate([]byte(config))
+	assert.NoError(t, err)
+	prompt, err := c.RenderRequest([]byte(`{"messages": [{"role": "user", "content": "Hi"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "<s>Hi</s>", prompt)
+
+	// Raw templates are loaded as is.
+	c, err = LoadChatTemplate([]byte("{{ messages | length }}"))
+	assert.NoError(t, err)
+	prompt, err = c.RenderRequest([]byte(`{"messages": [{"role": "user", "content": "Hi"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", prompt)
+}
+
+func TestTemplateRender(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`{{ 1 + 2 * 3 }} {{ 7 // 2 }} {{ 7 / 2 }} {{ -7 % 3 }}`, "7 3 3.5 2"},
+		{`{{ "a" ~ 1 ~ none }} {{ [1, "b"] }} {{ {"k": true} }}`, "a1None [1, 'b'] {'k': True}"},
+		{`{{ "Hello"[1:3] }} {{ [1, 2, 3][::-1] }} {{ "abc"[-1] }}`, "el [3, 2, 1] c"},
+		{`{% set ns = namespace(n=0) %}{% for i in range(3) %}{% set ns.n = ns.n + i %}{% endfor %}{{ ns.n }}`, "3"},
+		{`{% set x = 1 %}{% for i in [1] %}{% set x = 2 %}{% endfor %}{{ x }}`, "1"},
+		{`{% for k, v in {"a": 1, "b": 2}.items() %}{{ k }}={{ v }}{% if not loop.last %},{% endif %}{% endfor %}`, "a=1,b=2"},
+		{`{% for i in range(5) if i is odd %}{{ i }}{% else %}none{% endfor %}`, "13"},
+		{`{% for i in range(5) %}{% if i == 3 %}{% break %}{% endif %}{% if i == 1 %}{% continue %}{% endif %}{{ i }}{% endfor %}`, "02"},
+		{`{% macro greet(name, punct="!") %}Hi {{ name }}{{ punct }}{% endmacro %}{{ greet("A") }} {{ greet("B", punct="?") }}`, "Hi A! Hi B?"},
+		{`{{ undefined_var is defined }} {{ undefined_var | default("d") }} {{ "" | default("e", true) }}`, "False d e"},
+		{`{{ " x " | trim | upper }} {{ ["a", "b"] | join(", ") }} {{ [3, 1, 2] | sort | first }}`, "X a, b 1"},
+		{`{{ "a,b".split(",") }} {{ "Hi".startswith("H") }} {{ "x" in "xyz" }} {{ 2 not in [1] }}`, "['a', 'b'] True True True"},
+		{`{{ 'yes' if 1 > 2 else 'no' }} {{ 1.0 }} {{ 1e-05 }}`, "no 1.0 1e-05"},
+		{`{{ [{"a": 1}, {"a": 2}, {}] | selectattr("a", "defined") | map(attribute="a") | list }}`, "[1, 2]"},
+		{`{{ {"b": [1, 2], "a": "é\n"} | tojson }} {{ {"a": [1]} | tojson(indent=2) }}`, "{\"b\": [1, 2], \"a\": \"é\\n\"} {\n  \"a\": [\n    1\n  ]\n}"},
+		{"{#- comment -#}\n  a  {{- ' b ' -}}  c", "a b c"},
+	}
+	for _, tt := range tests {
+		tmpl, err := New(tt.template)
+		assert.NoError(t, err, tt.template)
+		if err != nil {
+			continue
+		}
+		out, err := tmpl.Render(nil)
+		assert.NoError(t, err, tt.template)
+		assert.Equal(t, tt.expected, out, tt.template)
+	}
+}
+
+func TestTemplateInvalid(t *testing.T) {
+	for _, source := range []string{
+		`{% if x %}`,
+		`{{ x `,
+		`{% for x in %}{% endfor %}`,
+		`{% endif %}`,
+		`{{ 'unterminated }}`,
+	} {
+		_, err := New(source)
+		assert.Error(t, err, source)
+	}
+}
@@ -0,0 +1,565 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chattemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Template values are nil (None), undefined, bool, int, float64, string, []interface{}, *Dict and callable.
+
+// undefined is the value of missing variables, attributes and items.
+type undefined struct {
+	name string
+}
+
+// callable is a function or a macro of the template.
+type callable func(args []interface{}, kwargs *Dict) (interface{}, error)
+
+// Dict is a dict which keeps the insertion order of keys, same as python.
+type Dict struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func NewDict() *Dict {
+	return &Dict{values: map[string]interface{}{}}
+}
+
+func (d *Dict) Get(key string) (interface{}, bool) {
+	if d == nil {
+		return nil, false
+	}
+	v, ok := d.values[key]
+	return v, ok
+}
+
+func (d *Dict) Set(key string, value interface{}) {
+	if _, ok := d.values[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+	d.values[key] = value
+}
+
+func (d *Dict) Delete(key string) {
+	if _, ok := d.values[key]; !ok {
+		return
+	}
+	delete(d.values, key)
+	for i, k := range d.keys {
+		if k == key {
+			d.keys = append(d.keys[:i:i], d.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (d *Dict) Keys() []string {
+	if d == nil {
+		return nil
+	}
+	return d.keys
+}
+
+func (d *Dict) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.keys)
+}
+
+// copy returns a shallow copy of the dict.
+func (d *Dict) copy() *Dict {
+	c := NewDict()
+	for _, k := range d.Keys() {
+		c.Set(k, d.values[k])
+	}
+	return c
+}
+
+// DecodeJSON decodes JSON into template values, objects are decoded to *Dict to keep the key order.
+func DecodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	return value, nil
+}
+
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := t.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			d := NewDict()
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeJSONValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				d.Set(key.(string), value)
+			}
+			_, err := decoder.Token()
+			return d, err
+		case '[':
+			list := []interface{}{}
+			for decoder.More() {
+				value, err := decodeJSONValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			_, err := decoder.Token()
+			return list, err
+		}
+		return nil, fmt.Errorf("invalid JSON delimiter %v", v)
+	case json.Number:
+		if n, err := strconv.Atoi(v.String()); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	default:
+		// string, bool or nil
+		return v, nil
+	}
+}
+
+func isTrue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil, undefined:
+		return false
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case *Dict:
+		return v.Len() > 0
+	default:
+		return true
+	}
+}
+
+func isUndefined(v interface{}) bool {
+	_, ok := v.(undefined)
+	return ok
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// toString returns the str() of a value, undefined values are rendered as empty strings.
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case undefined:
+		return ""
+	case string:
+		return v
+	default:
+		return repr(v)
+	}
+}
+
+// repr returns the python repr() of a value.
+func repr(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "None"
+	case undefined:
+		return ""
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return formatFloat(v)
+	case string:
+		return quoteString(v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = repr(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *Dict:
+		items := make([]string, 0, v.Len())
+		for _, k := range v.Keys() {
+			items = append(items, quoteString(k)+": "+repr(v.values[k]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	case callable:
+		return "<function>"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatFloat formats a float the same as python.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	abs := math.Abs(f)
+	if abs != 0 && (abs < 1e-4 || abs >= 1e16) {
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+		mantissa, exponent, _ := strings.Cut(s, "e")
+		sign := exponent[0]
+		exponent = strings.TrimLeft(exponent[1:], "0")
+		if len(exponent) < 2 {
+			exponent = strings.Repeat("0", 2-len(exponent)) + exponent
+		}
+		return mantissa + "e" + string(sign) + exponent
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// quoteString returns the python repr() of a string.
+func quoteString(s string) string {
+	quote := byte('\'')
+	if strings.Contains(s, "'") && !strings.Contains(s, "\"") {
+		quote = '"'
+	}
+	var b strings.Builder
+	b.WriteByte(quote)
+	for _, r := range s {
+		switch {
+		case r == rune(quote) || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte(quote)
+	return b.String()
+}
+
+// toJSON serializes a value the same as the tojson filter of HuggingFace chat templates,
+// which is json.dumps with ensure_ascii=False.
+func toJSON(v interface{}, indent string, sortKeys bool) (string, error) {
+	var b strings.Builder
+	if err := writeJSON(&b, v, indent, "", sortKeys); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func writeJSON(b *strings.Builder, v interface{}, indent, prefix string, sortKeys bool) error {
+	itemSeparator, newline, childPrefix := ", ", "", prefix
+	if indent != "" {
+		itemSeparator, newline, childPrefix = ",", "\n", prefix+indent
+	}
+	switch v := v.(type) {
+	case nil, undefined:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int:
+		b.WriteString(strconv.Itoa(v))
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			b.WriteString("Infinity")
+		case math.IsInf(v, -1):
+			b.WriteString("-Infinity")
+		case math.IsNaN(v):
+			b.WriteString("NaN")
+		default:
+			b.WriteString(formatFloat(v))
+		}
+	case string:
+		writeJSONString(b, v)
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteString(itemSeparator)
+			}
+			b.WriteString(newline + childPrefix)
+			if err := writeJSON(b, item, indent, childPrefix, sortKeys); err != nil {
+				return err
+			}
+		}
+		b.WriteString(newline + prefix + "]")
+	case *Dict:
+		if v.Len() == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		keys := v.Keys()
+		if sortKeys {
+			keys = append([]string(nil), keys...)
+			sort.Strings(keys)
+		}
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString(itemSeparator)
+			}
+			b.WriteString(newline + childPrefix)
+			writeJSONString(b, k)
+			b.WriteString(": ")
+			if err := writeJSON(b, v.values[k], indent, childPrefix, sortKeys); err != nil {
+				return err
+			}
+		}
+		b.WriteString(newline + prefix + "}")
+	default:
+		return fmt.Errorf("object of type %T is not JSON serializable", v)
+	}
+	return nil
+}
+
+func writeJSONString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case utf8.RuneError:
+			b.WriteString(`�`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+// equal compares two values with python semantics.
+func equal(a, b interface{}) bool {
+	if fa, ok := toNumber(a); ok {
+		if fb, ok := toNumber(b); ok {
+			return fa == fb
+		}
+		return false
+	}
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case undefined:
+		return isUndefined(b)
+	case string:
+		s, ok := b.(string)
+		return ok && a == s
+	case []interface{}:
+		l, ok := b.([]interface{})
+		if !ok || len(a) != len(l) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	case *Dict:
+		d, ok := b.(*Dict)
+		if !ok || a.Len() != d.Len() {
+			return false
+		}
+		for _, k := range a.Keys() {
+			v, ok := d.Get(k)
+			if !ok || !equal(a.values[k], v) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// toNumber converts bool, int and float64 to float64, bool is a number in python.
+func toNumber(v interface{}) (float64, bool) {
+	switch v.(type) {
+	case bool, int, float64:
+		return toFloat(v)
+	}
+	return 0, false
+}
+
+func compare(a, b interface{}) (int, error) {
+	if fa, ok := toNumber(a); ok {
+		if fb, ok := toNumber(b); ok {
+			switch {
+			case fa < fb:
+				return -1, nil
+			case fa > fb:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if sa, ok := a.(string); ok {
+		if sb, ok := b.(string); ok {
+			return strings.Compare(sa, sb), nil
+		}
+	}
+	return 0, fmt.Errorf("can't compare %s and %s", typeName(a), typeName(b))
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "NoneType"
+	case undefined:
+		return "Undefined"
+	case bool:
+		return "bool"
+	case int:
+		return "int"
+	case float64:
+		return "float"
+	case string:
+		return "str"
+	case []interface{}:
+		return "list"
+	case *Dict:
+		return "dict"
+	case callable:
+		return "function"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// iterate returns the items of an iterable, dicts are iterated by keys.
+func iterate(v interface{}) ([]interface{}, error) {
+	switch v := v.(type) {
+	case nil, undefined:
+		return nil, nil
+	case []interface{}:
+		return v, nil
+	case *Dict:
+		items := make([]interface{}, 0, v.Len())
+		for _, k := range v.Keys() {
+			items = append(items, k)
+		}
+		return items, nil
+	case string:
+		items := make([]interface{}, 0, len(v))
+		for _, r := range v {
+			items = append(items, string(r))
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("%s is not iterable", typeName(v))
+}
+
+func length(v interface{}) (int, error) {
+	switch v := v.(type) {
+	case string:
+		return utf8.RuneCountInString(v), nil
+	case []interface{}:
+		return len(v), nil
+	case *Dict:
+		return v.Len(), nil
+	case undefined:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("object of type %s has no len()", typeName(v))
+}
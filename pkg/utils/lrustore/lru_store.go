@@ -37,6 +37,9 @@ type LRUStore[K comparable, V any] struct {
 	getCurrentTime
 	interval time.Duration
 	ttl      time.Duration
+
+	stopCh    chan struct{}
+	closeOnce sync.Once
 }
 
 func NewLRUStore[K comparable, V any](cap int, ttl, interval time.Duration, f getCurrentTime) *LRUStore[K, V] {
@@ -47,6 +50,7 @@ func NewLRUStore[K comparable, V any](cap int, ttl, interval time.Duration, f ge
 		ttl:            ttl,
 		interval:       interval,
 		getCurrentTime: f,
+		stopCh:         make(chan struct{}),
 	}
 	store.lruList.head.next = store.lruList.tail
 	store.lruList.tail.prev = store.lruList.head
@@ -58,11 +62,23 @@ func NewLRUStore[K comparable, V any](cap int, ttl, interval time.Duration, f ge
 func (e *LRUStore[K, V]) startEviction() {
 	ticker := time.NewTicker(e.interval)
 	defer ticker.Stop()
-	for range ticker.C {
-		e.evict(e.getCurrentTime())
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+			e.evict(e.getCurrentTime())
+		}
 	}
 }
 
+// Close stops the eviction of the store, entries are no longer expired afterwards.
+func (e *LRUStore[K, V]) Close() {
+	e.closeOnce.Do(func() {
+		close(e.stopCh)
+	})
+}
+
 func (e *LRUStore[K, V]) Put(key K, value V) bool {
 	e.Lock()
 	defer e.Unlock()
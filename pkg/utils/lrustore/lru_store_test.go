@@ -103,3 +103,17 @@ func TestLRUStore_ConcurrentEvictions(t *testing.T) {
 		}
 	}
 }
+
+func TestLRUStore_Close(t *testing.T) {
+	store := NewLRUStore[string, string](2, time.Millisecond, time.Millisecond, DefaultGetCurrentTime)
+	store.Close()
+	store.Close()
+	time.Sleep(10 * time.Millisecond)
+
+	// Entries are no longer evicted once the store is closed.
+	store.Put("key1", "value1")
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := store.Get("key1"); !ok {
+		t.Errorf("expected key1 to be kept after close")
+	}
+}
@@ -19,4 +19,6 @@ type Store[K comparable, V any] interface {
 	Put(key K, value V) bool
 	Get(key K) (V, bool)
 	Len() int
+	// Close releases the resources of the store, e.g. stops its eviction.
+	Close()
 }
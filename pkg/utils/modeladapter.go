@@ -21,9 +21,13 @@ import (
 	"strings"
 )
 
-var AllowedSchemas = []string{"s3://", "gcs://", "huggingface://", "hf://", "/"}
+var AllowedSchemas = []string{"s3://", "gcs://", "huggingface://", "hf://", "http://", "https://", "/"}
 
-// ValidateArtifactURL checks if the ArtifactURL has a valid schema (s3://, gcs://, huggingface://, https://, /)
+// DownloadedSchemas are the schemas of the artifacts downloaded to the pods before they are loaded, the other artifacts
+// are passed to the inference engines as is.
+var DownloadedSchemas = []string{"s3://", "gcs://", "http://", "https://"}
+
+// ValidateArtifactURL checks if the ArtifactURL has a valid schema (s3://, gcs://, huggingface://, http(s)://, /)
 func ValidateArtifactURL(artifactURL string) error {
 	for _, schema := range AllowedSchemas {
 		if strings.HasPrefix(artifactURL, schema) {
@@ -32,3 +36,13 @@ func ValidateArtifactURL(artifactURL string) error {
 	}
 	return fmt.Errorf("unsupported schema")
 }
+
+// IsDownloadedArtifact returns whether the artifact is downloaded to the pods before it is loaded.
+func IsDownloadedArtifact(artifactURL string) bool {
+	for _, schema := range DownloadedSchemas {
+		if strings.HasPrefix(artifactURL, schema) {
+			return true
+		}
+	}
+	return false
+}
@@ -42,9 +42,23 @@ func TestValidateArtifactURL(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
-	// Case 4: Invalid scheme
+	// Case 4: Valid https URL
+	t.Run("valid https URL", func(t *testing.T) {
+		err := ValidateArtifactURL("https://example.com/lora.tar.gz")
+		assert.NoError(t, err)
+	})
+
+	// Case 5: Invalid scheme
 	t.Run("invalid scheme", func(t *testing.T) {
 		err := ValidateArtifactURL("ftp://bucket/path")
 		assert.EqualError(t, err, "unsupported schema")
 	})
 }
+
+func TestIsDownloadedArtifact(t *testing.T) {
+	assert.True(t, IsDownloadedArtifact("s3://bucket/path"))
+	assert.True(t, IsDownloadedArtifact("gcs://bucket/path"))
+	assert.True(t, IsDownloadedArtifact("https://example.com/lora.tar.gz"))
+	assert.False(t, IsDownloadedArtifact("huggingface://path/to/model"))
+	assert.False(t, IsDownloadedArtifact("/models/lora"))
+}
@@ -222,6 +222,18 @@ func FilterPods(pods []v1.Pod, filterFn filterPod) []v1.Pod {
 	return filtered
 }
 
+// ExcludePods returns the pods whose namespace/name key, see GeneratePodKey, is not in the excluded set.
+func ExcludePods(pods []*v1.Pod, excluded map[string]struct{}) []*v1.Pod {
+	filtered := make([]*v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if _, ok := excluded[GeneratePodKey(pod.Namespace, pod.Name)]; ok {
+			continue
+		}
+		filtered = append(filtered, pod)
+	}
+	return filtered
+}
+
 func FilterPodByName(podname string, pods []*v1.Pod) (*v1.Pod, bool) {
 	for _, pod := range pods {
 		if pod.Name == podname {
@@ -139,3 +139,18 @@ func TestModePortForPod(t *testing.T) {
 		assert.Equal(t, tt.expectedPort, GetModelPortForPod("1", tt.pod), tt.message)
 	}
 }
+
+func TestExcludePods(t *testing.T) {
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "other"}},
+	}
+
+	filtered := ExcludePods(pods, map[string]struct{}{"default/p1": {}})
+	assert.Equal(t, 2, len(filtered))
+	assert.Equal(t, "p2", filtered[0].Name)
+	assert.Equal(t, "other", filtered[1].Namespace)
+
+	assert.Equal(t, 3, len(ExcludePods(pods, map[string]struct{}{})))
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package prefixcacheindexer
 
 import (
+	"encoding/binary"
 	"math/rand"
 	"sync"
 	"time"
@@ -69,9 +70,14 @@ func NewPrefixHashTable() *PrefixHashTable {
 	return instance
 }
 
+// Close stops the eviction of the prefix blocks.
+func (c *PrefixHashTable) Close() {
+	c.store.Close()
+}
+
 // MatchPrefix matches the input token prefix's if already cached
 // returns map[podname]%prefixmatch along with all prefix hashes
-func (c *PrefixHashTable) MatchPrefix(tokens []byte, model string, readyPods map[string]struct{}) (map[string]int, []uint64) {
+func (c *PrefixHashTable) MatchPrefix(tokens []int, model string, readyPods map[string]struct{}) (map[string]int, []uint64) {
 	prefixHashes := getPrefixHashes(c.seed, tokens)
 	return c.seqSearchPrefix(prefixHashes, model, readyPods)
 }
@@ -139,21 +145,26 @@ func matchPods(blockPods map[string]time.Time, readyPods map[string]struct{}, pr
 	return isMatch
 }
 
-func getPrefixHashes(seed uint64, tokens []byte) []uint64 {
+func getPrefixHashes(seed uint64, tokens []int) []uint64 {
 	prefixHashes := []uint64{}
 	digest := xxhash.NewWithSeed(seed)
+	buf := make([]byte, 4*prefixCacheBlockSize)
 	for i := 0; i < len(tokens); i += prefixCacheBlockSize {
 		end := i + prefixCacheBlockSize
 		if end > len(tokens) {
 			break
 		}
-		_, _ = digest.Write(tokens[i:end])
+		// each token id is hashed as 4 little-endian bytes
+		for j, token := range tokens[i:end] {
+			binary.LittleEndian.PutUint32(buf[4*j:], uint32(token))
+		}
+		_, _ = digest.Write(buf)
 		prefixHashes = append(prefixHashes, digest.Sum64())
 		digest.ResetWithSeed(seed)
 	}
 	return prefixHashes
 }
 
-func (c *PrefixHashTable) GetPrefixHashes(tokens []byte) []uint64 {
+func (c *PrefixHashTable) GetPrefixHashes(tokens []int) []uint64 {
 	return getPrefixHashes(c.seed, tokens)
 }
@@ -17,7 +17,6 @@ limitations under the License.
 package prefixcacheindexer
 
 import (
-	"fmt"
 	"math/rand"
 	"sync"
 	"testing"
@@ -33,7 +32,7 @@ func Test_PrefixHashTableE2E(t *testing.T) {
 	targetPod2 := "p2"
 	prefixCacheBlockSize = 4
 
-	matchedPods, prefixHashes := cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9}, model, getReadyPods())
+	matchedPods, prefixHashes := cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}, model, getReadyPods())
 	assert.Equal(t, 0, len(matchedPods))
 	assert.Equal(t, 2, len(prefixHashes))
 
@@ -41,39 +40,39 @@ func Test_PrefixHashTableE2E(t *testing.T) {
 	cache.AddPrefix(prefixHashes, model, targetPod)
 
 	// run match prefix will different combinations
-	matchedPods, prefixHashes = cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7}, model, getReadyPods())
+	matchedPods, prefixHashes = cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7}, model, getReadyPods())
 	assert.Equal(t, 1, len(matchedPods))
 	assert.Equal(t, targetPod, getFirstKey(matchedPods))
 	assert.Equal(t, 1, len(prefixHashes))
 
-	matchedPods, prefixHashes = cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7, 8}, model, getReadyPods())
+	matchedPods, prefixHashes = cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7, 8}, model, getReadyPods())
 	assert.Equal(t, 1, len(matchedPods))
 	assert.Equal(t, targetPod, getFirstKey(matchedPods))
 	assert.Equal(t, 2, len(prefixHashes))
 
-	matchedPods, prefixHashes = cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9}, model, getReadyPods())
+	matchedPods, prefixHashes = cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}, model, getReadyPods())
 	assert.Equal(t, 1, len(matchedPods))
 	assert.Equal(t, targetPod, getFirstKey(matchedPods))
 	assert.Equal(t, 2, len(prefixHashes))
 
-	matchedPods, prefixHashes = cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}, model, getReadyPods())
+	matchedPods, prefixHashes = cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}, model, getReadyPods())
 	assert.Equal(t, 1, len(matchedPods))
 	assert.Equal(t, targetPod, getFirstKey(matchedPods))
 	assert.Equal(t, 3, len(prefixHashes))
 
 	// different model sharing same prefix
-	matchedPods, prefixHashes = cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}, model2, getReadyPods())
+	matchedPods, prefixHashes = cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}, model2, getReadyPods())
 	assert.Equal(t, 0, len(matchedPods))
 	assert.Equal(t, 3, len(prefixHashes))
 
 	cache.AddPrefix(prefixHashes, model2, targetPod)
 	cache.AddPrefix(prefixHashes[0:2], model2, targetPod2)
 
-	matchedPods, prefixHashes = cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7, 8}, model2, getReadyPods())
+	matchedPods, prefixHashes = cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7, 8}, model2, getReadyPods())
 	assert.Equal(t, 2, len(matchedPods))
 	assert.Equal(t, 2, len(prefixHashes))
 
-	matchedPods, prefixHashes = cache.MatchPrefix([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}, model2, getReadyPods())
+	matchedPods, prefixHashes = cache.MatchPrefix([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}, model2, getReadyPods())
 	assert.Equal(t, 2, len(matchedPods))
 	assert.Equal(t, 100, matchedPods[targetPod])
 	assert.Equal(t, 66, matchedPods[targetPod2])
@@ -89,7 +88,7 @@ func Test_PrefixHashTableConcurrency(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			tokens := []byte(fmt.Sprintf("this is %v message", rand.Intn(10)))
+			tokens := []int{rand.Intn(10), 1, 2, 3, 4, 5, 6, 7, 8}
 			_, prefixHashes := prefixHashTable.MatchPrefix(tokens, model, getReadyPods())
 			prefixHashTable.AddPrefix(prefixHashes, model, targetPod)
 		}()
@@ -22,10 +22,14 @@ func NewCharacterTokenizer() Tokenizer {
 	return &characterTokenizer{}
 }
 
-func (s characterTokenizer) TokenizeInputText(text string) ([]byte, error) {
+func (s characterTokenizer) TokenizeInputText(text string) ([]int, error) {
 	// Note: For some characters such as non-english letters or emoji's, one character may convert to multiple bytes.
 	// which may split across different token blocks. It does not impact prefix-match technically but characters
 	// may loose theoretical meaning.
 	// TODO: evaluate if text conversion can be done to []rune and then convert []rune to []byte with minimal overhead.
-	return []byte(text), nil
+	tokens := make([]int, len(text))
+	for i := 0; i < len(text); i++ {
+		tokens[i] = int(text[i])
+	}
+	return tokens, nil
 }
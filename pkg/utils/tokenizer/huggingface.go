@@ -0,0 +1,316 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// huggingFaceTokenizer encodes text the same way as a HuggingFace tokenizer.json.
+// It supports the BPE (including SentencePiece-style byte fallback) and Unigram models, together with the
+// normalizers, pre-tokenizers and post-processors used by common LLM tokenizers such as Llama, Mistral and Qwen.
+// Only encoding is supported.
+type huggingFaceTokenizer struct {
+	addedTokens  *addedTokenMatcher
+	normalizer   normalizer
+	preTokenizer preTokenizer
+	model        tokenModel
+	// prefixIDs and suffixIDs are the special tokens added by the post-processor, e.g. the bos token.
+	prefixIDs []int
+	suffixIDs []int
+}
+
+// hfTokenizerConfig is the subset of tokenizer.json used for encoding.
+type hfTokenizerConfig struct {
+	AddedTokens   []hfAddedToken `json:"added_tokens"`
+	Normalizer    *hfComponent   `json:"normalizer"`
+	PreTokenizer  *hfComponent   `json:"pre_tokenizer"`
+	PostProcessor *hfComponent   `json:"post_processor"`
+	Model         hfModel        `json:"model"`
+}
+
+type hfAddedToken struct {
+	ID      int    `json:"id"`
+	Content string `json:"content"`
+	Special bool   `json:"special"`
+}
+
+// hfComponent holds the fields of all supported normalizers, pre-tokenizers and post-processors.
+type hfComponent struct {
+	Type string `json:"type"`
+
+	// Sequence
+	Normalizers   []hfComponent `json:"normalizers"`
+	Pretokenizers []hfComponent `json:"pretokenizers"`
+	Processors    []hfComponent `json:"processors"`
+
+	// Prepend, Replace, Split and Strip
+	Prepend  string    `json:"prepend"`
+	Pattern  hfPattern `json:"pattern"`
+	Content  string    `json:"content"`
+	Behavior string    `json:"behavior"`
+	Invert   bool      `json:"invert"`
+	Left     bool      `json:"strip_left"`
+	Right    bool      `json:"strip_right"`
+
+	// ByteLevel and Metaspace
+	AddPrefixSpace *bool  `json:"add_prefix_space"`
+	UseRegex       *bool  `json:"use_regex"`
+	Replacement    string `json:"replacement"`
+	PrependScheme  string `json:"prepend_scheme"`
+	Split          *bool  `json:"split"`
+
+	// Digits
+	IndividualDigits bool `json:"individual_digits"`
+
+	// TemplateProcessing, BertProcessing and RobertaProcessing
+	Single        []hfTemplatePiece                 `json:"single"`
+	SpecialTokens map[string]hfTemplateSpecialToken `json:"special_tokens"`
+	Cls           []interface{}                     `json:"cls"`
+	Sep           []interface{}                     `json:"sep"`
+}
+
+type hfPattern struct {
+	String *string `json:"String"`
+	Regex  *string `json:"Regex"`
+}
+
+type hfTemplatePiece struct {
+	SpecialToken *struct {
+		ID string `json:"id"`
+	} `json:"SpecialToken"`
+	Sequence *struct {
+		ID string `json:"id"`
+	} `json:"Sequence"`
+}
+
+type hfTemplateSpecialToken struct {
+	IDs []int `json:"ids"`
+}
+
+type hfModel struct {
+	Type                    string          `json:"type"`
+	Vocab                   json.RawMessage `json:"vocab"`
+	Merges                  json.RawMessage `json:"merges"`
+	UnkToken                *string         `json:"unk_token"`
+	UnkID                   *int            `json:"unk_id"`
+	ByteFallback            bool            `json:"byte_fallback"`
+	FuseUnk                 bool            `json:"fuse_unk"`
+	IgnoreMerges            bool            `json:"ignore_merges"`
+	ContinuingSubwordPrefix *string         `json:"continuing_subword_prefix"`
+	EndOfWordSuffix         *string         `json:"end_of_word_suffix"`
+}
+
+// NewHuggingFaceTokenizerFromFile loads a tokenizer from a HuggingFace tokenizer.json file.
+func NewHuggingFaceTokenizerFromFile(path string) (Tokenizer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewHuggingFaceTokenizer(data)
+}
+
+// NewHuggingFaceTokenizer loads a tokenizer from the content of a HuggingFace tokenizer.json.
+func NewHuggingFaceTokenizer(data []byte) (Tokenizer, error) {
+	var config hfTokenizerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid tokenizer.json: %w", err)
+	}
+
+	t := &huggingFaceTokenizer{}
+	var err error
+	if t.model, err = newTokenModel(config.Model); err != nil {
+		return nil, err
+	}
+	if config.Normalizer != nil {
+		if t.normalizer, err = newNormalizer(*config.Normalizer); err != nil {
+			return nil, err
+		}
+	}
+	if config.PreTokenizer != nil {
+		if t.preTokenizer, err = newPreTokenizer(*config.PreTokenizer); err != nil {
+			return nil, err
+		}
+	}
+	if config.PostProcessor != nil {
+		if t.prefixIDs, t.suffixIDs, err = newPostProcessor(*config.PostProcessor); err != nil {
+			return nil, err
+		}
+	}
+	t.addedTokens = newAddedTokenMatcher(config.AddedTokens)
+	return t, nil
+}
+
+func (t *huggingFaceTokenizer) TokenizeInputText(text string) ([]int, error) {
+	ids := make([]int, 0, len(text)/3+len(t.prefixIDs)+len(t.suffixIDs))
+	ids = append(ids, t.prefixIDs...)
+	for _, segment := range t.addedTokens.split(text) {
+		if segment.id >= 0 {
+			ids = append(ids, segment.id)
+			continue
+		}
+
+		normalized := segment.text
+		if t.normalizer != nil {
+			normalized = t.normalizer.normalize(normalized)
+		}
+		pieces := []string{normalized}
+		if t.preTokenizer != nil {
+			pieces = t.preTokenizer.preTokenize(pieces, segment.offset == 0)
+		}
+		for _, piece := range pieces {
+			if piece != "" {
+				ids = t.model.encode(piece, ids)
+			}
+		}
+	}
+	return append(ids, t.suffixIDs...), nil
+}
+
+// newPostProcessor returns the special tokens the post-processor adds before and after a single sequence.
+func newPostProcessor(c hfComponent) ([]int, []int, error) {
+	switch c.Type {
+	case "TemplateProcessing":
+		var prefix, suffix []int
+		seenSequence := false
+		for _, piece := range c.Single {
+			if piece.Sequence != nil {
+				seenSequence = true
+				continue
+			}
+			if piece.SpecialToken == nil {
+				continue
+			}
+			special, ok := c.SpecialTokens[piece.SpecialToken.ID]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown special token %s in template", piece.SpecialToken.ID)
+			}
+			if seenSequence {
+				suffix = append(suffix, special.IDs...)
+			} else {
+				prefix = append(prefix, special.IDs...)
+			}
+		}
+		return prefix, suffix, nil
+	case "BertProcessing", "RobertaProcessing":
+		cls, err := getTokenPairID(c.Cls)
+		if err != nil {
+			return nil, nil, err
+		}
+		sep, err := getTokenPairID(c.Sep)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []int{cls}, []int{sep}, nil
+	case "Sequence":
+		var prefix, suffix []int
+		for _, processor := range c.Processors {
+			p, s, err := newPostProcessor(processor)
+			if err != nil {
+				return nil, nil, err
+			}
+			prefix = append(prefix, p...)
+			suffix = append(s, suffix...)
+		}
+		return prefix, suffix, nil
+	case "ByteLevel":
+		// Only adjusts offsets.
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported post_processor: %s", c.Type)
+	}
+}
+
+// getTokenPairID returns the id of a ["token", id] pair.
+func getTokenPairID(pair []interface{}) (int, error) {
+	if len(pair) != 2 {
+		return 0, fmt.Errorf("invalid special token pair: %v", pair)
+	}
+	id, ok := pair[1].(float64)
+	if !ok {
+		return 0, fmt.Errorf("invalid special token pair: %v", pair)
+	}
+	return int(id), nil
+}
+
+// addedTokenMatcher splits text on added tokens, which are encoded to their ids as is.
+type addedTokenMatcher struct {
+	// tokens are indexed by their first byte, longer tokens first.
+	tokens map[byte][]hfAddedToken
+}
+
+type textSegment struct {
+	text   string
+	offset int
+	// id is the added token id of the segment, -1 if the segment is not an added token.
+	id int
+}
+
+func newAddedTokenMatcher(addedTokens []hfAddedToken) *addedTokenMatcher {
+	m := &addedTokenMatcher{tokens: map[byte][]hfAddedToken{}}
+	for _, token := range addedTokens {
+		if token.Content == "" {
+			continue
+		}
+		m.tokens[token.Content[0]] = append(m.tokens[token.Content[0]], token)
+	}
+	for _, tokens := range m.tokens {
+		sort.SliceStable(tokens, func(i, j int) bool {
+			return len(tokens[i].Content) > len(tokens[j].Content)
+		})
+	}
+	return m
+}
+
+func (m *addedTokenMatcher) split(text string) []textSegment {
+	if len(m.tokens) == 0 {
+		return []textSegment{{text: text, id: -1}}
+	}
+
+	var segments []textSegment
+	start := 0
+	for i := 0; i < len(text); {
+		token, ok := m.match(text[i:])
+		if !ok {
+			i++
+			continue
+		}
+		if start < i {
+			segments = append(segments, textSegment{text: text[start:i], offset: start, id: -1})
+		}
+		segments = append(segments, textSegment{text: token.Content, offset: i, id: token.ID})
+		i += len(token.Content)
+		start = i
+	}
+	if start < len(text) || len(segments) == 0 {
+		segments = append(segments, textSegment{text: text[start:], offset: start, id: -1})
+	}
+	return segments
+}
+
+func (m *addedTokenMatcher) match(text string) (hfAddedToken, bool) {
+	for _, token := range m.tokens[text[0]] {
+		if strings.HasPrefix(text, token.Content) {
+			return token, true
+		}
+	}
+	return hfAddedToken{}, false
+}
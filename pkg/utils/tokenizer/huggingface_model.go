@@ -0,0 +1,417 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// wordCacheSize bounds the encoded words kept by a model, the cache is reset once full.
+const wordCacheSize = 100000
+
+type tokenModel interface {
+	// encode appends the token ids of a pre-tokenized word to ids.
+	encode(word string, ids []int) []int
+}
+
+func newTokenModel(m hfModel) (tokenModel, error) {
+	switch m.Type {
+	case "BPE", "":
+		return newBPEModel(m)
+	case "Unigram":
+		return newUnigramModel(m)
+	default:
+		return nil, fmt.Errorf("unsupported model: %s", m.Type)
+	}
+}
+
+// wordCache caches the token ids of frequent words.
+type wordCache struct {
+	mu    sync.RWMutex
+	words map[string][]int
+}
+
+func (c *wordCache) get(word string) ([]int, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ids, ok := c.words[word]
+	return ids, ok
+}
+
+func (c *wordCache) put(word string, ids []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.words == nil || len(c.words) >= wordCacheSize {
+		c.words = make(map[string][]int)
+	}
+	c.words[word] = ids
+}
+
+type bpeMerge struct {
+	rank int
+	id   int
+}
+
+type bpeModel struct {
+	vocab                   map[string]int
+	merges                  map[[2]int]bpeMerge
+	unkID                   int
+	fuseUnk                 bool
+	byteFallback            bool
+	ignoreMerges            bool
+	continuingSubwordPrefix string
+	endOfWordSuffix         string
+	cache                   wordCache
+}
+
+func newBPEModel(m hfModel) (*bpeModel, error) {
+	b := &bpeModel{
+		unkID:        -1,
+		fuseUnk:      m.FuseUnk,
+		byteFallback: m.ByteFallback,
+		ignoreMerges: m.IgnoreMerges,
+		merges:       map[[2]int]bpeMerge{},
+	}
+	if m.ContinuingSubwordPrefix != nil {
+		b.continuingSubwordPrefix = *m.ContinuingSubwordPrefix
+	}
+	if m.EndOfWordSuffix != nil {
+		b.endOfWordSuffix = *m.EndOfWordSuffix
+	}
+	if err := json.Unmarshal(m.Vocab, &b.vocab); err != nil {
+		return nil, fmt.Errorf("invalid BPE vocab: %w", err)
+	}
+	if m.UnkToken != nil {
+		if id, ok := b.vocab[*m.UnkToken]; ok {
+			b.unkID = id
+		}
+	}
+
+	merges, err := parseMerges(m.Merges)
+	if err != nil {
+		return nil, err
+	}
+	for rank, merge := range merges {
+		left, ok1 := b.vocab[merge[0]]
+		right, ok2 := b.vocab[merge[1]]
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("merge %q %q is out of vocab", merge[0], merge[1])
+		}
+		merged := merge[0] + strings.TrimPrefix(merge[1], b.continuingSubwordPrefix)
+		id, ok := b.vocab[merged]
+		if !ok {
+			return nil, fmt.Errorf("merged token %q is out of vocab", merged)
+		}
+		b.merges[[2]int{left, right}] = bpeMerge{rank: rank, id: id}
+	}
+	return b, nil
+}
+
+// parseMerges accepts both the legacy "a b" merges and the [a, b] merges of newer tokenizer.json.
+func parseMerges(raw json.RawMessage) ([][2]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var pairs [][2]string
+	if err := json.Unmarshal(raw, &pairs); err == nil {
+		return pairs, nil
+	}
+	var legacy []string
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return nil, fmt.Errorf("invalid BPE merges: %w", err)
+	}
+	pairs = make([][2]string, 0, len(legacy))
+	for _, merge := range legacy {
+		left, right, ok := strings.Cut(merge, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid BPE merge: %q", merge)
+		}
+		pairs = append(pairs, [2]string{left, right})
+	}
+	return pairs, nil
+}
+
+type bpeSymbol struct {
+	id         int
+	prev, next int
+	alive      bool
+}
+
+type bpeCandidate struct {
+	rank int
+	pos  int
+}
+
+type bpeCandidates []bpeCandidate
+
+func (h bpeCandidates) Len() int { return len(h) }
+func (h bpeCandidates) Less(i, j int) bool {
+	if h[i].rank != h[j].rank {
+		return h[i].rank < h[j].rank
+	}
+	return h[i].pos < h[j].pos
+}
+func (h bpeCandidates) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *bpeCandidates) Push(x interface{}) { *h = append(*h, x.(bpeCandidate)) }
+func (h *bpeCandidates) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func (b *bpeModel) encode(word string, ids []int) []int {
+	if b.ignoreMerges {
+		if id, ok := b.vocab[word]; ok {
+			return append(ids, id)
+		}
+	}
+	if cached, ok := b.cache.get(word); ok {
+		return append(ids, cached...)
+	}
+
+	symbols := b.initialSymbols(word)
+	candidates := &bpeCandidates{}
+	for i := 0; i+1 < len(symbols); i++ {
+		b.pushCandidate(candidates, symbols, i)
+	}
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(bpeCandidate)
+		left := &symbols[c.pos]
+		if !left.alive || left.next < 0 {
+			continue
+		}
+		right := &symbols[left.next]
+		merge, ok := b.merges[[2]int{left.id, right.id}]
+		if !ok || merge.rank != c.rank {
+			// Stale candidate, one of the symbols was merged.
+			continue
+		}
+		left.id = merge.id
+		right.alive = false
+		left.next = right.next
+		if right.next >= 0 {
+			symbols[right.next].prev = c.pos
+		}
+		if left.prev >= 0 {
+			b.pushCandidate(candidates, symbols, left.prev)
+		}
+		b.pushCandidate(candidates, symbols, c.pos)
+	}
+
+	encoded := make([]int, 0, len(symbols))
+	for i := 0; i >= 0 && i < len(symbols); i = symbols[i].next {
+		encoded = append(encoded, symbols[i].id)
+	}
+	b.cache.put(word, encoded)
+	return append(ids, encoded...)
+}
+
+func (b *bpeModel) pushCandidate(candidates *bpeCandidates, symbols []bpeSymbol, pos int) {
+	next := symbols[pos].next
+	if next < 0 {
+		return
+	}
+	if merge, ok := b.merges[[2]int{symbols[pos].id, symbols[next].id}]; ok {
+		heap.Push(candidates, bpeCandidate{rank: merge.rank, pos: pos})
+	}
+}
+
+// initialSymbols splits a word into characters, unknown characters fall back to bytes or the unk token.
+func (b *bpeModel) initialSymbols(word string) []bpeSymbol {
+	symbols := make([]bpeSymbol, 0, len(word))
+	appendSymbol := func(id int) {
+		n := len(symbols)
+		symbols = append(symbols, bpeSymbol{id: id, prev: n - 1, next: -1, alive: true})
+		if n > 0 {
+			symbols[n-1].next = n
+		}
+	}
+
+	lastUnk := false
+	for i, r := range word {
+		char := string(r)
+		if i > 0 {
+			char = b.continuingSubwordPrefix + char
+		}
+		if i+utf8.RuneLen(r) == len(word) {
+			char += b.endOfWordSuffix
+		}
+		if id, ok := b.vocab[char]; ok {
+			appendSymbol(id)
+			lastUnk = false
+			continue
+		}
+		if b.byteFallback {
+			if byteIDs, ok := b.byteIDs(string(r)); ok {
+				for _, id := range byteIDs {
+					appendSymbol(id)
+				}
+				lastUnk = false
+				continue
+			}
+		}
+		if b.unkID >= 0 && !(b.fuseUnk && lastUnk) {
+			appendSymbol(b.unkID)
+		}
+		lastUnk = true
+	}
+	return symbols
+}
+
+func (b *bpeModel) byteIDs(char string) ([]int, bool) {
+	ids := make([]int, 0, len(char))
+	for i := 0; i < len(char); i++ {
+		id, ok := b.vocab[fmt.Sprintf("<0x%02X>", char[i])]
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// unigramUnkPenalty is subtracted from the min score for unknown characters, same as SentencePiece.
+const unigramUnkPenalty = 10.0
+
+type unigramModel struct {
+	pieces       map[string]int
+	scores       []float64
+	unkID        int
+	byteFallback bool
+	maxPieceLen  int
+	unkScore     float64
+	cache        wordCache
+}
+
+func newUnigramModel(m hfModel) (*unigramModel, error) {
+	var vocab [][2]interface{}
+	if err := json.Unmarshal(m.Vocab, &vocab); err != nil {
+		return nil, fmt.Errorf("invalid Unigram vocab: %w", err)
+	}
+
+	u := &unigramModel{
+		pieces:       make(map[string]int, len(vocab)),
+		scores:       make([]float64, len(vocab)),
+		unkID:        -1,
+		byteFallback: m.ByteFallback,
+	}
+	minScore := math.Inf(1)
+	for id, entry := range vocab {
+		piece, ok1 := entry[0].(string)
+		score, ok2 := entry[1].(float64)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("invalid Unigram vocab entry: %v", entry)
+		}
+		u.pieces[piece] = id
+		u.scores[id] = score
+		minScore = math.Min(minScore, score)
+		u.maxPieceLen = max(u.maxPieceLen, len(piece))
+	}
+	if m.UnkID != nil {
+		u.unkID = *m.UnkID
+	}
+	u.unkScore = minScore - unigramUnkPenalty
+	return u, nil
+}
+
+// encode finds the most likely segmentation of the word with the Viterbi algorithm.
+func (u *unigramModel) encode(word string, ids []int) []int {
+	if cached, ok := u.cache.get(word); ok {
+		return append(ids, cached...)
+	}
+
+	n := len(word)
+	best := make([]float64, n+1)
+	// from and pieceID describe the last piece of the best segmentation ending at each byte offset.
+	from := make([]int, n+1)
+	pieceID := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = math.Inf(-1)
+	}
+	for start := 0; start < n; {
+		_, runeLen := utf8.DecodeRuneInString(word[start:])
+		if !math.IsInf(best[start], -1) {
+			found := false
+			for end := start + 1; end <= n && end-start <= u.maxPieceLen; end++ {
+				id, ok := u.pieces[word[start:end]]
+				if !ok {
+					continue
+				}
+				if end-start == runeLen {
+					found = true
+				}
+				if score := best[start] + u.scores[id]; score > best[end] {
+					best[end], from[end], pieceID[end] = score, start, id
+				}
+			}
+			if !found {
+				// Unknown character.
+				end := start + runeLen
+				if score := best[start] + u.unkScore; score > best[end] {
+					best[end], from[end], pieceID[end] = score, start, -1
+				}
+			}
+		}
+		start += runeLen
+	}
+
+	var reversed []int
+	for end := n; end > 0; end = from[end] {
+		if pieceID[end] >= 0 {
+			reversed = append(reversed, pieceID[end])
+			continue
+		}
+		unknown := word[from[end]:end]
+		if byteIDs, ok := u.byteIDs(unknown); ok {
+			for i := len(byteIDs) - 1; i >= 0; i-- {
+				reversed = append(reversed, byteIDs[i])
+			}
+		} else if u.unkID >= 0 && (len(reversed) == 0 || reversed[len(reversed)-1] != u.unkID) {
+			// Consecutive unknown characters are fused into one unk token.
+			reversed = append(reversed, u.unkID)
+		}
+	}
+
+	encoded := make([]int, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		encoded = append(encoded, reversed[i])
+	}
+	u.cache.put(word, encoded)
+	return append(ids, encoded...)
+}
+
+func (u *unigramModel) byteIDs(char string) ([]int, bool) {
+	if !u.byteFallback {
+		return nil, false
+	}
+	ids := make([]int, 0, len(char))
+	for i := 0; i < len(char); i++ {
+		id, ok := u.pieces[fmt.Sprintf("<0x%02X>", char[i])]
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
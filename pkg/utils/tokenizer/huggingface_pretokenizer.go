@@ -0,0 +1,426 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dlclark/regexp2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// gpt2Pattern is the regex of the ByteLevel pre-tokenizer.
+const gpt2Pattern = `'s|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+`
+
+type normalizer interface {
+	normalize(text string) string
+}
+
+type preTokenizer interface {
+	// preTokenize splits pieces into smaller pieces. atStart is true if the first piece starts the input text.
+	preTokenize(pieces []string, atStart bool) []string
+}
+
+func newNormalizer(c hfComponent) (normalizer, error) {
+	switch c.Type {
+	case "Sequence":
+		var normalizers sequenceNormalizer
+		for _, child := range c.Normalizers {
+			n, err := newNormalizer(child)
+			if err != nil {
+				return nil, err
+			}
+			normalizers = append(normalizers, n)
+		}
+		return normalizers, nil
+	case "NFC":
+		return unicodeNormalizer{form: norm.NFC}, nil
+	case "NFD":
+		return unicodeNormalizer{form: norm.NFD}, nil
+	case "NFKC", "Precompiled":
+		// The precompiled charsmap of SentencePiece is mostly NFKC.
+		return unicodeNormalizer{form: norm.NFKC}, nil
+	case "NFKD":
+		return unicodeNormalizer{form: norm.NFKD}, nil
+	case "Lowercase":
+		return lowercaseNormalizer{}, nil
+	case "Prepend":
+		return prependNormalizer{prepend: c.Prepend}, nil
+	case "Replace":
+		pattern, err := newPattern(c.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return replaceNormalizer{pattern: pattern, content: c.Content}, nil
+	case "Strip":
+		return stripNormalizer{left: c.Left, right: c.Right}, nil
+	default:
+		return nil, fmt.Errorf("unsupported normalizer: %s", c.Type)
+	}
+}
+
+type sequenceNormalizer []normalizer
+
+func (s sequenceNormalizer) normalize(text string) string {
+	for _, n := range s {
+		text = n.normalize(text)
+	}
+	return text
+}
+
+type unicodeNormalizer struct {
+	form norm.Form
+}
+
+func (u unicodeNormalizer) normalize(text string) string {
+	return u.form.String(text)
+}
+
+type lowercaseNormalizer struct{}
+
+func (lowercaseNormalizer) normalize(text string) string {
+	return strings.ToLower(text)
+}
+
+type prependNormalizer struct {
+	prepend string
+}
+
+func (p prependNormalizer) normalize(text string) string {
+	if text == "" {
+		return text
+	}
+	return p.prepend + text
+}
+
+type replaceNormalizer struct {
+	pattern *pattern
+	content string
+}
+
+func (r replaceNormalizer) normalize(text string) string {
+	var b strings.Builder
+	for _, s := range r.pattern.split(text) {
+		if s.isMatch {
+			b.WriteString(r.content)
+		} else {
+			b.WriteString(s.text)
+		}
+	}
+	return b.String()
+}
+
+type stripNormalizer struct {
+	left, right bool
+}
+
+func (s stripNormalizer) normalize(text string) string {
+	if s.left {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+	}
+	if s.right {
+		text = strings.TrimRightFunc(text, unicode.IsSpace)
+	}
+	return text
+}
+
+func newPreTokenizer(c hfComponent) (preTokenizer, error) {
+	switch c.Type {
+	case "Sequence":
+		var preTokenizers sequencePreTokenizer
+		for _, child := range c.Pretokenizers {
+			p, err := newPreTokenizer(child)
+			if err != nil {
+				return nil, err
+			}
+			preTokenizers = append(preTokenizers, p)
+		}
+		return preTokenizers, nil
+	case "ByteLevel":
+		b := byteLevelPreTokenizer{addPrefixSpace: c.AddPrefixSpace != nil && *c.AddPrefixSpace}
+		if c.UseRegex == nil || *c.UseRegex {
+			b.pattern = &pattern{regex: regexp2.MustCompile(gpt2Pattern, regexp2.None)}
+		}
+		return b, nil
+	case "Split":
+		pattern, err := newPattern(c.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return splitPreTokenizer{pattern: pattern, behavior: c.Behavior, invert: c.Invert}, nil
+	case "Metaspace":
+		m := metaspacePreTokenizer{
+			replacement:   c.Replacement,
+			prependScheme: c.PrependScheme,
+			split:         c.Split == nil || *c.Split,
+		}
+		if m.replacement == "" {
+			m.replacement = "▁"
+		}
+		if m.prependScheme == "" {
+			// Legacy configurations only carry add_prefix_space.
+			m.prependScheme = "always"
+			if c.AddPrefixSpace != nil && !*c.AddPrefixSpace {
+				m.prependScheme = "never"
+			}
+		}
+		return m, nil
+	case "Digits":
+		expr := `\p{N}+`
+		if c.IndividualDigits {
+			expr = `\p{N}`
+		}
+		return splitPreTokenizer{pattern: &pattern{regex: regexp2.MustCompile(expr, regexp2.None)}, behavior: "Isolated"}, nil
+	case "WhitespaceSplit":
+		return splitPreTokenizer{pattern: &pattern{regex: regexp2.MustCompile(`\s+`, regexp2.None)}, behavior: "Removed"}, nil
+	case "Whitespace":
+		return splitPreTokenizer{pattern: &pattern{regex: regexp2.MustCompile(`\w+|[^\w\s]+`, regexp2.None)}, behavior: "Removed", invert: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported pre_tokenizer: %s", c.Type)
+	}
+}
+
+type sequencePreTokenizer []preTokenizer
+
+func (s sequencePreTokenizer) preTokenize(pieces []string, atStart bool) []string {
+	for _, p := range s {
+		pieces = p.preTokenize(pieces, atStart)
+	}
+	return pieces
+}
+
+// byteLevelPreTokenizer splits pieces with the GPT-2 regex and maps their bytes to printable characters.
+type byteLevelPreTokenizer struct {
+	addPrefixSpace bool
+	pattern        *pattern
+}
+
+func (b byteLevelPreTokenizer) preTokenize(pieces []string, _ bool) []string {
+	result := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		if b.addPrefixSpace && !strings.HasPrefix(piece, " ") {
+			piece = " " + piece
+		}
+		words := []string{piece}
+		if b.pattern != nil {
+			words = words[:0]
+			for _, s := range b.pattern.split(piece) {
+				words = append(words, s.text)
+			}
+		}
+		for _, word := range words {
+			result = append(result, byteLevelEncode(word))
+		}
+	}
+	return result
+}
+
+type splitPreTokenizer struct {
+	pattern  *pattern
+	behavior string
+	invert   bool
+}
+
+func (s splitPreTokenizer) preTokenize(pieces []string, _ bool) []string {
+	result := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		splits := s.pattern.split(piece)
+		if s.invert {
+			for i := range splits {
+				splits[i].isMatch = !splits[i].isMatch
+			}
+		}
+		result = append(result, applySplitBehavior(splits, s.behavior)...)
+	}
+	return result
+}
+
+// metaspacePreTokenizer replaces spaces with the replacement, which starts every word as in SentencePiece.
+type metaspacePreTokenizer struct {
+	replacement   string
+	prependScheme string
+	split         bool
+}
+
+func (m metaspacePreTokenizer) preTokenize(pieces []string, atStart bool) []string {
+	result := make([]string, 0, len(pieces))
+	for i, piece := range pieces {
+		piece = strings.ReplaceAll(piece, " ", m.replacement)
+		prepend := m.prependScheme == "always" || (m.prependScheme == "first" && atStart && i == 0)
+		if prepend && !strings.HasPrefix(piece, m.replacement) {
+			piece = m.replacement + piece
+		}
+		if !m.split {
+			result = append(result, piece)
+			continue
+		}
+		p := &pattern{literal: m.replacement}
+		result = append(result, applySplitBehavior(p.split(piece), "MergedWithNext")...)
+	}
+	return result
+}
+
+// pattern is either a literal string or a regex, the regex supports lookarounds as in HuggingFace tokenizers.
+type pattern struct {
+	literal string
+	regex   *regexp2.Regexp
+}
+
+type split struct {
+	text    string
+	isMatch bool
+}
+
+func newPattern(p hfPattern) (*pattern, error) {
+	if p.String != nil {
+		return &pattern{literal: *p.String}, nil
+	}
+	if p.Regex != nil {
+		regex, err := regexp2.Compile(*p.Regex, regexp2.None)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", *p.Regex, err)
+		}
+		return &pattern{regex: regex}, nil
+	}
+	return nil, fmt.Errorf("empty pattern")
+}
+
+// split returns the matches and the gaps between them in order.
+func (p *pattern) split(text string) []split {
+	var splits []split
+	if p.regex == nil {
+		if p.literal == "" {
+			return []split{{text: text}}
+		}
+		for {
+			i := strings.Index(text, p.literal)
+			if i < 0 {
+				break
+			}
+			if i > 0 {
+				splits = append(splits, split{text: text[:i]})
+			}
+			splits = append(splits, split{text: p.literal, isMatch: true})
+			text = text[i+len(p.literal):]
+		}
+		if text != "" {
+			splits = append(splits, split{text: text})
+		}
+		return splits
+	}
+
+	// regexp2 reports rune offsets.
+	runes := []rune(text)
+	last := 0
+	match, _ := p.regex.FindRunesMatch(runes)
+	for match != nil {
+		if match.Length == 0 {
+			match, _ = p.regex.FindNextMatch(match)
+			continue
+		}
+		if match.Index > last {
+			splits = append(splits, split{text: string(runes[last:match.Index])})
+		}
+		splits = append(splits, split{text: string(runes[match.Index : match.Index+match.Length]), isMatch: true})
+		last = match.Index + match.Length
+		match, _ = p.regex.FindNextMatch(match)
+	}
+	if last < len(runes) {
+		splits = append(splits, split{text: string(runes[last:])})
+	}
+	return splits
+}
+
+// applySplitBehavior merges or removes matches the same way as the HuggingFace SplitDelimiterBehavior.
+func applySplitBehavior(splits []split, behavior string) []string {
+	var pieces []string
+	switch behavior {
+	case "Removed":
+		for _, s := range splits {
+			if !s.isMatch {
+				pieces = append(pieces, s.text)
+			}
+		}
+	case "MergedWithPrevious":
+		previousMatch := false
+		for _, s := range splits {
+			if s.isMatch && !previousMatch && len(pieces) > 0 {
+				pieces[len(pieces)-1] += s.text
+			} else {
+				pieces = append(pieces, s.text)
+			}
+			previousMatch = s.isMatch
+		}
+	case "MergedWithNext":
+		nextMatch := false
+		reversed := []string{}
+		for i := len(splits) - 1; i >= 0; i-- {
+			s := splits[i]
+			if s.isMatch && !nextMatch && len(reversed) > 0 {
+				reversed[len(reversed)-1] = s.text + reversed[len(reversed)-1]
+			} else {
+				reversed = append(reversed, s.text)
+			}
+			nextMatch = s.isMatch
+		}
+		for i := len(reversed) - 1; i >= 0; i-- {
+			pieces = append(pieces, reversed[i])
+		}
+	case "Contiguous":
+		previousMatch := false
+		for _, s := range splits {
+			if s.isMatch && previousMatch && len(pieces) > 0 {
+				pieces[len(pieces)-1] += s.text
+			} else {
+				pieces = append(pieces, s.text)
+			}
+			previousMatch = s.isMatch
+		}
+	default:
+		// Isolated
+		for _, s := range splits {
+			pieces = append(pieces, s.text)
+		}
+	}
+	return pieces
+}
+
+// byteLevelAlphabet maps bytes to printable characters as GPT-2 does, so that any byte sequence is a valid word.
+var byteLevelAlphabet = func() [256]rune {
+	var alphabet [256]rune
+	n := 0
+	for b := 0; b < 256; b++ {
+		if (b >= '!' && b <= '~') || (b >= 0xA1 && b <= 0xAC) || (b >= 0xAE && b <= 0xFF) {
+			alphabet[b] = rune(b)
+		} else {
+			alphabet[b] = rune(256 + n)
+			n++
+		}
+	}
+	return alphabet
+}()
+
+func byteLevelEncode(text string) string {
+	var b strings.Builder
+	b.Grow(len(text) * 2)
+	for i := 0; i < len(text); i++ {
+		b.WriteRune(byteLevelAlphabet[text[i]])
+	}
+	return b.String()
+}
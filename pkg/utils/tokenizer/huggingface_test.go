@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// byteLevelBPE is a GPT-2 style tokenizer.json.
+const byteLevelBPE = `{
+	"added_tokens": [{"id": 100, "content": "<|endoftext|>", "special": true}],
+	"pre_tokenizer": {"type": "ByteLevel", "add_prefix_space": false, "use_regex": true},
+	"post_processor": {"type": "ByteLevel"},
+	"model": {
+		"type": "BPE",
+		"vocab": {"h": 0, "e": 1, "l": 2, "o": 3, "Ġ": 4, "w": 5, "r": 6, "d": 7,
+			"he": 8, "ll": 9, "hell": 10, "hello": 11, "Ġw": 12, "or": 13, "Ġwor": 14},
+		"merges": ["h e", "l l", "he ll", "hell o", "Ġ w", "o r", "Ġw or"]
+	}
+}`
+
+// metaspaceBPE is a Llama style tokenizer.json.
+const metaspaceBPE = `{
+	"pre_tokenizer": {"type": "Metaspace", "replacement": "▁", "prepend_scheme": "first", "split": false},
+	"post_processor": {
+		"type": "TemplateProcessing",
+		"single": [{"SpecialToken": {"id": "<s>", "type_id": 0}}, {"Sequence": {"id": "A", "type_id": 0}}],
+		"special_tokens": {"<s>": {"id": "<s>", "ids": [1], "tokens": ["<s>"]}}
+	},
+	"model": {
+		"type": "BPE",
+		"unk_token": "<unk>",
+		"byte_fallback": true,
+		"fuse_unk": true,
+		"vocab": {"<unk>": 0, "<s>": 1, "</s>": 2, "<0x21>": 3, "▁": 4, "h": 5, "i": 6, "▁h": 7, "▁hi": 8},
+		"merges": [["▁", "h"], ["▁h", "i"]]
+	}
+}`
+
+const unigram = `{
+	"pre_tokenizer": {"type": "Metaspace", "replacement": "▁", "prepend_scheme": "always"},
+	"model": {
+		"type": "Unigram",
+		"unk_id": 0,
+		"vocab": [["<unk>", 0], ["▁", -2], ["a", -3], ["b", -3], ["ab", -2.5], ["▁ab", -1]]
+	}
+}`
+
+func TestHuggingFaceTokenizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		input    string
+		expected []int
+	}{
+		{"byte level bpe", byteLevelBPE, "hello world", []int{11, 14, 2, 7}},
+		{"byte level bpe with added token", byteLevelBPE, "hello<|endoftext|>hello", []int{11, 100, 11}},
+		{"metaspace bpe with bos", metaspaceBPE, "hi", []int{1, 8}},
+		{"metaspace bpe with byte fallback", metaspaceBPE, "hi!", []int{1, 8, 3}},
+		{"metaspace bpe with unk", metaspaceBPE, "hiéé", []int{1, 8, 0}},
+		{"unigram", unigram, "ab ab", []int{5, 5}},
+		{"unigram with unk", unigram, "abc", []int{5, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tk, err := NewHuggingFaceTokenizer([]byte(tt.config))
+			assert.NoError(t, err)
+			tokens, err := tk.TokenizeInputText(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tokens)
+
+			// Encoded words are cached, encode again to verify the cache.
+			tokens, err = tk.TokenizeInputText(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestHuggingFaceTokenizerInvalidConfig(t *testing.T) {
+	for _, config := range []string{
+		`{`,
+		`{"model": {"type": "WordPiece", "vocab": {}}}`,
+		`{"model": {"type": "BPE", "vocab": {"a": 0}, "merges": ["a b"]}}`,
+		`{"pre_tokenizer": {"type": "Unknown"}, "model": {"type": "BPE", "vocab": {}}}`,
+	} {
+		_, err := NewHuggingFaceTokenizer([]byte(config))
+		assert.Error(t, err, config)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/utils/chattemplate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	TypeCharacter   = "character"
+	TypeTiktoken    = "tiktoken"
+	TypeHuggingFace = "huggingface"
+
+	defaultConfigMapKey     = "tokenizer.json"
+	configMapLoadingTimeout = 10 * time.Second
+)
+
+// Source describes where the tokenizer of a model is loaded from.
+// A huggingface tokenizer is loaded from a local tokenizer.json path or from a key of a ConfigMap.
+type Source struct {
+	Type string `json:"type,omitempty"`
+	Path string `json:"path,omitempty"`
+	// ConfigMap is in the format of "namespace/name".
+	ConfigMap string `json:"configMap,omitempty"`
+	// Key of the ConfigMap, default "tokenizer.json".
+	Key string `json:"key,omitempty"`
+	// ChatTemplatePath is a tokenizer_config.json or a Jinja file with the chat template of the model.
+	ChatTemplatePath string `json:"chatTemplatePath,omitempty"`
+	// ChatTemplateKey is the key of the chat template in the ConfigMap, e.g. "tokenizer_config.json".
+	ChatTemplateKey string `json:"chatTemplateKey,omitempty"`
+}
+
+// Config maps model names to tokenizer sources, models not listed use the default source.
+type Config struct {
+	Default *Source           `json:"default,omitempty"`
+	Models  map[string]Source `json:"models,omitempty"`
+}
+
+// Registry holds the tokenizer and the chat template of each model.
+// They are loaded once and shared by models with the same source.
+type Registry struct {
+	defaultTokenizer    Tokenizer
+	defaultChatTemplate *chattemplate.ChatTemplate
+	models              map[string]Tokenizer
+	chatTemplates       map[string]*chattemplate.ChatTemplate
+}
+
+type loadedSource struct {
+	tokenizer    Tokenizer
+	chatTemplate *chattemplate.ChatTemplate
+}
+
+// LoadConfig reads a tokenizer config file in YAML or JSON.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("invalid tokenizer config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// NewRegistry loads the tokenizers of the config. ConfigMap sources require a kubernetes client.
+// A source failed to load is logged and its models fall back to the tokenizer of the caller.
+func NewRegistry(config Config, client kubernetes.Interface) *Registry {
+	r := &Registry{models: map[string]Tokenizer{}, chatTemplates: map[string]*chattemplate.ChatTemplate{}}
+	loaded := map[Source]loadedSource{}
+	load := func(source Source) loadedSource {
+		source = source.withDefaults()
+		if l, ok := loaded[source]; ok {
+			return l
+		}
+		var l loadedSource
+		var err error
+		if source.Type != "" {
+			if l.tokenizer, err = newTokenizerFromSource(source, client); err != nil {
+				klog.ErrorS(err, "failed to load tokenizer", "source", source)
+			}
+		}
+		if source.ChatTemplatePath != "" || source.ChatTemplateKey != "" {
+			if l.chatTemplate, err = newChatTemplateFromSource(source, client); err != nil {
+				klog.ErrorS(err, "failed to load chat template", "source", source)
+			}
+		}
+		loaded[source] = l
+		return l
+	}
+
+	if config.Default != nil {
+		l := load(*config.Default)
+		r.defaultTokenizer, r.defaultChatTemplate = l.tokenizer, l.chatTemplate
+	}
+	for model, source := range config.Models {
+		l := load(source)
+		if l.tokenizer != nil {
+			r.models[model] = l.tokenizer
+		}
+		if l.chatTemplate != nil {
+			r.chatTemplates[model] = l.chatTemplate
+		}
+		klog.InfoS("loaded tokenizer", "model", model, "source", source,
+			"tokenizer", l.tokenizer != nil, "chatTemplate", l.chatTemplate != nil)
+	}
+	return r
+}
+
+// Register sets the tokenizer of a model.
+// It is not safe to call Register concurrently with Lookup, register tokenizers before serving requests.
+func (r *Registry) Register(model string, t Tokenizer) {
+	r.models[model] = t
+}
+
+// Lookup returns the tokenizer of a model, or the default tokenizer of the registry.
+// It returns false if neither is available, it is safe to call on a nil registry.
+func (r *Registry) Lookup(model string) (Tokenizer, bool) {
+	if r == nil {
+		return nil, false
+	}
+	if t, ok := r.models[model]; ok {
+		return t, true
+	}
+	return r.defaultTokenizer, r.defaultTokenizer != nil
+}
+
+// LookupChatTemplate returns the chat template of a model, or the default chat template of the registry.
+// It returns false if neither is available, it is safe to call on a nil registry.
+func (r *Registry) LookupChatTemplate(model string) (*chattemplate.ChatTemplate, bool) {
+	if r == nil {
+		return nil, false
+	}
+	if t, ok := r.chatTemplates[model]; ok {
+		return t, true
+	}
+	return r.defaultChatTemplate, r.defaultChatTemplate != nil
+}
+
+func (s Source) withDefaults() Source {
+	if s.Type == "" && (s.Path != "" || s.ConfigMap != "") {
+		s.Type = TypeHuggingFace
+	}
+	if s.ConfigMap != "" && s.Key == "" {
+		s.Key = defaultConfigMapKey
+	}
+	return s
+}
+
+func newTokenizerFromSource(source Source, client kubernetes.Interface) (Tokenizer, error) {
+	switch source.Type {
+	case TypeCharacter:
+		return NewCharacterTokenizer(), nil
+	case TypeTiktoken:
+		return NewTiktokenTokenizer(), nil
+	case TypeHuggingFace:
+		if source.Path != "" {
+			return NewHuggingFaceTokenizerFromFile(source.Path)
+		}
+		if source.ConfigMap == "" {
+			return nil, fmt.Errorf("huggingface tokenizer requires a path or a configMap")
+		}
+		data, err := readConfigMap(client, source.ConfigMap, source.Key)
+		if err != nil {
+			return nil, err
+		}
+		return NewHuggingFaceTokenizer(data)
+	default:
+		return nil, fmt.Errorf("unsupported tokenizer type: %q", source.Type)
+	}
+}
+
+func newChatTemplateFromSource(source Source, client kubernetes.Interface) (*chattemplate.ChatTemplate, error) {
+	var data []byte
+	var err error
+	if source.ChatTemplatePath != "" {
+		data, err = os.ReadFile(source.ChatTemplatePath)
+	} else {
+		data, err = readConfigMap(client, source.ConfigMap, source.ChatTemplateKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return chattemplate.LoadChatTemplate(data)
+}
+
+func readConfigMap(client kubernetes.Interface, namespacedName, key string) ([]byte, error) {
+	if client == nil {
+		return nil, fmt.Errorf("kubernetes client is required to read configMap %s", namespacedName)
+	}
+	namespace, name, ok := strings.Cut(namespacedName, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid configMap %q, expected namespace/name", namespacedName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configMapLoadingTimeout)
+	defer cancel()
+	cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := cm.Data[key]; ok {
+		return []byte(data), nil
+	}
+	if data, ok := cm.BinaryData[key]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("key %s not found in configMap %s", key, namespacedName)
+}
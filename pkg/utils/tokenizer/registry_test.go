@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	tokenizerPath := filepath.Join(dir, "tokenizer.json")
+	assert.NoError(t, os.WriteFile(tokenizerPath, []byte(byteLevelBPE), 0o600))
+	configPath := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`
+default:
+  type: character
+models:
+  gpt2:
+    path: `+tokenizerPath+`
+  llama:
+    configMap: aibrix-system/llama-tokenizer
+  broken:
+    path: `+filepath.Join(dir, "missing.json")+`
+`), 0o600))
+
+	config, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "aibrix-system", Name: "llama-tokenizer"},
+		Data:       map[string]string{"tokenizer.json": metaspaceBPE},
+	})
+	registry := NewRegistry(config, client)
+
+	tests := []struct {
+		model    string
+		expected []int
+	}{
+		{"gpt2", []int{11}},
+		{"llama", []int{1, 7, 0}},
+		// Models not configured or failed to load use the default tokenizer.
+		{"broken", []int{'h', 'e', 'l', 'l', 'o'}},
+		{"unknown", []int{'h', 'e', 'l', 'l', 'o'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			tk, ok := registry.Lookup(tt.model)
+			assert.True(t, ok)
+			tokens, err := tk.TokenizeInputText("hello")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestRegistryWithoutDefault(t *testing.T) {
+	registry := NewRegistry(Config{Models: map[string]Source{"m1": {Type: TypeCharacter}}}, nil)
+	_, ok := registry.Lookup("m1")
+	assert.True(t, ok)
+	_, ok = registry.Lookup("m2")
+	assert.False(t, ok)
+
+	var nilRegistry *Registry
+	_, ok = nilRegistry.Lookup("m1")
+	assert.False(t, ok)
+}
+
+func TestRegistryChatTemplate(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "tokenizer_config.json")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{"bos_token": "<s>", "chat_template": "{{ bos_token }}{{ messages[0].content }}"}`), 0o600))
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "aibrix-system", Name: "chatml"},
+		Data:       map[string]string{"template.jinja": "<|im_start|>{{ messages[0].content }}"},
+	})
+	registry := NewRegistry(Config{Models: map[string]Source{
+		"llama": {Type: TypeCharacter, ChatTemplatePath: configPath},
+		"qwen":  {ConfigMap: "aibrix-system/chatml", ChatTemplateKey: "template.jinja"},
+	}}, client)
+
+	request := []byte(`{"messages": [{"role": "user", "content": "Hi"}]}`)
+	for model, expected := range map[string]string{"llama": "<s>Hi", "qwen": "<|im_start|>Hi"} {
+		template, ok := registry.LookupChatTemplate(model)
+		assert.True(t, ok, model)
+		prompt, err := template.RenderRequest(request)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, prompt)
+	}
+	_, ok := registry.LookupChatTemplate("unknown")
+	assert.False(t, ok)
+}
@@ -17,10 +17,7 @@ limitations under the License.
 package tokenizer
 
 import (
-	"bytes"
-	"encoding/binary"
-
-	"k8s.io/klog/v2"
+	"sync"
 
 	"github.com/pkoukk/tiktoken-go"
 	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
@@ -29,32 +26,32 @@ import (
 // https://cookbook.openai.com/examples/how_to_count_tokens_with_tiktoken
 const encoding = "cl100k_base"
 
+var (
+	tiktokenOnce     sync.Once
+	tiktokenEncoding *tiktoken.Tiktoken
+	tiktokenErr      error
+)
+
 type tiktokenTokenizer struct{}
 
 func NewTiktokenTokenizer() Tokenizer {
 	return &tiktokenTokenizer{}
 }
 
-func (s tiktokenTokenizer) TokenizeInputText(text string) ([]byte, error) {
-	// if you don't want download dictionary at runtime, you can use offline loader
-	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
-	tke, err := tiktoken.GetEncoding(encoding)
+func (s tiktokenTokenizer) TokenizeInputText(text string) ([]int, error) {
+	tke, err := getTiktokenEncoding()
 	if err != nil {
 		return nil, err
 	}
-
-	// encode
-	token := tke.Encode(text, nil, nil)
-	return intToByteArray(token), nil
+	return tke.Encode(text, nil, nil), nil
 }
 
-func intToByteArray(intArray []int) []byte {
-	var buf bytes.Buffer
-	for _, num := range intArray {
-		err := binary.Write(&buf, binary.BigEndian, int32(num))
-		if err != nil {
-			klog.ErrorS(err, "binary.Write failed")
-		}
-	}
-	return buf.Bytes()
+// getTiktokenEncoding loads the encoding once, it is shared by all tiktoken tokenizers.
+func getTiktokenEncoding() (*tiktoken.Tiktoken, error) {
+	tiktokenOnce.Do(func() {
+		// if you don't want download dictionary at runtime, you can use offline loader
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+		tiktokenEncoding, tiktokenErr = tiktoken.GetEncoding(encoding)
+	})
+	return tiktokenEncoding, tiktokenErr
 }
@@ -16,6 +16,7 @@ limitations under the License.
 
 package tokenizer
 
+// Tokenizer encodes text into token ids, it must be safe for concurrent use.
 type Tokenizer interface {
-	TokenizeInputText(string) ([]byte, error)
+	TokenizeInputText(string) ([]int, error)
 }
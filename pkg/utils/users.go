@@ -19,15 +19,83 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// FixedWindowRateLimiter counts rpm and tpm in 1-minute windows, it is the default limiter.
+	FixedWindowRateLimiter = "fixed-window"
+	// TokenBucketRateLimiter refills rpm and tpm continuously and allows bursts.
+	TokenBucketRateLimiter = "token-bucket"
+)
+
+const (
+	PriorityClassHigh   = "high"
+	PriorityClassNormal = "normal"
+	PriorityClassLow    = "low"
+)
+
+const (
+	userIndexKey = "aibrix-user-index"
+	// Usage keys outlive their period, so that the usage of the last period can still be read.
+	dailyUsageTTL   = 48 * time.Hour
+	monthlyUsageTTL = 62 * 24 * time.Hour
+)
+
+// User is a tenant of the gateway, requests are authenticated as a user by its api keys or the user header.
 type User struct {
 	Name string `json:"name" validate:"required"`
 	Rpm  int64  `json:"rpm"`
 	Tpm  int64  `json:"tpm"`
+	// RateLimiter picks the limiter applied to rpm and tpm, either fixed-window (default) or token-bucket.
+	RateLimiter string `json:"rateLimiter,omitempty"`
+	// Burst is the request bucket size of the token-bucket limiter, defaults to rpm.
+	Burst int64 `json:"burst,omitempty"`
+	// MaxConcurrency limits the in-flight requests of the user, 0 means unlimited.
+	MaxConcurrency int64 `json:"maxConcurrency,omitempty"`
+	// Models is the allowlist of models the user can request, empty allows all models.
+	Models []string `json:"models,omitempty"`
+	// DailyTokenQuota and MonthlyTokenQuota limit the total tokens per UTC day and month, 0 means unlimited.
+	DailyTokenQuota   int64 `json:"dailyTokenQuota,omitempty"`
+	MonthlyTokenQuota int64 `json:"monthlyTokenQuota,omitempty"`
+	// PriorityClass is the priority of the user's requests, either high, normal (default) or low.
+	PriorityClass string `json:"priorityClass,omitempty"`
+	// ResponseCache opts the user in to the response cache of the gateway, if it is enabled.
+	ResponseCache bool `json:"responseCache,omitempty"`
+}
+
+// AllowsModel returns true if the model is in the user's allowlist or the allowlist is empty.
+func (u User) AllowsModel(model string) bool {
+	return len(u.Models) == 0 || slices.Contains(u.Models, model)
+}
+
+// ValidateUser checks the limits, quotas and enums of a user.
+func ValidateUser(u User) error {
+	if u.Name == "" {
+		return fmt.Errorf("name can not be empty")
+	}
+	if u.Rpm < 0 || u.Tpm < 0 {
+		return fmt.Errorf("rpm or tpm can not negative")
+	}
+	if u.Burst < 0 || u.MaxConcurrency < 0 {
+		return fmt.Errorf("burst or maxConcurrency can not negative")
+	}
+	if u.DailyTokenQuota < 0 || u.MonthlyTokenQuota < 0 {
+		return fmt.Errorf("dailyTokenQuota or monthlyTokenQuota can not negative")
+	}
+	if u.RateLimiter != "" && u.RateLimiter != FixedWindowRateLimiter && u.RateLimiter != TokenBucketRateLimiter {
+		return fmt.Errorf("unknown rateLimiter: %s, supported: %s, %s", u.RateLimiter, FixedWindowRateLimiter, TokenBucketRateLimiter)
+	}
+	if u.PriorityClass != "" && u.PriorityClass != PriorityClassHigh && u.PriorityClass != PriorityClassNormal && u.PriorityClass != PriorityClassLow {
+		return fmt.Errorf("unknown priorityClass: %s, supported: %s, %s, %s", u.PriorityClass, PriorityClassHigh, PriorityClassNormal, PriorityClassLow)
+	}
+	return nil
 }
 
 func CheckUser(ctx context.Context, u User, redisClient *redis.Client) bool {
@@ -54,8 +122,8 @@ func GetUser(ctx context.Context, u User, redisClient *redis.Client) (User, erro
 }
 
 func SetUser(ctx context.Context, u User, redisClient *redis.Client) error {
-	if u.Rpm < 0 || u.Tpm < 0 {
-		return fmt.Errorf("rpm or tpm can not negative")
+	if err := ValidateUser(u); err != nil {
+		return err
 	}
 
 	b, err := json.Marshal(&u)
@@ -63,13 +131,133 @@ func SetUser(ctx context.Context, u User, redisClient *redis.Client) error {
 		return err
 	}
 
-	return redisClient.Set(ctx, genKey(u.Name), string(b), 0).Err()
+	pipe := redisClient.TxPipeline()
+	pipe.Set(ctx, genKey(u.Name), string(b), 0)
+	pipe.ZAdd(ctx, userIndexKey, redis.Z{Member: u.Name})
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
+// DelUser deletes the user together with its api keys.
 func DelUser(ctx context.Context, u User, redisClient *redis.Client) error {
-	return redisClient.Del(ctx, genKey(u.Name)).Err()
+	if err := DelUserAPIKeys(ctx, u.Name, redisClient); err != nil {
+		return err
+	}
+
+	pipe := redisClient.TxPipeline()
+	pipe.Del(ctx, genKey(u.Name))
+	pipe.ZRem(ctx, userIndexKey, u.Name)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ListUsers returns at most limit users ordered by name, starting after the given name.
+// The returned bool is true if there are more users to list.
+func ListUsers(ctx context.Context, after string, limit int64, redisClient *redis.Client) ([]User, bool, error) {
+	names, hasMore, err := listIndex(ctx, userIndexKey, after, limit, redisClient)
+	if err != nil || len(names) == 0 {
+		return []User{}, false, err
+	}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, genKey(name))
+	}
+	vals, err := redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	users := make([]User, 0, len(vals))
+	for _, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			// Deleted after it was listed.
+			continue
+		}
+		var user User
+		if err := json.Unmarshal([]byte(str), &user); err != nil {
+			return nil, false, err
+		}
+		users = append(users, user)
+	}
+	return users, hasMore, nil
+}
+
+// IndexUsers adds users created before the user index existed to the index.
+func IndexUsers(ctx context.Context, redisClient *redis.Client) error {
+	iter := redisClient.Scan(ctx, 0, genKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		name := iter.Val()[len(genKey("")):]
+		if err := redisClient.ZAdd(ctx, userIndexKey, redis.Z{Member: name}).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// GetTokenUsage returns the tokens used by the user in the UTC day and month of now.
+func GetTokenUsage(ctx context.Context, name string, now time.Time, redisClient *redis.Client) (int64, int64, error) {
+	vals, err := redisClient.MGet(ctx, genDailyUsageKey(name, now), genMonthlyUsageKey(name, now)).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	usage := make([]int64, len(vals))
+	for i, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+		if usage[i], err = strconv.ParseInt(str, 10, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return usage[0], usage[1], nil
+}
+
+// IncrTokenUsage adds tokens to the daily and monthly usage of the user, negative tokens refund the usage.
+func IncrTokenUsage(ctx context.Context, name string, tokens int64, now time.Time, redisClient *redis.Client) error {
+	pipe := redisClient.Pipeline()
+	pipe.IncrBy(ctx, genDailyUsageKey(name, now), tokens)
+	pipe.Expire(ctx, genDailyUsageKey(name, now), dailyUsageTTL)
+	pipe.IncrBy(ctx, genMonthlyUsageKey(name, now), tokens)
+	pipe.Expire(ctx, genMonthlyUsageKey(name, now), monthlyUsageTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// listIndex pages through a sorted set whose members share the same score, i.e. ordered lexicographically.
+func listIndex(ctx context.Context, key, after string, limit int64, redisClient *redis.Client) ([]string, bool, error) {
+	minMember := "-"
+	if after != "" {
+		minMember = "(" + after
+	}
+	members, err := redisClient.ZRangeByLex(ctx, key, &redis.ZRangeBy{
+		Min:   minMember,
+		Max:   "+",
+		Count: limit + 1,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return []string{}, false, nil
+		}
+		return nil, false, err
+	}
+	if int64(len(members)) > limit {
+		return members[:limit], true, nil
+	}
+	return members, false, nil
 }
 
 func genKey(s string) string {
 	return fmt.Sprintf("aibrix-users/%s", s)
 }
+
+func genDailyUsageKey(name string, now time.Time) string {
+	return fmt.Sprintf("aibrix-usage/%s/daily/%s", name, now.UTC().Format("2006-01-02"))
+}
+
+func genMonthlyUsageKey(name string, now time.Time) string {
+	return fmt.Sprintf("aibrix-usage/%s/monthly/%s", name, now.UTC().Format("2006-01"))
+}
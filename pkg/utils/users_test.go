@@ -0,0 +1,60 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUser(t *testing.T) {
+	testCases := []struct {
+		name    string
+		user    User
+		wantErr bool
+	}{
+		{name: "minimal user", user: User{Name: "u"}},
+		{name: "full user", user: User{Name: "u", Rpm: 10, Tpm: 100, RateLimiter: TokenBucketRateLimiter, Burst: 5, MaxConcurrency: 2,
+			Models: []string{"m"}, DailyTokenQuota: 1000, MonthlyTokenQuota: 10000, PriorityClass: PriorityClassHigh}},
+		{name: "empty name", user: User{}, wantErr: true},
+		{name: "negative rpm", user: User{Name: "u", Rpm: -1}, wantErr: true},
+		{name: "negative quota", user: User{Name: "u", DailyTokenQuota: -1}, wantErr: true},
+		{name: "unknown rate limiter", user: User{Name: "u", RateLimiter: "sliding-window"}, wantErr: true},
+		{name: "unknown priority class", user: User{Name: "u", PriorityClass: "urgent"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateUser(tc.user)
+			assert.Equal(t, tc.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestUserAllowsModel(t *testing.T) {
+	assert.True(t, User{Name: "u"}.AllowsModel("m1"))
+	assert.True(t, User{Name: "u", Models: []string{"m1", "m2"}}.AllowsModel("m2"))
+	assert.False(t, User{Name: "u", Models: []string{"m1"}}.AllowsModel("m2"))
+}
+
+func TestHashAPIKey(t *testing.T) {
+	hash := HashAPIKey("sk-test")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashAPIKey("sk-test"))
+	assert.NotEqual(t, hash, HashAPIKey("sk-test2"))
+}
@@ -120,3 +120,18 @@ func LoadEnvFloat(key string, defaultValue float64) float64 {
 	klog.Infof("set %s: %g, using default value", key, defaultValue)
 	return defaultValue
 }
+
+func LoadEnvBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr != "" {
+		value, err := strconv.ParseBool(valueStr)
+		if err != nil {
+			klog.Warningf("invalid %s: %s, falling back to default: %t", key, valueStr, defaultValue)
+		} else {
+			klog.Infof("set %s: %t", key, value)
+			return value
+		}
+	}
+	klog.Infof("set %s: %t, using default value", key, defaultValue)
+	return defaultValue
+}
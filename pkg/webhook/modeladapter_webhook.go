@@ -20,10 +20,18 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"slices"
+	"strconv"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
@@ -31,14 +39,26 @@ import (
 	"github.com/vllm-project/aibrix/pkg/utils"
 )
 
-type ModelAdapterWebhook struct{}
+const (
+	// modelIdentifier is the label of the model served by a pod.
+	modelIdentifier = "model.aibrix.ai/name"
+
+	defaultSchedulerName = "default"
+	defaultReplicas      = 1
+)
+
+type ModelAdapterWebhook struct {
+	// client reads the pods and secrets the model adapters refer to.
+	client client.Reader
+}
 
 // SetupModelAdapterWebhook will setup the manager to manage the ModelAdapter webhook
 func SetupModelAdapterWebhook(mgr ctrl.Manager) error {
+	w := &ModelAdapterWebhook{client: mgr.GetAPIReader()}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&modelapi.ModelAdapter{}).
-		WithDefaulter(&ModelAdapterWebhook{}).
-		WithValidator(&ModelAdapterWebhook{}).
+		WithDefaulter(w).
+		WithValidator(w).
 		Complete()
 }
 
@@ -48,6 +68,16 @@ var _ webhook.CustomDefaulter = &ModelAdapterWebhook{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (w *ModelAdapterWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	adapter, ok := obj.(*modelapi.ModelAdapter)
+	if !ok {
+		return fmt.Errorf("expected a ModelAdapter but got a %T", obj)
+	}
+	if adapter.Spec.SchedulerName == "" {
+		adapter.Spec.SchedulerName = defaultSchedulerName
+	}
+	if adapter.Spec.Replicas == nil {
+		adapter.Spec.Replicas = ptr.To[int32](defaultReplicas)
+	}
 	return nil
 }
 
@@ -57,10 +87,55 @@ var _ webhook.CustomValidator = &ModelAdapterWebhook{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (w *ModelAdapterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	var allErrs field.ErrorList
+	adapter, ok := obj.(*modelapi.ModelAdapter)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAdapter but got a %T", obj)
+	}
+
+	allErrs := validateModelAdapterSpec(adapter)
+	if len(allErrs) == 0 {
+		// the references are only resolved on creation, the objects they refer to may come and go later.
+		allErrs = append(allErrs, w.validateReferences(ctx, adapter)...)
+	}
+	return modelAdapterWarnings(adapter), allErrs.ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (w *ModelAdapterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldAdapter, ok := oldObj.(*modelapi.ModelAdapter)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAdapter but got a %T", oldObj)
+	}
+	adapter, ok := newObj.(*modelapi.ModelAdapter)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAdapter but got a %T", newObj)
+	}
+	if adapter.DeletionTimestamp != nil {
+		// do not block the removal of the finalizer.
+		return nil, nil
+	}
+
+	allErrs := validateModelAdapterSpec(adapter)
+	// the loaded adapters are not reloaded, another artifact or base model takes a new ModelAdapter.
 	specPath := field.NewPath("spec")
+	if adapter.Spec.ArtifactURL != oldAdapter.Spec.ArtifactURL {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("artifactURL"), "artifactURL is immutable, create a new ModelAdapter to load another artifact"))
+	}
+	if !ptr.Equal(adapter.Spec.BaseModel, oldAdapter.Spec.BaseModel) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("baseModel"), "baseModel is immutable, create a new ModelAdapter for another base model"))
+	}
+	return modelAdapterWarnings(adapter), allErrs.ToAggregate()
+}
 
-	adapter := obj.(*modelapi.ModelAdapter)
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (w *ModelAdapterWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateModelAdapterSpec validates the spec of a ModelAdapter on its own.
+func validateModelAdapterSpec(adapter *modelapi.ModelAdapter) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
 
 	if _, err := url.ParseRequestURI(adapter.Spec.ArtifactURL); err != nil {
 		allErrs = append(allErrs, field.Invalid(specPath.Child("artifactURL"), adapter.Spec.ArtifactURL, fmt.Sprintf("artifactURL is invalid: %v", err)))
@@ -74,15 +149,68 @@ func (w *ModelAdapterWebhook) ValidateCreate(ctx context.Context, obj runtime.Ob
 		allErrs = append(allErrs, field.NotSupported(specPath.Child("artifactURL"), adapter.Spec.ArtifactURL, utils.AllowedSchemas))
 	}
 
-	return nil, allErrs.ToAggregate()
+	if adapter.Spec.PodSelector == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("podSelector"), "podSelector is required"))
+	} else if _, err := metav1.LabelSelectorAsSelector(adapter.Spec.PodSelector); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("podSelector"), adapter.Spec.PodSelector, err.Error()))
+	}
+
+	if autoscaling := adapter.Spec.Autoscaling; autoscaling != nil {
+		autoscalingPath := specPath.Child("autoscaling")
+		if autoscaling.MaxReplicas < 1 || autoscaling.MaxReplicas < autoscaling.MinReplicas {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("maxReplicas"), autoscaling.MaxReplicas, "maxReplicas must be at least 1 and minReplicas"))
+		}
+		if autoscaling.TargetRequestsPerSecond != "" {
+			if target, err := strconv.ParseFloat(autoscaling.TargetRequestsPerSecond, 64); err != nil || target <= 0 {
+				allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("targetRequestsPerSecond"), autoscaling.TargetRequestsPerSecond, "targetRequestsPerSecond must be a number greater than 0"))
+			}
+		}
+	}
+
+	return allErrs
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (w *ModelAdapterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+// validateReferences validates that the pods of the base model and the secrets a ModelAdapter refers to exist.
+func (w *ModelAdapterWebhook) validateReferences(ctx context.Context, adapter *modelapi.ModelAdapter) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if baseModel := adapter.Spec.BaseModel; baseModel != nil {
+		selector, _ := metav1.LabelSelectorAsSelector(adapter.Spec.PodSelector)
+		pods := &corev1.PodList{}
+		if err := w.client.List(ctx, pods, client.InNamespace(adapter.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			allErrs = append(allErrs, field.InternalError(specPath.Child("podSelector"), err))
+		} else if !slices.ContainsFunc(pods.Items, func(pod corev1.Pod) bool { return pod.Labels[modelIdentifier] == *baseModel }) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("podSelector"), adapter.Spec.PodSelector,
+				fmt.Sprintf("podSelector matches no pod of the base model %s labeled %s", *baseModel, modelIdentifier)))
+		}
+	}
+
+	if ref := adapter.Spec.CredentialsSecretRef; ref != nil {
+		allErrs = append(allErrs, w.validateSecret(ctx, adapter.Namespace, ref.Name, specPath.Child("credentialsSecretRef", "name"))...)
+	}
+	if name, ok := adapter.Spec.AdditionalConfig[modelapi.ModelAdapterAPIKeySecretConfig]; ok {
+		allErrs = append(allErrs, w.validateSecret(ctx, adapter.Namespace, name, specPath.Child("additionalConfig").Key(modelapi.ModelAdapterAPIKeySecretConfig))...)
+	}
+	return allErrs
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (w *ModelAdapterWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+func (w *ModelAdapterWebhook) validateSecret(ctx context.Context, namespace, name string, fldPath *field.Path) field.ErrorList {
+	secret := &corev1.Secret{}
+	if err := w.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return field.ErrorList{field.NotFound(fldPath, name)}
+		}
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	return nil
+}
+
+// modelAdapterWarnings warns about the api key of the inference engines in plain text.
+func modelAdapterWarnings(adapter *modelapi.ModelAdapter) admission.Warnings {
+	if _, ok := adapter.Spec.AdditionalConfig[modelapi.ModelAdapterAPIKeyConfig]; !ok {
+		return nil
+	}
+	return admission.Warnings{fmt.Sprintf("spec.additionalConfig[%s] holds the api key in plain text, store it under the key %s of a Secret and set spec.additionalConfig[%s] to the name of the Secret instead",
+		modelapi.ModelAdapterAPIKeyConfig, modelapi.ModelAdapterAPIKeyConfig, modelapi.ModelAdapterAPIKeySecretConfig)}
 }
@@ -42,6 +42,9 @@ class DownloadExtraConfig:
     hf_token: Optional[str] = None
     hf_revision: Optional[str] = None
 
+    # Auth config for http(s)
+    http_bearer_token: Optional[str] = None
+
     # parrallel config
     num_threads: Optional[int] = None
     max_io_queue: Optional[int] = None
@@ -231,6 +234,12 @@ def get_downloader(
             return TOSDownloaderV2(
                 model_uri, model_name, download_config, enable_progress_bar
             )
+    elif re.match(envs.DOWNLOADER_HTTP_REGEX, model_uri):
+        from aibrix.downloader.http import HTTPDownloader
+
+        return HTTPDownloader(
+            model_uri, model_name, download_config, enable_progress_bar
+        )
     else:
         from aibrix.downloader.huggingface import HuggingFaceDownloader
 
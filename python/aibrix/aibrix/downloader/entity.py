@@ -31,6 +31,7 @@ class RemoteSource(Enum):
     S3 = "s3"
     TOS = "tos"
     HUGGINGFACE = "huggingface"
+    HTTP = "http"
     UNKNOWN = "unknown"
 
     def __str__(self):
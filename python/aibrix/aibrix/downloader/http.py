@@ -0,0 +1,154 @@
+# Copyright 2024 The Aibrix Team.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import tarfile
+import zipfile
+from contextlib import nullcontext
+from pathlib import Path
+from typing import ClassVar, List, Optional
+from urllib.parse import urlparse
+
+import httpx
+from tqdm import tqdm
+
+from aibrix.common.errors import ArgNotCongiuredError, ArgNotFormatError
+from aibrix.downloader.base import (
+    DEFAULT_DOWNLOADER_EXTRA_CONFIG,
+    BaseDownloader,
+    DownloadExtraConfig,
+)
+from aibrix.downloader.entity import RemoteSource, get_local_download_paths
+from aibrix.downloader.utils import (
+    infer_model_name,
+    meta_file,
+    need_to_download,
+    save_meta_data,
+)
+from aibrix.logger import init_logger
+
+logger = init_logger(__name__)
+
+TAR_SUFFIXES = (".tar", ".tar.gz", ".tgz")
+ZIP_SUFFIXES = (".zip",)
+DOWNLOAD_CHUNK_SIZE = 1024 * 1024
+
+
+def _check_archive_member(local_path: Path, name: str):
+    target = local_path.joinpath(name).resolve()
+    if not target.is_relative_to(local_path.resolve()):
+        raise ValueError(f"Archive member {name} is outside of {local_path}.")
+
+
+def extract_archive(archive: Path, local_path: Path):
+    """Extract a tar or zip archive to local_path, other files are left as is."""
+    if archive.name.endswith(TAR_SUFFIXES):
+        with tarfile.open(archive) as tar:
+            for member in tar.getmembers():
+                if not (member.isfile() or member.isdir()):
+                    raise ValueError(f"Archive member {member.name} is not a file.")
+                _check_archive_member(local_path, member.name)
+            tar.extractall(local_path)
+    elif archive.name.endswith(ZIP_SUFFIXES):
+        with zipfile.ZipFile(archive) as zip_file:
+            for name in zip_file.namelist():
+                _check_archive_member(local_path, name)
+            zip_file.extractall(local_path)
+
+
+class HTTPDownloader(BaseDownloader):
+    """Downloader of a single file from an http(s) url. Tar and zip archives are
+    extracted to the model directory once downloaded."""
+
+    _source: ClassVar[RemoteSource] = RemoteSource.HTTP
+
+    def __init__(
+        self,
+        model_uri: str,
+        model_name: Optional[str] = None,
+        download_extra_config: DownloadExtraConfig = DEFAULT_DOWNLOADER_EXTRA_CONFIG,
+        enable_progress_bar: bool = False,
+    ):
+        bucket_path = urlparse(model_uri).path
+        if model_name is None:
+            model_name = infer_model_name(bucket_path)
+            logger.info(f"model_name is not set, using `{model_name}` as model_name")
+
+        self.download_extra_config = download_extra_config
+        token = self.download_extra_config.http_bearer_token
+        headers = {"Authorization": f"Bearer {token}"} if token else {}
+        self.client = httpx.Client(headers=headers, follow_redirects=True)
+
+        super().__init__(
+            model_uri=model_uri,
+            model_name=model_name,
+            bucket_path=bucket_path,
+            bucket_name=None,
+            download_extra_config=download_extra_config,
+            enable_progress_bar=enable_progress_bar,
+        )  # type: ignore
+
+    def _valid_config(self):
+        if self.model_name is None or self.model_name == "":
+            raise ArgNotCongiuredError(arg_name="model_name", arg_source="--model-name")
+
+        if self.bucket_path == "" or self.bucket_path.endswith("/"):
+            raise ArgNotFormatError(
+                arg_name="model_uri", expected_format="http(s)://host/path/file"
+            )
+
+    def _is_directory(self) -> bool:
+        """model_uri of http(s) is a single file."""
+        return False
+
+    def _directory_list(self, path: str) -> List[str]:
+        return [path]
+
+    def _support_range_download(self) -> bool:
+        return False
+
+    def download(
+        self,
+        local_path: Path,
+        bucket_path: str,
+        bucket_name: Optional[str] = None,
+        enable_range: bool = True,
+    ):
+        _file_name = bucket_path.split("/")[-1]
+        local_file = local_path.joinpath(_file_name).absolute()
+        meta_data_file = meta_file(
+            local_path=local_path, file_name=_file_name, source=self._source.value
+        )
+
+        # presigned urls may not be signed for HEAD, check the headers of GET.
+        with self.client.stream("GET", self.model_uri) as response:
+            response.raise_for_status()
+            etag = response.headers.get("ETag", "")
+            file_size = int(response.headers.get("Content-Length", 0))
+            if not need_to_download(local_file, meta_data_file, file_size, etag):
+                return
+
+            with tqdm(
+                desc=_file_name, total=file_size, unit="b", unit_scale=True
+            ) if self.enable_progress_bar else nullcontext() as pbar:
+                download_file = get_local_download_paths(
+                    local_path, _file_name, self._source
+                )
+                with download_file.download_lock():
+                    with open(local_file, "wb") as f:
+                        for chunk in response.iter_bytes(DOWNLOAD_CHUNK_SIZE):
+                            f.write(chunk)
+                            if pbar is not None:
+                                pbar.update(len(chunk))
+                    extract_archive(local_file, local_path)
+                    save_meta_data(meta_data_file, etag)
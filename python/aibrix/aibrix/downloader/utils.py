@@ -11,9 +11,10 @@
 # WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 # See the License for the specific language governing permissions and
 # limitations under the License.
+import hashlib
 import os
 from pathlib import Path
-from typing import Union
+from typing import List, Union
 
 from aibrix import envs
 from aibrix.config import DOWNLOAD_CACHE_DIR
@@ -99,3 +100,31 @@ def infer_model_name(uri: str):
         raise ValueError("Model uri is empty.")
 
     return uri.strip().strip("/").split("/")[-1]
+
+
+def file_sha256(file_path: Union[Path, str]) -> str:
+    sha256 = hashlib.sha256()
+    with open(file_path, "rb") as f:
+        for chunk in iter(lambda: f.read(1024 * 1024), b""):
+            sha256.update(chunk)
+    return sha256.hexdigest()
+
+
+def compute_checksum(root: Union[Path, str], files: List[Union[Path, str]]) -> str:
+    """Compute the checksum of downloaded files in `sha256:<hex>`.
+
+    The checksum of a single file is its sha256, otherwise it is the sha256 of
+    the `sha256sum` listing of the files sorted by their paths relative to root,
+    the same as
+    `find . -type f -printf '%P\\n' | LC_ALL=C sort | xargs sha256sum | sha256sum`
+    in the root of the files.
+    """
+    if len(files) == 1:
+        return f"sha256:{file_sha256(files[0])}"
+
+    relative_paths = sorted(Path(file).relative_to(root).as_posix() for file in files)
+    listing = "".join(
+        f"{file_sha256(Path(root).joinpath(path))}  {path}\n"
+        for path in relative_paths
+    )
+    return f"sha256:{hashlib.sha256(listing.encode()).hexdigest()}"
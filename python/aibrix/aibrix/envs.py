@@ -65,6 +65,7 @@ DOWNLOADER_CHECK_FILE_EXIST = _is_true(os.getenv("DOWNLOADER_CHECK_FILE_EXIST",
 # Downloader Regex
 DOWNLOADER_S3_REGEX = r"^s3://"
 DOWNLOADER_TOS_REGEX = r"^tos://"
+DOWNLOADER_HTTP_REGEX = r"^https?://"
 
 # Downloader HuggingFace Envs
 DOWNLOADER_HF_TOKEN = os.getenv("HF_TOKEN")
@@ -12,6 +12,7 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import shutil
 from http import HTTPStatus
 from multiprocessing import Process
 from pathlib import Path
@@ -28,6 +29,7 @@ from aibrix.downloader.entity import (
     DownloadModel,
     ModelDownloadStatus,
 )
+from aibrix.downloader.utils import compute_checksum
 from aibrix.openapi.protocol import (
     DownloadModelRequest,
     ErrorResponse,
@@ -37,6 +39,35 @@ from aibrix.openapi.protocol import (
 )
 
 
+# VERIFIED_CHECKSUM_FILE records the checksum a downloaded model is verified with.
+VERIFIED_CHECKSUM_FILE = ".cache/checksum"
+
+
+def verify_checksum(model: DownloadModel, checksum: str) -> Optional[str]:
+    """Verify the checksum of the downloaded files of the model, the files are
+    removed if it mismatches so that they are downloaded again.
+
+    Returns the error message of a mismatch, None if the checksum matches.
+    """
+    model_path = model.model_root_path
+    verified_file = model_path.joinpath(VERIFIED_CHECKSUM_FILE)
+    if verified_file.exists() and verified_file.read_text() == checksum:
+        return None
+
+    files = [file.file_path for file in model.download_files]
+    actual = compute_checksum(model_path, files)
+    if actual != checksum:
+        shutil.rmtree(model_path, ignore_errors=True)
+        return (
+            f"Checksum of model {model.model_name} is {actual}, expected "
+            f"{checksum}, the downloaded files are removed"
+        )
+
+    verified_file.parent.mkdir(parents=True, exist_ok=True)
+    verified_file.write_text(checksum)
+    return None
+
+
 class ModelManager:
     @staticmethod
     async def model_download(
@@ -81,6 +112,19 @@ class ModelManager:
 
         model_path = local_path.joinpath(downloader.model_name_path)
         model_status = model.status if model else ModelDownloadStatus.NOT_EXIST
+        if (
+            model is not None
+            and model_status == ModelDownloadStatus.DOWNLOADED
+            and request.checksum is not None
+        ):
+            error = verify_checksum(model, request.checksum)
+            if error is not None:
+                return ErrorResponse(
+                    message=error,
+                    type="ChecksumMismatchError",
+                    code=HTTPStatus.UNPROCESSABLE_ENTITY.value,
+                )
+
         if model_status in [
             ModelDownloadStatus.DOWNLOADED,
             ModelDownloadStatus.DOWNLOADING,
@@ -50,6 +50,8 @@ class DownloadModelRequest(NoProtectedBaseModel):
     local_dir: Optional[str] = None
     model_name: Optional[str] = None
     download_extra_config: Optional[Dict] = None
+    # checksum of the downloaded files in `sha256:<hex>`, verified once downloaded
+    checksum: Optional[str] = None
 
 
 class ModelStatusCard(NoProtectedBaseModel):
@@ -0,0 +1,65 @@
+# Copyright 2024 The Aibrix Team.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import io
+import tarfile
+import tempfile
+from pathlib import Path
+
+import pytest
+
+from aibrix.common.errors import ArgNotFormatError
+from aibrix.downloader.base import get_downloader
+from aibrix.downloader.http import HTTPDownloader, extract_archive
+
+
+def test_get_downloader_http():
+    downloader = get_downloader(
+        "https://example.com/adapters/lora.tar.gz?signature=xyz",
+        download_extra_config={"http_bearer_token": "token"},
+    )
+    assert isinstance(downloader, HTTPDownloader)
+    assert downloader.model_name == "lora.tar.gz"
+    assert downloader.bucket_path == "/adapters/lora.tar.gz"
+    assert downloader.client.headers["Authorization"] == "Bearer token"
+
+
+def test_get_downloader_http_directory():
+    with pytest.raises(ArgNotFormatError) as exception:
+        get_downloader("https://example.com/adapters/", model_name="lora")
+    assert "Argument `model_uri` is not in the expected format" in str(
+        exception.value
+    )
+
+
+def _write_tar(archive: Path, name: str, content: bytes):
+    with tarfile.open(archive, "w:gz") as tar:
+        info = tarfile.TarInfo(name)
+        info.size = len(content)
+        tar.addfile(info, io.BytesIO(content))
+
+
+def test_extract_archive():
+    with tempfile.TemporaryDirectory() as tmp_dir:
+        local_path = Path(tmp_dir)
+        archive = local_path.joinpath("lora.tar.gz")
+        _write_tar(archive, "adapter_config.json", b"{}")
+        extract_archive(archive, local_path)
+        assert local_path.joinpath("adapter_config.json").read_bytes() == b"{}"
+
+        # members out of the model directory are rejected
+        _write_tar(archive, "../adapter_config.json", b"{}")
+        with pytest.raises(ValueError):
+            extract_archive(archive, local_path)
+        assert not local_path.parent.joinpath("adapter_config.json").exists()
@@ -24,6 +24,7 @@ from aibrix import envs
 from aibrix.config import DOWNLOAD_CACHE_DIR
 from aibrix.downloader.utils import (
     check_file_exist,
+    compute_checksum,
     infer_model_name,
     load_meta_data,
     meta_file,
@@ -154,3 +155,25 @@ def test_infer_model_name():
 
     model_name = infer_model_name("s3://bucket/path/to/model")
     assert model_name == "model"
+
+
+def test_compute_checksum():
+    with tempfile.TemporaryDirectory() as tmp_dir:
+        root = Path(tmp_dir)
+        root.joinpath("adapter_config.json").write_text("a\n")
+        root.joinpath("adapter_model.safetensors").write_text("bb\n")
+
+        # the checksum of a single file is its sha256
+        assert (
+            compute_checksum(root, [root.joinpath("adapter_config.json")])
+            == "sha256:87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7"
+        )
+        # otherwise the sha256 of the sha256sum listing of the files sorted by path
+        files = [
+            root.joinpath("adapter_model.safetensors"),
+            root.joinpath("adapter_config.json"),
+        ]
+        assert (
+            compute_checksum(root, files)
+            == "sha256:d7f053e6eab07cb458b4bdb9b361b5d794bf917290598d5509e217dfb82d1357"
+        )
@@ -158,5 +158,64 @@ var _ = ginkgo.Describe("modelAdapter default and validation", func() {
 			},
 			failed: true,
 		}),
+		ginkgo.Entry("adapter creation with no pod of the base model should be failed", &testValidatingCase{
+			adapter: func() *modelapi.ModelAdapter {
+				adapter := modelapi.ModelAdapter{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-adapter",
+						Namespace: ns.Name,
+					},
+					Spec: modelapi.ModelAdapterSpec{
+						BaseModel:   ptr.To("llama2-7b"),
+						ArtifactURL: "s3://test-bucket/test-model",
+						PodSelector: &metav1.LabelSelector{},
+					},
+				}
+				return &adapter
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("adapter creation with missing credentials secret should be failed", &testValidatingCase{
+			adapter: func() *modelapi.ModelAdapter {
+				adapter := modelapi.ModelAdapter{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-adapter",
+						Namespace: ns.Name,
+					},
+					Spec: modelapi.ModelAdapterSpec{
+						ArtifactURL:          "s3://test-bucket/test-model",
+						PodSelector:          &metav1.LabelSelector{},
+						CredentialsSecretRef: &corev1.LocalObjectReference{Name: "test-credentials"},
+					},
+				}
+				return &adapter
+			},
+			failed: true,
+		}),
 	)
+
+	ginkgo.It("test defaulting and updating", func() {
+		adapter := &modelapi.ModelAdapter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-adapter",
+				Namespace: ns.Name,
+			},
+			Spec: modelapi.ModelAdapterSpec{
+				ArtifactURL: "s3://test-bucket/test-model",
+				PodSelector: &metav1.LabelSelector{},
+			},
+		}
+		gomega.Expect(k8sClient.Create(ctx, adapter)).To(gomega.Succeed())
+		gomega.Expect(adapter.Spec.SchedulerName).To(gomega.Equal("default"))
+		gomega.Expect(adapter.Spec.Replicas).To(gomega.Equal(ptr.To[int32](1)))
+
+		adapter.Spec.Replicas = ptr.To[int32](2)
+		gomega.Expect(k8sClient.Update(ctx, adapter)).To(gomega.Succeed())
+
+		adapter.Spec.ArtifactURL = "s3://test-bucket/another-model"
+		gomega.Expect(k8sClient.Update(ctx, adapter)).Should(gomega.HaveOccurred())
+		adapter.Spec.ArtifactURL = "s3://test-bucket/test-model"
+		adapter.Spec.BaseModel = ptr.To("llama2-7b")
+		gomega.Expect(k8sClient.Update(ctx, adapter)).Should(gomega.HaveOccurred())
+	})
 })